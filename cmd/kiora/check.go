@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/cmd/kiora/config"
+	kconfig "github.com/sinkingpoint/kiora/lib/kiora/config"
+)
+
+// CheckCmd loads and validates config files, printing any errors and lint warnings. It fails if any of the configs
+// are invalid (or have warnings, in strict mode) so that it can be used in CI.
+type CheckCmd struct {
+	Files  []string `arg:"" optional:"" help:"the config files to check. Defaults to the --config.file"`
+	Strict bool     `help:"treat warnings as errors"`
+}
+
+func (c *CheckCmd) Run() error {
+	files := c.Files
+	if len(files) == 0 {
+		files = []string{CLI.ConfigFile}
+	}
+
+	errCount, warningCount := 0, 0
+	for _, file := range files {
+		conf, err := config.LoadConfigFile(file, zerolog.Nop(), kconfig.WithValidateOnly())
+		if err != nil {
+			errCount++
+			fmt.Fprintf(os.Stderr, "error: %s\n", config.FormatConfigError(file, err))
+			continue
+		}
+
+		// Invalid tenant configs don't stop the main config from loading, but they're still errors.
+		for _, tenantErr := range conf.TenantErrors() {
+			errCount++
+			fmt.Fprintf(os.Stderr, "error: %s\n", config.FormatConfigError(tenantErr.Path, tenantErr.Err))
+		}
+
+		configs := []*config.ConfigFile{conf}
+		for _, tenant := range conf.Tenants() {
+			tenantConf, _ := conf.TenantConfig(tenant)
+			configs = append(configs, tenantConf)
+		}
+
+		for _, conf := range configs {
+			warnings := conf.Lint()
+			for _, warning := range warnings {
+				fmt.Fprintf(os.Stderr, "warning: %s: %s\n", conf.Path(), warning)
+			}
+
+			warningCount += len(warnings)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "checked %d config file(s): %d error(s), %d warning(s)\n", len(files), errCount, warningCount)
+
+	if errCount > 0 {
+		return fmt.Errorf("%d config file(s) are invalid", errCount)
+	}
+
+	if c.Strict && warningCount > 0 {
+		return fmt.Errorf("found %d warning(s) in strict mode", warningCount)
+	}
+
+	return nil
+}
@@ -0,0 +1,570 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	pmodel "github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+)
+
+// alertmanagerConfig is the subset of an Alertmanager config that can be converted into a Kiora config.
+type alertmanagerConfig struct {
+	Global            map[string]interface{} `yaml:"global"`
+	Route             *alertmanagerRoute     `yaml:"route"`
+	Receivers         []alertmanagerReceiver `yaml:"receivers"`
+	InhibitRules      []interface{}          `yaml:"inhibit_rules"`
+	Templates         []string               `yaml:"templates"`
+	TimeIntervals     []interface{}          `yaml:"time_intervals"`
+	MuteTimeIntervals []interface{}          `yaml:"mute_time_intervals"`
+}
+
+type alertmanagerRoute struct {
+	Receiver            string               `yaml:"receiver"`
+	GroupBy             []string             `yaml:"group_by"`
+	GroupWait           string               `yaml:"group_wait"`
+	GroupInterval       string               `yaml:"group_interval"`
+	RepeatInterval      string               `yaml:"repeat_interval"`
+	Matchers            []string             `yaml:"matchers"`
+	Match               map[string]string    `yaml:"match"`
+	MatchRE             map[string]string    `yaml:"match_re"`
+	Continue            bool                 `yaml:"continue"`
+	MuteTimeIntervals   []string             `yaml:"mute_time_intervals"`
+	ActiveTimeIntervals []string             `yaml:"active_time_intervals"`
+	Routes              []*alertmanagerRoute `yaml:"routes"`
+}
+
+type alertmanagerReceiver struct {
+	Name         string
+	SlackConfigs []map[string]interface{}
+
+	// Integrations are the names of all the other integrations (e.g. `email_configs`) that the receiver has.
+	Integrations []string
+}
+
+func (r *alertmanagerReceiver) UnmarshalYAML(value *yaml.Node) error {
+	raw := map[string]yaml.Node{}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+
+	for key, node := range raw {
+		node := node
+		switch key {
+		case "name":
+			if err := node.Decode(&r.Name); err != nil {
+				return err
+			}
+		case "slack_configs":
+			if err := node.Decode(&r.SlackConfigs); err != nil {
+				return err
+			}
+		default:
+			r.Integrations = append(r.Integrations, key)
+		}
+	}
+
+	sort.Strings(r.Integrations)
+	return nil
+}
+
+// alertmanagerMatcherRegex matches a single Alertmanager matcher, e.g. `severity=~"critical|page"`.
+var alertmanagerMatcherRegex = regexp.MustCompile(`^\s*([a-zA-Z_:][a-zA-Z0-9_:]*)\s*(=~|!~|!=|=)\s*(.*?)\s*$`)
+
+// dotIDRegex matches names that can be used as node names in a config without quoting them.
+var dotIDRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// matcherFilter is an Alertmanager matcher, converted into the attributes of a regex filter.
+type matcherFilter struct {
+	field  string
+	regex  string
+	negate bool
+
+	// source is the original matcher, for warnings.
+	source string
+}
+
+func (m matcherFilter) negated() matcherFilter {
+	m.negate = !m.negate
+	return m
+}
+
+func (m matcherFilter) attrs() map[string]string {
+	attrs := map[string]string{
+		"type":  "regex",
+		"field": m.field,
+		"regex": m.regex,
+	}
+
+	if m.negate {
+		attrs["negate"] = "true"
+	}
+
+	return attrs
+}
+
+// dotStmt is a node or an edge in the generated config.
+type dotStmt struct {
+	from, to string
+	attrs    map[string]string
+}
+
+// alertmanagerConverter builds a Kiora config out of an Alertmanager one.
+type alertmanagerConverter struct {
+	conf     alertmanagerConfig
+	nodes    []dotStmt
+	edges    []dotStmt
+	warnings []string
+
+	// names are the names of all the nodes that have been created, to keep them unique.
+	names HashSet
+
+	// receivers maps the names of Alertmanager receivers to the notifier nodes that were created for them.
+	receivers map[string][]string
+}
+
+// ConvertAlertmanagerConfig converts the given Alertmanager config into an equivalent Kiora config. Anything in the Alertmanager
+// config that can't be represented is described in the returned warnings.
+func ConvertAlertmanagerConfig(body []byte) (string, []string, error) {
+	converter := &alertmanagerConverter{
+		names:     HashSet{ALERT_ROOT: {}, SILENCES_LEAF: {}, ACK_LEAF: {}},
+		receivers: map[string][]string{},
+	}
+
+	if err := yaml.Unmarshal(body, &converter.conf); err != nil {
+		return "", nil, errors.Wrap(err, "failed to parse alertmanager config")
+	}
+
+	if converter.conf.Route == nil {
+		return "", nil, errors.New("alertmanager config has no route")
+	}
+
+	converter.convertGlobals()
+	converter.convertReceivers()
+	if err := converter.convertRoute(converter.conf.Route, ALERT_ROOT, "route", ""); err != nil {
+		return "", nil, err
+	}
+
+	converter.prune()
+
+	return converter.dot(), converter.warnings, nil
+}
+
+// prune removes nodes that don't lead to any notifiers, e.g. routes to receivers that don't have any supported integrations.
+func (c *alertmanagerConverter) prune() {
+	notifiers := HashSet{}
+	for _, names := range c.receivers {
+		for _, name := range names {
+			notifiers[name] = struct{}{}
+		}
+	}
+
+	for {
+		hasOutgoing := HashSet{}
+		for _, edge := range c.edges {
+			hasOutgoing[edge.from] = struct{}{}
+		}
+
+		dead := HashSet{}
+		nodes := c.nodes[:0]
+		for _, node := range c.nodes {
+			_, isNotifier := notifiers[node.from]
+			if _, ok := hasOutgoing[node.from]; ok || isNotifier {
+				nodes = append(nodes, node)
+			} else {
+				dead[node.from] = struct{}{}
+			}
+		}
+
+		c.nodes = nodes
+		if len(dead) == 0 {
+			return
+		}
+
+		edges := c.edges[:0]
+		for _, edge := range c.edges {
+			if _, ok := dead[edge.to]; !ok {
+				edges = append(edges, edge)
+			}
+		}
+
+		c.edges = edges
+	}
+}
+
+func (c *alertmanagerConverter) warn(format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+// uniqueName returns a valid node name based on the given name, that doesn't collide with any other node.
+func (c *alertmanagerConverter) uniqueName(base string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+
+		return '_'
+	}, base)
+
+	if !dotIDRegex.MatchString(name) {
+		name = "_" + name
+	}
+
+	unique := name
+	for i := 1; ; i++ {
+		if _, ok := c.names[unique]; !ok {
+			break
+		}
+
+		unique = fmt.Sprintf("%s_%d", name, i)
+	}
+
+	c.names[unique] = struct{}{}
+	return unique
+}
+
+func (c *alertmanagerConverter) addNode(base string, attrs map[string]string) string {
+	name := c.uniqueName(base)
+	c.nodes = append(c.nodes, dotStmt{from: name, attrs: attrs})
+	return name
+}
+
+func (c *alertmanagerConverter) addEdge(from, to string, attrs map[string]string) {
+	c.edges = append(c.edges, dotStmt{from: from, to: to, attrs: attrs})
+}
+
+// addChain adds edges from `from` to `to`, passing through each of the given filters in turn. Edges only have
+// one filter, so chains of filters go through anchors named after the given base.
+func (c *alertmanagerConverter) addChain(from, to, base string, filters []matcherFilter) {
+	for i, filter := range filters {
+		next := to
+		if i < len(filters)-1 {
+			next = c.addNode(fmt.Sprintf("%s_%d", base, i), nil)
+		}
+
+		c.addEdge(from, next, filter.attrs())
+		from = next
+	}
+
+	if len(filters) == 0 {
+		c.addEdge(from, to, nil)
+	}
+}
+
+func (c *alertmanagerConverter) convertGlobals() {
+	keys := make([]string, 0, len(c.conf.Global))
+	for key := range c.conf.Global {
+		if key != "slack_api_url" && key != "slack_api_url_file" {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		c.warn("global.%s is not supported", key)
+	}
+
+	if len(c.conf.InhibitRules) > 0 {
+		c.warn("inhibit_rules are not supported, so %d inhibit rule(s) were dropped", len(c.conf.InhibitRules))
+	}
+
+	if len(c.conf.Templates) > 0 {
+		c.warn("templates are not supported, so notifications will use the default templates")
+	}
+
+	if len(c.conf.TimeIntervals) > 0 || len(c.conf.MuteTimeIntervals) > 0 {
+		c.warn("time intervals are not supported, so alerts will be sent at all times")
+	}
+}
+
+func (c *alertmanagerConverter) convertReceivers() {
+	for _, receiver := range c.conf.Receivers {
+		for _, integration := range receiver.Integrations {
+			c.warn("receiver %q: %s are not supported", receiver.Name, integration)
+		}
+
+		for _, slackConfig := range receiver.SlackConfigs {
+			// The URL in the slack config overrides the global one, even if one is a file and the other isn't.
+			url, urlFile := slackConfig["api_url"], slackConfig["api_url_file"]
+			if url == nil && urlFile == nil {
+				url, urlFile = c.conf.Global["slack_api_url"], c.conf.Global["slack_api_url_file"]
+			}
+
+			attrs := map[string]string{"type": "slack"}
+			if url != nil {
+				attrs["api_url"] = fmt.Sprint(url)
+			}
+
+			if urlFile != nil {
+				attrs["api_url_file"] = fmt.Sprint(urlFile)
+			}
+
+			if attrs["api_url"] == "" && attrs["api_url_file"] == "" {
+				c.warn("receiver %q: slack config has no api_url, so it was dropped", receiver.Name)
+				continue
+			}
+
+			unsupported := []string{}
+			for key := range slackConfig {
+				if key != "api_url" && key != "api_url_file" {
+					unsupported = append(unsupported, key)
+				}
+			}
+
+			sort.Strings(unsupported)
+			for _, key := range unsupported {
+				c.warn("receiver %q: slack option %q is not supported", receiver.Name, key)
+			}
+
+			c.receivers[receiver.Name] = append(c.receivers[receiver.Name], c.addNode(receiver.Name, attrs))
+		}
+	}
+}
+
+// convertRoute adds the nodes and edges for the given route, and all its children. Alerts that match the route arrive at the `entry` node.
+func (c *alertmanagerConverter) convertRoute(route *alertmanagerRoute, entry, base, receiver string) error {
+	if route.Receiver != "" {
+		receiver = route.Receiver
+	}
+
+	if receiver != "" && !c.hasReceiver(receiver) {
+		return fmt.Errorf("%s references unknown receiver %q", base, receiver)
+	}
+
+	tail := entry
+	if len(route.GroupBy) > 0 {
+		if len(route.GroupBy) == 1 && route.GroupBy[0] == "..." {
+			c.warn("%s: grouping by all labels is not supported", base)
+		} else {
+			next := c.addNode(base+"_group_by", map[string]string{"type": "group_labels", "labels": strings.Join(route.GroupBy, ",")})
+			c.addEdge(tail, next, nil)
+			tail = next
+		}
+	}
+
+	if route.GroupWait != "" {
+		duration, err := pmodel.ParseDuration(route.GroupWait)
+		if err != nil {
+			return errors.Wrapf(err, "%s has an invalid group_wait", base)
+		}
+
+		next := c.addNode(base+"_group_wait", map[string]string{"type": "group_wait", "duration": time.Duration(duration).String()})
+		c.addEdge(tail, next, nil)
+		tail = next
+	}
+
+	if route.GroupInterval != "" {
+		c.warn("%s: group_interval is not supported", base)
+	}
+
+	if route.RepeatInterval != "" {
+		c.warn("%s: repeat_interval is not supported", base)
+	}
+
+	if len(route.MuteTimeIntervals) > 0 || len(route.ActiveTimeIntervals) > 0 {
+		c.warn("%s: time intervals are not supported", base)
+	}
+
+	children := make([][]matcherFilter, len(route.Routes))
+	for i, child := range route.Routes {
+		filters, err := c.convertMatchers(child, fmt.Sprintf("%s_%d", base, i))
+		if err != nil {
+			return err
+		}
+
+		children[i] = filters
+	}
+
+	for i, child := range route.Routes {
+		childBase := fmt.Sprintf("%s_%d", base, i)
+		filters := children[i]
+
+		// Alertmanager sends alerts to the first route that they match (unless the route has `continue` set), but Kiora sends alerts
+		// down every path that they match. To emulate that, exclude anything that an earlier route would have caught.
+		reachable := true
+		for j := 0; j < i; j++ {
+			if route.Routes[j].Continue {
+				continue
+			}
+
+			exclusion, ok := c.exclude(children[j], fmt.Sprintf("%s_%d", base, j), childBase)
+			if !ok {
+				reachable = false
+				break
+			}
+
+			filters = append(filters, exclusion...)
+		}
+
+		if !reachable {
+			continue
+		}
+
+		childEntry := c.addNode(childBase, nil)
+		c.addChain(tail, childEntry, childBase+"_match", filters)
+		if err := c.convertRoute(child, childEntry, childBase, receiver); err != nil {
+			return err
+		}
+	}
+
+	// Alerts only go to the route's receiver if they didn't match any of its children.
+	filters := []matcherFilter{}
+	for i, child := range children {
+		exclusion, ok := c.exclude(child, fmt.Sprintf("%s_%d", base, i), base)
+		if !ok {
+			return nil
+		}
+
+		filters = append(filters, exclusion...)
+	}
+
+	notifiers := c.receivers[receiver]
+	switch {
+	case len(notifiers) == 0:
+	case len(notifiers) == 1:
+		c.addChain(tail, notifiers[0], base+"_default", filters)
+	default:
+		anchor := c.addNode(base+"_default", nil)
+		c.addChain(tail, anchor, base+"_default", filters)
+		for _, notifier := range notifiers {
+			c.addEdge(anchor, notifier, nil)
+		}
+	}
+
+	return nil
+}
+
+// exclude returns filters that only let through alerts that don't match all the given filters. If that's not possible,
+// (i.e. the filters match everything), it returns false, and if it can't be represented a warning is added.
+func (c *alertmanagerConverter) exclude(filters []matcherFilter, excluded, route string) ([]matcherFilter, bool) {
+	switch len(filters) {
+	case 0:
+		return nil, false
+	case 1:
+		return []matcherFilter{filters[0].negated()}, true
+	default:
+		c.warn("%s: can't exclude alerts that match %s, because it has more than one matcher, so alerts may be sent to both", route, excluded)
+		return nil, true
+	}
+}
+
+func (c *alertmanagerConverter) hasReceiver(name string) bool {
+	for _, receiver := range c.conf.Receivers {
+		if receiver.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// convertMatchers converts all the matchers on the given route into regex filters.
+func (c *alertmanagerConverter) convertMatchers(route *alertmanagerRoute, base string) ([]matcherFilter, error) {
+	filters := []matcherFilter{}
+	for _, label := range sortedAttrKeys(route.Match) {
+		filters = append(filters, matcherFilter{field: label, regex: "^" + regexp.QuoteMeta(route.Match[label]) + "$", source: fmt.Sprintf("%s=%q", label, route.Match[label])})
+	}
+
+	for _, label := range sortedAttrKeys(route.MatchRE) {
+		filters = append(filters, matcherFilter{field: label, regex: "^(?:" + route.MatchRE[label] + ")$", source: fmt.Sprintf("%s=~%q", label, route.MatchRE[label])})
+	}
+
+	for _, rawMatcher := range route.Matchers {
+		filter, err := parseAlertmanagerMatcher(rawMatcher)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s has an invalid matcher", base)
+		}
+
+		filters = append(filters, filter)
+	}
+
+	for _, filter := range filters {
+		if _, err := regexp.Compile(filter.regex); err != nil {
+			return nil, errors.Wrapf(err, "%s has an invalid matcher %s", base, filter.source)
+		}
+
+		if !filter.negate && regexp.MustCompile(filter.regex).MatchString("") {
+			c.warn("%s: %s matches alerts without a %q label in Alertmanager, but only alerts with the label in Kiora", base, filter.source, filter.field)
+		}
+	}
+
+	return filters, nil
+}
+
+// parseAlertmanagerMatcher parses a single matcher, e.g. `severity!="warning"`.
+func parseAlertmanagerMatcher(rawMatcher string) (matcherFilter, error) {
+	match := alertmanagerMatcherRegex.FindStringSubmatch(rawMatcher)
+	if match == nil {
+		return matcherFilter{}, fmt.Errorf("failed to parse matcher %q", rawMatcher)
+	}
+
+	label, op, value := match[1], match[2], match[3]
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return matcherFilter{}, errors.Wrapf(err, "failed to parse matcher %q", rawMatcher)
+		}
+
+		value = unquoted
+	}
+
+	filter := matcherFilter{field: label, source: rawMatcher}
+	switch op {
+	case "=", "!=":
+		filter.regex = "^" + regexp.QuoteMeta(value) + "$"
+	case "=~", "!~":
+		filter.regex = "^(?:" + value + ")$"
+	}
+
+	filter.negate = op == "!=" || op == "!~"
+	return filter, nil
+}
+
+// dot renders the converted config.
+func (c *alertmanagerConverter) dot() string {
+	dot := strings.Builder{}
+	dot.WriteString("// Converted from an Alertmanager config with `kiora import alertmanager`.\n")
+	dot.WriteString("digraph config {\n")
+	for _, node := range c.nodes {
+		if len(node.attrs) == 0 {
+			fmt.Fprintf(&dot, "\t%s;\n", node.from)
+		} else {
+			fmt.Fprintf(&dot, "\t%s [%s];\n", node.from, formatDOTAttrs(node.attrs))
+		}
+	}
+
+	if len(c.nodes) > 0 && len(c.edges) > 0 {
+		dot.WriteString("\n")
+	}
+
+	for _, edge := range c.edges {
+		if len(edge.attrs) == 0 {
+			fmt.Fprintf(&dot, "\t%s -> %s;\n", edge.from, edge.to)
+		} else {
+			fmt.Fprintf(&dot, "\t%s -> %s [%s];\n", edge.from, edge.to, formatDOTAttrs(edge.attrs))
+		}
+	}
+
+	dot.WriteString("}\n")
+	return dot.String()
+}
+
+// formatDOTAttrs formats the given attributes as `key="value"` pairs, with the type first.
+func formatDOTAttrs(attrs map[string]string) string {
+	keys := sortedAttrKeys(attrs)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i] == "type" && keys[j] != "type"
+	})
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, key, strings.ReplaceAll(attrs[key], `"`, `\"`)))
+	}
+
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,154 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/cmd/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+const testAlertmanagerConfig = `
+global:
+  resolve_timeout: 5m
+  slack_api_url: https://hooks.slack.com/services/default
+route:
+  receiver: default
+  group_by: [alertname, cluster]
+  group_wait: 30s
+  routes:
+    - matchers: ['team="db"']
+      receiver: db
+      group_wait: 1m
+    - match_re:
+        severity: critical|page
+      receiver: pager
+      continue: true
+    - matchers: ['team=~"web|frontend"']
+      receiver: web
+      routes:
+        - matchers: [env!="prod"]
+          receiver: "null"
+receivers:
+  - name: default
+    slack_configs:
+      - channel: '#alerts'
+  - name: db
+    slack_configs:
+      - api_url: https://hooks.slack.com/services/db
+  - name: pager
+    slack_configs:
+      - api_url: https://hooks.slack.com/services/pager
+  - name: web
+    slack_configs:
+      - api_url: https://hooks.slack.com/services/web
+    email_configs:
+      - to: web@example.com
+  - name: "null"
+inhibit_rules:
+  - source_matchers: [severity="critical"]
+    target_matchers: [severity="warning"]
+`
+
+func TestConvertAlertmanagerConfig(t *testing.T) {
+	config.RegisterNodes()
+
+	dot, warnings, err := config.ConvertAlertmanagerConfig([]byte(testAlertmanagerConfig))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"global.resolve_timeout is not supported",
+		"inhibit_rules are not supported, so 1 inhibit rule(s) were dropped",
+		`receiver "default": slack option "channel" is not supported`,
+		`receiver "web": email_configs are not supported`,
+	}, warnings)
+
+	fileName := writeConfigFile(t, dot)
+	defer os.Remove(fileName)
+
+	cfg, err := config.LoadConfigFile(fileName, zerolog.Nop())
+	require.NoError(t, err, dot)
+	require.Empty(t, cfg.Lint(), dot)
+
+	tests := []struct {
+		name      string
+		labels    model.Labels
+		notifiers []string
+	}{
+		{
+			name:      "unmatched alerts go to the root receiver",
+			labels:    model.Labels{"team": "foo"},
+			notifiers: []string{"default"},
+		},
+		{
+			name:      "alerts only go to the first matching route",
+			labels:    model.Labels{"team": "db", "severity": "critical"},
+			notifiers: []string{"db"},
+		},
+		{
+			name:      "continue sends alerts to later routes",
+			labels:    model.Labels{"team": "web", "env": "prod", "severity": "page"},
+			notifiers: []string{"pager", "web"},
+		},
+		{
+			name:      "child routes take precedence over their parent",
+			labels:    model.Labels{"team": "frontend", "env": "dev"},
+			notifiers: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alert := model.Alert{Labels: tt.labels}
+			notifiers := []string{}
+			for _, route := range cfg.RouteAlert(context.Background(), &alert) {
+				notifiers = append(notifiers, string(route.Name()))
+				require.Equal(t, []string{"alertname", "cluster"}, route.GroupLabels)
+
+				if route.Name() == "db" {
+					require.Equal(t, time.Minute, route.GroupWait)
+				} else {
+					require.Equal(t, 30*time.Second, route.GroupWait)
+				}
+			}
+
+			sort.Strings(notifiers)
+			require.Equal(t, tt.notifiers, notifiers)
+		})
+	}
+}
+
+func TestConvertAlertmanagerConfigErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        string
+		expectedError string
+	}{
+		{
+			name:          "no route",
+			config:        `receivers: [{name: foo}]`,
+			expectedError: "alertmanager config has no route",
+		},
+		{
+			name:          "unknown receiver",
+			config:        `route: {receiver: foo}`,
+			expectedError: `route references unknown receiver "foo"`,
+		},
+		{
+			name:          "invalid matcher",
+			config:        "route: {receiver: foo, routes: [{matchers: ['foo']}]}\nreceivers: [{name: foo}]",
+			expectedError: "route_0 has an invalid matcher",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := config.ConvertAlertmanagerConfig([]byte(tt.config))
+			require.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
@@ -3,11 +3,9 @@ package config
 import (
 	"context"
 	"fmt"
-	"os"
+	"sort"
 	"text/template"
 
-	"github.com/awalterschulze/gographviz"
-	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -24,9 +22,11 @@ const (
 )
 
 var _ = config.Config(&ConfigFile{})
+var _ = config.Grapher(&ConfigFile{})
 
 type globalOptions struct {
-	TenantKey *template.Template `config:"tenant_key"`
+	TenantKey     *template.Template `config:"tenant_key"`
+	TenantConfigs string             `config:"tenant_configs"`
 }
 
 // Link represents a connection between nodes, that may or may not have an attached filter.
@@ -45,6 +45,22 @@ type ConfigFile struct {
 	// reverseLinks is a map of node name to a list of links that go into that node, for backwards traversal.
 	reverseLinks map[string][]Link
 
+	// nodeTypes is a map of node name to the type that the node was configured with.
+	nodeTypes map[string]string
+
+	// edges describes every link in the order they were defined, for visualising the config.
+	edges []config.GraphEdge
+
+	// unusedTemplates are the names of the templates that the config defines, but never instantiates.
+	unusedTemplates []string
+
+	// tenants is a map of tenant to the config that routes that tenant's data, for tenants that have their own config.
+	tenants map[config.Tenant]*ConfigFile
+
+	// tenantErrors are the errors from tenant configs that failed to load.
+	tenantErrors []TenantConfigError
+
+	path    string
 	globals *config.Globals
 }
 
@@ -55,7 +71,26 @@ func (c *ConfigFile) GetNotifiersForAlert(ctx context.Context, a *model.Alert) [
 	ctx, span := otel.Tracer("").Start(ctx, "ConfigFile.GetNotifiersForAlert")
 	defer span.End()
 
-	leaves := []config.NotifierSettings{}
+	routes := c.RouteAlert(ctx, a)
+	leaves := make([]config.NotifierSettings, 0, len(routes))
+	for _, route := range routes {
+		leaves = append(leaves, route.NotifierSettings)
+	}
+
+	return leaves
+}
+
+// RouteAlert walks the config graph from the `alerts` root, following every link whose filter the alert passes, and returns
+// a route for every notifier that it reaches, with the settings built up along the path to it.
+func (c *ConfigFile) RouteAlert(ctx context.Context, a *model.Alert) []config.Route {
+	ctx, span := otel.Tracer("").Start(ctx, "ConfigFile.RouteAlert")
+	defer span.End()
+
+	if tenantConf := c.configFor(ctx, a); tenantConf != c {
+		return tenantConf.RouteAlert(ctx, a)
+	}
+
+	routes := []config.Route{}
 
 	// nodeMeta is a node that we've traversed to, and the partial configuration that we've built up along the path there.
 	// TODO(cdouch): I'm not _entirely_ sure what happens when we get a two paths to the same node, but with different
@@ -63,12 +98,14 @@ func (c *ConfigFile) GetNotifiersForAlert(ctx context.Context, a *model.Alert) [
 	type nodeMeta struct {
 		name        string
 		partialConf config.NotifierSettings
+		path        []string
 	}
 
 	// We use a stack here to do a depth-first search of the graph, starting at the `alerts` node.
 	stack := []nodeMeta{{
 		name:        ALERT_ROOT,
 		partialConf: config.DefaultNotifierSettings(),
+		path:        []string{ALERT_ROOT},
 	}}
 
 	for len(stack) > 0 {
@@ -82,44 +119,95 @@ func (c *ConfigFile) GetNotifiersForAlert(ctx context.Context, a *model.Alert) [
 		}
 
 		for _, link := range c.links[node.name] {
-			matchesFilter := link.incomingFilter == nil || link.incomingFilter.Filter(ctx, a) != nil
+			matchesFilter := link.incomingFilter == nil || link.incomingFilter.Filter(ctx, a) == nil
 			if matchesFilter {
 				stack = append(stack, nodeMeta{
 					name:        link.to,
 					partialConf: node.partialConf,
+					path:        append(node.path[:len(node.path):len(node.path)], link.to),
 				})
 			}
 		}
 
 		if notifier, ok := c.nodes[node.name].(config.Notifier); notifier != nil && ok {
-			leaves = append(leaves, node.partialConf.WithNotifier(notifier))
+			routes = append(routes, config.Route{
+				NotifierSettings: node.partialConf.WithNotifier(notifier),
+				Path:             node.path,
+			})
 		}
 	}
 
-	return leaves
+	return routes
+}
+
+// TransformAlert walks the config graph from the `alerts` root, applying every transformer node (e.g. relabel nodes)
+// that the alert reaches to it in place. Filters along the way see the alert as it has been transformed so far. Each node
+// is applied at most once, so transformers are best placed in a chain before the graph branches.
+func (c *ConfigFile) TransformAlert(ctx context.Context, a *model.Alert) error {
+	ctx, span := otel.Tracer("").Start(ctx, "ConfigFile.TransformAlert")
+	defer span.End()
+
+	if tenantConf := c.configFor(ctx, a); tenantConf != c {
+		return tenantConf.TransformAlert(ctx, a)
+	}
+
+	visited := HashSet{}
+	stack := []string{ALERT_ROOT}
+	for len(stack) > 0 {
+		nodeName := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, ok := visited[nodeName]; ok {
+			continue
+		}
+
+		visited[nodeName] = struct{}{}
+
+		if transformer, ok := c.nodes[nodeName].(config.AlertTransformerNode); transformer != nil && ok {
+			if err := transformer.TransformAlert(ctx, a); err != nil {
+				return errors.Wrapf(err, "failed to apply %q", nodeName)
+			}
+		}
+
+		for _, link := range c.links[nodeName] {
+			if link.incomingFilter == nil || link.incomingFilter.Filter(ctx, a) == nil {
+				stack = append(stack, link.to)
+			}
+		}
+	}
+
+	return nil
 }
 
 // validateData walks the config graph, along every path into the given leaf. We check every path against the given Fielder,
-// and return an error if we can't find a path into the leaf that matches the data.
+// and return a *config.ValidationError explaining every path that was tried if we can't find a path into the leaf that matches the data.
 func (c *ConfigFile) validateData(ctx context.Context, leaf string, data config.Fielder) error {
 	roots := calculateRootsFrom(c, leaf) // TODO(cdouch): memoize this.
 	if len(roots) == 0 {
 		return nil
 	}
 
-	var allErrs error
-	for root := range roots {
-		if err := searchForNode(ctx, c, root, leaf, data); err == nil {
+	validationErr := &config.ValidationError{
+		Leaf: leaf,
+	}
+
+	// Walk the roots in order so that the explanation is deterministic.
+	for _, root := range sortedKeys(roots) {
+		found, rejections := searchForNode(ctx, c, root, leaf, data, nil)
+		if found {
 			return nil
-		} else {
-			allErrs = multierror.Append(allErrs, err)
 		}
+
+		validationErr.Paths = append(validationErr.Paths, rejections...)
 	}
 
-	return allErrs
+	return validationErr
 }
 
 func (c *ConfigFile) ValidateData(ctx context.Context, data config.Fielder) error {
+	if tenantConf := c.configFor(ctx, data); tenantConf != c {
+		return tenantConf.ValidateData(ctx, data)
+	}
+
 	switch data.(type) {
 	case *model.AlertAcknowledgement:
 		return c.validateData(ctx, ACK_LEAF, data)
@@ -132,27 +220,26 @@ func (c *ConfigFile) ValidateData(ctx context.Context, data config.Fielder) erro
 	}
 }
 
-// LoadConfigFile reads the given file, and parses it into a config, returning any parsing errors.
-func LoadConfigFile(path string, logger zerolog.Logger) (*ConfigFile, error) {
-	conf := &ConfigFile{
+// newConfigFile returns an empty config, that has been loaded from the given path.
+func newConfigFile(path string) *ConfigFile {
+	return &ConfigFile{
 		nodes:        make(map[string]config.Node),
+		nodeTypes:    make(map[string]string),
 		links:        make(map[string][]Link),
 		reverseLinks: make(map[string][]Link),
+		tenants:      make(map[config.Tenant]*ConfigFile),
+		path:         path,
 	}
+}
 
-	body, err := os.ReadFile(path)
-	if err != nil {
-		return conf, errors.Wrap(err, "failed to read config file")
-	}
+// LoadConfigFile reads the given file, and parses it into a config, returning any parsing errors. The given options are passed on to the
+// globals of the config, e.g. to load it with config.WithValidateOnly.
+func LoadConfigFile(path string, logger zerolog.Logger, opts ...config.GlobalsOpt) (*ConfigFile, error) {
+	conf := newConfigFile(path)
 
-	graphAst, err := gographviz.Parse(body)
+	configGraph, _, err := loadConfigGraph(path)
 	if err != nil {
-		return conf, errors.Wrap(err, "failed to parse config file as dot")
-	}
-
-	configGraph := newConfigGraph()
-	if err := gographviz.Analyse(graphAst, &configGraph); err != nil {
-		return conf, errors.Wrap(err, "failed to load config file")
+		return conf, err
 	}
 
 	options := globalOptions{}
@@ -166,52 +253,119 @@ func LoadConfigFile(path string, logger zerolog.Logger) (*ConfigFile, error) {
 		tenanter = config.NewTemplateTenanter(options.TenantKey)
 	}
 
-	conf.globals = config.NewGlobals(config.WithLogger(logger), config.WithTenanter(tenanter))
+	conf.globals = config.NewGlobals(append([]config.GlobalsOpt{config.WithLogger(logger), config.WithTenanter(tenanter)}, opts...)...)
+
+	if err := conf.build(configGraph); err != nil {
+		return conf, err
+	}
+
+	if err := conf.Validate(); err != nil {
+		return conf, err
+	}
+
+	if options.TenantConfigs != "" {
+		if options.TenantKey == nil {
+			return conf, fmt.Errorf("%q requires %q to be set, so that data can be assigned to tenants", TENANT_CONFIGS_ATTR, "tenant_key")
+		}
+
+		if err := conf.loadTenantConfigs(tenantConfigDir(path, options.TenantConfigs)); err != nil {
+			return conf, err
+		}
+	}
+
+	return conf, nil
+}
+
+// build constructs the nodes and links of the given graph into the config.
+func (c *ConfigFile) build(configGraph configGraph) error {
+	c.unusedTemplates = configGraph.unusedTemplates
 
 	for _, rawNode := range configGraph.nodes {
-		nodeType := rawNode.attrs["type"]
+		// Anchor nodes ignore their attributes, so interpolating them here is also what catches references that can't be resolved.
+		attrs, err := interpolateAttrs(rawNode.attrs)
+		if err != nil {
+			return errors.Wrapf(err, "invalid node %q", rawNode.name)
+		}
+
+		nodeType := attrs["type"]
 		cons, ok := config.LookupNode(nodeType)
 		if !ok {
-			return conf, fmt.Errorf("invalid node type: %q", nodeType)
+			return fmt.Errorf("invalid node type: %q", nodeType)
+		}
+
+		// Constructors interpolate the attributes that they unmarshal themselves, but some of them read the type directly.
+		nodeAttrs := make(map[string]string, len(rawNode.attrs))
+		for k, v := range rawNode.attrs {
+			nodeAttrs[k] = v
 		}
 
-		node, err := cons(rawNode.name, conf.globals, rawNode.attrs)
+		if _, ok := nodeAttrs["type"]; ok {
+			nodeAttrs["type"] = nodeType
+		}
+
+		node, err := cons(rawNode.name, c.globals.InConfigDir(rawNode.dir), nodeAttrs)
 		if err != nil {
-			return conf, err
+			return err
 		}
 
-		conf.nodes[rawNode.name] = node
+		c.nodes[rawNode.name] = node
+		c.nodeTypes[rawNode.name] = nodeType
 	}
 
 	for _, rawLink := range configGraph.edges {
-		linkType := rawLink.attrs["type"]
+		attrs, err := interpolateAttrs(rawLink.attrs)
+		if err != nil {
+			return errors.Wrapf(err, "invalid link %s -> %s", rawLink.from, rawLink.to)
+		}
+
+		linkType := attrs["type"]
 		cons, ok := config.LookupFilter(linkType)
 
 		if !ok {
-			return conf, fmt.Errorf("invalid link type: %q", linkType)
+			return fmt.Errorf("invalid link type: %q", linkType)
+		}
+
+		// Filters consume their attributes as they're constructed, so take a copy for describing the edge.
+		filterAttrs := make(map[string]string, len(rawLink.attrs))
+		for k, v := range rawLink.attrs {
+			if k != "type" {
+				filterAttrs[k] = v
+			}
 		}
 
-		filter, err := cons(conf.globals, rawLink.attrs)
+		filter, err := cons(c.globals, rawLink.attrs)
 		if err != nil {
-			return conf, err
+			return err
 		}
 
 		if filter == nil {
 			panic(fmt.Sprintf("BUG: filter %q produced a nil filter", linkType))
 		}
 
-		conf.links[rawLink.from] = append(conf.links[rawLink.from], Link{
+		c.links[rawLink.from] = append(c.links[rawLink.from], Link{
 			to:             rawLink.to,
 			incomingFilter: filter,
 		})
 
-		conf.reverseLinks[rawLink.to] = append(conf.reverseLinks[rawLink.to], Link{
+		c.reverseLinks[rawLink.to] = append(c.reverseLinks[rawLink.to], Link{
 			to:             rawLink.from,
 			incomingFilter: filter,
 		})
+
+		edge := config.GraphEdge{
+			From: rawLink.from,
+			To:   rawLink.to,
+		}
+
+		if linkType != "" {
+			edge.Filter = linkType
+			edge.FilterAttrs = filterAttrs
+		}
+
+		c.edges = append(c.edges, edge)
 	}
 
-	return conf, conf.Validate()
+	return nil
 }
 
 // validateConfIsAcyclic starts at the given roots, and validates that there are no cycles in the
@@ -268,6 +422,39 @@ func (c *ConfigFile) Validate() error {
 	return nil
 }
 
+// Graph describes the nodes and edges of the config, for visualising it.
+func (c *ConfigFile) Graph() config.Graph {
+	graph := config.Graph{
+		Nodes: make([]config.GraphNode, 0, len(c.nodes)),
+		Edges: make([]config.GraphEdge, len(c.edges)),
+	}
+
+	for _, name := range c.sortedNodeNames() {
+		nodeType := c.nodeTypes[name]
+		if isPseudoNode(name) {
+			nodeType = "pseudo"
+		} else if nodeType == "" {
+			nodeType = c.nodes[name].Type()
+		}
+
+		_, notifier := c.nodes[name].(config.Notifier)
+		graph.Nodes = append(graph.Nodes, config.GraphNode{
+			Name:     name,
+			Type:     nodeType,
+			Notifier: notifier,
+		})
+	}
+
+	copy(graph.Edges, c.edges)
+
+	return graph
+}
+
+// Path returns the path of the file that the config was loaded from.
+func (c *ConfigFile) Path() string {
+	return c.path
+}
+
 func (c *ConfigFile) Globals() *config.Globals {
 	return c.globals
 }
@@ -283,3 +470,14 @@ func toHashSet(s []string) HashSet {
 
 	return set
 }
+
+// sortedKeys returns the members of the given set, in order.
+func sortedKeys(set HashSet) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -3,10 +3,13 @@ package config_test
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/sinkingpoint/kiora/cmd/kiora/config"
+	kconfig "github.com/sinkingpoint/kiora/lib/kiora/config"
 	"github.com/sinkingpoint/kiora/lib/kiora/model"
 	"github.com/stretchr/testify/require"
 )
@@ -73,6 +76,49 @@ func TestConfigLoad(t *testing.T) {
 	}
 }
 
+func TestConfigGetNotifiersForAlert(t *testing.T) {
+	config.RegisterNodes()
+	fileName := writeConfigFile(t, `digraph config {
+		web [type="stdout"];
+		everyone [type="stdout"];
+
+		alerts -> web [type="regex" field="team" regex="^web$"];
+		alerts -> everyone;
+	}`)
+
+	cfg, err := config.LoadConfigFile(fileName, zerolog.New(os.Stdout))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name              string
+		labels            model.Labels
+		expectedNotifiers []string
+	}{
+		{
+			name:              "alerts that pass a filter are routed through it",
+			labels:            model.Labels{"alertname": "foo", "team": "web"},
+			expectedNotifiers: []string{"web", "everyone"},
+		},
+		{
+			name:              "alerts that fail a filter aren't routed through it",
+			labels:            model.Labels{"alertname": "foo", "team": "db"},
+			expectedNotifiers: []string{"everyone"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alert := model.Alert{Labels: tt.labels}
+			notifiers := []string{}
+			for _, settings := range cfg.GetNotifiersForAlert(context.TODO(), &alert) {
+				notifiers = append(notifiers, string(settings.Notifier.Name()))
+			}
+
+			require.ElementsMatch(t, tt.expectedNotifiers, notifiers)
+		})
+	}
+}
+
 func TestConfigAckFilter(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -176,3 +222,336 @@ func TestConfigAckFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigSilenceMatcherFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		silence     *model.Silence
+		expectError bool
+	}{
+		{
+			name: "matcher on env",
+			silence: &model.Silence{
+				Matchers: []model.Matcher{model.LabelValueEqualMatcher("env", "dev")},
+			},
+			expectError: false,
+		},
+		{
+			name: "matcher on wrong env",
+			silence: &model.Silence{
+				Matchers: []model.Matcher{model.LabelValueEqualMatcher("env", "prod")},
+			},
+			expectError: true,
+		},
+		{
+			name: "no env matcher",
+			silence: &model.Silence{
+				Matchers: []model.Matcher{model.LabelValueEqualMatcher("alertname", "foo")},
+			},
+			expectError: true,
+		},
+		{
+			name: "regex matcher on env",
+			silence: &model.Silence{
+				Matchers: []model.Matcher{{Label: "env", Value: "dev", IsRegex: true}},
+			},
+			expectError: true,
+		},
+		{
+			name: "negative matcher on env",
+			silence: &model.Silence{
+				Matchers: []model.Matcher{{Label: "env", Value: "dev", IsNegative: true}},
+			},
+			expectError: true,
+		},
+		{
+			name: "negative matcher on env before an equality matcher",
+			silence: &model.Silence{
+				Matchers: []model.Matcher{{Label: "env", Value: "dev", IsNegative: true}, model.LabelValueEqualMatcher("env", "dev")},
+			},
+			expectError: false,
+		},
+		{
+			name: "equality matcher on env before a regex matcher",
+			silence: &model.Silence{
+				Matchers: []model.Matcher{model.LabelValueEqualMatcher("env", "dev"), {Label: "env", Value: "prod", IsRegex: true}},
+			},
+			expectError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.RegisterNodes()
+			fileName := writeConfigFile(t, `digraph config {
+				dev_only -> silences [type="regex" field="__matcher_env__" regex="^dev$"];
+			}`)
+			cfg, err := config.LoadConfigFile(fileName, zerolog.New(os.Stdout))
+			require.NoError(t, err)
+
+			err = cfg.ValidateData(context.TODO(), tt.silence)
+			if tt.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfigTransformAlert(t *testing.T) {
+	config.RegisterNodes()
+	fileName := writeConfigFile(t, `digraph config {
+		add_host [type="relabel" source_labels="instance" regex="([^:]+):.*" target_label="host"];
+		drop_instance [type="relabel" action="labeldrop" regex="instance"];
+		console [type="stdout"];
+
+		alerts -> add_host -> drop_instance -> console;
+	}`)
+
+	cfg, err := config.LoadConfigFile(fileName, zerolog.New(os.Stdout))
+	require.NoError(t, err)
+
+	alert := model.Alert{
+		Labels: model.Labels{
+			"alertname": "foo",
+			"instance":  "foo:9090",
+		},
+	}
+
+	require.NoError(t, cfg.TransformAlert(context.TODO(), &alert))
+	require.Equal(t, model.Labels{
+		"alertname": "foo",
+		"host":      "foo",
+	}, alert.Labels)
+}
+
+func TestConfigValidationExplanation(t *testing.T) {
+	config.RegisterNodes()
+	fileName := writeConfigFile(t, `digraph config {
+		commented -> short_silences [type="regex" field="__comment__" regex=".+"];
+		short_silences -> silences [type="duration" field="__duration__" max="1h"];
+		dead_end -> nowhere;
+		dead_end -> silences [type="regex" field="__creator__" regex="admin"];
+	}`)
+	defer os.Remove(fileName)
+
+	cfg, err := config.LoadConfigFile(fileName, zerolog.New(os.Stdout))
+	require.NoError(t, err)
+
+	silence := &model.Silence{
+		Creator:   "foo",
+		StartTime: time.Unix(0, 0),
+		EndTime:   time.Unix(0, 0).Add(2 * time.Hour),
+	}
+
+	err = cfg.ValidateData(context.TODO(), silence)
+	require.Error(t, err)
+
+	validationErr := &kconfig.ValidationError{}
+	require.ErrorAs(t, err, &validationErr)
+	require.Equal(t, "silences", validationErr.Leaf)
+	require.Len(t, validationErr.Paths, 3)
+
+	require.Equal(t, []string{"commented"}, validationErr.Paths[0].Path)
+	require.Equal(t, "short_silences", validationErr.Paths[0].To)
+	require.Equal(t, "regex", validationErr.Paths[0].Filter)
+
+	require.Equal(t, []string{"dead_end", "nowhere"}, validationErr.Paths[1].Path)
+	require.Empty(t, validationErr.Paths[1].To)
+
+	require.Equal(t, []string{"dead_end"}, validationErr.Paths[2].Path)
+	require.Equal(t, "silences", validationErr.Paths[2].To)
+
+	// Fixing the comment should get the silence as far as the duration filter.
+	silence.Comment = "maintenance"
+	err = cfg.ValidateData(context.TODO(), silence)
+	require.ErrorAs(t, err, &validationErr)
+	require.Equal(t, []string{"commented", "short_silences"}, validationErr.Paths[0].Path)
+	require.Equal(t, "duration", validationErr.Paths[0].Filter)
+}
+
+func TestConfigRolesField(t *testing.T) {
+	config.RegisterNodes()
+	fileName := writeConfigFile(t, `digraph config {
+		short_silences -> silences [type="duration" field="__duration__" max="1h"];
+		admins -> silences [type="regex" field="__roles__" regex="(^|,)admin(,|$)"];
+	}`)
+
+	cfg, err := config.LoadConfigFile(fileName, zerolog.New(os.Stdout))
+	require.NoError(t, err)
+
+	silence := &model.Silence{
+		StartTime: time.Unix(0, 0),
+		EndTime:   time.Unix(0, 0).Add(2 * time.Hour),
+	}
+
+	// Long silences can only be created by admins.
+	require.Error(t, cfg.ValidateData(context.TODO(), silence))
+	require.Error(t, cfg.ValidateData(kconfig.ContextWithRoles(context.TODO(), []string{"oncall"}), silence))
+	require.NoError(t, cfg.ValidateData(kconfig.ContextWithRoles(context.TODO(), []string{"admin", "oncall"}), silence))
+
+	silence.EndTime = time.Unix(0, 0).Add(time.Hour)
+	require.NoError(t, cfg.ValidateData(kconfig.ContextWithRoles(context.TODO(), []string{"oncall"}), silence))
+}
+
+func TestConfigSchemaWithRoles(t *testing.T) {
+	config.RegisterNodes()
+	fileName := writeConfigFile(t, `digraph config {
+		schema -> alerts [type="schema" required_labels="alertname"];
+	}`)
+
+	cfg, err := config.LoadConfigFile(fileName, zerolog.New(os.Stdout))
+	require.NoError(t, err)
+
+	// Requests checked against a policy have roles, which shouldn't stop filters from seeing the alert itself.
+	ctx := kconfig.ContextWithRoles(context.TODO(), []string{"sender"})
+	require.NoError(t, cfg.ValidateData(ctx, &model.Alert{Labels: model.Labels{"alertname": "foo"}}))
+	require.Error(t, cfg.ValidateData(ctx, &model.Alert{Labels: model.Labels{"severity": "critical"}}))
+}
+
+func TestConfigGraph(t *testing.T) {
+	config.RegisterNodes()
+	fileName := writeConfigFile(t, `digraph config {
+		console [type="stdout"];
+		pager [type="stdout"];
+
+		alerts -> console;
+		alerts -> pager [type="regex" field="severity" regex="critical"];
+	}`)
+	defer os.Remove(fileName)
+
+	cfg, err := config.LoadConfigFile(fileName, zerolog.New(os.Stdout))
+	require.NoError(t, err)
+
+	graph := cfg.Graph()
+	require.Equal(t, []kconfig.GraphNode{
+		{Name: "alerts", Type: "pseudo"},
+		{Name: "console", Type: "stdout", Notifier: true},
+		{Name: "pager", Type: "stdout", Notifier: true},
+	}, graph.Nodes)
+
+	require.Len(t, graph.Edges, 2)
+	require.Equal(t, "", graph.Edges[0].FilterDescription())
+	require.Equal(t, `regex(field="severity", regex="critical")`, graph.Edges[1].FilterDescription())
+
+	alert := model.Alert{Labels: model.Labels{"severity": "warning"}}
+	graph.Highlight(cfg.RouteAlert(context.TODO(), &alert))
+	require.True(t, graph.Nodes[1].Highlighted)
+	require.False(t, graph.Nodes[2].Highlighted)
+	require.True(t, graph.Edges[0].Highlighted)
+	require.False(t, graph.Edges[1].Highlighted)
+
+	require.Equal(t, `digraph config {
+	"alerts" [color="red" penwidth="2" type="pseudo"];
+	"console" [color="red" penwidth="2" type="stdout"];
+	"pager" [type="stdout"];
+	"alerts" -> "console" [color="red" penwidth="2"];
+	"alerts" -> "pager" [field="severity" regex="critical" type="regex"];
+}
+`, graph.DOT())
+}
+
+func TestConfigInterpolation(t *testing.T) {
+	config.RegisterNodes()
+	t.Setenv("KIORA_TEST_TEAM", "foo")
+
+	fileName := writeConfigFile(t, `digraph config {
+		console [type="stdout"];
+		alerts -> console [type="regex" field="team" regex="^${KIORA_TEST_TEAM}$"];
+	}`)
+	defer os.Remove(fileName)
+
+	cfg, err := config.LoadConfigFile(fileName, zerolog.New(os.Stdout))
+	require.NoError(t, err)
+
+	alert := model.Alert{Labels: model.Labels{"team": "foo"}}
+	require.Len(t, cfg.RouteAlert(context.TODO(), &alert), 1)
+
+	// Describing the config should show the reference, not the interpolated value.
+	require.Equal(t, "^${KIORA_TEST_TEAM}$", cfg.Graph().Edges[0].FilterAttrs["regex"])
+}
+
+func TestConfigInterpolatesEveryAttribute(t *testing.T) {
+	config.RegisterNodes()
+	t.Setenv("KIORA_TEST_NODE_TYPE", "stdout")
+	t.Setenv("KIORA_TEST_LINK_TYPE", "regex")
+	t.Setenv("KIORA_TEST_TEMPLATE", "notify")
+	t.Setenv("KIORA_TEST_INCLUDE", "teams/*.dot")
+
+	tests := []struct {
+		name          string
+		files         map[string]string
+		expectedError string
+		notifiers     []string
+	}{
+		{
+			name: "node types",
+			files: map[string]string{
+				"kiora.dot": `digraph config { console [type="${KIORA_TEST_NODE_TYPE}"]; alerts -> console; }`,
+			},
+			notifiers: []string{"console"},
+		},
+		{
+			name: "link types",
+			files: map[string]string{
+				"kiora.dot": `digraph config { foo [type="stdout"]; alerts -> foo [type="${KIORA_TEST_LINK_TYPE}" field="team" regex="foo"]; }`,
+			},
+			notifiers: []string{"foo"},
+		},
+		{
+			name: "templates",
+			files: map[string]string{
+				"kiora.dot": `digraph config {
+					subgraph notify { params = "${KIORA_TEST_TEMPLATE}_team"; input -> notify_team [type="regex" field="team" regex="$(notify_team)"]; notify_team [type="stdout"]; }
+					foo [type="template" template="${KIORA_TEST_TEMPLATE}" notify_team="foo"];
+					alerts -> foo;
+				}`,
+			},
+			notifiers: []string{"foo/notify_team"},
+		},
+		{
+			name: "includes",
+			files: map[string]string{
+				"kiora.dot":     `digraph config { include = "${KIORA_TEST_INCLUDE}"; }`,
+				"teams/foo.dot": `digraph foo { foo [type="stdout"]; alerts -> foo; }`,
+			},
+			notifiers: []string{"foo"},
+		},
+		{
+			name: "anchor nodes",
+			files: map[string]string{
+				"kiora.dot": `digraph config { anchor [comment="${KIORA_TEST_UNSET}"]; alerts -> anchor; }`,
+			},
+			expectedError: `invalid node "anchor": failed to interpolate attribute "comment": environment variable "KIORA_TEST_UNSET" is not set`,
+		},
+		{
+			name: "links without a type",
+			files: map[string]string{
+				"kiora.dot": `digraph config { console [type="stdout"]; alerts -> console [comment="${KIORA_TEST_UNSET}"]; }`,
+			},
+			expectedError: `invalid link alerts -> console: failed to interpolate attribute "comment": environment variable "KIORA_TEST_UNSET" is not set`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigDir(t, tt.files)
+			cfg, err := config.LoadConfigFile(filepath.Join(dir, "kiora.dot"), zerolog.New(os.Stdout))
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+
+			alert := model.Alert{Labels: model.Labels{"team": "foo"}}
+			notifiers := []string{}
+			for _, route := range cfg.RouteAlert(context.TODO(), &alert) {
+				notifiers = append(notifiers, string(route.Notifier.Name()))
+			}
+
+			require.ElementsMatch(t, tt.notifiers, notifiers)
+		})
+	}
+}
@@ -7,12 +7,16 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/lib/kiora/config/unmarshal"
 )
 
 // node is a node in the config graph that defines a filter, or a receiver.
 type node struct {
 	name  string
 	attrs map[string]string
+
+	// dir is the directory of the file that the node was defined in, that relative paths in its attributes are resolved against.
+	dir string
 }
 
 // edge defines an edge between two nodes in the graph.
@@ -22,6 +26,23 @@ type edge struct {
 	attrs map[string]string
 }
 
+// interpolateAttrs returns a copy of the given attributes, with any references to environment variables and files interpolated.
+// The graph itself keeps the references, so that describing the config doesn't leak secrets, and constructors interpolate the
+// attributes they unmarshal themselves so that they can redact them, so this is for the attributes that the loader reads directly.
+func interpolateAttrs(attrs map[string]string) (map[string]string, error) {
+	interpolated := make(map[string]string, len(attrs))
+	for name, value := range attrs {
+		value, _, err := unmarshal.Interpolate(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to interpolate attribute %q", name)
+		}
+
+		interpolated[name] = value
+	}
+
+	return interpolated, nil
+}
+
 // configGraph is the raw graphviz graph, as loaded from the config file.
 type configGraph struct {
 	name      string
@@ -29,6 +50,9 @@ type configGraph struct {
 	subGraphs map[string]configGraph
 	nodes     map[string]node
 	edges     []edge
+
+	// unusedTemplates are the names of the templates that were defined, but never instantiated.
+	unusedTemplates []string
 }
 
 // newConfigGraph constructs a new configGraph, initializing all the maps.
@@ -79,7 +103,8 @@ func (c *configGraph) AddEdge(src, dst string, directed bool, attrs map[string]s
 
 func (c *configGraph) AddNode(parentGraph, name string, attrs map[string]string) error {
 	if parentGraph == c.name {
-		if _, ok := c.nodes[name]; ok {
+		// Nodes referenced in an edge before they're declared are added without any attributes, so those can be filled in later.
+		if existing, ok := c.nodes[name]; ok && len(existing.attrs) > 0 {
 			return fmt.Errorf("config graph already contains a node called %q", name)
 		}
 
@@ -88,8 +113,8 @@ func (c *configGraph) AddNode(parentGraph, name string, attrs map[string]string)
 		}
 
 		c.nodes[name] = node{
-			name,
-			attrs,
+			name:  name,
+			attrs: attrs,
 		}
 
 		return nil
@@ -2,8 +2,8 @@ package config
 
 import (
 	"context"
+	"fmt"
 
-	"github.com/hashicorp/go-multierror"
 	"github.com/sinkingpoint/kiora/lib/kiora/config"
 )
 
@@ -35,27 +35,43 @@ func calculateRootsFrom(graph *ConfigFile, nodeName string) HashSet {
 	return roots
 }
 
-// searchForAckNode starts at the given fromNode and does a depth-first search across the graph,
-// checking the filters on each link and trying to find a path to the given destinationNode,
-// returning whether or not it was able to find it.
-func searchForNode(ctx context.Context, graph *ConfigFile, fromNode, destinationNode string, data config.Fielder) error {
+// searchForNode starts at the given fromNode and does a depth-first search across the graph, checking the filters
+// on each link and trying to find a path to the given destinationNode. It returns whether or not it was able to find one,
+// and if it wasn't, every path that it tried and why it was rejected.
+func searchForNode(ctx context.Context, graph *ConfigFile, fromNode, destinationNode string, data config.Fielder, path []string) (bool, []config.RejectedPath) {
+	path = append(path[:len(path):len(path)], fromNode)
 	if fromNode == destinationNode {
-		return nil
+		return true, nil
 	}
 
-	var allErrs error
-	for _, link := range graph.links[fromNode] {
+	links := graph.links[fromNode]
+	if len(links) == 0 {
+		return false, []config.RejectedPath{{
+			Path:   path,
+			Reason: fmt.Sprintf("path doesn't lead to %q", destinationNode),
+		}}
+	}
+
+	rejections := []config.RejectedPath{}
+	for _, link := range links {
 		if err := link.incomingFilter.Filter(ctx, data); err != nil {
-			allErrs = multierror.Append(allErrs, err)
+			rejections = append(rejections, config.RejectedPath{
+				Path:   path,
+				To:     link.to,
+				Filter: link.incomingFilter.Type(),
+				Reason: err.Error(),
+			})
+
 			continue
 		}
 
-		if err := searchForNode(ctx, graph, link.to, destinationNode, data); err == nil {
-			return nil
-		} else {
-			allErrs = multierror.Append(allErrs, err)
+		found, pathRejections := searchForNode(ctx, graph, link.to, destinationNode, data, path)
+		if found {
+			return true, nil
 		}
+
+		rejections = append(rejections, pathRejections...)
 	}
 
-	return allErrs
+	return false, rejections
 }
@@ -6,8 +6,11 @@ import (
 	"github.com/sinkingpoint/kiora/lib/kiora/config/filters/nop"
 	"github.com/sinkingpoint/kiora/lib/kiora/config/filters/ratelimit"
 	"github.com/sinkingpoint/kiora/lib/kiora/config/filters/regex"
+	"github.com/sinkingpoint/kiora/lib/kiora/config/filters/schema"
 	_ "github.com/sinkingpoint/kiora/lib/kiora/config/notifiers/filenotifier"
 	_ "github.com/sinkingpoint/kiora/lib/kiora/config/notifiers/slack"
+	_ "github.com/sinkingpoint/kiora/lib/kiora/config/transformers/enrich"
+	_ "github.com/sinkingpoint/kiora/lib/kiora/config/transformers/relabel"
 )
 
 func RegisterNodes() {
@@ -15,4 +18,5 @@ func RegisterNodes() {
 	config.RegisterFilter("regex", regex.NewFilter)
 	config.RegisterFilter("duration", duration.NewFilter)
 	config.RegisterFilter("ratelimit", ratelimit.NewFilter)
+	config.RegisterFilter("schema", schema.NewFilter)
 }
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/awalterschulze/gographviz"
+	"github.com/awalterschulze/gographviz/ast"
+	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/lib/kiora/config/unmarshal"
+)
+
+// INCLUDE_ATTR is the graph attribute that lists the files to merge into a config, as a comma separated list of globs.
+const INCLUDE_ATTR = "include"
+
+// includedFileError is an error in a config file that was included by another config file.
+type includedFileError struct {
+	path string
+	err  error
+}
+
+func (e *includedFileError) Error() string {
+	return fmt.Sprintf("in included file %s: %s", e.path, e.err.Error())
+}
+
+func (e *includedFileError) Unwrap() error {
+	return e.err
+}
+
+// configGraphLoader merges a config file, and all the files that it includes, into a single graph.
+type configGraphLoader struct {
+	graph     configGraph
+	templates map[string]configTemplate
+
+	// files are the absolute paths of all the files that have been read, in the order they were read.
+	files []string
+
+	// nodeSources and templateSources are the files that each node and template were defined in, for reporting collisions.
+	nodeSources     map[string]string
+	templateSources map[string]string
+}
+
+// loadConfigGraph reads the config file at the given path along with every file that it includes, merging them into a single graph
+// and instantiating any templates. The files that were read are returned, even if loading them failed.
+func loadConfigGraph(path string) (configGraph, []string, error) {
+	loader := &configGraphLoader{
+		graph:           newConfigGraph(),
+		templates:       make(map[string]configTemplate),
+		nodeSources:     make(map[string]string),
+		templateSources: make(map[string]string),
+	}
+
+	if err := loader.loadFile(path, true); err != nil {
+		return loader.graph, loader.files, err
+	}
+
+	loader.graph.unusedTemplates = unusedTemplates(loader.graph, loader.templates)
+	if err := instantiateTemplates(&loader.graph, loader.templates); err != nil {
+		return loader.graph, loader.files, err
+	}
+
+	return loader.graph, loader.files, nil
+}
+
+// loadFile parses the config file at the given path, merges it into the graph, and then recursively loads any files that it includes.
+// Files that have already been loaded are skipped, so overlapping includes (or a file that includes itself) are fine.
+func (l *configGraphLoader) loadFile(path string, root bool) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve config file path")
+	}
+
+	for _, file := range l.files {
+		if file == absPath {
+			return nil
+		}
+	}
+
+	l.files = append(l.files, absPath)
+
+	graph, templates, err := parseConfigGraph(absPath)
+	if err != nil {
+		if root {
+			return err
+		}
+
+		return &includedFileError{path: path, err: err}
+	}
+
+	for name, node := range graph.nodes {
+		node.dir = filepath.Dir(absPath)
+		graph.nodes[name] = node
+	}
+
+	include, _, err := unmarshal.Interpolate(graph.attrs[INCLUDE_ATTR])
+	if err != nil {
+		return errors.Wrapf(err, "invalid %q in %s", INCLUDE_ATTR, path)
+	}
+
+	delete(graph.attrs, INCLUDE_ATTR)
+
+	if root {
+		l.graph.name = graph.name
+		l.graph.attrs = graph.attrs
+	} else if len(graph.attrs) > 0 {
+		return fmt.Errorf("included file %s sets the graph attribute %q, but graph attributes can only be set in the main config file", path, sortedAttrKeys(graph.attrs)[0])
+	}
+
+	if err := l.mergeGraph(path, graph, templates); err != nil {
+		return err
+	}
+
+	for _, pattern := range strings.Split(include, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(absPath), pattern)
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return errors.Wrapf(err, "invalid include %q in %s", pattern, path)
+		}
+
+		// Globs that don't match anything are fine (e.g. an empty directory of team configs), but a missing file is probably a typo.
+		if len(matches) == 0 && !strings.ContainsAny(pattern, `*?[\`) {
+			return fmt.Errorf("%s includes %s, which doesn't exist", path, pattern)
+		}
+
+		for _, match := range matches {
+			if err := l.loadFile(match, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// mergeGraph merges the nodes, edges, and templates from the given file into the loaded graph.
+func (l *configGraphLoader) mergeGraph(path string, graph configGraph, templates map[string]configTemplate) error {
+	for _, name := range sortedNodeKeys(graph.nodes) {
+		newNode := graph.nodes[name]
+		existing, ok := l.graph.nodes[name]
+
+		// Nodes without any attributes are references to nodes that might be defined elsewhere (e.g. `alerts`), so they can be merged.
+		switch {
+		case !ok || len(existing.attrs) == 0:
+			l.graph.nodes[name] = newNode
+			l.nodeSources[name] = path
+		case len(newNode.attrs) == 0:
+			continue
+		default:
+			return fmt.Errorf("node %q is defined in both %s and %s", name, l.nodeSources[name], path)
+		}
+	}
+
+	l.graph.edges = append(l.graph.edges, graph.edges...)
+
+	for name, template := range templates {
+		if _, ok := l.templates[name]; ok {
+			return fmt.Errorf("template %q is defined in both %s and %s", name, l.templateSources[name], path)
+		}
+
+		l.templates[name] = template
+		l.templateSources[name] = path
+	}
+
+	return nil
+}
+
+// parseConfigGraph parses a single config file, splitting any subgraphs in it out as templates.
+func parseConfigGraph(path string) (configGraph, map[string]configTemplate, error) {
+	graph := newConfigGraph()
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return graph, nil, errors.Wrap(err, "failed to read config file")
+	}
+
+	graphAst, err := gographviz.Parse(body)
+	if err != nil {
+		return graph, nil, errors.Wrap(err, "failed to parse config file as dot")
+	}
+
+	templates := make(map[string]configTemplate)
+	stmts := make(ast.StmtList, 0, len(graphAst.StmtList))
+	for _, stmt := range graphAst.StmtList {
+		subGraph, ok := stmt.(*ast.SubGraph)
+		if !ok {
+			stmts = append(stmts, stmt)
+			continue
+		}
+
+		template, err := parseTemplate(subGraph)
+		if err != nil {
+			return graph, nil, err
+		}
+
+		if _, ok := templates[template.name]; ok {
+			return graph, nil, fmt.Errorf("template %q is defined more than once", template.name)
+		}
+
+		templates[template.name] = template
+	}
+
+	graphAst.StmtList = stmts
+	if err := gographviz.Analyse(graphAst, &graph); err != nil {
+		return graph, nil, errors.Wrap(err, "failed to load config file")
+	}
+
+	return graph, templates, nil
+}
+
+// sortedNodeKeys returns the names of the given nodes, in order, so that merging is deterministic.
+func sortedNodeKeys(nodes map[string]node) []string {
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// sortedAttrKeys returns the keys of the given attributes, in order.
+func sortedAttrKeys(attrs map[string]string) []string {
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,163 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/cmd/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigDir writes the given files into a new temporary directory, returning the path to the directory.
+func writeConfigDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	}
+
+	return dir
+}
+
+func TestConfigIncludes(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		expectedError string
+		notifiers     []string
+	}{
+		{
+			name: "included files are merged",
+			files: map[string]string{
+				"kiora.dot":     `digraph config { include = "teams/*.dot"; console [type="stdout"]; alerts -> console; }`,
+				"teams/foo.dot": `digraph foo { foo [type="stdout"]; alerts -> foo [type="regex" field="team" regex="foo"]; }`,
+				"teams/bar.dot": `digraph bar { bar [type="stdout"]; alerts -> bar [type="regex" field="team" regex="bar"]; }`,
+			},
+			notifiers: []string{"console", "foo"},
+		},
+		{
+			name: "includes can be nested and overlap",
+			files: map[string]string{
+				"kiora.dot":      `digraph config { include = "teams/*.dot, teams/foo.dot"; }`,
+				"teams/foo.dot":  `digraph foo { include = "../shared.dot"; alerts -> shared; }`,
+				"shared.dot":     `digraph shared { include = "kiora.dot"; shared [type="stdout"]; }`,
+				"teams/none.dot": `digraph none {}`,
+			},
+			notifiers: []string{"shared"},
+		},
+		{
+			name: "an empty glob is fine",
+			files: map[string]string{
+				"kiora.dot": `digraph config { include = "teams/*.dot"; }`,
+			},
+			notifiers: []string{},
+		},
+		{
+			name: "a missing file is an error",
+			files: map[string]string{
+				"kiora.dot": `digraph config { include = "team.dot"; }`,
+			},
+			expectedError: "team.dot, which doesn't exist",
+		},
+		{
+			name: "node collisions are reported",
+			files: map[string]string{
+				"kiora.dot":     `digraph config { include = "teams/*.dot"; }`,
+				"teams/foo.dot": `digraph foo { console [type="stdout"]; alerts -> console; }`,
+				"teams/bar.dot": `digraph bar { console [type="stderr"]; alerts -> console; }`,
+			},
+			expectedError: `node "console" is defined in both`,
+		},
+		{
+			name: "included files can't set globals",
+			files: map[string]string{
+				"kiora.dot":     `digraph config { include = "teams/*.dot"; }`,
+				"teams/foo.dot": `digraph foo { tenant_key = "foo"; }`,
+			},
+			expectedError: `sets the graph attribute "tenant_key"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.RegisterNodes()
+			dir := writeConfigDir(t, tt.files)
+
+			cfg, err := config.LoadConfigFile(filepath.Join(dir, "kiora.dot"), zerolog.Nop())
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+
+			alert := model.Alert{Labels: model.Labels{"team": "foo"}}
+			notifiers := []string{}
+			for _, route := range cfg.RouteAlert(context.Background(), &alert) {
+				notifiers = append(notifiers, string(route.Name()))
+			}
+
+			require.ElementsMatch(t, tt.notifiers, notifiers)
+		})
+	}
+}
+
+func TestConfigIncludesRelativePaths(t *testing.T) {
+	config.RegisterNodes()
+	dir := writeConfigDir(t, map[string]string{
+		"kiora.dot":          `digraph config { include = "teams/*.dot"; console [type="stdout"]; enrich -> console; }`,
+		"teams/foo.dot":      `digraph foo { enrich [type="enrich" path="services.csv" keys="service" labels="team"]; alerts -> enrich; }`,
+		"teams/services.csv": "service,team\nfoo,team-foo\n",
+	})
+
+	// The lookup file is next to the file that defines the node, not the main config file.
+	cfg, err := config.LoadConfigFile(filepath.Join(dir, "kiora.dot"), zerolog.New(os.Stdout))
+	require.NoError(t, err)
+
+	alert := model.Alert{Labels: model.Labels{"service": "foo"}}
+	require.NoError(t, cfg.TransformAlert(context.TODO(), &alert))
+	require.Equal(t, "team-foo", alert.Labels["team"])
+}
+
+func TestFormatConfigErrorInIncludedFile(t *testing.T) {
+	config.RegisterNodes()
+	dir := writeConfigDir(t, map[string]string{
+		"kiora.dot":     `digraph config { include = "teams/*.dot"; }`,
+		"teams/foo.dot": "digraph foo {\n  alerts -> ;\n}",
+	})
+
+	_, err := config.LoadConfigFile(filepath.Join(dir, "kiora.dot"), zerolog.Nop())
+	require.Error(t, err)
+	require.Contains(t, config.FormatConfigError(filepath.Join(dir, "kiora.dot"), err), filepath.Join(dir, "teams/foo.dot")+":2:")
+}
+
+func TestConfigFileWatcherIncludes(t *testing.T) {
+	config.RegisterNodes()
+	dir := writeConfigDir(t, map[string]string{
+		"kiora.dot":     `digraph config { include = "teams/*.dot"; }`,
+		"teams/foo.dot": `digraph foo { foo [type="stdout"]; alerts -> foo; }`,
+	})
+
+	reloadable, err := config.NewReloadableConfigFile(filepath.Join(dir, "kiora.dot"), zerolog.Nop())
+	require.NoError(t, err)
+
+	watcher := config.NewConfigFileWatcher(filepath.Join(dir, "kiora.dot"), 0, reloadable, zerolog.Nop())
+	watcher.Check(context.Background())
+	require.Equal(t, uint64(0), reloadable.Stats().Successes)
+
+	// Changing an included file should trigger a reload.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "teams/foo.dot"), []byte(`digraph foo { bar [type="stdout"]; alerts -> bar; }`), 0o644))
+	watcher.Check(context.Background())
+	require.Equal(t, uint64(1), reloadable.Stats().Successes)
+
+	// As should adding a new one.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "teams/baz.dot"), []byte(`digraph baz { baz [type="stdout"]; alerts -> baz; }`), 0o644))
+	watcher.Check(context.Background())
+	require.Equal(t, uint64(2), reloadable.Stats().Successes)
+}
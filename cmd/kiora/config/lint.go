@@ -0,0 +1,243 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/config/filters/regex"
+)
+
+// LintWarning is a problem with a config that doesn't stop it from loading, but probably isn't what the author intended.
+type LintWarning struct {
+	// Node is the name of the node that the warning is about, if any.
+	Node string
+
+	// Message describes the problem.
+	Message string
+}
+
+func (l LintWarning) String() string {
+	if l.Node == "" {
+		return l.Message
+	}
+
+	return fmt.Sprintf("%q: %s", l.Node, l.Message)
+}
+
+// gographvizPosRegex matches the position that gographviz includes in its parse errors.
+var gographvizPosRegex = regexp.MustCompile(`Pos\(offset=\d+, line=(\d+), column=(\d+)\)`)
+
+// FormatConfigError formats an error from loading the config file at the given path, prefixing it
+// with the line and column that the error occurred at if gographviz gave us one. If the error was in a file
+// that the config includes, the position is given in that file instead.
+func FormatConfigError(path string, err error) string {
+	included := &includedFileError{}
+	if errors.As(err, &included) {
+		path = included.path
+	}
+
+	if match := gographvizPosRegex.FindStringSubmatch(err.Error()); match != nil {
+		return fmt.Sprintf("%s:%s:%s: %s", path, match[1], match[2], err.Error())
+	}
+
+	return fmt.Sprintf("%s: %s", path, err.Error())
+}
+
+// Lint checks the config for things that are valid, but are probably mistakes - nodes that can never be reached,
+// anchors that go nowhere, templates that are never used, and chains of filters that can never all match.
+func (c *ConfigFile) Lint() []LintWarning {
+	warnings := []LintWarning{}
+
+	fromAlerts := c.reachableFrom(ALERT_ROOT, c.links)
+
+	// Nodes that lead into a pseudo-node (including into `alerts`) are used to validate data.
+	validators := HashSet{}
+	for _, leaf := range []string{ALERT_ROOT, SILENCES_LEAF, ACK_LEAF} {
+		for name := range c.reachableFrom(leaf, c.reverseLinks) {
+			validators[name] = struct{}{}
+		}
+	}
+
+	for _, name := range c.sortedNodeNames() {
+		if isPseudoNode(name) {
+			continue
+		}
+
+		node := c.nodes[name]
+		_, reachable := fromAlerts[name]
+		_, validates := validators[name]
+
+		if _, ok := node.(config.Notifier); ok && !reachable {
+			warnings = append(warnings, LintWarning{Node: name, Message: "notifier is not reachable from alerts, so it will never be notified"})
+			continue
+		}
+
+		if !reachable && !validates {
+			warnings = append(warnings, LintWarning{Node: name, Message: "node has no path from alerts, and no path into a pseudo-node, so it does nothing"})
+			continue
+		}
+
+		if _, ok := node.(*config.AnchorNode); ok && len(c.links[name]) == 0 {
+			warnings = append(warnings, LintWarning{Node: name, Message: "anchor has no outgoing edges"})
+		}
+	}
+
+	for _, name := range c.unusedTemplates {
+		warnings = append(warnings, LintWarning{Message: fmt.Sprintf("template %q is never instantiated", name)})
+	}
+
+	return append(warnings, c.lintContradictoryFilters()...)
+}
+
+// reachableFrom returns the set of nodes that can be reached from the given node by following the given links, ignoring filters.
+func (c *ConfigFile) reachableFrom(start string, links map[string][]Link) HashSet {
+	visited := HashSet{}
+	stack := []string{start}
+	for len(stack) > 0 {
+		nodeName := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, ok := visited[nodeName]; ok {
+			continue
+		}
+
+		visited[nodeName] = struct{}{}
+		for _, link := range links[nodeName] {
+			stack = append(stack, link.to)
+		}
+	}
+
+	return visited
+}
+
+// regexEdge is an edge in the graph with a (non-negated) regex filter on it.
+type regexEdge struct {
+	from, to string
+	filter   *regex.RegexFilter
+}
+
+// lintContradictoryFilters looks for regex filters on the same field along a path, where one filter only matches a single value that
+// the other filter doesn't match. Data can never pass both of those filters, so anything below them is unreachable.
+func (c *ConfigFile) lintContradictoryFilters() []LintWarning {
+	warnings := []LintWarning{}
+	seen := map[string]struct{}{}
+
+	// Rather than walking every path, which grows exponentially with the number of branches, we work out the regex filters on every
+	// path into each node once. Any of them can be on the same path as a filter on an edge out of the node.
+	upstream := map[string][]regexEdge{}
+	var filtersInto func(nodeName string) []regexEdge
+	filtersInto = func(nodeName string) []regexEdge {
+		if filters, ok := upstream[nodeName]; ok {
+			return filters
+		}
+
+		// Configs can't have cycles, but this stops us from recursing forever if one slips through.
+		upstream[nodeName] = nil
+
+		filters := []regexEdge{}
+		added := map[regexEdge]struct{}{}
+		add := func(edge regexEdge) {
+			if _, ok := added[edge]; !ok {
+				added[edge] = struct{}{}
+				filters = append(filters, edge)
+			}
+		}
+
+		for _, link := range c.reverseLinks[nodeName] {
+			for _, edge := range filtersInto(link.to) {
+				add(edge)
+			}
+
+			if filter, ok := link.incomingFilter.(*regex.RegexFilter); ok && !filter.Negate {
+				add(regexEdge{from: link.to, to: nodeName, filter: filter})
+			}
+		}
+
+		upstream[nodeName] = filters
+		return filters
+	}
+
+	for _, nodeName := range c.sortedNodeNames() {
+		for _, link := range c.links[nodeName] {
+			filter, ok := link.incomingFilter.(*regex.RegexFilter)
+			if !ok || filter.Negate {
+				continue
+			}
+
+			for _, previous := range filtersInto(nodeName) {
+				if previous.filter.Label != filter.Label || !filtersContradict(previous.filter, filter) {
+					continue
+				}
+
+				message := fmt.Sprintf("filter on edge %s -> %s (%s=~%q) can never match along with the filter on edge %s -> %s (%s=~%q)",
+					nodeName, link.to, filter.Label, filter.Regex.String(), previous.from, previous.to, previous.filter.Label, previous.filter.Regex.String())
+				if _, ok := seen[message]; !ok {
+					seen[message] = struct{}{}
+					warnings = append(warnings, LintWarning{Message: message})
+				}
+			}
+		}
+	}
+
+	return warnings
+}
+
+// filtersContradict returns true if one of the given filters only matches a single value, which the other filter doesn't match.
+func filtersContradict(a, b *regex.RegexFilter) bool {
+	if value, ok := exactMatch(a.Regex.String()); ok && !b.Regex.MatchString(value) {
+		return true
+	}
+
+	if value, ok := exactMatch(b.Regex.String()); ok && !a.Regex.MatchString(value) {
+		return true
+	}
+
+	return false
+}
+
+// exactMatch returns the only string that the given regex can match, if it's an anchored literal like `^foo$`.
+func exactMatch(rawRegex string) (string, bool) {
+	re, err := syntax.Parse(rawRegex, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+
+	re = re.Simplify()
+	if re.Op != syntax.OpConcat || len(re.Sub) != 3 {
+		return "", false
+	}
+
+	begin, literal, end := re.Sub[0], re.Sub[1], re.Sub[2]
+	if begin.Op != syntax.OpBeginText || end.Op != syntax.OpEndText {
+		return "", false
+	}
+
+	if literal.Op == syntax.OpCapture && len(literal.Sub) == 1 {
+		literal = literal.Sub[0]
+	}
+
+	if literal.Op != syntax.OpLiteral || literal.Flags&syntax.FoldCase != 0 {
+		return "", false
+	}
+
+	return string(literal.Rune), true
+}
+
+// sortedNodeNames returns the names of all the nodes in the config, in order, so that lint output is deterministic.
+func (c *ConfigFile) sortedNodeNames() []string {
+	names := make([]string, 0, len(c.nodes))
+	for name := range c.nodes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func isPseudoNode(name string) bool {
+	return name == ALERT_ROOT || name == SILENCES_LEAF || name == ACK_LEAF
+}
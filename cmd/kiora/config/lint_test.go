@@ -0,0 +1,147 @@
+package config_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/cmd/kiora/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigLint(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           string
+		expectedWarnings []string
+	}{
+		{
+			name: "clean config",
+			config: `digraph Config {
+				console [type="stdout"];
+				alerts -> console;
+				validate -> silences [type="regex" field="__creator__" regex=".+@example.com"];
+			}`,
+			expectedWarnings: []string{},
+		},
+		{
+			name: "unreachable notifier",
+			config: `digraph Config {
+				console [type="stdout"];
+				foo -> console;
+			}`,
+			expectedWarnings: []string{
+				`"console": notifier is not reachable from alerts, so it will never be notified`,
+				`"foo": node has no path from alerts, and no path into a pseudo-node, so it does nothing`,
+			},
+		},
+		{
+			name: "anchor with no outgoing edges",
+			config: `digraph Config {
+				alerts -> foo;
+			}`,
+			expectedWarnings: []string{
+				`"foo": anchor has no outgoing edges`,
+			},
+		},
+		{
+			name: "contradictory filters",
+			config: `digraph Config {
+				console [type="stdout"];
+				alerts -> prod [type="regex" field="env" regex="^prod$"];
+				prod -> console [type="regex" field="env" regex="dev"];
+			}`,
+			expectedWarnings: []string{
+				`filter on edge prod -> console (env=~"dev") can never match along with the filter on edge alerts -> prod (env=~"^prod$")`,
+			},
+		},
+		{
+			name: "unused template",
+			config: `digraph Config {
+				subgraph used {
+					input -> console;
+					console [type="stdout"];
+				}
+
+				subgraph unused {
+					input -> console;
+					console [type="stdout"];
+				}
+
+				route [type="template" template="used"];
+				alerts -> route;
+			}`,
+			expectedWarnings: []string{
+				`template "unused" is never instantiated`,
+			},
+		},
+		{
+			name: "compatible filters",
+			config: `digraph Config {
+				console [type="stdout"];
+				alerts -> prod [type="regex" field="env" regex="^prod$"];
+				prod -> console [type="regex" field="env" regex="pro.*"];
+			}`,
+			expectedWarnings: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.RegisterNodes()
+			fileName := writeConfigFile(t, tt.config)
+			defer os.Remove(fileName)
+
+			conf, err := config.LoadConfigFile(fileName, zerolog.Nop())
+			require.NoError(t, err)
+
+			warnings := []string{}
+			for _, warning := range conf.Lint() {
+				warnings = append(warnings, warning.String())
+			}
+
+			require.Equal(t, tt.expectedWarnings, warnings)
+		})
+	}
+}
+
+func TestFormatConfigError(t *testing.T) {
+	config.RegisterNodes()
+	fileName := writeConfigFile(t, "digraph Config {\n\talerts -> \n}")
+	defer os.Remove(fileName)
+
+	_, err := config.LoadConfigFile(fileName, zerolog.Nop())
+	require.Error(t, err)
+	require.Regexp(t, "^"+fileName+`:3:1: `, config.FormatConfigError(fileName, err))
+
+	require.Equal(t, "foo.dot: bar", config.FormatConfigError("foo.dot", errors.New("bar")))
+}
+
+func TestConfigLintManyPaths(t *testing.T) {
+	config.RegisterNodes()
+
+	// A chain of diamonds has 2^n paths through it, which is far too many to walk one at a time.
+	graph := "digraph Config {\n\tvalidate -> layer_0 [type=\"regex\" field=\"__creator__\" regex=\"^admin$\"];\n"
+	for i := 0; i < 64; i++ {
+		graph += fmt.Sprintf("\tlayer_%d -> left_%d -> layer_%d;\n\tlayer_%d -> right_%d -> layer_%d;\n", i, i, i+1, i, i, i+1)
+	}
+
+	graph += "\tlayer_64 -> silences [type=\"regex\" field=\"__creator__\" regex=\"bob\"];\n}"
+
+	fileName := writeConfigFile(t, graph)
+	defer os.Remove(fileName)
+
+	conf, err := config.LoadConfigFile(fileName, zerolog.Nop())
+	require.NoError(t, err)
+
+	warnings := []string{}
+	for _, warning := range conf.Lint() {
+		warnings = append(warnings, warning.String())
+	}
+
+	require.Equal(t, []string{
+		`filter on edge layer_64 -> silences (__creator__=~"bob") can never match along with the filter on edge validate -> layer_0 (__creator__=~"^admin$")`,
+	}, warnings)
+}
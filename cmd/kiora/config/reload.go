@@ -0,0 +1,126 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+)
+
+// DefaultWatchInterval is the default amount of time between checks of the config file for changes.
+const DefaultWatchInterval = 10 * time.Second
+
+// NewReloadableConfigFile loads the config file at the given path into a config that re-reads the file every time it is reloaded.
+// Tenants whose configs become invalid keep the config they had before the reload.
+func NewReloadableConfigFile(path string, logger zerolog.Logger) (*config.ReloadableConfig, error) {
+	var previous *ConfigFile
+	return config.NewReloadableConfig(func() (config.Config, error) {
+		conf, err := LoadConfigFile(path, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		if previous != nil {
+			conf.keepTenantConfigs(previous)
+		}
+
+		previous = conf
+		return conf, nil
+	})
+}
+
+// ConfigFileWatcher is a service that polls a config file for changes, reloading the config when its contents change.
+type ConfigFileWatcher struct {
+	path     string
+	interval time.Duration
+	reloader config.Reloader
+	logger   zerolog.Logger
+
+	// lastHash is the hash of the contents of the config files the last time we looked at them.
+	lastHash []byte
+}
+
+func NewConfigFileWatcher(path string, interval time.Duration, reloader config.Reloader, logger zerolog.Logger) *ConfigFileWatcher {
+	watcher := &ConfigFileWatcher{
+		path:     path,
+		interval: interval,
+		reloader: reloader,
+		logger:   logger.With().Str("component", "config_watcher").Logger(),
+	}
+
+	// Seed the hash with the current contents so that we don't immediately reload the config we just loaded.
+	watcher.lastHash, _ = hashConfigFiles(path)
+
+	return watcher
+}
+
+func (c *ConfigFileWatcher) Name() string {
+	return "config_watcher"
+}
+
+// Run checks the config file every interval until the given context is done.
+func (c *ConfigFileWatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			c.Check(ctx)
+		}
+	}
+}
+
+// Check reloads the config if the contents of the config file, or any of the files it includes, have changed since the last check. Failed reloads
+// aren't retried until the file changes again.
+func (c *ConfigFileWatcher) Check(ctx context.Context) {
+	hash, err := hashConfigFiles(c.path)
+	if err != nil {
+		c.logger.Warn().Err(err).Msg("failed to read config file")
+		return
+	}
+
+	if bytes.Equal(hash, c.lastHash) {
+		return
+	}
+
+	c.lastHash = hash
+
+	if err := c.reloader.Reload(ctx); err != nil {
+		c.logger.Error().Err(err).Msg("config file changed, but the new config is invalid. Keeping the old config")
+		return
+	}
+
+	c.logger.Info().Msg("config file changed, reloaded config")
+}
+
+// hashConfigFiles returns the sha256 hash of the names and contents of the config file at the given path, every file that it includes,
+// and every tenant config.
+func hashConfigFiles(path string) ([]byte, error) {
+	// Errors in the config itself are reported when it's reloaded - here we only care about which files were read.
+	graph, files, _ := loadConfigGraph(path)
+	if len(files) == 0 {
+		files = []string{path}
+	}
+
+	files = append(files, tenantConfigFilesFor(path, graph)...)
+
+	hash := sha256.New()
+	for _, file := range files {
+		body, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		hash.Write([]byte(file))
+		hash.Write(body)
+	}
+
+	return hash.Sum(nil), nil
+}
@@ -0,0 +1,52 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/cmd/kiora/config"
+	kconfig "github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFileWatcher(t *testing.T) {
+	config.RegisterNodes()
+
+	fileName := writeConfigFile(t, `digraph Config { tenant_key = "foo" }`)
+	defer os.Remove(fileName)
+
+	reloadable, err := config.NewReloadableConfigFile(fileName, zerolog.Nop())
+	require.NoError(t, err)
+
+	watcher := config.NewConfigFileWatcher(fileName, time.Second, reloadable, zerolog.Nop())
+
+	requireTenant := func(expected kconfig.Tenant) {
+		t.Helper()
+		tenant, err := reloadable.Globals().Tenanter.GetTenant(context.Background(), &model.Alert{})
+		require.NoError(t, err)
+		require.Equal(t, expected, tenant)
+	}
+
+	// An unchanged file shouldn't trigger a reload.
+	watcher.Check(context.Background())
+	require.Equal(t, uint64(0), reloadable.Stats().Successes)
+	requireTenant("foo")
+
+	// An invalid config should be rejected, leaving the old one in place.
+	require.NoError(t, os.WriteFile(fileName, []byte(`digraph Config { foo = "bar" }`), 0o644))
+	watcher.Check(context.Background())
+	require.Equal(t, uint64(1), reloadable.Stats().Failures)
+	require.False(t, reloadable.Stats().LastReloadSuccessful)
+	requireTenant("foo")
+
+	// A valid config should be swapped in.
+	require.NoError(t, os.WriteFile(fileName, []byte(`digraph Config { tenant_key = "bar" }`), 0o644))
+	watcher.Check(context.Background())
+	require.Equal(t, uint64(1), reloadable.Stats().Successes)
+	require.True(t, reloadable.Stats().LastReloadSuccessful)
+	requireTenant("bar")
+}
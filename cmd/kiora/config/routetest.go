@@ -0,0 +1,180 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"gopkg.in/yaml.v3"
+)
+
+// RouteTestFile is a declarative set of tests for the routing of a config. e.g.
+//
+//	config: ./kiora.dot
+//	tests:
+//	  - name: critical alerts page the on-call
+//	    alert:
+//	      labels:
+//	        severity: critical
+//	    notifiers:
+//	      - name: pagerduty
+//	        group_wait: 30s
+//	      - name: slack
+type RouteTestFile struct {
+	// Config is the path to the config file to test, relative to the test file.
+	Config string `yaml:"config"`
+
+	// Tests are the test cases to run against the config.
+	Tests []RouteTest `yaml:"tests"`
+}
+
+// RouteTest is a single alert, and the notifiers that it is expected to be routed to.
+type RouteTest struct {
+	// Name is a human readable name for the test.
+	Name string `yaml:"name"`
+
+	// Alert is the alert to route.
+	Alert struct {
+		Labels      model.Labels      `yaml:"labels"`
+		Annotations map[string]string `yaml:"annotations"`
+	} `yaml:"alert"`
+
+	// Notifiers are the notifiers that the alert is expected to be routed to, in any order. An empty list asserts that the alert goes nowhere.
+	Notifiers []ExpectedRoute `yaml:"notifiers"`
+}
+
+// ExpectedRoute is a notifier that an alert is expected to be sent to. The group settings are only checked if they are set.
+type ExpectedRoute struct {
+	Name        string         `yaml:"name"`
+	GroupLabels []string       `yaml:"group_labels"`
+	GroupWait   *time.Duration `yaml:"group_wait"`
+}
+
+// LoadRouteTestFile reads a route test file, resolving the path to the config relative to the test file.
+func LoadRouteTestFile(path string) (*RouteTestFile, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read route test file")
+	}
+
+	testFile := &RouteTestFile{}
+	decoder := yaml.NewDecoder(bytes.NewReader(body))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(testFile); err != nil {
+		return nil, errors.Wrap(err, "failed to parse route test file")
+	}
+
+	if testFile.Config != "" && !filepath.IsAbs(testFile.Config) {
+		testFile.Config = filepath.Join(filepath.Dir(path), testFile.Config)
+	}
+
+	return testFile, nil
+}
+
+// RunRouteTests transforms and routes the alert in each of the given tests, returning a description of every
+// test whose alert didn't go to exactly the expected notifiers.
+func (c *ConfigFile) RunRouteTests(ctx context.Context, tests []RouteTest) []string {
+	failures := []string{}
+	for i, test := range tests {
+		name := test.Name
+		if name == "" {
+			name = fmt.Sprintf("test %d", i)
+		}
+
+		alert := model.Alert{
+			Labels:      model.Labels{},
+			Annotations: map[string]string{},
+			Status:      model.AlertStatusFiring,
+		}
+
+		for k, v := range test.Alert.Labels {
+			alert.Labels[k] = v
+		}
+
+		for k, v := range test.Alert.Annotations {
+			alert.Annotations[k] = v
+		}
+
+		if err := c.TransformAlert(ctx, &alert); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: failed to transform alert: %s", name, err))
+			continue
+		}
+
+		actual := map[string][]ExpectedRoute{}
+		actualNames := []string{}
+		for _, route := range c.RouteAlert(ctx, &alert) {
+			groupWait := route.GroupWait
+			notifierName := string(route.Name())
+			actual[notifierName] = append(actual[notifierName], ExpectedRoute{
+				Name:        notifierName,
+				GroupLabels: route.GroupLabels,
+				GroupWait:   &groupWait,
+			})
+
+			actualNames = append(actualNames, fmt.Sprintf("%s (via %s)", notifierName, strings.Join(route.Path, " -> ")))
+		}
+
+		sort.Strings(actualNames)
+
+		expectedNames := make([]string, 0, len(test.Notifiers))
+		for _, expected := range test.Notifiers {
+			expectedNames = append(expectedNames, expected.Name)
+		}
+
+		sort.Strings(expectedNames)
+
+		if msg := compareRoutes(test.Notifiers, actual); msg != "" {
+			failures = append(failures, fmt.Sprintf("%s: %s. Expected [%s], got [%s]", name, msg, strings.Join(expectedNames, ", "), strings.Join(actualNames, ", ")))
+		}
+	}
+
+	return failures
+}
+
+// compareRoutes returns a description of the first difference between the expected routes and the actual ones,
+// or an empty string if they match.
+func compareRoutes(expected []ExpectedRoute, actual map[string][]ExpectedRoute) string {
+	remaining := map[string][]ExpectedRoute{}
+	for name, routes := range actual {
+		remaining[name] = routes
+	}
+
+	for _, want := range expected {
+		routes := remaining[want.Name]
+		if len(routes) == 0 {
+			return fmt.Sprintf("alert was not routed to %q", want.Name)
+		}
+
+		got := routes[0]
+		remaining[want.Name] = routes[1:]
+
+		if want.GroupLabels != nil && strings.Join(want.GroupLabels, ",") != strings.Join(got.GroupLabels, ",") {
+			return fmt.Sprintf("%q has group labels %q, expected %q", want.Name, got.GroupLabels, want.GroupLabels)
+		}
+
+		if want.GroupWait != nil && *want.GroupWait != *got.GroupWait {
+			return fmt.Sprintf("%q has group wait %s, expected %s", want.Name, *got.GroupWait, *want.GroupWait)
+		}
+	}
+
+	names := make([]string, 0, len(remaining))
+	for name, routes := range remaining {
+		if len(routes) > 0 {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) > 0 {
+		sort.Strings(names)
+		return fmt.Sprintf("alert was unexpectedly routed to %q", names)
+	}
+
+	return ""
+}
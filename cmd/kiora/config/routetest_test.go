@@ -0,0 +1,95 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/cmd/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+const routingConfig = `digraph Config {
+	pager [type="stdout"];
+	console [type="stdout"];
+	slow [type="group_wait" duration="1m"];
+
+	alerts -> pager [type="regex" field="severity" regex="critical"];
+	alerts -> slow -> console;
+}`
+
+func TestConfigRouteAlert(t *testing.T) {
+	config.RegisterNodes()
+	fileName := writeConfigFile(t, routingConfig)
+	defer os.Remove(fileName)
+
+	conf, err := config.LoadConfigFile(fileName, zerolog.Nop())
+	require.NoError(t, err)
+
+	routes := conf.RouteAlert(context.Background(), &model.Alert{Labels: model.Labels{"severity": "critical"}})
+	require.Len(t, routes, 2)
+
+	paths := map[string][]string{}
+	for _, route := range routes {
+		paths[string(route.Name())] = route.Path
+		if route.Name() == "console" {
+			require.Equal(t, "1m0s", route.GroupWait.String())
+		}
+	}
+
+	require.Equal(t, map[string][]string{
+		"pager":   {"alerts", "pager"},
+		"console": {"alerts", "slow", "console"},
+	}, paths)
+
+	routes = conf.RouteAlert(context.Background(), &model.Alert{Labels: model.Labels{"severity": "warning"}})
+	require.Len(t, routes, 1)
+	require.Equal(t, "console", string(routes[0].Name()))
+}
+
+func TestRunRouteTests(t *testing.T) {
+	config.RegisterNodes()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kiora.dot"), []byte(routingConfig), 0o644))
+
+	testFileName := filepath.Join(dir, "tests.yaml")
+	require.NoError(t, os.WriteFile(testFileName, []byte(`config: kiora.dot
+tests:
+  - name: critical alerts page
+    alert:
+      labels:
+        severity: critical
+    notifiers:
+      - name: pager
+      - name: console
+        group_wait: 1m
+  - name: warnings don't page
+    alert:
+      labels:
+        severity: warning
+    notifiers:
+      - name: pager
+  - name: wrong group wait
+    alert:
+      labels:
+        severity: warning
+    notifiers:
+      - name: console
+        group_wait: 10s
+`), 0o644))
+
+	testFile, err := config.LoadRouteTestFile(testFileName)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "kiora.dot"), testFile.Config)
+
+	conf, err := config.LoadConfigFile(testFile.Config, zerolog.Nop())
+	require.NoError(t, err)
+
+	failures := conf.RunRouteTests(context.Background(), testFile.Tests)
+	require.Len(t, failures, 2)
+	require.Contains(t, failures[0], `warnings don't page: alert was not routed to "pager"`)
+	require.Contains(t, failures[1], `wrong group wait: "console" has group wait 1m0s, expected 10s`)
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/awalterschulze/gographviz"
+	"github.com/awalterschulze/gographviz/ast"
+	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/lib/kiora/config/unmarshal"
+)
+
+const (
+	// TEMPLATE_NODE_TYPE is the type of nodes that instantiate a template.
+	TEMPLATE_NODE_TYPE = "template"
+
+	// TEMPLATE_INPUT is the node in a template that edges into an instance of the template lead to.
+	TEMPLATE_INPUT = "input"
+
+	// TEMPLATE_OUTPUT is the node in a template that edges out of an instance of the template come from.
+	TEMPLATE_OUTPUT = "output"
+)
+
+// templateParamRegex matches references to template parameters in attributes, e.g. `$(team)`.
+var templateParamRegex = regexp.MustCompile(`\$\((\w+)\)`)
+
+// configTemplate is a subgraph that can be instantiated multiple times in a config, with different parameters. e.g.
+//
+//	subgraph team_route {
+//		params = "team,channel";
+//		input -> notify [type="regex" field="team" regex="$(team)"];
+//		notify [type="slack" channel="$(channel)"];
+//	}
+//
+//	team_foo [type="template" template="team_route" team="foo" channel="#foo"];
+//	alerts -> team_foo;
+type configTemplate struct {
+	name string
+
+	// params are the names of the parameters that every instance of the template must set.
+	params []string
+
+	graph configGraph
+}
+
+// parseTemplate loads the given subgraph as a template.
+func parseTemplate(subGraph *ast.SubGraph) (configTemplate, error) {
+	template := configTemplate{
+		name:  strings.Trim(subGraph.ID.String(), "\""),
+		graph: newConfigGraph(),
+	}
+
+	graphAst := &ast.Graph{
+		Type:     ast.DIGRAPH,
+		ID:       subGraph.ID,
+		StmtList: subGraph.StmtList,
+	}
+
+	if err := gographviz.Analyse(graphAst, &template.graph); err != nil {
+		return template, errors.Wrapf(err, "failed to load template %q", template.name)
+	}
+
+	if len(template.graph.subGraphs) > 0 {
+		return template, fmt.Errorf("template %q can't contain subgraphs", template.name)
+	}
+
+	params, _, err := unmarshal.Interpolate(template.graph.attrs["params"])
+	if err != nil {
+		return template, errors.Wrapf(err, "invalid params for template %q", template.name)
+	}
+
+	for _, param := range strings.Split(params, ",") {
+		if param = strings.TrimSpace(param); param != "" {
+			template.params = append(template.params, param)
+		}
+	}
+
+	delete(template.graph.attrs, "params")
+	if len(template.graph.attrs) > 0 {
+		return template, fmt.Errorf("template %q has unknown attribute %q", template.name, sortedAttrKeys(template.graph.attrs)[0])
+	}
+
+	if _, ok := template.graph.nodes[TEMPLATE_INPUT]; !ok {
+		return template, fmt.Errorf("template %q doesn't have an %q node", template.name, TEMPLATE_INPUT)
+	}
+
+	for _, name := range sortedNodeKeys(template.graph.nodes) {
+		node := template.graph.nodes[name]
+		if (name == TEMPLATE_INPUT || name == TEMPLATE_OUTPUT) && len(node.attrs) > 0 {
+			return template, fmt.Errorf("the %q node in template %q can't have any attributes", name, template.name)
+		}
+
+		if node.attrs["type"] == TEMPLATE_NODE_TYPE {
+			return template, fmt.Errorf("templates can't instantiate other templates, but %q in template %q does", name, template.name)
+		}
+	}
+
+	return template, nil
+}
+
+// instantiateTemplates replaces every node of type `template` in the graph with a copy of the template it references. The nodes in
+// the template are prefixed with the name of the instance (e.g. `team_foo/notify`), except for pseudo-nodes, which are shared.
+func instantiateTemplates(graph *configGraph, templates map[string]configTemplate) error {
+	for _, name := range sortedNodeKeys(graph.nodes) {
+		instance := graph.nodes[name]
+		if instance.attrs["type"] != TEMPLATE_NODE_TYPE {
+			continue
+		}
+
+		templateName, _, err := unmarshal.Interpolate(instance.attrs["template"])
+		if err != nil {
+			return errors.Wrapf(err, "node %q has an invalid template", name)
+		}
+
+		template, ok := templates[templateName]
+		if !ok {
+			return fmt.Errorf("node %q instantiates unknown template %q", name, templateName)
+		}
+
+		params := make(map[string]string, len(instance.attrs))
+		for k, v := range instance.attrs {
+			if k != "type" && k != "template" {
+				params[k] = v
+			}
+		}
+
+		for _, param := range template.params {
+			if _, ok := params[param]; !ok {
+				return fmt.Errorf("node %q is missing the parameter %q for template %q", name, param, template.name)
+			}
+		}
+
+		declared := toHashSet(template.params)
+		for _, param := range sortedAttrKeys(params) {
+			if _, ok := declared[param]; !ok {
+				return fmt.Errorf("template %q has no parameter %q, but %q sets it", template.name, param, name)
+			}
+		}
+
+		rename := func(nodeName string) string {
+			switch {
+			case nodeName == TEMPLATE_INPUT:
+				return name
+			case isPseudoNode(nodeName):
+				return nodeName
+			default:
+				return name + "/" + nodeName
+			}
+		}
+
+		// The instance becomes an anchor that leads into the template.
+		graph.nodes[name] = node{name: name, attrs: map[string]string{}, dir: instance.dir}
+
+		for _, templateNodeName := range sortedNodeKeys(template.graph.nodes) {
+			if templateNodeName == TEMPLATE_INPUT || isPseudoNode(templateNodeName) {
+				continue
+			}
+
+			newName := rename(templateNodeName)
+			if _, ok := graph.nodes[newName]; ok {
+				return fmt.Errorf("instantiating template %q as %q creates the node %q, which already exists", template.name, name, newName)
+			}
+
+			// Paths in templates are generally passed in as parameters, so they're relative to the file that instantiated it.
+			graph.nodes[newName] = node{
+				name:  newName,
+				attrs: substituteParams(template.graph.nodes[templateNodeName].attrs, params),
+				dir:   instance.dir,
+			}
+		}
+
+		// Edges out of the instance come out of the template's output.
+		_, hasOutput := template.graph.nodes[TEMPLATE_OUTPUT]
+		for i := range graph.edges {
+			if graph.edges[i].from != name {
+				continue
+			}
+
+			if !hasOutput {
+				return fmt.Errorf("%q has outgoing edges, but template %q doesn't have an %q node", name, template.name, TEMPLATE_OUTPUT)
+			}
+
+			graph.edges[i].from = rename(TEMPLATE_OUTPUT)
+		}
+
+		for _, templateEdge := range template.graph.edges {
+			graph.edges = append(graph.edges, edge{
+				from:  rename(templateEdge.from),
+				to:    rename(templateEdge.to),
+				attrs: substituteParams(templateEdge.attrs, params),
+			})
+		}
+	}
+
+	return nil
+}
+
+// unusedTemplates returns the names of the given templates that no node in the graph instantiates, in sorted order.
+func unusedTemplates(graph configGraph, templates map[string]configTemplate) []string {
+	used := HashSet{}
+	for _, node := range graph.nodes {
+		if node.attrs["type"] == TEMPLATE_NODE_TYPE {
+			// References that can't be interpolated are reported when the template is instantiated.
+			name, _, _ := unmarshal.Interpolate(node.attrs["template"])
+			used[name] = struct{}{}
+		}
+	}
+
+	unused := []string{}
+	for name := range templates {
+		if _, ok := used[name]; !ok {
+			unused = append(unused, name)
+		}
+	}
+
+	sort.Strings(unused)
+	return unused
+}
+
+// substituteParams returns a copy of the given attributes, with references to the given parameters replaced with their values.
+// References to anything that isn't a parameter are left alone.
+func substituteParams(attrs map[string]string, params map[string]string) map[string]string {
+	substituted := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		substituted[k] = templateParamRegex.ReplaceAllStringFunc(v, func(match string) string {
+			if value, ok := params[templateParamRegex.FindStringSubmatch(match)[1]]; ok {
+				return value
+			}
+
+			return match
+		})
+	}
+
+	return substituted
+}
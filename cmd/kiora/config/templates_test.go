@@ -0,0 +1,112 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/cmd/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+const teamRouteTemplate = `
+	subgraph team_route {
+		params = "team,path";
+		input -> notify [type="regex" field="team" regex="^$(team)$"];
+		notify [type="file" path="$(path)"];
+		notify -> output;
+	}
+`
+
+func TestConfigTemplates(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        string
+		expectedError string
+		routes        map[string][]string
+	}{
+		{
+			name: "templates can be instantiated multiple times",
+			config: `digraph config {` + teamRouteTemplate + `
+				team_foo [type="template" template="team_route" team="foo" path="/tmp/foo"];
+				team_bar [type="template" template="team_route" team="bar" path="/tmp/bar"];
+				console [type="stdout"];
+				alerts -> team_foo -> console;
+				alerts -> team_bar;
+			}`,
+			routes: map[string][]string{
+				"foo": {"alerts -> team_foo -> team_foo/notify", "alerts -> team_foo -> team_foo/notify -> team_foo/output -> console"},
+				"bar": {"alerts -> team_bar -> team_bar/notify"},
+				"baz": {},
+			},
+		},
+		{
+			name: "unknown template",
+			config: `digraph config {
+				team_foo [type="template" template="team_route" team="foo" path="/tmp/foo"];
+			}`,
+			expectedError: `node "team_foo" instantiates unknown template "team_route"`,
+		},
+		{
+			name: "missing parameter",
+			config: `digraph config {` + teamRouteTemplate + `
+				team_foo [type="template" template="team_route" team="foo"];
+			}`,
+			expectedError: `node "team_foo" is missing the parameter "path" for template "team_route"`,
+		},
+		{
+			name: "unknown parameter",
+			config: `digraph config {` + teamRouteTemplate + `
+				team_foo [type="template" template="team_route" team="foo" path="/tmp/foo" channel="foo"];
+			}`,
+			expectedError: `template "team_route" has no parameter "channel"`,
+		},
+		{
+			name: "template without an input",
+			config: `digraph config {
+				subgraph broken { notify [type="stdout"]; }
+			}`,
+			expectedError: `template "broken" doesn't have an "input" node`,
+		},
+		{
+			name: "edges out of a template without an output",
+			config: `digraph config {
+				subgraph console { input -> notify; notify [type="stdout"]; }
+				instance [type="template" template="console"];
+				alerts -> instance -> alerts;
+			}`,
+			expectedError: `"instance" has outgoing edges, but template "console" doesn't have an "output" node`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.RegisterNodes()
+			fileName := writeConfigFile(t, tt.config)
+			defer os.Remove(fileName)
+
+			cfg, err := config.LoadConfigFile(fileName, zerolog.Nop())
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+
+			for team, expected := range tt.routes {
+				alert := model.Alert{Labels: model.Labels{"team": team}}
+				paths := []string{}
+				for _, route := range cfg.RouteAlert(context.Background(), &alert) {
+					paths = append(paths, strings.Join(route.Path, " -> "))
+				}
+
+				sort.Strings(paths)
+				require.Equal(t, expected, paths, team)
+			}
+		})
+	}
+}
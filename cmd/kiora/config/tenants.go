@@ -0,0 +1,203 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/config/unmarshal"
+)
+
+const (
+	// TENANT_CONFIGS_ATTR is the graph attribute that points to a directory of per-tenant configs, relative to the main config file.
+	TENANT_CONFIGS_ATTR = "tenant_configs"
+
+	// TENANT_CONFIG_SUFFIX is the suffix of files in the tenant config directory. The rest of the file name is the tenant.
+	TENANT_CONFIG_SUFFIX = ".dot"
+)
+
+// TenantConfigError is an error loading the config of a single tenant. Tenant configs that fail to load don't stop the rest of the config
+// from loading, so they're reported separately.
+type TenantConfigError struct {
+	Tenant config.Tenant
+	Path   string
+	Err    error
+}
+
+func (e TenantConfigError) Error() string {
+	return fmt.Sprintf("failed to load the config for tenant %q from %s: %s", e.Tenant, e.Path, e.Err.Error())
+}
+
+func (e TenantConfigError) Unwrap() error {
+	return e.Err
+}
+
+// tenantConfigDir resolves the tenant config directory of the config file at the given path.
+func tenantConfigDir(path, dir string) string {
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+
+	return filepath.Join(filepath.Dir(path), dir)
+}
+
+// tenantConfigFiles returns the tenant config files in the given directory, keyed by tenant.
+func tenantConfigFiles(dir string) (map[config.Tenant]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read tenant config directory")
+	}
+
+	files := make(map[config.Tenant]string, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), TENANT_CONFIG_SUFFIX) {
+			continue
+		}
+
+		tenant := config.Tenant(strings.TrimSuffix(entry.Name(), TENANT_CONFIG_SUFFIX))
+		files[tenant] = filepath.Join(dir, entry.Name())
+	}
+
+	return files, nil
+}
+
+// loadTenantConfigs loads every config in the given directory as the config of the tenant it's named after. Tenants whose configs
+// are invalid are logged and recorded in tenantErrors, rather than failing the whole config, so that one tenant can't break routing for the others.
+func (c *ConfigFile) loadTenantConfigs(dir string) error {
+	files, err := tenantConfigFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	logger := c.globals.Logger("tenant_configs")
+	for _, tenant := range sortedTenants(files) {
+		path := files[tenant]
+		tenantConf, err := loadTenantConfigFile(path, c.globals)
+		if err != nil {
+			tenantErr := TenantConfigError{Tenant: tenant, Path: path, Err: err}
+			c.tenantErrors = append(c.tenantErrors, tenantErr)
+			logger.Error().Err(tenantErr).Str("tenant", string(tenant)).Msg("invalid tenant config")
+			continue
+		}
+
+		c.tenants[tenant] = tenantConf
+	}
+
+	return nil
+}
+
+// loadTenantConfigFile loads the config of a single tenant. Tenant configs can include other files and use templates,
+// but share the globals of the main config, so they can't set graph attributes.
+func loadTenantConfigFile(path string, globals *config.Globals) (*ConfigFile, error) {
+	conf := newConfigFile(path)
+	conf.globals = globals
+
+	configGraph, _, err := loadConfigGraph(path)
+	if err != nil {
+		return conf, err
+	}
+
+	if len(configGraph.attrs) > 0 {
+		return conf, fmt.Errorf("tenant configs can't set graph attributes, but %s sets %q", path, sortedAttrKeys(configGraph.attrs)[0])
+	}
+
+	if err := conf.build(configGraph); err != nil {
+		return conf, err
+	}
+
+	return conf, conf.Validate()
+}
+
+// keepTenantConfigs replaces the configs of tenants that failed to load with the configs they had in the given, previous, version of the config.
+// This means that breaking a tenant config keeps that tenant running on its last good config, rather than falling back to the main config.
+func (c *ConfigFile) keepTenantConfigs(previous *ConfigFile) {
+	for _, tenantErr := range c.tenantErrors {
+		if tenantConf, ok := previous.tenants[tenantErr.Tenant]; ok {
+			c.tenants[tenantErr.Tenant] = tenantConf
+		}
+	}
+}
+
+// configFor returns the config that handles the given data - the config of the tenant that it belongs to if that tenant has its own,
+// or this config otherwise.
+func (c *ConfigFile) configFor(ctx context.Context, data config.Fielder) *ConfigFile {
+	if len(c.tenants) == 0 {
+		return c
+	}
+
+	tenant, err := c.globals.Tenanter.GetTenant(ctx, data)
+	if err != nil {
+		logger := c.globals.Logger("tenant_configs")
+		logger.Warn().Err(err).Msg("failed to get tenant, falling back to the main config")
+		return c
+	}
+
+	if tenantConf, ok := c.tenants[tenant]; ok {
+		return tenantConf
+	}
+
+	return c
+}
+
+// Tenants returns every tenant that has its own config, in order.
+func (c *ConfigFile) Tenants() []config.Tenant {
+	return sortedTenants(c.tenants)
+}
+
+// TenantConfig returns the config of the given tenant, if it has its own.
+func (c *ConfigFile) TenantConfig(tenant config.Tenant) (*ConfigFile, bool) {
+	tenantConf, ok := c.tenants[tenant]
+	return tenantConf, ok
+}
+
+// TenantErrors returns the errors of every tenant config that failed to load.
+func (c *ConfigFile) TenantErrors() []TenantConfigError {
+	return c.tenantErrors
+}
+
+// tenantConfigFilesFor returns the tenant config files of the config at the given path (and all the files that they include),
+// so that the watcher can tell when they change.
+func tenantConfigFilesFor(path string, graph configGraph) []string {
+	dir, ok := graph.attrs[TENANT_CONFIGS_ATTR]
+	if !ok {
+		return nil
+	}
+
+	dir, _, err := unmarshal.Interpolate(dir)
+	if err != nil {
+		return nil
+	}
+
+	files, err := tenantConfigFiles(tenantConfigDir(path, dir))
+	if err != nil {
+		return nil
+	}
+
+	allFiles := []string{}
+	for _, tenant := range sortedTenants(files) {
+		_, tenantFiles, _ := loadConfigGraph(files[tenant])
+		if len(tenantFiles) == 0 {
+			tenantFiles = []string{files[tenant]}
+		}
+
+		allFiles = append(allFiles, tenantFiles...)
+	}
+
+	return allFiles
+}
+
+// sortedTenants returns the tenants in the given map, in order.
+func sortedTenants[T any](tenants map[config.Tenant]T) []config.Tenant {
+	keys := make([]config.Tenant, 0, len(tenants))
+	for tenant := range tenants {
+		keys = append(keys, tenant)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
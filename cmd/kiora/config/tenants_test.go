@@ -0,0 +1,147 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/cmd/kiora/config"
+	kconfig "github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+// routeNames returns the names of the notifiers that the given config routes an alert for the given team to.
+func routeNames(t *testing.T, conf kconfig.Config, team string) []string {
+	t.Helper()
+	alert := model.Alert{Labels: model.Labels{"team": team}}
+	notifiers := []string{}
+	for _, route := range conf.RouteAlert(context.Background(), &alert) {
+		notifiers = append(notifiers, string(route.Name()))
+	}
+
+	return notifiers
+}
+
+func TestConfigTenants(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		expectedError string
+
+		// notifiers maps each team to the notifiers that its alerts should be routed to.
+		notifiers    map[string][]string
+		tenantErrors []kconfig.Tenant
+	}{
+		{
+			name: "tenants are routed by their own configs",
+			files: map[string]string{
+				"kiora.dot":       `digraph config { tenant_key = "{{ .team }}"; tenant_configs = "tenants"; console [type="stdout"]; alerts -> console; }`,
+				"tenants/foo.dot": `digraph foo { foo [type="stdout"]; alerts -> foo; }`,
+				"tenants/bar.dot": `digraph bar { bar [type="stdout"]; alerts -> bar; }`,
+			},
+			notifiers: map[string][]string{
+				"foo": {"foo"},
+				"bar": {"bar"},
+				"baz": {"console"},
+			},
+		},
+		{
+			name: "an invalid tenant config doesn't break the others",
+			files: map[string]string{
+				"kiora.dot":       `digraph config { tenant_key = "{{ .team }}"; tenant_configs = "tenants"; console [type="stdout"]; alerts -> console; }`,
+				"tenants/foo.dot": `digraph foo { foo [type="stdout"]; alerts -> foo; }`,
+				"tenants/bar.dot": `digraph bar { bar [type="nope"]; alerts -> bar; }`,
+			},
+			notifiers: map[string][]string{
+				"foo": {"foo"},
+				"bar": {"console"},
+			},
+			tenantErrors: []kconfig.Tenant{"bar"},
+		},
+		{
+			name: "tenant configs can't set globals",
+			files: map[string]string{
+				"kiora.dot":       `digraph config { tenant_key = "{{ .team }}"; tenant_configs = "tenants"; }`,
+				"tenants/foo.dot": `digraph foo { tenant_key = "bar"; }`,
+			},
+			notifiers:    map[string][]string{"foo": {}},
+			tenantErrors: []kconfig.Tenant{"foo"},
+		},
+		{
+			name: "tenant configs require a tenant key",
+			files: map[string]string{
+				"kiora.dot":       `digraph config { tenant_configs = "tenants"; }`,
+				"tenants/foo.dot": `digraph foo {}`,
+			},
+			expectedError: `"tenant_configs" requires "tenant_key"`,
+		},
+		{
+			name: "a missing tenant config directory is an error",
+			files: map[string]string{
+				"kiora.dot": `digraph config { tenant_key = "{{ .team }}"; tenant_configs = "tenants"; }`,
+			},
+			expectedError: "failed to read tenant config directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.RegisterNodes()
+			dir := writeConfigDir(t, tt.files)
+
+			conf, err := config.LoadConfigFile(filepath.Join(dir, "kiora.dot"), zerolog.Nop())
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+
+			for team, notifiers := range tt.notifiers {
+				require.ElementsMatch(t, notifiers, routeNames(t, conf, team), team)
+			}
+
+			tenantErrors := []kconfig.Tenant{}
+			for _, tenantErr := range conf.TenantErrors() {
+				tenantErrors = append(tenantErrors, tenantErr.Tenant)
+			}
+
+			require.ElementsMatch(t, tt.tenantErrors, tenantErrors)
+		})
+	}
+}
+
+func TestConfigTenantsReload(t *testing.T) {
+	config.RegisterNodes()
+	dir := writeConfigDir(t, map[string]string{
+		"kiora.dot":       `digraph config { tenant_key = "{{ .team }}"; tenant_configs = "tenants"; }`,
+		"tenants/foo.dot": `digraph foo { foo [type="stdout"]; alerts -> foo; }`,
+	})
+
+	reloadable, err := config.NewReloadableConfigFile(filepath.Join(dir, "kiora.dot"), zerolog.Nop())
+	require.NoError(t, err)
+
+	watcher := config.NewConfigFileWatcher(filepath.Join(dir, "kiora.dot"), time.Second, reloadable, zerolog.Nop())
+
+	// Changing a tenant config should trigger a reload.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tenants/foo.dot"), []byte(`digraph foo { foo2 [type="stdout"]; alerts -> foo2; }`), 0o644))
+	watcher.Check(context.Background())
+	require.Equal(t, uint64(1), reloadable.Stats().Successes)
+	require.Equal(t, []string{"foo2"}, routeNames(t, reloadable, "foo"))
+
+	// Breaking a tenant config should keep that tenant on its last good config, while still picking up new tenants.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tenants/foo.dot"), []byte(`digraph foo { foo3 [type="nope"]; alerts -> foo3; }`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tenants/bar.dot"), []byte(`digraph bar { bar [type="stdout"]; alerts -> bar; }`), 0o644))
+	watcher.Check(context.Background())
+	require.Equal(t, uint64(2), reloadable.Stats().Successes)
+	require.Equal(t, []string{"foo2"}, routeNames(t, reloadable, "foo"))
+	require.Equal(t, []string{"bar"}, routeNames(t, reloadable, "bar"))
+
+	// And it should stay there across further reloads until it's fixed.
+	require.NoError(t, reloadable.Reload(context.Background()))
+	require.Equal(t, []string{"foo2"}, routeNames(t, reloadable, "foo"))
+}
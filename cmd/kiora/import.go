@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/cmd/kiora/config"
+)
+
+// ImportCmd groups commands that convert configs from other systems into Kiora configs.
+type ImportCmd struct {
+	Alertmanager ImportAlertmanagerCmd `cmd:"" help:"Convert an Alertmanager config into a Kiora config."`
+}
+
+// ImportAlertmanagerCmd converts an Alertmanager config into a Kiora config, printing warnings for anything that can't be converted.
+type ImportAlertmanagerCmd struct {
+	File   string `arg:"" help:"the alertmanager.yml to convert"`
+	Output string `short:"o" help:"the file to write the Kiora config to. Defaults to stdout"`
+}
+
+func (i *ImportAlertmanagerCmd) Run() error {
+	body, err := os.ReadFile(i.File)
+	if err != nil {
+		return errors.Wrap(err, "failed to read alertmanager config")
+	}
+
+	dot, warnings, err := config.ConvertAlertmanagerConfig(body)
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+
+	if i.Output == "" {
+		fmt.Print(dot)
+		return nil
+	}
+
+	return os.WriteFile(i.Output, []byte(dot), 0o644)
+}
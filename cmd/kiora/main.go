@@ -4,11 +4,15 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/sinkingpoint/kiora/cmd/kiora/config"
+	"github.com/sinkingpoint/kiora/internal/audit"
+	"github.com/sinkingpoint/kiora/internal/auth"
 	"github.com/sinkingpoint/kiora/internal/server"
 	"github.com/sinkingpoint/kiora/internal/tracing"
 	"github.com/sinkingpoint/kiora/lib/kiora/kioradb"
@@ -16,39 +20,80 @@ import (
 
 var CLI struct {
 	tracing.TracingConfiguration ` prefix:"tracing."`
-	HTTPListenAddress            string `name:"web.listen-url" help:"the address to listen on" default:"localhost:4278"`
-	ConfigFile                   string `name:"config.file" short:"c" help:"the config file to load config from" default:"./kiora.dot"`
+	auth.AuthConfiguration       `embed:"" prefix:"auth."`
+	audit.AuditConfiguration     `embed:"" prefix:"audit."`
+	HTTPListenAddress            string        `name:"web.listen-url" help:"the address to listen on" default:"localhost:4278"`
+	GRPCListenAddress            string        `name:"grpc.listen-url" help:"the address to serve the gRPC API on. Empty disables the gRPC API"`
+	ConfigFile                   string        `name:"config.file" short:"c" help:"the config file to load config from" default:"./kiora.dot"`
+	ConfigWatchInterval          time.Duration `name:"config.watch-interval" help:"how often to check the config file for changes, reloading it if it changes. 0 disables watching" default:"10s"`
+	TenantHeader                 string        `name:"web.tenant-header" help:"the header that API requests set the tenant they're scoped to with" default:"X-Kiora-Tenant"`
+	AdminTenant                  string        `name:"web.admin-tenant" help:"the tenant that can see and modify the data of every tenant, if the API is unauthenticated. Empty disables the admin tenant"`
+	CORSOrigins                  []string      `name:"web.cors-origins" help:"the origins that browsers can make requests to the API from. Credentials are only allowed for origins that are listed explicitly" default:"*"`
 
 	NodeName             string   `name:"cluster.node-name" help:"the name to join the cluster with"`
 	ClusterListenAddress string   `name:"cluster.listen-url" help:"the address to run cluster activities on" default:"localhost:4279"`
 	ClusterShardLabels   []string `name:"cluster.shard-labels" help:"the labels that determine which node in a cluster will send a given alert"`
 	BootstrapPeers       []string `name:"cluster.bootstrap-peers" help:"the peers to bootstrap with"`
 
-	StorageBackend string `name:"storage.backend" help:"the storage backend to use" default:"boltdb"`
+	StorageBackend string `name:"storage.backend" help:"the storage backend to use (boltdb, sqlite, or inmemory)" default:"boltdb"`
 	StoragePath    string `name:"storage.path" help:"the path to store data in" default:"./kiora.db"`
+
+	Serve  ServeCmd  `cmd:"" default:"1" help:"Run the Kiora server."`
+	Check  CheckCmd  `cmd:"" help:"Check config files for errors and likely mistakes, without starting the server."`
+	Routes RoutesCmd `cmd:"" help:"Show and test how alerts are routed through the config."`
+	Import ImportCmd `cmd:"" help:"Convert configs from other systems into Kiora configs."`
+}
+
+// ServeCmd runs the Kiora server. This is the default command.
+type ServeCmd struct{}
+
+func (s *ServeCmd) Run() error {
+	serve()
+	return nil
 }
 
 func main() {
 	CLI.TracingConfiguration = tracing.DefaultTracingConfiguration()
-	kong.Parse(&CLI, kong.Name("kiora"), kong.Description("An experimental Alertmanager"), kong.UsageOnError(), kong.ConfigureHelp(kong.HelpOptions{
+	ctx := kong.Parse(&CLI, kong.Name("kiora"), kong.Description("An experimental Alertmanager"), kong.UsageOnError(), kong.ConfigureHelp(kong.HelpOptions{
 		Compact: true,
 	}))
 
+	config.RegisterNodes()
+
+	if err := ctx.Run(); err != nil {
+		ctx.FatalIfErrorf(err)
+	}
+}
+
+func serve() {
 	logger := zerolog.New(os.Stderr).Level(zerolog.DebugLevel)
 	log.Logger = logger
 
-	config.RegisterNodes()
-	config, err := config.LoadConfigFile(CLI.ConfigFile, logger)
+	conf, err := config.NewReloadableConfigFile(CLI.ConfigFile, logger)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("failed to load config")
 	}
 
 	serverConfig := server.NewServerConfig()
 	serverConfig.HTTPListenAddress = CLI.HTTPListenAddress
+	serverConfig.GRPCListenAddress = CLI.GRPCListenAddress
 	serverConfig.ClusterListenAddress = CLI.ClusterListenAddress
 	serverConfig.ClusterShardLabels = CLI.ClusterShardLabels
 	serverConfig.BootstrapPeers = CLI.BootstrapPeers
-	serverConfig.ServiceConfig = config
+	serverConfig.ServiceConfig = conf
+	serverConfig.Authenticators, err = CLI.AuthConfiguration.Authenticators()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to configure authentication")
+	}
+
+	serverConfig.Policy, err = CLI.AuthConfiguration.Policy()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to load authorization policy")
+	}
+
+	serverConfig.TenantHeader = CLI.TenantHeader
+	serverConfig.AdminTenant = CLI.AdminTenant
+	serverConfig.CORSOrigins = CLI.CORSOrigins
 	serverConfig.Logger = logger
 
 	tp, err := tracing.InitTracing(CLI.TracingConfiguration)
@@ -73,16 +118,49 @@ func main() {
 		}
 	case "inmemory":
 		db = kioradb.NewInMemoryDB()
+	case "sqlite":
+		db, err = kioradb.NewSQLiteDB(CLI.StoragePath, logger)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to create sqlite db")
+		}
 	default:
 		logger.Fatal().Msgf("unknown storage backend %s", CLI.StorageBackend)
 	}
 
+	serverConfig.AuditSink, err = CLI.AuditConfiguration.NewSink(db)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to configure audit log")
+	}
+
 	server, err := server.NewKioraServer(serverConfig, db)
 	if err != nil {
 		logger.Err(err).Msg("failed to create server")
 		return
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Reload the config on SIGHUP.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			logger.Info().Msg("Received SIGHUP, reloading config")
+			if err := conf.Reload(ctx); err != nil {
+				logger.Error().Err(err).Msg("failed to reload config. Keeping the old config")
+				continue
+			}
+
+			logger.Info().Msg("Reloaded config")
+		}
+	}()
+
+	if CLI.ConfigWatchInterval > 0 {
+		watcher := config.NewConfigFileWatcher(CLI.ConfigFile, CLI.ConfigWatchInterval, conf, logger)
+		go watcher.Run(ctx) //nolint:errcheck // The watcher only returns when the context is cancelled.
+	}
+
 	// Setup a SIGINT handler, so that we can shutdown gracefully.
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
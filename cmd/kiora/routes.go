@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/cmd/kiora/config"
+	kconfig "github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+// RoutesCmd groups commands that show how alerts are routed through a config, without starting the server.
+type RoutesCmd struct {
+	Show RoutesShowCmd `cmd:"" help:"Show the notifiers that an alert would be sent to."`
+	Test RoutesTestCmd `cmd:"" help:"Run route test files, failing if any alerts aren't routed to the expected notifiers."`
+}
+
+// RoutesShowCmd routes a single alert through the config, printing the notifiers it reaches.
+type RoutesShowCmd struct {
+	Labels      map[string]string `short:"l" help:"the labels of the alert"`
+	Annotations map[string]string `short:"a" help:"the annotations of the alert"`
+}
+
+func (r *RoutesShowCmd) Run() error {
+	conf, err := config.LoadConfigFile(CLI.ConfigFile, zerolog.Nop(), kconfig.WithValidateOnly())
+	if err != nil {
+		return fmt.Errorf("%s", config.FormatConfigError(CLI.ConfigFile, err))
+	}
+
+	alert := model.Alert{
+		Labels:      model.Labels{},
+		Annotations: map[string]string{},
+		Status:      model.AlertStatusFiring,
+	}
+
+	for k, v := range r.Labels {
+		alert.Labels[k] = v
+	}
+
+	for k, v := range r.Annotations {
+		alert.Annotations[k] = v
+	}
+
+	ctx := context.Background()
+	if err := conf.TransformAlert(ctx, &alert); err != nil {
+		return err
+	}
+
+	type route struct {
+		Notifier    string   `json:"notifier"`
+		GroupLabels []string `json:"groupLabels"`
+		GroupWait   string   `json:"groupWait"`
+		Path        []string `json:"path"`
+	}
+
+	routes := []route{}
+	for _, r := range conf.RouteAlert(ctx, &alert) {
+		routes = append(routes, route{
+			Notifier:    string(r.Name()),
+			GroupLabels: r.GroupLabels,
+			GroupWait:   r.GroupWait.String(),
+			Path:        r.Path,
+		})
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(routes)
+}
+
+// RoutesTestCmd runs declarative route tests against their configs.
+type RoutesTestCmd struct {
+	Files []string `arg:"" help:"the route test files to run"`
+}
+
+func (r *RoutesTestCmd) Run() error {
+	failed := 0
+	for _, file := range r.Files {
+		testFile, err := config.LoadRouteTestFile(file)
+		if err != nil {
+			return err
+		}
+
+		configFile := testFile.Config
+		if configFile == "" {
+			configFile = CLI.ConfigFile
+		}
+
+		conf, err := config.LoadConfigFile(configFile, zerolog.Nop(), kconfig.WithValidateOnly())
+		if err != nil {
+			return fmt.Errorf("%s", config.FormatConfigError(configFile, err))
+		}
+
+		failures := conf.RunRouteTests(context.Background(), testFile.Tests)
+		for _, failure := range failures {
+			fmt.Fprintf(os.Stderr, "FAIL: %s: %s\n", file, failure)
+		}
+
+		fmt.Fprintf(os.Stderr, "%s: %d/%d tests passed\n", file, len(testFile.Tests)-len(failures), len(testFile.Tests))
+		failed += len(failures)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d route test(s) failed", failed)
+	}
+
+	return nil
+}
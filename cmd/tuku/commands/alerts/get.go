@@ -5,12 +5,22 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/sinkingpoint/kiora/cmd/tuku/commands"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
 )
 
-type AlertsGetCmd struct{}
+type AlertsGetCmd struct {
+	Query string `arg:"" optional:"" help:"An alert query to filter the alerts with, e.g. 'severity=\"critical\" and status in (firing, acked)'."`
+}
 
 func (a *AlertsGetCmd) Run(ctx *commands.Context) error {
-	alerts, err := ctx.Kiora.GetAlerts()
+	// Parse the query locally so that typos are caught with a useful error before we send it.
+	if a.Query != "" {
+		if _, err := query.ParseAlertFilter(a.Query); err != nil {
+			return errors.Wrap(err, "failed to parse query")
+		}
+	}
+
+	alerts, err := ctx.Kiora.GetAlerts(a.Query)
 	if err != nil {
 		return err
 	}
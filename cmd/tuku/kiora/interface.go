@@ -6,8 +6,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/internal/server/api/apiv1"
 	"github.com/sinkingpoint/kiora/lib/kiora/model"
 )
 
@@ -30,8 +34,14 @@ func (k *KioraInstance) getRequest(method, uri string, body io.Reader) (*http.Re
 	return http.NewRequest(method, url, body)
 }
 
-func (k *KioraInstance) GetAlerts() ([]model.Alert, error) {
-	req, err := k.getRequest(http.MethodGet, "alerts", nil)
+// GetAlerts gets the alerts that match the given alert query, or all of them if the query is empty.
+func (k *KioraInstance) GetAlerts(query string) ([]model.Alert, error) {
+	uri := "alerts"
+	if query != "" {
+		uri += "?" + url.Values{"query": []string{query}}.Encode()
+	}
+
+	req, err := k.getRequest(http.MethodGet, uri, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create request")
 	}
@@ -44,16 +54,20 @@ func (k *KioraInstance) GetAlerts() ([]model.Alert, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code: %d (%q)", resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
-	alerts := []model.Alert{}
+	alerts := struct {
+		Items []model.Alert `json:"items"`
+	}{}
+
 	decoder := json.NewDecoder(resp.Body)
 	if err := decoder.Decode(&alerts); err != nil {
 		return nil, errors.Wrap(err, "failed to decode response")
 	}
 
-	return alerts, nil
+	return alerts.Items, nil
 }
 
 func (k *KioraInstance) PostAlerts(alerts []model.Alert) error {
@@ -81,11 +95,21 @@ func (k *KioraInstance) PostAlerts(alerts []model.Alert) error {
 		return errors.Wrap(err, "failed to read response body")
 	}
 
-	if resp.StatusCode != http.StatusAccepted {
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusBadRequest {
 		return fmt.Errorf("unexpected status code: %d (%q)", resp.StatusCode, string(body))
 	}
 
-	return nil
+	postResponse := apiv1.PostAlertsResponse{}
+	if err := json.Unmarshal(body, &postResponse); err != nil {
+		return fmt.Errorf("unexpected response: %d (%q)", resp.StatusCode, string(body))
+	}
+
+	var rejectedErr error
+	for _, rejected := range postResponse.Rejected {
+		rejectedErr = multierror.Append(rejectedErr, fmt.Errorf("alert %d rejected: %s", rejected.Index, rejected.Reason))
+	}
+
+	return rejectedErr
 }
 
 func (k *KioraInstance) PostSilence(silence model.Silence) (*model.Silence, error) {
@@ -113,6 +137,12 @@ func (k *KioraInstance) PostSilence(silence model.Silence) (*model.Silence, erro
 		return nil, errors.Wrap(err, "failed to read response body")
 	}
 
+	if resp.StatusCode == http.StatusBadRequest {
+		if err := decodeValidationError(body); err != nil {
+			return nil, errors.Wrap(err, "silence rejected")
+		}
+	}
+
 	if resp.StatusCode != http.StatusCreated {
 		return nil, fmt.Errorf("unexpected status code: %d (%q)", resp.StatusCode, string(body))
 	}
@@ -124,3 +154,29 @@ func (k *KioraInstance) PostSilence(silence model.Silence) (*model.Silence, erro
 
 	return &silence, nil
 }
+
+// decodeValidationError decodes an explanation of why the config rejected some data into an error that lists every
+// path that was tried, and why it failed. Returns nil if the body isn't a validation error.
+func decodeValidationError(body []byte) error {
+	validationErr := apiv1.ValidationError{}
+	if err := json.Unmarshal(body, &validationErr); err != nil || validationErr.Leaf == "" {
+		return nil
+	}
+
+	explanation := strings.Builder{}
+	fmt.Fprintf(&explanation, "no path into %q accepted it:", validationErr.Leaf)
+	for _, path := range validationErr.Paths {
+		fmt.Fprintf(&explanation, "\n  %s", strings.Join(path.Path, " -> "))
+		if path.To != nil {
+			fmt.Fprintf(&explanation, " -> %s", *path.To)
+		}
+
+		if path.Filter != nil {
+			fmt.Fprintf(&explanation, " (%s filter)", *path.Filter)
+		}
+
+		fmt.Fprintf(&explanation, ": %s", path.Reason)
+	}
+
+	return errors.New(explanation.String())
+}
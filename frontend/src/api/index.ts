@@ -8,8 +8,10 @@ export type { OpenAPIConfig } from './core/OpenAPI';
 
 export { Alert } from './models/Alert';
 export type { AlertAcknowledgement } from './models/AlertAcknowledgement';
+export type { AlertList } from './models/AlertList';
 export type { Matcher } from './models/Matcher';
 export type { Silence } from './models/Silence';
+export type { SilenceList } from './models/SilenceList';
 export type { StatsResult } from './models/StatsResult';
 
 export { DefaultService } from './services/DefaultService';
@@ -0,0 +1,18 @@
+/* istanbul ignore file */
+/* tslint:disable */
+/* eslint-disable */
+
+import type { Alert } from './Alert';
+
+export type AlertList = {
+    items: Array<Alert>;
+    /**
+     * The number of alerts that match the query, across every page
+     */
+    total: number;
+    /**
+     * The cursor to get the next page with. Not set if this is the last page
+     */
+    nextCursor?: string;
+};
+
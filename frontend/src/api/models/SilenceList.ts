@@ -0,0 +1,18 @@
+/* istanbul ignore file */
+/* tslint:disable */
+/* eslint-disable */
+
+import type { Silence } from './Silence';
+
+export type SilenceList = {
+    items: Array<Silence>;
+    /**
+     * The number of silences that match the query, across every page
+     */
+    total: number;
+    /**
+     * The cursor to get the next page with. Not set if this is the last page
+     */
+    nextCursor?: string;
+};
+
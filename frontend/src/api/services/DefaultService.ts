@@ -3,7 +3,9 @@
 /* eslint-disable */
 import type { Alert } from '../models/Alert';
 import type { AlertAcknowledgement } from '../models/AlertAcknowledgement';
+import type { AlertList } from '../models/AlertList';
 import type { Silence } from '../models/Silence';
+import type { SilenceList } from '../models/SilenceList';
 import type { StatsResult } from '../models/StatsResult';
 
 import type { CancelablePromise } from '../core/CancelablePromise';
@@ -16,7 +18,7 @@ export class DefaultService {
      * Get alerts details
      * Takes an optional filter, limit, ordering, and fields and returns alerts based on those
      *
-     * @returns Alert Got alerts
+     * @returns AlertList Got alerts
      * @throws ApiError
      */
     public static getAlerts({
@@ -25,6 +27,7 @@ export class DefaultService {
         sort,
         order,
         matchers,
+        cursor,
     }: {
         /**
          * The maximum number of results to return
@@ -46,7 +49,11 @@ export class DefaultService {
          * The matchers used to filter the returned list. Only alerts that match all the matchers will be returned.
          */
         matchers?: Array<string>,
-    }): CancelablePromise<Array<Alert>> {
+        /**
+         * The nextCursor from a previous page, to return the results after it
+         */
+        cursor?: string,
+    }): CancelablePromise<AlertList> {
         return __request(OpenAPI, {
             method: 'GET',
             url: '/alerts',
@@ -56,6 +63,7 @@ export class DefaultService {
                 'sort': sort,
                 'order': order,
                 'matchers': matchers,
+                'cursor': cursor,
             },
             errors: {
                 400: `Invalid query parameters`,
@@ -145,7 +153,7 @@ export class DefaultService {
 
     /**
      * Get silences
-     * @returns Silence Returns all the silences
+     * @returns SilenceList Returns all the silences
      * @throws ApiError
      */
     public static getSilences({
@@ -154,6 +162,7 @@ export class DefaultService {
         sort,
         order,
         matchers,
+        cursor,
     }: {
         /**
          * The maximum number of results to return
@@ -178,7 +187,11 @@ export class DefaultService {
          *
          */
         matchers?: Array<string>,
-    }): CancelablePromise<Array<Silence>> {
+        /**
+         * The nextCursor from a previous page, to return the results after it
+         */
+        cursor?: string,
+    }): CancelablePromise<SilenceList> {
         return __request(OpenAPI, {
             method: 'GET',
             url: '/silences',
@@ -188,6 +201,7 @@ export class DefaultService {
                 'sort': sort,
                 'order': order,
                 'matchers': matchers,
+                'cursor': cursor,
             },
         });
     }
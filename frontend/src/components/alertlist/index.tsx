@@ -42,7 +42,7 @@ const AlertList = () => {
 		await DefaultService.getAlerts({ sort: ["__starts_at__"], order: "DESC", limit: 100 })
 			.then((newAlerts) => {
 				setAlerts({
-					alerts: newAlerts,
+					alerts: newAlerts.items,
 					error: "",
 				});
 			})
@@ -108,14 +108,14 @@ const AlertView = ({ id }: AlertProps) => {
 		}
 
 		DefaultService.getAlerts({ matchers: [`__id__=${id}`] })
-			.then((alerts) => {
-				if (alerts.length === 0) {
+			.then((response) => {
+				if (response.items.length === 0) {
 					return;
 				}
 
 				setState({
 					loading: false,
-					alert: alerts[0],
+					alert: response.items[0],
 				});
 			})
 			.catch((error) => {
@@ -40,8 +40,8 @@ const CreateSilence = ({ duration, creator, comment, matchers }: PreviewPageProp
 const PreviewPage = ({ duration, creator, comment, matchers }: PreviewPageProps) => {
 	const [alerts, setAlerts] = useState<Alert[]>([]);
 	const fetchAffectedAlerts = () => {
-		DefaultService.getAlerts({ matchers }).then((alerts) => {
-			setAlerts(alerts);
+		DefaultService.getAlerts({ matchers }).then((response) => {
+			setAlerts(response.items);
 		});
 	};
 
@@ -40,7 +40,7 @@ const AllSilencesView = () => {
 		DefaultService.getSilences({ limit: 10, sort: ["__starts_at__"], order: "DESC" })
 			.then((response) => {
 				setSilences({
-					silences: response,
+					silences: response.items,
 					error: "",
 				});
 			})
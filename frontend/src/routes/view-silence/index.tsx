@@ -79,7 +79,7 @@ const ViewSilence = ({ id }: ViewSilenceProps) => {
 
 	const fetchSilence = () => {
 		DefaultService.getSilences({ matchers: [`__id__=${id}`] }).then((response) => {
-			if (response.length == 0) {
+			if (response.items.length == 0) {
 				setSilence({
 					error: "Silence not found",
 				});
@@ -88,7 +88,7 @@ const ViewSilence = ({ id }: ViewSilenceProps) => {
 			}
 
 			setSilence({
-				silence: response[0],
+				silence: response.items[0],
 			});
 		});
 	};
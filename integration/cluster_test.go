@@ -25,7 +25,7 @@ func TestKioraClusterAlerts(t *testing.T) {
 
 	for _, k := range nodes {
 		reqURL := k.GetHTTPURL("/api/v1/alerts")
-		resp, err := http.Get(reqURL)
+		resp, err := http.DefaultClient.Do(k.newAdminRequest(context.TODO(), http.MethodGet, reqURL, nil))
 		require.NoError(t, err)
 		defer resp.Body.Close()
 
@@ -191,6 +191,31 @@ func TestSilencesSilence(t *testing.T) {
 	require.True(t, foundAlerted, "Expected one node to send the alert for the non-silenced alert")
 }
 
+// Test that silences sent without a tenant silence alerts in every tenant, like they did before silences had tenants.
+func TestSilencesWithoutTenantSilenceEveryTenant(t *testing.T) {
+	initT(t)
+
+	// testdata/kiora.dot assigns alerts to tenants by their service.
+	alert := dummyAlert()
+	alert.Labels["service"] = "web"
+	silence := dummySilence()
+
+	nodes := StartKioraCluster(t, 3)
+	nodes[0].SendSilence(context.TODO(), silence)
+	time.Sleep(2 * time.Second)
+
+	nodes[0].SendAlert(context.TODO(), alert)
+	time.Sleep(2 * time.Second)
+
+	alerts := nodes[0].GetAlerts(context.TODO())
+	require.Len(t, alerts, 1)
+	require.Equal(t, model.AlertStatusSilenced, alerts[0].Status)
+
+	for _, node := range nodes {
+		require.NotContains(t, node.Stdout(), `"service":"web"`)
+	}
+}
+
 // Test that if we send an alert, and then silence it, the alert status gets updated.
 func TestSilencesSilenceAfterAlert(t *testing.T) {
 	initT(t)
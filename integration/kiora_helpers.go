@@ -23,6 +23,9 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// adminTenant is the tenant that instances are started with as their admin tenant, which the helpers send requests in.
+const adminTenant = "admin"
+
 // TODO(cdouch): Move this somewhere in the lib so we don't have to redefine it here and in the API.
 type ackRequest struct {
 	model.AlertAcknowledgement
@@ -124,6 +127,7 @@ func (k *KioraInstance) Start() *KioraInstance {
 		"--web.listen-url", "localhost:" + httpPort,
 		"--cluster.listen-url", "localhost:" + clusterPort,
 		"--storage.backend", "inmemory", // Use an in-memory db for testing.
+		"--web.admin-tenant", adminTenant, // The test configs assign alerts to tenants, so requests need a tenant that can see all of them.
 	}, k.args...)
 
 	k.httpPort = httpPort
@@ -226,6 +230,16 @@ func (k *KioraInstance) WaitForExit(ctx context.Context) error {
 	}
 }
 
+// newAdminRequest returns a request to the given URL in the admin tenant, which can see and modify the data of every tenant.
+func (k *KioraInstance) newAdminRequest(ctx context.Context, method, url string, body io.Reader) *http.Request {
+	k.t.Helper()
+	request, err := http.NewRequestWithContext(ctx, method, url, body)
+	require.NoError(k.t, err)
+	request.Header.Set("X-Kiora-Tenant", adminTenant)
+
+	return request
+}
+
 func (k *KioraInstance) SendAlert(ctx context.Context, alert model.Alert) {
 	k.t.Helper()
 	requestURL := k.GetHTTPURL("/api/v1/alerts")
@@ -233,7 +247,10 @@ func (k *KioraInstance) SendAlert(ctx context.Context, alert model.Alert) {
 	alertBytes, err := json.Marshal([]model.Alert{alert})
 	require.NoError(k.t, err)
 
-	resp, err := http.Post(requestURL, "application/json", bytes.NewReader(alertBytes))
+	request := k.newAdminRequest(ctx, http.MethodPost, requestURL, bytes.NewReader(alertBytes))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(request)
 	require.NoError(k.t, err)
 	body, err := io.ReadAll(resp.Body)
 	require.NoError(k.t, err)
@@ -249,7 +266,10 @@ func (k *KioraInstance) SendAlertAcknowledgement(ctx context.Context, ack ackReq
 	ackBytes, err := json.Marshal(ack)
 	require.NoError(k.t, err)
 
-	resp, err := http.Post(requestURL, "application/json", bytes.NewReader(ackBytes))
+	request := k.newAdminRequest(ctx, http.MethodPost, requestURL, bytes.NewReader(ackBytes))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(request)
 	require.NoError(k.t, err)
 	body, err := io.ReadAll(resp.Body)
 	require.NoError(k.t, err)
@@ -265,7 +285,10 @@ func (k *KioraInstance) SendSilence(ctx context.Context, silence model.Silence)
 	silenceBytes, err := json.Marshal(silence)
 	require.NoError(k.t, err)
 
-	resp, err := http.Post(requestURL, "application/json", bytes.NewReader(silenceBytes))
+	request := k.newAdminRequest(ctx, http.MethodPost, requestURL, bytes.NewReader(silenceBytes))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(request)
 	require.NoError(k.t, err)
 	body, err := io.ReadAll(resp.Body)
 	require.NoError(k.t, err)
@@ -282,25 +305,27 @@ func (k *KioraInstance) SendSilence(ctx context.Context, silence model.Silence)
 func (k *KioraInstance) GetAlerts(ctx context.Context) []model.Alert {
 	k.t.Helper()
 	requestURL := k.GetHTTPURL("/api/v1/alerts")
-	resp, err := http.Get(requestURL)
+	resp, err := http.DefaultClient.Do(k.newAdminRequest(ctx, http.MethodGet, requestURL, nil))
 	require.NoError(k.t, err)
 	body, err := io.ReadAll(resp.Body)
 	require.NoError(k.t, err)
 	resp.Body.Close()
 
 	require.Equal(k.t, http.StatusOK, resp.StatusCode, "body: %s", string(body))
-	alerts := []model.Alert{}
+	alerts := struct {
+		Items []model.Alert `json:"items"`
+	}{}
+
 	err = json.Unmarshal(body, &alerts)
 	require.NoError(k.t, err)
-	return alerts
+	return alerts.Items
 }
 
 func (k *KioraInstance) GetSilences(ctx context.Context, matchers []string) []model.Silence {
 	k.t.Helper()
 
 	requestURL := k.GetHTTPURL("/api/v1/silences")
-	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
-	require.NoError(k.t, err)
+	request := k.newAdminRequest(ctx, http.MethodGet, requestURL, nil)
 
 	if len(matchers) > 0 {
 		q := request.URL.Query()
@@ -319,10 +344,13 @@ func (k *KioraInstance) GetSilences(ctx context.Context, matchers []string) []mo
 
 	resp.Body.Close()
 
-	silences := []model.Silence{}
+	silences := struct {
+		Items []model.Silence `json:"items"`
+	}{}
+
 	require.NoError(k.t, json.Unmarshal(body, &silences))
 
-	return silences
+	return silences.Items
 }
 
 // kioraInstanceName returns a 16 character long random string that will be used as the name of a KioraInstance.
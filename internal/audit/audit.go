@@ -0,0 +1,137 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/gorilla/mux"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+// ErrNotQueryable is returned when querying the audit log of a Sink that can't be read back, like stdout.
+var ErrNotQueryable = errors.New("audit log can't be queried")
+
+// Sink is somewhere that the audit log is written to.
+type Sink interface {
+	// Write appends the given entry to the audit log.
+	Write(ctx context.Context, entry model.AuditEntry) error
+}
+
+// Querier is a Sink that can be read back.
+type Querier interface {
+	// Query returns the entries in the audit log matching the given query, newest first.
+	Query(ctx context.Context, q query.AuditQuery) ([]model.AuditEntry, error)
+}
+
+// Query queries the given sink, returning ErrNotQueryable if it can't be read back.
+func Query(ctx context.Context, sink Sink, q query.AuditQuery) ([]model.AuditEntry, error) {
+	querier, ok := sink.(Querier)
+	if !ok {
+		return nil, ErrNotQueryable
+	}
+
+	return querier.Query(ctx, q)
+}
+
+var (
+	_ Sink    = &DBSink{}
+	_ Querier = &DBSink{}
+)
+
+// DBSink is a Sink that stores the audit log in the database.
+type DBSink struct {
+	db kioradb.AuditLog
+}
+
+func NewDBSink(db kioradb.AuditLog) *DBSink {
+	return &DBSink{
+		db: db,
+	}
+}
+
+func (d *DBSink) Write(ctx context.Context, entry model.AuditEntry) error {
+	return d.db.StoreAuditEntries(ctx, entry)
+}
+
+func (d *DBSink) Query(ctx context.Context, q query.AuditQuery) ([]model.AuditEntry, error) {
+	return d.db.QueryAuditEntries(ctx, q)
+}
+
+// Source is where a request came from.
+type Source struct {
+	// IP is the address of the client that made the request.
+	IP string
+
+	// ForwardedFor is the X-Forwarded-For header of the request, if it was made through a proxy.
+	ForwardedFor string
+}
+
+type sourceKey struct{}
+
+// ContextWithSource returns a copy of the given context, with the given source attached.
+func ContextWithSource(ctx context.Context, source Source) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// SourceFromContext returns the source attached to the given context, if it came from a request.
+func SourceFromContext(ctx context.Context) (Source, bool) {
+	source, ok := ctx.Value(sourceKey{}).(Source)
+	return source, ok
+}
+
+// Middleware attaches the source of every request to the request context, so that it can be recorded in the audit log.
+func Middleware() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				ip = r.RemoteAddr
+			}
+
+			source := Source{
+				IP:           ip,
+				ForwardedFor: r.Header.Get("X-Forwarded-For"),
+			}
+
+			next.ServeHTTP(w, r.WithContext(ContextWithSource(r.Context(), source)))
+		})
+	}
+}
+
+// AuditConfiguration configures where the audit log of changes made through the API is written.
+type AuditConfiguration struct {
+	Sink string `help:"where to write the audit log of changes made through the API: none, stdout, file, or db" enum:"none,stdout,file,db" default:"none"`
+	File string `help:"the file to write the audit log to, with --audit.sink=file"`
+}
+
+// NewSink constructs the sink in the configuration, storing the audit log in the given DB if it's configured to. Returns nil
+// if auditing is disabled.
+func (c AuditConfiguration) NewSink(db kioradb.DB) (Sink, error) {
+	switch c.Sink {
+	case "", "none":
+		return nil, nil
+	case "stdout":
+		return NewWriterSink(os.Stdout), nil
+	case "file":
+		if c.File == "" {
+			return nil, errors.New("the file audit sink requires a file")
+		}
+
+		return NewFileSink(c.File)
+	case "db":
+		auditLog, ok := db.(kioradb.AuditLog)
+		if !ok {
+			return nil, errors.New("the storage backend can't store an audit log")
+		}
+
+		return NewDBSink(auditLog), nil
+	default:
+		return nil, fmt.Errorf("unknown audit sink %q", c.Sink)
+	}
+}
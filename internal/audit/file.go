@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+var (
+	_ Sink    = &WriterSink{}
+	_ Sink    = &FileSink{}
+	_ Querier = &FileSink{}
+)
+
+// WriterSink is a Sink that writes the audit log to a writer, like stdout, as one JSON object per line.
+type WriterSink struct {
+	lock   sync.Mutex
+	writer io.Writer
+}
+
+func NewWriterSink(writer io.Writer) *WriterSink {
+	return &WriterSink{
+		writer: writer,
+	}
+}
+
+func (w *WriterSink) Write(ctx context.Context, entry model.AuditEntry) error {
+	bytes, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal audit entry")
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	_, err = w.writer.Write(append(bytes, '\n'))
+	return err
+}
+
+// FileSink is a Sink that appends the audit log to a file, as one JSON object per line.
+type FileSink struct {
+	*WriterSink
+	path string
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open audit log")
+	}
+
+	return &FileSink{
+		WriterSink: NewWriterSink(file),
+		path:       path,
+	}, nil
+}
+
+// Query reads the whole file back, so it's best suited to small logs.
+func (f *FileSink) Query(ctx context.Context, q query.AuditQuery) ([]model.AuditEntry, error) {
+	// Hold the lock so that we don't read half written entries.
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open audit log")
+	}
+
+	defer file.Close()
+
+	entries := []model.AuditEntry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		var entry model.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal audit entry")
+		}
+
+		if q.MatchesEntry(&entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read audit log")
+	}
+
+	// Entries are appended as they happen, so reversing the file gives the newest first.
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	if q.Limit > 0 && len(entries) > q.Limit {
+		entries = entries[:q.Limit]
+	}
+
+	return entries, nil
+}
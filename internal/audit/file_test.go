@@ -0,0 +1,55 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sinkingpoint/kiora/internal/audit"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := audit.NewFileSink(path)
+	require.NoError(t, err)
+
+	start := time.Unix(1000, 0).UTC()
+	for i, actor := range []string{"alice", "bob", "alice"} {
+		require.NoError(t, sink.Write(context.Background(), model.AuditEntry{
+			ID:      actor + "-" + string(rune('a'+i)),
+			Time:    start.Add(time.Duration(i) * time.Minute),
+			Actor:   actor,
+			Action:  model.AuditActionPostSilence,
+			Request: json.RawMessage(`{"comment":"test"}`),
+			Outcome: model.AuditOutcomeAccepted,
+		}))
+	}
+
+	// Entries are written one per line, so that they can be shipped by log collectors.
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Len(t, strings.Split(strings.TrimSpace(string(contents)), "\n"), 3)
+
+	entries, err := audit.Query(context.Background(), sink, query.AuditQuery{Actor: "alice"})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "alice-c", entries[0].ID)
+	require.Equal(t, "alice-a", entries[1].ID)
+	require.JSONEq(t, `{"comment":"test"}`, string(entries[0].Request))
+
+	entries, err = audit.Query(context.Background(), sink, query.AuditQuery{From: start.Add(time.Minute), Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "alice-c", entries[0].ID)
+
+	// Stdout can't be read back.
+	_, err = audit.Query(context.Background(), audit.NewWriterSink(os.Stdout), query.AuditQuery{})
+	require.ErrorIs(t, err, audit.ErrNotQueryable)
+}
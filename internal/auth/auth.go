@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+)
+
+var (
+	// ErrNoCredentials is returned by Authenticators when a request doesn't have any credentials that they recognise, so that the next one can try.
+	ErrNoCredentials = errors.New("no credentials")
+
+	// ErrInvalidCredentials is returned by Authenticators when a request has credentials that they recognise, but that aren't valid.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Identity is the user (or service) that made a request.
+type Identity struct {
+	// Name is the name of the identity, e.g. a username, or the subject of a JWT.
+	Name string
+
+	// Groups are the groups that the identity belongs to.
+	Groups []string
+
+	// Tenant is the tenant that the identity belongs to, if it's restricted to one.
+	Tenant string
+
+	// Method is the way that the identity was authenticated, e.g. `token`, `basic`, or `jwt`.
+	Method string
+}
+
+type identityKey struct{}
+
+// ContextWithIdentity returns a copy of the given context, with the given identity attached.
+func ContextWithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the identity attached to the given context, if the request it came from was authenticated.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// Authenticator works out the identity that made a request from its credentials.
+type Authenticator interface {
+	// Authenticate returns the identity that made the given request. Returns ErrNoCredentials if the request doesn't have
+	// credentials that the Authenticator handles, or an error wrapping ErrInvalidCredentials if the credentials aren't valid.
+	Authenticate(r *http.Request) (Identity, error)
+}
+
+// Middleware authenticates every request with the given authenticators, in order, adding the identity of the first one that recognises
+// the request's credentials to the request context. Requests without any recognised credentials, or with invalid credentials, are rejected.
+func Middleware(authenticators []Authenticator, logger zerolog.Logger) mux.MiddlewareFunc {
+	logger = logger.With().Str("component", "auth").Logger()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, authenticator := range authenticators {
+				identity, err := authenticator.Authenticate(r)
+				if errors.Is(err, ErrNoCredentials) {
+					continue
+				}
+
+				if err != nil {
+					logger.Debug().Err(err).Str("path", r.URL.Path).Msg("rejected invalid credentials")
+					http.Error(w, "invalid credentials", http.StatusUnauthorized)
+					return
+				}
+
+				next.ServeHTTP(w, r.WithContext(ContextWithIdentity(r.Context(), identity)))
+				return
+			}
+
+			w.Header().Set("WWW-Authenticate", `Basic realm="kiora", Bearer realm="kiora"`)
+			http.Error(w, "authentication required", http.StatusUnauthorized)
+		})
+	}
+}
+
+// AuthConfiguration configures the authenticators that requests to the API are checked with.
+type AuthConfiguration struct {
+	TokensFile     string `help:"a YAML file of static bearer tokens that can authenticate with the API"`
+	BasicFile      string `help:"a YAML file of users (with bcrypt password hashes) that can authenticate with the API using HTTP basic auth"`
+	JWKSFile       string `name:"jwks-file" help:"a JSON Web Key Set file to validate JWT bearer tokens against"`
+	JWKSURL        string `name:"jwks-url" help:"a URL serving a JSON Web Key Set to validate JWT bearer tokens against"`
+	JWTIssuer      string `name:"jwt-issuer" help:"the issuer that JWTs must have been issued by"`
+	JWTAudience    string `name:"jwt-audience" help:"the audience that JWTs must have been issued for"`
+	JWTNameClaim   string `name:"jwt-name-claim" help:"the JWT claim holding the name of the user" default:"sub"`
+	JWTGroupsClaim string `name:"jwt-groups-claim" help:"the JWT claim holding the groups of the user" default:"groups"`
+	JWTTenantClaim string `name:"jwt-tenant-claim" help:"the JWT claim holding the tenant of the user" default:"tenant"`
+	PolicyFile     string `help:"a YAML file of roles, and the users, groups, and tokens that they're bound to, restricting what each can do with the API"`
+}
+
+// Authenticators constructs the authenticators in the configuration. If nothing is configured, no authenticators are returned
+// and the API is left unauthenticated.
+func (c AuthConfiguration) Authenticators() ([]Authenticator, error) {
+	authenticators := []Authenticator{}
+	if c.TokensFile != "" {
+		tokens, err := NewStaticTokenAuthenticator(c.TokensFile)
+		if err != nil {
+			return nil, err
+		}
+
+		authenticators = append(authenticators, tokens)
+	}
+
+	if c.BasicFile != "" {
+		basic, err := NewBasicAuthenticator(c.BasicFile)
+		if err != nil {
+			return nil, err
+		}
+
+		authenticators = append(authenticators, basic)
+	}
+
+	if c.JWKSFile != "" || c.JWKSURL != "" {
+		jwt, err := NewJWTAuthenticator(JWTOptions{
+			JWKSFile:    c.JWKSFile,
+			JWKSURL:     c.JWKSURL,
+			Issuer:      c.JWTIssuer,
+			Audience:    c.JWTAudience,
+			NameClaim:   c.JWTNameClaim,
+			GroupsClaim: c.JWTGroupsClaim,
+			TenantClaim: c.JWTTenantClaim,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		authenticators = append(authenticators, jwt)
+	}
+
+	return authenticators, nil
+}
+
+// Policy loads the policy in the configuration, returning nil if there isn't one (in which case, everything is allowed).
+func (c AuthConfiguration) Policy() (*Policy, error) {
+	if c.PolicyFile == "" {
+		return nil, nil
+	}
+
+	return LoadPolicyFile(c.PolicyFile)
+}
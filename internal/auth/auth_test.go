@@ -0,0 +1,114 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/internal/auth"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// writeFile writes the given contents to a file in a new temporary directory, returning its path.
+func writeFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestMiddleware(t *testing.T) {
+	tokens, err := auth.NewStaticTokenAuthenticator(writeFile(t, "tokens.yaml", `
+tokens:
+  - name: prometheus
+    token: secret-token
+    groups: [senders]
+    tenant: foo
+`))
+	require.NoError(t, err)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	basic, err := auth.NewBasicAuthenticator(writeFile(t, "users.yaml", `
+users:
+  - name: alice
+    password_hash: "`+string(hash)+`"
+    groups: [oncall]
+`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		setAuth          func(r *http.Request)
+		expectedStatus   int
+		expectedIdentity auth.Identity
+	}{
+		{
+			name:           "requests without credentials are rejected",
+			setAuth:        func(r *http.Request) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:             "static tokens authenticate",
+			setAuth:          func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret-token") },
+			expectedStatus:   http.StatusOK,
+			expectedIdentity: auth.Identity{Name: "prometheus", Groups: []string{"senders"}, Tenant: "foo", Method: "token"},
+		},
+		{
+			name:           "unknown tokens are rejected",
+			setAuth:        func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong-token") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:             "basic auth authenticates",
+			setAuth:          func(r *http.Request) { r.SetBasicAuth("alice", "hunter2") },
+			expectedStatus:   http.StatusOK,
+			expectedIdentity: auth.Identity{Name: "alice", Groups: []string{"oncall"}, Method: "basic"},
+		},
+		{
+			name:           "wrong passwords are rejected",
+			setAuth:        func(r *http.Request) { r.SetBasicAuth("alice", "hunter3") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "unknown users are rejected",
+			setAuth:        func(r *http.Request) { r.SetBasicAuth("bob", "hunter2") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var identity auth.Identity
+			handler := auth.Middleware([]auth.Authenticator{tokens, basic}, zerolog.Nop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var ok bool
+				identity, ok = auth.IdentityFromContext(r.Context())
+				require.True(t, ok)
+			}))
+
+			request := httptest.NewRequest(http.MethodGet, "http://localhost/api/v1/alerts", nil)
+			tt.setAuth(request)
+
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+			require.Equal(t, tt.expectedStatus, resp.Code)
+			require.Equal(t, tt.expectedIdentity, identity)
+		})
+	}
+}
+
+func TestStaticFileErrors(t *testing.T) {
+	_, err := auth.NewStaticTokenAuthenticator(writeFile(t, "tokens.yaml", "tokens:\n  - name: foo\n"))
+	require.ErrorContains(t, err, "must have a name and a token")
+
+	_, err = auth.NewStaticTokenAuthenticator(writeFile(t, "tokens.yaml", "tokens:\n  - name: foo\n    tokne: bar\n"))
+	require.ErrorContains(t, err, "field tokne not found")
+
+	_, err = auth.NewBasicAuthenticator(writeFile(t, "users.yaml", "users:\n  - name: foo\n    password_hash: hunter2\n"))
+	require.ErrorContains(t, err, "invalid password_hash")
+}
@@ -0,0 +1,376 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/internal/stubs"
+	"golang.org/x/sync/singleflight"
+)
+
+var _ = Authenticator(&JWTAuthenticator{})
+
+// jwksRefreshInterval is the minimum amount of time between reloads of a JWKS file or URL, so that tokens with unknown key IDs can't be used to hammer it.
+const jwksRefreshInterval = time.Minute
+
+// jwksFetchTimeout is the longest that fetching a JWKS URL can take. Fetches can happen while authenticating requests, so they can't hang forever.
+const jwksFetchTimeout = 10 * time.Second
+
+// JWTOptions configures a JWTAuthenticator.
+type JWTOptions struct {
+	// JWKSFile is the path to a file containing the JSON Web Key Set that tokens are signed with. Exactly one of JWKSFile and JWKSURL must be set.
+	// Like the URL, it's read again when a token is signed with a key we haven't seen, so keys can be rotated without a restart.
+	JWKSFile string
+
+	// JWKSURL is a URL that serves the JSON Web Key Set that tokens are signed with. It's fetched again when a token is signed with a key we haven't seen.
+	JWKSURL string
+
+	// Issuer is the issuer that tokens must have been issued by, if set.
+	Issuer string
+
+	// Audience is the audience that tokens must have been issued for, if set.
+	Audience string
+
+	// NameClaim is the claim that holds the name of the identity. Defaults to `sub`.
+	NameClaim string
+
+	// GroupsClaim is the claim that holds the groups of the identity, as a list or a comma separated string. Defaults to `groups`.
+	GroupsClaim string
+
+	// TenantClaim is the claim that holds the tenant of the identity. Defaults to `tenant`.
+	TenantClaim string
+
+	// HTTPClient is the client used to fetch the JWKS URL. Defaults to a client that times out after 10 seconds.
+	HTTPClient *http.Client
+}
+
+// JWTAuthenticator authenticates requests with JWT bearer tokens, validated against a JSON Web Key Set.
+type JWTAuthenticator struct {
+	opts   JWTOptions
+	keys   *keySet
+	parser *jwt.Parser
+}
+
+// NewJWTAuthenticator constructs a JWTAuthenticator with the given options, loading the key set so that misconfigurations are caught at startup.
+func NewJWTAuthenticator(opts JWTOptions) (*JWTAuthenticator, error) {
+	if (opts.JWKSFile == "") == (opts.JWKSURL == "") {
+		return nil, errors.New("exactly one of a JWKS file or a JWKS URL must be set")
+	}
+
+	if opts.NameClaim == "" {
+		opts.NameClaim = "sub"
+	}
+
+	if opts.GroupsClaim == "" {
+		opts.GroupsClaim = "groups"
+	}
+
+	if opts.TenantClaim == "" {
+		opts.TenantClaim = "tenant"
+	}
+
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: jwksFetchTimeout}
+	}
+
+	keys := &keySet{file: opts.JWKSFile, url: opts.JWKSURL, client: opts.HTTPClient}
+	if err := keys.refresh(); err != nil {
+		return nil, err
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(stubs.Time.Now),
+	}
+
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+
+	return &JWTAuthenticator{
+		opts:   opts,
+		keys:   keys,
+		parser: jwt.NewParser(parserOpts...),
+	}, nil
+}
+
+func (j *JWTAuthenticator) Authenticate(r *http.Request) (Identity, error) {
+	bearer, ok := bearerToken(r)
+
+	// Anything that isn't shaped like a JWT might be a static token, so leave it to the other authenticators.
+	if !ok || strings.Count(bearer, ".") != 2 {
+		return Identity{}, ErrNoCredentials
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := j.parser.ParseWithClaims(bearer, claims, j.keys.keyFunc); err != nil {
+		return Identity{}, errors.Wrap(ErrInvalidCredentials, err.Error())
+	}
+
+	name, _ := claims[j.opts.NameClaim].(string)
+	if name == "" {
+		return Identity{}, errors.Wrapf(ErrInvalidCredentials, "token is missing the %q claim", j.opts.NameClaim)
+	}
+
+	tenant, _ := claims[j.opts.TenantClaim].(string)
+
+	return Identity{
+		Name:   name,
+		Groups: groupsFromClaim(claims[j.opts.GroupsClaim]),
+		Tenant: tenant,
+		Method: "jwt",
+	}, nil
+}
+
+// groupsFromClaim reads a list of groups from a claim, which can either be a list of strings, or a comma separated string.
+func groupsFromClaim(claim any) []string {
+	groups := []string{}
+	switch claim := claim.(type) {
+	case string:
+		for _, group := range strings.Split(claim, ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				groups = append(groups, group)
+			}
+		}
+	case []any:
+		for _, group := range claim {
+			if group, ok := group.(string); ok {
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	return groups
+}
+
+// keySet is a JSON Web Key Set, loaded from a file or a URL.
+type keySet struct {
+	file   string
+	url    string
+	client *http.Client
+
+	lock      sync.Mutex
+	keys      map[string]any
+	lastFetch time.Time
+
+	// refreshes shares a refresh between all the requests that need one at the same time, so that they don't each load the set.
+	refreshes singleflight.Group
+}
+
+// keyFunc returns the key that the given token was signed with. Tokens without a key ID can be used when the set only has one key.
+func (k *keySet) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	k.lock.Lock()
+	key, ok := k.lookup(kid)
+	refresh := !ok && stubs.Time.Now().Sub(k.lastFetch) > jwksRefreshInterval
+	k.lock.Unlock()
+
+	// The key might have been rotated in since we last fetched the set.
+	if refresh {
+		if _, err, _ := k.refreshes.Do("refresh", func() (any, error) { return nil, k.refresh() }); err != nil {
+			return nil, err
+		}
+
+		k.lock.Lock()
+		key, ok = k.lookup(kid)
+		k.lock.Unlock()
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	return key, nil
+}
+
+// lookup returns the key with the given ID. k.lock must be held.
+func (k *keySet) lookup(kid string) (any, bool) {
+	if kid == "" && len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key, true
+		}
+	}
+
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+// refresh reloads the key set from its file or URL.
+func (k *keySet) refresh() error {
+	var body []byte
+	var err error
+	if k.file != "" {
+		body, err = os.ReadFile(k.file)
+	} else {
+		body, err = k.fetch()
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.lastFetch = stubs.Time.Now()
+
+	if err != nil {
+		return errors.Wrap(err, "failed to load JWKS")
+	}
+
+	keys, err := parseJWKS(body)
+	if err != nil {
+		return err
+	}
+
+	k.keys = keys
+	return nil
+}
+
+func (k *keySet) fetch() ([]byte, error) {
+	resp, err := k.client.Get(k.url)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d fetching %s", resp.StatusCode, k.url)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// jsonWebKey is a single key in a JSON Web Key Set, as defined in RFC 7517.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+
+	// N and E are the modulus and exponent of RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// Crv, X, and Y are the curve and coordinates of EC keys.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS parses the public keys out of a JSON Web Key Set, keyed by their IDs. Keys that aren't for signing, or of types that we don't support, are skipped.
+func parseJWKS(body []byte) (map[string]any, error) {
+	set := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+
+	if err := json.Unmarshal(body, &set); err != nil {
+		return nil, errors.Wrap(err, "failed to parse JWKS")
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		var publicKey any
+		var err error
+		switch key.Kty {
+		case "RSA":
+			publicKey, err = key.rsaPublicKey()
+		case "EC":
+			publicKey, err = key.ecPublicKey()
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key %q in JWKS", key.Kid)
+		}
+
+		keys[key.Kid] = publicKey
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS doesn't contain any usable signing keys")
+	}
+
+	return keys, nil
+}
+
+func (j jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(j.N)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid modulus")
+	}
+
+	e, err := decodeBigInt(j.E)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid exponent")
+	}
+
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, errors.New("exponent is too large")
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (j jsonWebKey) ecPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch j.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+	}
+
+	x, err := decodeBigInt(j.X)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid x coordinate")
+	}
+
+	y, err := decodeBigInt(j.Y)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid y coordinate")
+	}
+
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("point is not on the curve")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// decodeBigInt decodes a base64url encoded, big endian, integer.
+func decodeBigInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, errors.New("missing value")
+	}
+
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(bytes), nil
+}
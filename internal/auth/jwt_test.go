@@ -0,0 +1,242 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sinkingpoint/kiora/internal/auth"
+	"github.com/sinkingpoint/kiora/internal/stubs"
+	"github.com/stretchr/testify/require"
+)
+
+// rsaJWKS returns a JSON Web Key Set containing the public half of the given key, with the given key ID.
+func rsaJWKS(t *testing.T, kid string, key *rsa.PrivateKey) string {
+	t.Helper()
+	set := map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+
+	body, err := json.Marshal(set)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	authenticator, err := auth.NewJWTAuthenticator(auth.JWTOptions{
+		JWKSFile: writeFile(t, "jwks.json", rsaJWKS(t, "test", key)),
+		Issuer:   "https://issuer.example.com",
+	})
+	require.NoError(t, err)
+
+	sign := func(key *rsa.PrivateKey, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test"
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":    "alice",
+			"iss":    "https://issuer.example.com",
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"groups": []string{"oncall", "admins"},
+			"tenant": "foo",
+		}
+	}
+
+	tests := []struct {
+		name             string
+		token            func() string
+		expectedErr      error
+		expectedIdentity auth.Identity
+	}{
+		{
+			name:             "valid tokens authenticate",
+			token:            func() string { return sign(key, validClaims()) },
+			expectedIdentity: auth.Identity{Name: "alice", Groups: []string{"oncall", "admins"}, Tenant: "foo", Method: "jwt"},
+		},
+		{
+			name:        "tokens signed by other keys are rejected",
+			token:       func() string { return sign(otherKey, validClaims()) },
+			expectedErr: auth.ErrInvalidCredentials,
+		},
+		{
+			name: "expired tokens are rejected",
+			token: func() string {
+				claims := validClaims()
+				claims["exp"] = time.Now().Add(-time.Hour).Unix()
+				return sign(key, claims)
+			},
+			expectedErr: auth.ErrInvalidCredentials,
+		},
+		{
+			name: "tokens from other issuers are rejected",
+			token: func() string {
+				claims := validClaims()
+				claims["iss"] = "https://evil.example.com"
+				return sign(key, claims)
+			},
+			expectedErr: auth.ErrInvalidCredentials,
+		},
+		{
+			name:        "things that aren't JWTs are left to other authenticators",
+			token:       func() string { return "secret-token" },
+			expectedErr: auth.ErrNoCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "http://localhost/api/v1/alerts", nil)
+			request.Header.Set("Authorization", "Bearer "+tt.token())
+
+			identity, err := authenticator.Authenticate(request)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedIdentity, identity)
+		})
+	}
+}
+
+func TestJWTAuthenticatorJWKSURL(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(rsaJWKS(t, "test", key))) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	authenticator, err := auth.NewJWTAuthenticator(auth.JWTOptions{JWKSURL: server.URL})
+	require.NoError(t, err)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()})
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost/api/v1/alerts", nil)
+	request.Header.Set("Authorization", "Bearer "+signed)
+
+	identity, err := authenticator.Authenticate(request)
+	require.NoError(t, err)
+	require.Equal(t, "alice", identity.Name)
+}
+
+func TestJWTAuthenticatorJWKSFileRotation(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	path := writeFile(t, "jwks.json", rsaJWKS(t, "old", oldKey))
+	authenticator, err := auth.NewJWTAuthenticator(auth.JWTOptions{JWKSFile: path})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(rsaJWKS(t, "new", newKey)), 0o600))
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()})
+	token.Header["kid"] = "new"
+	signed, err := token.SignedString(newKey)
+	require.NoError(t, err)
+
+	authenticate := func() error {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost/api/v1/alerts", nil)
+		request.Header.Set("Authorization", "Bearer "+signed)
+		_, err := authenticator.Authenticate(request)
+		return err
+	}
+
+	// The file was only just loaded, so it isn't read again yet.
+	require.ErrorIs(t, authenticate(), auth.ErrInvalidCredentials)
+
+	// Once the refresh interval has passed, the rotated key is picked up without a restart.
+	now := time.Now().Add(2 * time.Minute)
+	stubs.Time.Now = func() time.Time { return now }
+	defer func() { stubs.Time.Now = time.Now }()
+
+	require.NoError(t, authenticate())
+}
+
+func TestJWTAuthenticatorJWKSURLSharesRefreshes(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	fetches := atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) == 1 {
+			w.Write([]byte(rsaJWKS(t, "old", oldKey))) //nolint:errcheck
+			return
+		}
+
+		// Slow down the refresh so that every request needs it while it's happening.
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(rsaJWKS(t, "new", newKey))) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	authenticator, err := auth.NewJWTAuthenticator(auth.JWTOptions{JWKSURL: server.URL})
+	require.NoError(t, err)
+
+	// Skip past the refresh interval, so that the rotated key is fetched.
+	now := time.Now().Add(2 * time.Minute)
+	stubs.Time.Now = func() time.Time { return now }
+	defer func() { stubs.Time.Now = time.Now }()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()})
+	token.Header["kid"] = "new"
+	signed, err := token.SignedString(newKey)
+	require.NoError(t, err)
+
+	wg := sync.WaitGroup{}
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			request := httptest.NewRequest(http.MethodGet, "http://localhost/api/v1/alerts", nil)
+			request.Header.Set("Authorization", "Bearer "+signed)
+			_, errs[i] = authenticator.Authenticate(request)
+		}(i)
+	}
+
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, int32(2), fetches.Load())
+}
@@ -0,0 +1,233 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+)
+
+// Permission is something that an identity is allowed to do with the API.
+type Permission string
+
+const (
+	PermissionReadAlerts    Permission = "alerts:read"
+	PermissionWriteAlerts   Permission = "alerts:write"
+	PermissionWriteAcks     Permission = "acks:write"
+	PermissionReadSilences  Permission = "silences:read"
+	PermissionWriteSilences Permission = "silences:write"
+	PermissionReadConfig    Permission = "config:read"
+	PermissionReloadConfig  Permission = "config:reload"
+	PermissionReadCluster   Permission = "cluster:read"
+	PermissionReadAudit     Permission = "audit:read"
+
+	// PermissionAllTenants lets an identity see and modify the data of every tenant, rather than just its own.
+	PermissionAllTenants Permission = "tenants:all"
+
+	// PermissionAll grants every permission.
+	PermissionAll Permission = "*"
+)
+
+// knownPermissions are all the permissions that roles can grant, for catching typos.
+var knownPermissions = map[Permission]struct{}{
+	PermissionReadAlerts:    {},
+	PermissionWriteAlerts:   {},
+	PermissionWriteAcks:     {},
+	PermissionReadSilences:  {},
+	PermissionWriteSilences: {},
+	PermissionReadConfig:    {},
+	PermissionReloadConfig:  {},
+	PermissionReadCluster:   {},
+	PermissionReadAudit:     {},
+	PermissionAllTenants:    {},
+	PermissionAll:           {},
+}
+
+// DefaultRoles are the roles that are available in every policy, unless the policy redefines them.
+var DefaultRoles = map[string][]Permission{
+	// Senders, like Prometheus, can only post alerts.
+	"sender": {PermissionWriteAlerts},
+	"viewer": {PermissionReadAlerts, PermissionReadSilences, PermissionReadConfig},
+	"oncall": {PermissionReadAlerts, PermissionReadSilences, PermissionReadConfig, PermissionWriteAcks, PermissionWriteSilences},
+	"admin":  {PermissionAll},
+}
+
+// RoleBinding grants a role to the given users, groups, and tokens.
+type RoleBinding struct {
+	Role   string   `yaml:"role"`
+	Users  []string `yaml:"users"`
+	Groups []string `yaml:"groups"`
+	Tokens []string `yaml:"tokens"`
+}
+
+// Policy decides which roles identities have, and what those roles are allowed to do.
+type Policy struct {
+	roles    map[string][]Permission
+	bindings []RoleBinding
+}
+
+// NewPolicy constructs a policy from the given roles (on top of the DefaultRoles) and bindings.
+func NewPolicy(roles map[string][]Permission, bindings []RoleBinding) (*Policy, error) {
+	policy := &Policy{
+		roles:    make(map[string][]Permission, len(DefaultRoles)+len(roles)),
+		bindings: bindings,
+	}
+
+	for name, permissions := range DefaultRoles {
+		policy.roles[name] = permissions
+	}
+
+	for name, permissions := range roles {
+		for _, permission := range permissions {
+			if _, ok := knownPermissions[permission]; !ok {
+				return nil, fmt.Errorf("role %q has unknown permission %q", name, permission)
+			}
+		}
+
+		policy.roles[name] = permissions
+	}
+
+	for i, binding := range bindings {
+		if _, ok := policy.roles[binding.Role]; !ok {
+			return nil, fmt.Errorf("binding %d refers to unknown role %q", i, binding.Role)
+		}
+	}
+
+	return policy, nil
+}
+
+// LoadPolicyFile loads the policy in the YAML file at the given path, e.g.
+//
+//	roles:
+//	  silencer: [silences:read, silences:write]
+//	bindings:
+//	  - role: sender
+//	    tokens: [prometheus]
+//	  - role: oncall
+//	    groups: [sre]
+func LoadPolicyFile(path string) (*Policy, error) {
+	file := struct {
+		Roles    map[string][]Permission `yaml:"roles"`
+		Bindings []RoleBinding           `yaml:"bindings"`
+	}{}
+
+	if err := readYAMLFile(path, &file); err != nil {
+		return nil, errors.Wrap(err, "failed to load policy")
+	}
+
+	policy, err := NewPolicy(file.Roles, file.Bindings)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid policy in %s", path)
+	}
+
+	return policy, nil
+}
+
+// Roles returns the roles that are bound to the given identity, in order. Tokens are bound by name, and everything else by user name.
+func (p *Policy) Roles(identity Identity) []string {
+	groups := make(map[string]struct{}, len(identity.Groups))
+	for _, group := range identity.Groups {
+		groups[group] = struct{}{}
+	}
+
+	roles := map[string]struct{}{}
+	for _, binding := range p.bindings {
+		names := binding.Users
+		if identity.Method == "token" {
+			names = binding.Tokens
+		}
+
+		bound := false
+		for _, name := range names {
+			bound = bound || name == identity.Name
+		}
+
+		for _, group := range binding.Groups {
+			_, inGroup := groups[group]
+			bound = bound || inGroup
+		}
+
+		if bound {
+			roles[binding.Role] = struct{}{}
+		}
+	}
+
+	sorted := make([]string, 0, len(roles))
+	for role := range roles {
+		sorted = append(sorted, role)
+	}
+
+	sort.Strings(sorted)
+	return sorted
+}
+
+// permissions returns every permission granted by the given roles.
+func (p *Policy) permissions(roles []string) map[Permission]struct{} {
+	permissions := map[Permission]struct{}{}
+	for _, role := range roles {
+		for _, permission := range p.roles[role] {
+			permissions[permission] = struct{}{}
+		}
+	}
+
+	return permissions
+}
+
+type permissionsKey struct{}
+
+// PolicyMiddleware works out the roles of the identity that made every request, and what they're allowed to do. Requests
+// that weren't authenticated have no roles, so they can't do anything.
+func PolicyMiddleware(policy *Policy) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			roles := []string{}
+			if identity, ok := IdentityFromContext(r.Context()); ok {
+				roles = policy.Roles(identity)
+			}
+
+			ctx := context.WithValue(r.Context(), permissionsKey{}, policy.permissions(roles))
+			ctx = config.ContextWithRoles(ctx, roles)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// HasPermission returns true if a policy grants the given permission to the request in the given context. Requests that
+// weren't checked against a policy don't have any permissions.
+func HasPermission(ctx context.Context, permission Permission) bool {
+	permissions, ok := ctx.Value(permissionsKey{}).(map[Permission]struct{})
+	if !ok {
+		return false
+	}
+
+	_, all := permissions[PermissionAll]
+	_, granted := permissions[permission]
+	return all || granted
+}
+
+// Allowed returns true if the request in the given context has the given permission. If requests aren't checked
+// against a policy (i.e. there isn't one configured), everything is allowed.
+func Allowed(ctx context.Context, permission Permission) bool {
+	if _, ok := ctx.Value(permissionsKey{}).(map[Permission]struct{}); !ok {
+		return true
+	}
+
+	return HasPermission(ctx, permission)
+}
+
+// RequirePermission rejects requests to the given handler that don't have the given permission. If requests aren't checked
+// against a policy (i.e. there isn't one configured), everything is allowed.
+func RequirePermission(permission Permission, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !Allowed(r.Context(), permission) {
+			http.Error(w, fmt.Sprintf("permission %q is required", permission), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
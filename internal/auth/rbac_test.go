@@ -0,0 +1,118 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sinkingpoint/kiora/internal/auth"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy(t *testing.T) {
+	policy, err := auth.LoadPolicyFile(writeFile(t, "policy.yaml", `
+roles:
+  silencer: [silences:read, silences:write]
+bindings:
+  - role: sender
+    tokens: [prometheus]
+  - role: silencer
+    users: [alice]
+  - role: oncall
+    groups: [sre]
+  - role: admin
+    users: [root]
+`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		identity       *auth.Identity
+		permission     auth.Permission
+		expectedRoles  []string
+		expectedStatus int
+	}{
+		{
+			name:           "tokens are bound by name",
+			identity:       &auth.Identity{Name: "prometheus", Method: "token"},
+			permission:     auth.PermissionWriteAlerts,
+			expectedRoles:  []string{"sender"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "tokens don't get the roles of users with the same name",
+			identity:       &auth.Identity{Name: "alice", Method: "token"},
+			permission:     auth.PermissionWriteSilences,
+			expectedRoles:  []string{},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "users get the roles of their groups",
+			identity:       &auth.Identity{Name: "alice", Groups: []string{"sre"}, Method: "jwt"},
+			permission:     auth.PermissionWriteAcks,
+			expectedRoles:  []string{"oncall", "silencer"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "users can't do things their roles don't allow",
+			identity:       &auth.Identity{Name: "alice", Method: "basic"},
+			permission:     auth.PermissionReloadConfig,
+			expectedRoles:  []string{"silencer"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "admins can do everything",
+			identity:       &auth.Identity{Name: "root", Method: "basic"},
+			permission:     auth.PermissionAllTenants,
+			expectedRoles:  []string{"admin"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unauthenticated requests can't do anything",
+			permission:     auth.PermissionReadAlerts,
+			expectedRoles:  []string{},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var roles []string
+			handler := auth.PolicyMiddleware(policy)(auth.RequirePermission(tt.permission, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+			recordRoles := auth.PolicyMiddleware(policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				roles, _ = config.RolesFromContext(r.Context())
+			}))
+
+			request := httptest.NewRequest(http.MethodGet, "http://localhost/api/v1/alerts", nil)
+			if tt.identity != nil {
+				request = request.WithContext(auth.ContextWithIdentity(request.Context(), *tt.identity))
+			}
+
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+			recordRoles.ServeHTTP(httptest.NewRecorder(), request)
+
+			require.Equal(t, tt.expectedStatus, resp.Code)
+			require.Equal(t, tt.expectedRoles, roles)
+		})
+	}
+}
+
+func TestPolicyErrors(t *testing.T) {
+	_, err := auth.LoadPolicyFile(writeFile(t, "policy.yaml", "bindings:\n  - role: superuser\n    users: [alice]\n"))
+	require.ErrorContains(t, err, `unknown role "superuser"`)
+
+	_, err = auth.LoadPolicyFile(writeFile(t, "policy.yaml", "roles:\n  silencer: [silences:wirte]\n"))
+	require.ErrorContains(t, err, `unknown permission "silences:wirte"`)
+}
+
+func TestRequirePermissionWithoutPolicy(t *testing.T) {
+	handler := auth.RequirePermission(auth.PermissionReloadConfig, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "http://localhost/api/v1/config/reload", nil))
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.False(t, auth.HasPermission(context.Background(), auth.PermissionReloadConfig))
+}
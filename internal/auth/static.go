@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = Authenticator(&StaticTokenAuthenticator{})
+var _ = Authenticator(&BasicAuthenticator{})
+
+// StaticToken is a bearer token that authenticates as a fixed identity.
+type StaticToken struct {
+	// Name is the name of the identity that the token authenticates as.
+	Name string `yaml:"name"`
+
+	// Token is the secret value of the token.
+	Token string `yaml:"token"`
+
+	Groups []string `yaml:"groups"`
+	Tenant string   `yaml:"tenant"`
+}
+
+// StaticTokenAuthenticator authenticates requests with bearer tokens from a file.
+type StaticTokenAuthenticator struct {
+	tokens []StaticToken
+}
+
+// NewStaticTokenAuthenticator loads the tokens in the YAML file at the given path, e.g.
+//
+//	tokens:
+//	  - name: prometheus
+//	    token: some-long-random-string
+//	    groups: [senders]
+func NewStaticTokenAuthenticator(path string) (*StaticTokenAuthenticator, error) {
+	file := struct {
+		Tokens []StaticToken `yaml:"tokens"`
+	}{}
+
+	if err := readYAMLFile(path, &file); err != nil {
+		return nil, errors.Wrap(err, "failed to load tokens")
+	}
+
+	for i, token := range file.Tokens {
+		if token.Name == "" || token.Token == "" {
+			return nil, fmt.Errorf("token %d in %s must have a name and a token", i, path)
+		}
+	}
+
+	return &StaticTokenAuthenticator{tokens: file.Tokens}, nil
+}
+
+func (s *StaticTokenAuthenticator) Authenticate(r *http.Request) (Identity, error) {
+	bearer, ok := bearerToken(r)
+	if !ok {
+		return Identity{}, ErrNoCredentials
+	}
+
+	for _, token := range s.tokens {
+		if subtle.ConstantTimeCompare([]byte(bearer), []byte(token.Token)) == 1 {
+			return Identity{
+				Name:   token.Name,
+				Groups: token.Groups,
+				Tenant: token.Tenant,
+				Method: "token",
+			}, nil
+		}
+	}
+
+	// Other authenticators (e.g. JWTs) might recognise the token, so this isn't necessarily invalid.
+	return Identity{}, ErrNoCredentials
+}
+
+// BasicUser is a user that can authenticate with HTTP basic auth.
+type BasicUser struct {
+	Name string `yaml:"name"`
+
+	// PasswordHash is the bcrypt hash of the user's password, e.g. from `htpasswd -nbB`.
+	PasswordHash string `yaml:"password_hash"`
+
+	Groups []string `yaml:"groups"`
+	Tenant string   `yaml:"tenant"`
+}
+
+// BasicAuthenticator authenticates requests with HTTP basic auth, against users from a file.
+type BasicAuthenticator struct {
+	users map[string]BasicUser
+}
+
+// NewBasicAuthenticator loads the users in the YAML file at the given path, e.g.
+//
+//	users:
+//	  - name: alice
+//	    password_hash: $2y$10$...
+//	    groups: [oncall]
+func NewBasicAuthenticator(path string) (*BasicAuthenticator, error) {
+	file := struct {
+		Users []BasicUser `yaml:"users"`
+	}{}
+
+	if err := readYAMLFile(path, &file); err != nil {
+		return nil, errors.Wrap(err, "failed to load users")
+	}
+
+	users := make(map[string]BasicUser, len(file.Users))
+	for i, user := range file.Users {
+		if user.Name == "" || user.PasswordHash == "" {
+			return nil, fmt.Errorf("user %d in %s must have a name and a password_hash", i, path)
+		}
+
+		if _, err := bcrypt.Cost([]byte(user.PasswordHash)); err != nil {
+			return nil, errors.Wrapf(err, "user %q in %s has an invalid password_hash", user.Name, path)
+		}
+
+		if _, ok := users[user.Name]; ok {
+			return nil, fmt.Errorf("user %q is defined more than once in %s", user.Name, path)
+		}
+
+		users[user.Name] = user
+	}
+
+	return &BasicAuthenticator{users: users}, nil
+}
+
+func (b *BasicAuthenticator) Authenticate(r *http.Request) (Identity, error) {
+	name, password, ok := r.BasicAuth()
+	if !ok {
+		return Identity{}, ErrNoCredentials
+	}
+
+	user, ok := b.users[name]
+	if !ok {
+		return Identity{}, errors.Wrapf(ErrInvalidCredentials, "unknown user %q", name)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return Identity{}, errors.Wrapf(ErrInvalidCredentials, "wrong password for %q", name)
+	}
+
+	return Identity{
+		Name:   user.Name,
+		Groups: user.Groups,
+		Tenant: user.Tenant,
+		Method: "basic",
+	}, nil
+}
+
+// bearerToken returns the bearer token in the Authorization header of the given request, if it has one.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
+
+// readYAMLFile decodes the YAML file at the given path into out, rejecting unknown fields so that typos are caught.
+func readYAMLFile(path string, out any) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	return decoder.Decode(out)
+}
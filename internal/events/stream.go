@@ -0,0 +1,176 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sinkingpoint/kiora/internal/stubs"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+const (
+	// DefaultHistorySize is the number of events that a stream keeps for consumers that resume after disconnecting.
+	DefaultHistorySize = 10000
+
+	// subscriptionBufferSize is the number of events that can be waiting for a subscriber before it's considered too slow, and dropped.
+	subscriptionBufferSize = 1000
+)
+
+// Filter decides whether an event is sent to a subscriber.
+type Filter func(ctx context.Context, event *model.Event) bool
+
+// Stream fans out the changes processed by this node to everything that's subscribed to them, keeping a window
+// of recent events so that subscribers can resume from the last event they saw.
+type Stream struct {
+	// epoch identifies this stream, so that IDs from before a restart aren't mistaken for IDs in this one.
+	epoch string
+
+	lock        sync.Mutex
+	seq         uint64
+	history     []model.Event
+	historySize int
+	subscribers map[*Subscription]struct{}
+}
+
+func NewStream(historySize int) *Stream {
+	return &Stream{
+		epoch:       strconv.FormatInt(stubs.Time.Now().UnixNano(), 36),
+		historySize: historySize,
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+// Publish assigns IDs to the given events, and sends them to every subscriber whose filter matches them. Publishing to a nil stream does nothing.
+func (s *Stream) Publish(events ...model.Event) {
+	if s == nil || len(events) == 0 {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, event := range events {
+		s.seq++
+		event.ID = s.formatID(s.seq)
+		if event.Time.IsZero() {
+			event.Time = stubs.Time.Now()
+		}
+
+		s.history = append(s.history, event)
+		if len(s.history) > s.historySize {
+			s.history = s.history[len(s.history)-s.historySize:]
+		}
+
+		for sub := range s.subscribers {
+			if !sub.matches(&event) {
+				continue
+			}
+
+			select {
+			case sub.events <- event:
+			default:
+				// The subscriber isn't keeping up, so we drop it rather than blocking everything else. It can resume from the last event it saw.
+				s.unsubscribe(sub)
+			}
+		}
+	}
+}
+
+// Subscribe returns a subscription to the events in the stream that match the given filter. If lastEventID is set, the subscription
+// starts with the events after it. If those events are no longer in the stream (or lastEventID is from another stream), the subscription
+// is marked as Missed, and starts from the next event.
+func (s *Stream) Subscribe(ctx context.Context, lastEventID string, filter Filter) *Subscription {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sub := &Subscription{
+		ctx:    ctx,
+		filter: filter,
+		stream: s,
+	}
+
+	backlog := []model.Event{}
+	if lastEventID != "" {
+		backlog, sub.Missed = s.eventsAfter(lastEventID)
+	}
+
+	sub.events = make(chan model.Event, subscriptionBufferSize+len(backlog))
+	for _, event := range backlog {
+		if sub.matches(&event) {
+			sub.events <- event
+		}
+	}
+
+	s.subscribers[sub] = struct{}{}
+
+	return sub
+}
+
+// eventsAfter returns the events in the history after the one with the given ID, and whether any events after it are missing from the history.
+func (s *Stream) eventsAfter(id string) ([]model.Event, bool) {
+	epoch, seqStr, ok := strings.Cut(id, "-")
+	if !ok || epoch != s.epoch {
+		return nil, true
+	}
+
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if err != nil || seq > s.seq {
+		return nil, true
+	}
+
+	// The history is contiguous, so the first event in it tells us where the event after the given one is.
+	first := s.seq - uint64(len(s.history)) + 1
+	if seq+1 < first {
+		return nil, true
+	}
+
+	events := make([]model.Event, 0, s.seq-seq)
+	events = append(events, s.history[seq+1-first:]...)
+	return events, false
+}
+
+func (s *Stream) formatID(seq uint64) string {
+	return fmt.Sprintf("%s-%d", s.epoch, seq)
+}
+
+// unsubscribe removes the given subscriber from the stream, closing its channel. The stream lock must be held.
+func (s *Stream) unsubscribe(sub *Subscription) {
+	if _, ok := s.subscribers[sub]; !ok {
+		return
+	}
+
+	delete(s.subscribers, sub)
+	close(sub.events)
+}
+
+// Subscription is a subscriber to a Stream.
+type Subscription struct {
+	// Missed is true if the subscription was resumed from an event that's no longer in the stream, so some events were missed.
+	Missed bool
+
+	ctx    context.Context
+	filter Filter
+	stream *Stream
+	events chan model.Event
+}
+
+// Events returns the events sent to the subscriber. The channel is closed when the subscription is closed, or if the subscriber falls
+// too far behind the stream.
+func (s *Subscription) Events() <-chan model.Event {
+	return s.events
+}
+
+// Close stops sending events to the subscriber.
+func (s *Subscription) Close() {
+	s.stream.lock.Lock()
+	defer s.stream.lock.Unlock()
+
+	s.stream.unsubscribe(s)
+}
+
+func (s *Subscription) matches(event *model.Event) bool {
+	return s.filter == nil || s.filter(s.ctx, event)
+}
@@ -0,0 +1,150 @@
+package events_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sinkingpoint/kiora/internal/events"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+// receive returns the events waiting for the given subscription, without blocking.
+func receive(sub *events.Subscription) []model.Event {
+	received := []model.Event{}
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return received
+			}
+
+			received = append(received, event)
+		default:
+			return received
+		}
+	}
+}
+
+func silenceEvent(id string) model.Event {
+	return model.Event{Type: model.EventTypeSilence, Silence: &model.Silence{ID: id}}
+}
+
+func silenceIDs(events []model.Event) []string {
+	ids := []string{}
+	for _, event := range events {
+		ids = append(ids, event.Silence.ID)
+	}
+
+	return ids
+}
+
+func TestStreamSubscribe(t *testing.T) {
+	stream := events.NewStream(10)
+	stream.Publish(silenceEvent("before"))
+
+	onlyFoo := func(ctx context.Context, event *model.Event) bool {
+		return event.Silence.ID != "bar"
+	}
+
+	all := stream.Subscribe(context.Background(), "", nil)
+	filtered := stream.Subscribe(context.Background(), "", onlyFoo)
+
+	stream.Publish(silenceEvent("foo"), silenceEvent("bar"))
+
+	allEvents := receive(all)
+	require.Equal(t, []string{"foo", "bar"}, silenceIDs(allEvents))
+	require.NotEqual(t, allEvents[0].ID, allEvents[1].ID)
+	require.False(t, allEvents[0].Time.IsZero())
+
+	require.Equal(t, []string{"foo"}, silenceIDs(receive(filtered)))
+
+	// Closed subscriptions don't receive anything else.
+	filtered.Close()
+	stream.Publish(silenceEvent("baz"))
+	require.Equal(t, []string{"baz"}, silenceIDs(receive(all)))
+	require.Empty(t, receive(filtered))
+}
+
+func TestStreamResume(t *testing.T) {
+	stream := events.NewStream(3)
+
+	sub := stream.Subscribe(context.Background(), "", nil)
+	stream.Publish(silenceEvent("1"), silenceEvent("2"), silenceEvent("3"))
+	published := receive(sub)
+	require.Len(t, published, 3)
+
+	tests := []struct {
+		name           string
+		lastEventID    string
+		expectedIDs    []string
+		expectedMissed bool
+	}{
+		{
+			name:        "resuming from an event in the history sends the events after it",
+			lastEventID: published[0].ID,
+			expectedIDs: []string{"2", "3"},
+		},
+		{
+			name:        "resuming from the last event sends nothing",
+			lastEventID: published[2].ID,
+			expectedIDs: []string{},
+		},
+		{
+			name:           "resuming from an event from another stream is missed",
+			lastEventID:    "foo-1",
+			expectedIDs:    []string{},
+			expectedMissed: true,
+		},
+		{
+			name:           "resuming from an invalid ID is missed",
+			lastEventID:    "foo",
+			expectedIDs:    []string{},
+			expectedMissed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := stream.Subscribe(context.Background(), tt.lastEventID, nil)
+			defer sub.Close()
+
+			require.Equal(t, tt.expectedMissed, sub.Missed)
+			require.Equal(t, tt.expectedIDs, silenceIDs(receive(sub)))
+		})
+	}
+
+	// Once the event after the first one falls out of the history, resuming from the first one misses it.
+	stream.Publish(silenceEvent("4"), silenceEvent("5"))
+	resumed := stream.Subscribe(context.Background(), published[0].ID, nil)
+	require.True(t, resumed.Missed)
+	require.Empty(t, receive(resumed))
+
+	resumed = stream.Subscribe(context.Background(), published[1].ID, nil)
+	require.False(t, resumed.Missed)
+	require.Equal(t, []string{"3", "4", "5"}, silenceIDs(receive(resumed)))
+}
+
+func TestStreamDropsSlowSubscribers(t *testing.T) {
+	stream := events.NewStream(10)
+	sub := stream.Subscribe(context.Background(), "", nil)
+
+	// Publish more events than the subscriber can hold without reading any of them.
+	for i := 0; i < 2000; i++ {
+		stream.Publish(silenceEvent("foo"))
+	}
+
+	received := receive(sub)
+	require.Less(t, len(received), 2000)
+
+	_, ok := <-sub.Events()
+	require.False(t, ok, "expected the slow subscriber to be dropped")
+
+	// Closing a dropped subscription is fine.
+	sub.Close()
+}
+
+func TestPublishToNilStream(t *testing.T) {
+	var stream *events.Stream
+	stream.Publish(silenceEvent("foo"))
+}
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/sinkingpoint/kiora/internal/clustering"
+	"github.com/sinkingpoint/kiora/internal/events"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
 	"github.com/sinkingpoint/kiora/lib/kiora/kioradb"
 	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
 	"github.com/sinkingpoint/kiora/lib/kiora/model"
@@ -17,17 +19,40 @@ var _ = clustering.EventDelegate(&DBEventDelegate{})
 type DBEventDelegate struct {
 	db kioradb.DB
 
+	// conf is used to work out which tenant alerts belong to, so that silences only apply to alerts in the tenant that created them.
+	conf config.Config
+
 	// buffer is the bufferDB that stores the alerts and silences.
 	// Because the Delegate receives alerts one at a time, buffering them before flushing them to the database
 	// can significantly improve performance.
 	buffer *bufferDB
+
+	// events is the stream that changes are published to, so that they can be streamed to API consumers.
+	events *events.Stream
+}
+
+// DBEventDelegateOption configures the optional parts of a DBEventDelegate.
+type DBEventDelegateOption func(*DBEventDelegate)
+
+// WithEventStream publishes the changes processed by the delegate to the given stream.
+func WithEventStream(stream *events.Stream) DBEventDelegateOption {
+	return func(d *DBEventDelegate) {
+		d.events = stream
+	}
 }
 
-func NewDBEventDelegate(db kioradb.DB) *DBEventDelegate {
-	return &DBEventDelegate{
+func NewDBEventDelegate(db kioradb.DB, conf config.Config, opts ...DBEventDelegateOption) *DBEventDelegate {
+	delegate := &DBEventDelegate{
 		db:     db,
+		conf:   conf,
 		buffer: NewBufferDB(db, 1000, 1000, 10000, 100*time.Millisecond),
 	}
+
+	for _, opt := range opts {
+		opt(delegate)
+	}
+
+	return delegate
 }
 
 func (d *DBEventDelegate) Name() string {
@@ -44,9 +69,11 @@ func (d *DBEventDelegate) ProcessAlert(ctx context.Context, alert model.Alert) {
 
 	currentAlerts := d.db.QueryAlerts(ctx, query.NewAlertQuery(query.ExactLabelMatch(alert.Labels)))
 
+	var currentAlert *model.Alert
+
 	// Copy attributes from the current alert if it exists.
 	if len(currentAlerts) > 0 {
-		currentAlert := currentAlerts[0]
+		currentAlert = &currentAlerts[0]
 		if alert.Status != model.AlertStatusResolved && alert.Status != model.AlertStatusTimedOut {
 			if alert.LastNotifyTime.IsZero() {
 				alert.LastNotifyTime = currentAlert.LastNotifyTime
@@ -70,7 +97,11 @@ func (d *DBEventDelegate) ProcessAlert(ctx context.Context, alert model.Alert) {
 	// If it's firing, silence it if there's a matching silence. We can't do this async in a service
 	// because that would cause a race condition where the alert could be fired before the silence is applied.
 	if alert.Status == model.AlertStatusFiring {
-		silences := d.db.QuerySilences(ctx, query.NewSilenceQuery(query.AllSilences(query.PartialLabelMatch(alert.Labels), query.SilenceIsActive())))
+		inTenant := query.SilenceFilterFunc(func(ctx context.Context, silence *model.Silence) bool {
+			return d.inSameTenant(ctx, silence, &alert)
+		})
+
+		silences := d.db.QuerySilences(ctx, query.NewSilenceQuery(query.AllSilences(query.PartialLabelMatch(alert.Labels), query.SilenceIsActive(), inTenant)))
 		if len(silences) > 0 {
 			alert.Status = model.AlertStatusSilenced
 		}
@@ -78,6 +109,11 @@ func (d *DBEventDelegate) ProcessAlert(ctx context.Context, alert model.Alert) {
 
 	// TODO(cdouch): Handle errors here.
 	d.buffer.StoreAlerts(ctx, alert) // nolint
+
+	// Alerts are sent again every time they're evaluated, so we only publish the ones that have actually changed.
+	if currentAlert == nil || alertChanged(currentAlert, &alert) {
+		d.events.Publish(model.Event{Type: model.EventTypeAlert, Alert: &alert})
+	}
 }
 
 func (d *DBEventDelegate) ProcessAlertAcknowledgement(ctx context.Context, alertID string, ack model.AlertAcknowledgement) {
@@ -96,6 +132,8 @@ func (d *DBEventDelegate) ProcessAlertAcknowledgement(ctx context.Context, alert
 
 	// TODO(cdouch): Handle errors here.
 	d.buffer.StoreAlerts(ctx, alert) // nolint
+
+	d.events.Publish(model.Event{Type: model.EventTypeAck, Alert: &alert, Acknowledgement: &ack})
 }
 
 func (d *DBEventDelegate) ProcessSilence(ctx context.Context, silence model.Silence) {
@@ -103,16 +141,56 @@ func (d *DBEventDelegate) ProcessSilence(ctx context.Context, silence model.Sile
 	if len(existingSilence) == 0 && silence.IsActive() {
 		// This is a new silence, so we need to apply it to all the alerts.
 		alerts := d.db.QueryAlerts(ctx, query.NewAlertQuery(query.AlertFilterFunc(func(ctx context.Context, alert *model.Alert) bool {
-			return silence.Matches(alert.Labels) && (alert.Status == model.AlertStatusFiring || alert.Status == model.AlertStatusAcked)
+			return silence.Matches(alert.Labels) && (alert.Status == model.AlertStatusFiring || alert.Status == model.AlertStatusAcked) && d.inSameTenant(ctx, &silence, alert)
 		})))
 
 		for _, alert := range alerts {
+			alert := alert
 			alert.Status = model.AlertStatusSilenced
 			// TODO(cdouch): Handle errors here.
 			d.db.StoreAlerts(ctx, alert) // nolint
+			d.events.Publish(model.Event{Type: model.EventTypeAlert, Alert: &alert})
 		}
 	}
 
 	// TODO(cdouch): Handle errors here.
 	d.buffer.StoreSilences(ctx, silence) // nolint
+
+	d.events.Publish(model.Event{Type: model.EventTypeSilence, Silence: &silence})
+}
+
+// alertChanged returns true if the given alert differs from the stored one in a way that consumers of the event stream care about.
+// Things that change every time an alert is sent, like its time out deadline, are ignored.
+func alertChanged(current, alert *model.Alert) bool {
+	if current.Status != alert.Status || !current.StartTime.Equal(alert.StartTime) || !current.EndTime.Equal(alert.EndTime) {
+		return true
+	}
+
+	if (current.Acknowledgement == nil) != (alert.Acknowledgement == nil) {
+		return true
+	}
+
+	if len(current.Annotations) != len(alert.Annotations) {
+		return true
+	}
+
+	for k, v := range alert.Annotations {
+		if currentV, ok := current.Annotations[k]; !ok || currentV != v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inSameTenant returns true if the given alert is in the tenant that created the given silence. Silences without a tenant (created by
+// admins, or before silences had tenants) silence every tenant. Otherwise, alerts that can't be assigned to a tenant aren't in any tenant,
+// so they can't be silenced.
+func (d *DBEventDelegate) inSameTenant(ctx context.Context, silence *model.Silence, alert *model.Alert) bool {
+	if d.conf == nil || silence.Tenant == "" {
+		return true
+	}
+
+	tenant, err := d.conf.Globals().Tenanter.GetTenant(ctx, alert)
+	return err == nil && tenant == config.Tenant(silence.Tenant)
 }
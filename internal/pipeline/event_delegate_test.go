@@ -0,0 +1,132 @@
+package pipeline_test
+
+import (
+	"context"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sinkingpoint/kiora/internal/events"
+	"github.com/sinkingpoint/kiora/internal/pipeline"
+	"github.com/sinkingpoint/kiora/internal/stubs"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/sinkingpoint/kiora/mocks/mock_config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBEventDelegateSilencesAreTenanted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	tenanter := config.NewTemplateTenanter(template.Must(template.New("tenant").Parse("{{ .team }}")))
+	conf := mock_config.NewMockConfig(ctrl)
+	conf.EXPECT().Globals().Return(config.NewGlobals(config.WithTenanter(tenanter))).AnyTimes()
+
+	db := kioradb.NewInMemoryDB()
+	alerts := []model.Alert{
+		{Labels: model.Labels{"alertname": "disk_full", "team": "foo"}, Status: model.AlertStatusFiring, StartTime: stubs.Time.Now()},
+		{Labels: model.Labels{"alertname": "disk_full", "team": "bar"}, Status: model.AlertStatusFiring, StartTime: stubs.Time.Now()},
+	}
+
+	for i := range alerts {
+		require.NoError(t, alerts[i].Materialise())
+	}
+
+	require.NoError(t, db.StoreAlerts(context.Background(), alerts...))
+
+	// A silence created by foo that matches both alerts should only silence foo's.
+	delegate := pipeline.NewDBEventDelegate(db, conf)
+	delegate.ProcessSilence(context.Background(), model.Silence{
+		ID:        "silence",
+		Tenant:    "foo",
+		StartTime: stubs.Time.Now().Add(-time.Minute),
+		EndTime:   stubs.Time.Now().Add(time.Hour),
+		Matchers:  []model.Matcher{{Label: "alertname", Value: "disk_full"}},
+	})
+
+	statuses := map[string]model.AlertStatus{}
+	for _, alert := range db.QueryAlerts(context.Background(), query.NewAlertQuery(query.MatchAll())) {
+		statuses[alert.Labels["team"]] = alert.Status
+	}
+
+	require.Equal(t, map[string]model.AlertStatus{
+		"foo": model.AlertStatusSilenced,
+		"bar": model.AlertStatusFiring,
+	}, statuses)
+}
+
+func TestDBEventDelegateSilencesWithoutATenant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	tenanter := config.NewTemplateTenanter(template.Must(template.New("tenant").Parse("{{ .team }}")))
+	conf := mock_config.NewMockConfig(ctrl)
+	conf.EXPECT().Globals().Return(config.NewGlobals(config.WithTenanter(tenanter))).AnyTimes()
+
+	db := kioradb.NewInMemoryDB()
+
+	// Silences without a tenant were created by admins (or before silences had tenants), so they silence every tenant.
+	delegate := pipeline.NewDBEventDelegate(db, conf)
+	delegate.ProcessSilence(context.Background(), model.Silence{
+		ID:        "silence",
+		StartTime: stubs.Time.Now().Add(-time.Minute),
+		EndTime:   stubs.Time.Now().Add(time.Hour),
+		Matchers:  []model.Matcher{{Label: "alertname", Value: "disk_full"}},
+	})
+
+	for _, team := range []string{"foo", "bar"} {
+		alert := model.Alert{Labels: model.Labels{"alertname": "disk_full", "team": team}, Status: model.AlertStatusFiring, StartTime: stubs.Time.Now()}
+		require.NoError(t, alert.Materialise())
+		delegate.ProcessAlert(context.Background(), alert)
+	}
+
+	for _, alert := range db.QueryAlerts(context.Background(), query.NewAlertQuery(query.MatchAll())) {
+		require.Equal(t, model.AlertStatusSilenced, alert.Status, alert.Labels["team"])
+	}
+}
+
+func TestDBEventDelegatePublishesChanges(t *testing.T) {
+	db := kioradb.NewInMemoryDB()
+	stream := events.NewStream(events.DefaultHistorySize)
+	sub := stream.Subscribe(context.Background(), "", nil)
+	delegate := pipeline.NewDBEventDelegate(db, nil, pipeline.WithEventStream(stream))
+
+	alert := model.Alert{Labels: model.Labels{"alertname": "disk_full"}, Annotations: map[string]string{}, Status: model.AlertStatusFiring, StartTime: stubs.Time.Now()}
+	require.NoError(t, alert.Materialise())
+	require.NoError(t, db.StoreAlerts(context.Background(), alert))
+
+	// Alerts are resent every time they're evaluated, which isn't a change.
+	refreshed := alert
+	refreshed.TimeOutDeadline = alert.TimeOutDeadline.Add(time.Minute)
+	delegate.ProcessAlert(context.Background(), refreshed)
+
+	resolved := alert
+	resolved.Status = model.AlertStatusResolved
+	delegate.ProcessAlert(context.Background(), resolved)
+
+	ack := model.AlertAcknowledgement{Creator: "alice", Comment: "looking"}
+	delegate.ProcessAlertAcknowledgement(context.Background(), alert.ID, ack)
+
+	silence := model.Silence{ID: "silence", StartTime: stubs.Time.Now(), EndTime: stubs.Time.Now().Add(time.Hour), Matchers: []model.Matcher{{Label: "alertname", Value: "cpu_high"}}}
+	delegate.ProcessSilence(context.Background(), silence)
+
+	received := []model.Event{}
+	for len(received) < 3 {
+		select {
+		case event := <-sub.Events():
+			received = append(received, event)
+		case <-time.After(time.Second):
+			t.Fatalf("expected 3 events, got %d", len(received))
+		}
+	}
+
+	require.Equal(t, model.EventTypeAlert, received[0].Type)
+	require.Equal(t, model.AlertStatusResolved, received[0].Alert.Status)
+
+	require.Equal(t, model.EventTypeAck, received[1].Type)
+	require.Equal(t, alert.ID, received[1].Alert.ID)
+	require.Equal(t, &ack, received[1].Acknowledgement)
+
+	require.Equal(t, model.EventTypeSilence, received[2].Type)
+	require.Equal(t, "silence", received[2].Silence.ID)
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 
+	"github.com/sinkingpoint/kiora/internal/audit"
+	"github.com/sinkingpoint/kiora/internal/auth"
 	"github.com/sinkingpoint/kiora/internal/clustering"
+	"github.com/sinkingpoint/kiora/internal/events"
 	"github.com/sinkingpoint/kiora/internal/services"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
 	"github.com/sinkingpoint/kiora/lib/kiora/kioradb"
 	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
 	"github.com/sinkingpoint/kiora/lib/kiora/model"
@@ -18,84 +21,241 @@ var _ = API(&APIImpl{})
 
 // API defines an interface that represents all the operations that can be performed on the kiora API.
 type API interface {
-	// GetAlerts returns a list of alerts matching the given query.
-	GetAlerts(ctx context.Context, q query.AlertQuery) ([]model.Alert, error)
+	// GetAlerts returns the page of alerts matching the given query, in the tenant that the request is scoped to. Returns
+	// query.ErrInvalidCursor if the query starts after a cursor that didn't come from the same query.
+	GetAlerts(ctx context.Context, q query.AlertQuery) (query.Page[model.Alert], error)
 
-	// PostAlerts stores the given alerts in the database, updating any existing alerts with the same labels.
-	PostAlerts(ctx context.Context, alerts []model.Alert) error
+	// PostAlerts materialises, validates, and stores the given alerts, updating any existing alerts with the same labels.
+	// Alerts that fail validation are rejected individually without blocking the rest of the batch. The returned error
+	// is reserved for failures that affect the whole batch.
+	PostAlerts(ctx context.Context, alerts []model.Alert) (PostAlertsResult, error)
 
-	// QueryAlertStats executes the given stats query, returning the resulting frames.
+	// QueryAlertStats executes the given stats query over the alerts in the tenant that the request is scoped to, returning the resulting frames.
 	QueryAlertStats(ctx context.Context, q query.AlertStatsQuery) ([]query.StatsResult, error)
 
-	// GetSilences returns a list of silences matching the given query.
-	GetSilences(ctx context.Context, query query.SilenceQuery) ([]model.Silence, error)
+	// GetSilences returns the page of silences matching the given query, that apply to the tenant that the request is scoped to. Returns
+	// query.ErrInvalidCursor if the query starts after a cursor that didn't come from the same query.
+	GetSilences(ctx context.Context, query query.SilenceQuery) (query.Page[model.Silence], error)
 
-	// PostSilences stores the given silences in the database, updating any existing silences with the same ID.
-	PostSilence(ctx context.Context, silences model.Silence) error
+	// PostSilences stores the given silences in the database, updating any existing silences with the same ID. Silences are
+	// created in the tenant that the request is scoped to, by the identity that made the request, which are set on the given silence.
+	PostSilence(ctx context.Context, silences *model.Silence) error
 
-	// AckAlert acknowledges the given alert with the given acknowledgement.
+	// AckAlert acknowledges the given alert with the given acknowledgement, created by the identity that made the request. The alert must
+	// be in the tenant that the request is scoped to.
 	AckAlert(ctx context.Context, alertID string, alertAck model.AlertAcknowledgement) error
 
 	// GetClusterStatus returns the status of the nodes in the cluster.
 	GetClusterStatus(ctx context.Context) ([]any, error)
+
+	// RouteAlert applies the config's transformations to the given alert, and returns the notifiers it would be sent to,
+	// without storing the alert or notifying anything.
+	RouteAlert(ctx context.Context, alert *model.Alert) ([]config.Route, error)
+
+	// GetConfigGraph describes the nodes and edges of the config. If an alert is given, the graph is annotated
+	// with the paths that the alert takes through the config.
+	GetConfigGraph(ctx context.Context, alert *model.Alert) (config.Graph, error)
+
+	// ReloadConfig reloads the config, keeping the old config if the new one is invalid. Returns ErrConfigNotReloadable
+	// if the config doesn't support reloading.
+	ReloadConfig(ctx context.Context) error
+
+	// GetAuditEntries returns the entries in the audit log matching the given query, in the tenant that the request is scoped to.
+	// Returns audit.ErrNotQueryable if the audit log isn't enabled, or can't be read back.
+	GetAuditEntries(ctx context.Context, q query.AuditQuery) ([]model.AuditEntry, error)
+
+	// SubscribeEvents subscribes to the changes to alerts, silences, and acknowledgements in the tenant that the request is scoped to.
+	// Only events of the given types are sent, or all of them if no types are given, and only if they match the given filter. If lastEventID
+	// is set, the subscription resumes after that event. Returns ErrEventsNotStreamed if the API doesn't have an event stream.
+	SubscribeEvents(ctx context.Context, types []model.EventType, filter query.AlertFilter, lastEventID string) (*events.Subscription, error)
 }
 
+var (
+	// ErrConfigNotReloadable is returned by ReloadConfig when the config can't be reloaded at runtime.
+	ErrConfigNotReloadable = errors.New("config does not support reloading")
+
+	// ErrConfigNotGraphable is returned by GetConfigGraph when the config can't describe itself as a graph.
+	ErrConfigNotGraphable = errors.New("config can't be described as a graph")
+
+	// ErrEventsNotStreamed is returned by SubscribeEvents when the API doesn't have an event stream to subscribe to.
+	ErrEventsNotStreamed = errors.New("events are not streamed")
+
+	// ErrSilenceTenantRequired is returned by PostSilence when a request that can't see every tenant tries to create a silence
+	// without a tenant, which would silence every tenant's alerts.
+	ErrSilenceTenantRequired = errors.New("silences must be created in a tenant")
+)
+
 type APIImpl struct {
 	bus       services.Bus
 	clusterer clustering.Clusterer
+	auditSink audit.Sink
+	events    *events.Stream
 }
 
-func NewAPIImpl(bus services.Bus, clusterer clustering.Clusterer) *APIImpl {
-	return &APIImpl{
+// APIOption configures the optional parts of an APIImpl.
+type APIOption func(*APIImpl)
+
+// WithAuditSink records every change made through the API in the audit log in the given sink.
+func WithAuditSink(sink audit.Sink) APIOption {
+	return func(a *APIImpl) {
+		a.auditSink = sink
+	}
+}
+
+// WithEventStream allows API consumers to subscribe to the changes published to the given stream.
+func WithEventStream(stream *events.Stream) APIOption {
+	return func(a *APIImpl) {
+		a.events = stream
+	}
+}
+
+func NewAPIImpl(bus services.Bus, clusterer clustering.Clusterer, opts ...APIOption) *APIImpl {
+	api := &APIImpl{
 		bus:       bus,
 		clusterer: clusterer,
 	}
+
+	for _, opt := range opts {
+		opt(api)
+	}
+
+	return api
 }
 
-func (a *APIImpl) GetAlerts(ctx context.Context, q query.AlertQuery) ([]model.Alert, error) {
-	return a.bus.DB().QueryAlerts(ctx, q), nil
+func (a *APIImpl) GetAlerts(ctx context.Context, q query.AlertQuery) (query.Page[model.Alert], error) {
+	q.Filter = a.scopeAlertFilter(ctx, q.Filter)
+	return kioradb.QueryAlertPage(ctx, a.bus.DB(), q)
 }
 
-func (a *APIImpl) PostAlerts(ctx context.Context, alerts []model.Alert) error {
-	var postErr error
+func (a *APIImpl) PostAlerts(ctx context.Context, alerts []model.Alert) (PostAlertsResult, error) {
+	auditor := a.startAudit(ctx, model.AuditActionPostAlerts, alerts)
+
+	result := PostAlertsResult{
+		Accepted: make([]AlertAcceptance, 0, len(alerts)),
+		Rejected: []AlertRejection{},
+	}
+
+	reject := func(i int, err error) {
+		result.Rejected = append(result.Rejected, AlertRejection{
+			Index: i,
+			Alert: alerts[i],
+			Err:   err,
+		})
+	}
+
+	accepted := make([]model.Alert, 0, len(alerts))
 	for i := range alerts {
+		if err := a.bus.Config().TransformAlert(ctx, &alerts[i]); err != nil {
+			reject(i, err)
+			continue
+		}
+
+		// Transforms can change the labels of the alert, so we materialise it (and calculate its ID) afterwards.
+		if err := alerts[i].Materialise(); err != nil {
+			reject(i, err)
+			continue
+		}
+
 		if err := a.bus.Config().ValidateData(ctx, &alerts[i]); err != nil {
-			postErr = multierror.Append(postErr, err)
+			reject(i, err)
+			continue
 		}
+
+		result.Accepted = append(result.Accepted, AlertAcceptance{
+			Index: i,
+			Alert: alerts[i],
+		})
+
+		accepted = append(accepted, alerts[i])
 	}
 
-	if postErr != nil {
-		return postErr
+	// A single bad alert shouldn't block the rest of the batch, so broadcast everything that was accepted
+	// and report the rejections individually.
+	rejections := make([]error, 0, len(result.Rejected))
+	for _, rejected := range result.Rejected {
+		rejections = append(rejections, errors.Wrapf(rejected.Err, "alert %d", rejected.Index))
 	}
 
-	return a.bus.Broadcaster().BroadcastAlerts(ctx, alerts...)
+	if len(accepted) > 0 {
+		auditor.broadcast(accepted)
+		if err := a.bus.Broadcaster().BroadcastAlerts(ctx, accepted...); err != nil {
+			auditor.finish(ctx, err, rejections...)
+			return PostAlertsResult{}, err
+		}
+	}
+
+	auditor.finish(ctx, nil, rejections...)
+	return result, nil
 }
 
 func (a *APIImpl) QueryAlertStats(ctx context.Context, q query.AlertStatsQuery) ([]query.StatsResult, error) {
+	if scope := TenantScopeFromContext(ctx); !scope.Admin {
+		q = &scopedStatsQuery{AlertStatsQuery: q, filter: a.scopeAlertFilter(ctx, q.Filter())}
+	}
+
 	return kioradb.QueryAlertStats(ctx, a.bus.DB(), q)
 }
 
-func (a *APIImpl) GetSilences(ctx context.Context, q query.SilenceQuery) ([]model.Silence, error) {
-	return a.bus.DB().QuerySilences(ctx, q), nil
+func (a *APIImpl) GetSilences(ctx context.Context, q query.SilenceQuery) (query.Page[model.Silence], error) {
+	if scope := TenantScopeFromContext(ctx); !scope.Admin {
+		// Silences without a tenant silence every tenant's alerts, so every tenant can see them.
+		inTenant := query.SilenceTenant(string(scope.Tenant))
+
+		if q.Filter == nil {
+			q.Filter = inTenant
+		} else {
+			q.Filter = query.AllSilences(q.Filter, inTenant)
+		}
+	}
+
+	// We paginate here, rather than in the DB, so that we know the total number of silences that match.
+	silences := a.bus.DB().QuerySilences(ctx, query.NewSilenceQuery(q.Filter))
+	return query.PaginateSilences(silences, q.Query)
 }
 
-func (a *APIImpl) PostSilence(ctx context.Context, silence model.Silence) error {
-	if err := a.bus.Config().ValidateData(ctx, &silence); err != nil {
+func (a *APIImpl) PostSilence(ctx context.Context, silence *model.Silence) (err error) {
+	auditor := a.startAudit(ctx, model.AuditActionPostSilence, silence)
+	defer func() { auditor.finish(ctx, err) }()
+
+	// Authenticated requests create silences as themselves, rather than whoever the request claims to be.
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		silence.Creator = identity.Name
+	}
+
+	// Admins can create silences in any tenant (or in none, silencing every tenant), but everyone else can only create them in their own.
+	if scope := TenantScopeFromContext(ctx); !scope.Admin {
+		if scope.Tenant == "" && hasTenants(a.bus.Config()) {
+			return ErrSilenceTenantRequired
+		}
+
+		silence.Tenant = string(scope.Tenant)
+	}
+
+	if err := a.bus.Config().ValidateData(ctx, silence); err != nil {
 		return err
 	}
 
-	return a.bus.Broadcaster().BroadcastSilences(ctx, silence)
+	auditor.broadcast(silence)
+	return a.bus.Broadcaster().BroadcastSilences(ctx, *silence)
 }
 
-func (a *APIImpl) AckAlert(ctx context.Context, alertID string, alertAck model.AlertAcknowledgement) error {
+func (a *APIImpl) AckAlert(ctx context.Context, alertID string, alertAck model.AlertAcknowledgement) (err error) {
+	auditor := a.startAudit(ctx, model.AuditActionAckAlert, auditedAck{AlertID: alertID, AlertAcknowledgement: alertAck})
+	defer func() { auditor.finish(ctx, err) }()
+
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		alertAck.Creator = identity.Name
+	}
+
 	if err := a.bus.Config().ValidateData(ctx, &alertAck); err != nil {
 		return err
 	}
 
-	if len(a.bus.DB().QueryAlerts(ctx, query.NewAlertQuery(query.ID(alertID)))) == 0 {
+	if len(a.bus.DB().QueryAlerts(ctx, query.NewAlertQuery(a.scopeAlertFilter(ctx, query.ID(alertID))))) == 0 {
 		return fmt.Errorf("alert %q not found", alertID)
 	}
 
+	auditor.broadcast(auditedAck{AlertID: alertID, AlertAcknowledgement: alertAck})
 	return a.bus.Broadcaster().BroadcastAlertAcknowledgement(ctx, alertID, alertAck)
 }
 
@@ -106,3 +266,90 @@ func (a *APIImpl) GetClusterStatus(ctx context.Context) ([]any, error) {
 
 	return a.clusterer.Nodes(), nil
 }
+
+func (a *APIImpl) RouteAlert(ctx context.Context, alert *model.Alert) ([]config.Route, error) {
+	if err := a.bus.Config().TransformAlert(ctx, alert); err != nil {
+		return nil, errors.Wrap(err, "failed to transform alert")
+	}
+
+	return a.bus.Config().RouteAlert(ctx, alert), nil
+}
+
+func (a *APIImpl) GetConfigGraph(ctx context.Context, alert *model.Alert) (config.Graph, error) {
+	grapher, ok := a.bus.Config().(config.Grapher)
+	if !ok {
+		return config.Graph{}, ErrConfigNotGraphable
+	}
+
+	graph := grapher.Graph()
+	if alert != nil {
+		routes, err := a.RouteAlert(ctx, alert)
+		if err != nil {
+			return config.Graph{}, err
+		}
+
+		graph.Highlight(routes)
+	}
+
+	return graph, nil
+}
+
+func (a *APIImpl) ReloadConfig(ctx context.Context) (err error) {
+	auditor := a.startAudit(ctx, model.AuditActionReloadConfig, nil)
+	defer func() { auditor.finish(ctx, err) }()
+
+	reloader, ok := a.bus.Config().(config.Reloader)
+	if !ok {
+		return ErrConfigNotReloadable
+	}
+
+	return reloader.Reload(ctx)
+}
+
+func (a *APIImpl) GetAuditEntries(ctx context.Context, q query.AuditQuery) ([]model.AuditEntry, error) {
+	if a.auditSink == nil {
+		return nil, audit.ErrNotQueryable
+	}
+
+	if scope := TenantScopeFromContext(ctx); !scope.Admin {
+		tenant := string(scope.Tenant)
+		q.Tenant = &tenant
+	}
+
+	return audit.Query(ctx, a.auditSink, q)
+}
+
+func (a *APIImpl) SubscribeEvents(ctx context.Context, types []model.EventType, filter query.AlertFilter, lastEventID string) (*events.Subscription, error) {
+	if a.events == nil {
+		return nil, ErrEventsNotStreamed
+	}
+
+	wantedTypes := map[model.EventType]struct{}{}
+	for _, ty := range types {
+		wantedTypes[ty] = struct{}{}
+	}
+
+	scope := TenantScopeFromContext(ctx)
+	alertFilter := a.scopeAlertFilter(ctx, filter)
+	silenceFilter, _ := filter.(query.SilenceFilter)
+
+	return a.events.Subscribe(ctx, lastEventID, func(ctx context.Context, event *model.Event) bool {
+		if _, ok := wantedTypes[event.Type]; len(wantedTypes) > 0 && !ok {
+			return false
+		}
+
+		switch {
+		case event.Alert != nil:
+			return alertFilter == nil || alertFilter.MatchesAlert(ctx, event.Alert)
+		case event.Silence != nil:
+			if !scope.Admin && !query.SilenceTenant(string(scope.Tenant)).MatchesSilence(ctx, event.Silence) {
+				return false
+			}
+
+			// Not every alert filter can be applied to silences, so silences that we can't filter are always sent.
+			return silenceFilter == nil || silenceFilter.MatchesSilence(ctx, event.Silence)
+		default:
+			return false
+		}
+	}), nil
+}
@@ -0,0 +1,423 @@
+package apigrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/internal/auth"
+	"github.com/sinkingpoint/kiora/internal/events"
+	"github.com/sinkingpoint/kiora/internal/server/api"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/kiorapb"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Register serves the gRPC API on the given server, backed by the given API, so that it behaves identically to the HTTP API.
+func Register(server *grpc.Server, api api.API, logger zerolog.Logger) {
+	kiorapb.RegisterKioraServer(server, New(api, logger))
+}
+
+var _ = kiorapb.KioraServer(&apigrpc{})
+
+type apigrpc struct {
+	kiorapb.UnimplementedKioraServer
+
+	api    api.API
+	logger zerolog.Logger
+}
+
+func New(api api.API, logger zerolog.Logger) *apigrpc {
+	return &apigrpc{
+		api:    api,
+		logger: logger.With().Str("component", "apigrpc").Logger(),
+	}
+}
+
+// requirePermission returns a PermissionDenied error if the call in the given context doesn't have the given permission.
+func requirePermission(ctx context.Context, permission auth.Permission) error {
+	if !auth.Allowed(ctx, permission) {
+		return status.Errorf(codes.PermissionDenied, "permission %q is required", permission)
+	}
+
+	return nil
+}
+
+// errorStatus converts an error from the API into a status, with errors caused by the data that was sent reported as invalid arguments.
+func (a *apigrpc) errorStatus(err error, msg string) error {
+	var validationErr *config.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return status.Error(codes.InvalidArgument, validationErr.Error())
+	case errors.Is(err, query.ErrInvalidCursor):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, api.ErrEventsNotStreamed):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, api.ErrSilenceTenantRequired):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		a.logger.Debug().Err(err).Msg(msg)
+		return status.Errorf(codes.Internal, "%s: %s", msg, err.Error())
+	}
+}
+
+// parseMatchers parses matchers in the same form as the HTTP API.
+func parseMatchers(matcherStrings []string) ([]model.Matcher, error) {
+	matchers := make([]model.Matcher, 0, len(matcherStrings))
+	for _, matcherString := range matcherStrings {
+		matcher := model.Matcher{}
+		if err := matcher.UnmarshalText(matcherString); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failed to unmarshal matcher: %q", matcherString)
+		}
+
+		matchers = append(matchers, matcher)
+	}
+
+	return matchers, nil
+}
+
+func queryOpts(limit, offset int32, sort []string, order, cursor string) ([]query.QueryOption, error) {
+	opts := []query.QueryOption{}
+	if cursor != "" {
+		opts = append(opts, query.StartAfter(cursor))
+	}
+	if limit > 0 {
+		opts = append(opts, query.Limit(int(limit)))
+	}
+
+	if offset > 0 {
+		opts = append(opts, query.Offset(int(offset)))
+	}
+
+	switch {
+	case len(sort) > 0:
+		direction := query.OrderAsc
+		if order == string(query.OrderDesc) {
+			direction = query.OrderDesc
+		}
+
+		opts = append(opts, query.OrderBy(sort, direction))
+	case order != "":
+		return nil, status.Error(codes.InvalidArgument, "order specified without sort")
+	}
+
+	return opts, nil
+}
+
+func (a *apigrpc) GetAlerts(ctx context.Context, req *kiorapb.GetAlertsRequest) (*kiorapb.GetAlertsResponse, error) {
+	if err := requirePermission(ctx, auth.PermissionReadAlerts); err != nil {
+		return nil, err
+	}
+
+	matchers, err := parseMatchers(req.GetMatchers())
+	if err != nil {
+		return nil, err
+	}
+
+	filters := make([]query.AlertFilter, 0, len(matchers))
+	for _, matcher := range matchers {
+		if matcher.Label == "__id__" && !matcher.IsRegex && !matcher.IsNegative {
+			// For __id__=value matchers, we can use the ID filter, which is more efficient.
+			filters = append(filters, query.ID(matcher.Value))
+		} else {
+			filters = append(filters, query.Matcher(matcher))
+		}
+	}
+
+	if req.GetQuery() != "" {
+		alertQuery, err := query.ParseAlertFilter(req.GetQuery())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid query: %s", err.Error())
+		}
+
+		filters = append(filters, alertQuery)
+	}
+
+	filter := query.AlertFilter(query.MatchAll())
+	if len(filters) > 0 {
+		filter = query.AllAlerts(filters...)
+	}
+
+	opts, err := queryOpts(req.GetLimit(), req.GetOffset(), req.GetSort(), req.GetOrder(), req.GetCursor())
+	if err != nil {
+		return nil, err
+	}
+
+	alerts, err := a.api.GetAlerts(ctx, query.NewAlertQuery(filter, opts...))
+	if err != nil {
+		return nil, a.errorStatus(err, "failed to get alerts")
+	}
+
+	response := &kiorapb.GetAlertsResponse{
+		Alerts:     make([]*kiorapb.Alert, 0, len(alerts.Items)),
+		Total:      int32(alerts.Total),
+		NextCursor: alerts.NextCursor,
+	}
+
+	for i := range alerts.Items {
+		response.Alerts = append(response.Alerts, newAlert(&alerts.Items[i]))
+	}
+
+	return response, nil
+}
+
+func (a *apigrpc) PostAlerts(ctx context.Context, req *kiorapb.PostAlertsRequest) (*kiorapb.PostAlertsResponse, error) {
+	if err := requirePermission(ctx, auth.PermissionWriteAlerts); err != nil {
+		return nil, err
+	}
+
+	alerts := make([]model.Alert, 0, len(req.GetAlerts()))
+	for _, alert := range req.GetAlerts() {
+		alerts = append(alerts, fromAlert(alert))
+	}
+
+	result, err := a.api.PostAlerts(ctx, alerts)
+	if err != nil {
+		return nil, a.errorStatus(err, "failed to post alerts")
+	}
+
+	return newPostAlertsResponse(result), nil
+}
+
+func (a *apigrpc) QueryAlertStats(ctx context.Context, req *kiorapb.QueryAlertStatsRequest) (*kiorapb.QueryAlertStatsResponse, error) {
+	if err := requirePermission(ctx, auth.PermissionReadAlerts); err != nil {
+		return nil, err
+	}
+
+	args := req.GetArgs()
+	if args == nil {
+		args = map[string]string{}
+	}
+
+	q, err := query.UnmarshalAlertStatsQuery(req.GetType(), args)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to unmarshal query: %s", err.Error())
+	}
+
+	results, err := a.api.QueryAlertStats(ctx, q)
+	if err != nil {
+		return nil, a.errorStatus(err, "failed to query alert stats")
+	}
+
+	response := &kiorapb.QueryAlertStatsResponse{Results: make([]*kiorapb.StatsResult, 0, len(results))}
+	for _, result := range results {
+		response.Results = append(response.Results, newStatsResult(result))
+	}
+
+	return response, nil
+}
+
+func (a *apigrpc) GetSilences(ctx context.Context, req *kiorapb.GetSilencesRequest) (*kiorapb.GetSilencesResponse, error) {
+	if err := requirePermission(ctx, auth.PermissionReadSilences); err != nil {
+		return nil, err
+	}
+
+	matchers, err := parseMatchers(req.GetMatchers())
+	if err != nil {
+		return nil, err
+	}
+
+	filters := make([]query.SilenceFilter, 0, len(matchers))
+	for _, matcher := range matchers {
+		if matcher.Label == "__id__" && !matcher.IsRegex && !matcher.IsNegative {
+			// For __id__=value matchers, we can use the ID filter, which is more efficient.
+			filters = append(filters, query.ID(matcher.Value))
+		} else {
+			filters = append(filters, query.Matcher(matcher))
+		}
+	}
+
+	if len(filters) == 0 {
+		filters = append(filters, query.MatchAll())
+	}
+
+	opts, err := queryOpts(req.GetLimit(), req.GetOffset(), req.GetSort(), req.GetOrder(), req.GetCursor())
+	if err != nil {
+		return nil, err
+	}
+
+	silences, err := a.api.GetSilences(ctx, query.NewSilenceQuery(query.AllSilences(filters...), opts...))
+	if err != nil {
+		return nil, a.errorStatus(err, "failed to get silences")
+	}
+
+	response := &kiorapb.GetSilencesResponse{
+		Silences:   make([]*kiorapb.Silence, 0, len(silences.Items)),
+		Total:      int32(silences.Total),
+		NextCursor: silences.NextCursor,
+	}
+
+	for i := range silences.Items {
+		response.Silences = append(response.Silences, newSilence(&silences.Items[i]))
+	}
+
+	return response, nil
+}
+
+func (a *apigrpc) PostSilence(ctx context.Context, req *kiorapb.PostSilenceRequest) (*kiorapb.PostSilenceResponse, error) {
+	if err := requirePermission(ctx, auth.PermissionWriteSilences); err != nil {
+		return nil, err
+	}
+
+	if req.GetSilence() == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing silence")
+	}
+
+	silence, err := fromSilence(req.GetSilence())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to parse silence: %s", err.Error())
+	}
+
+	if err := a.api.PostSilence(ctx, &silence); err != nil {
+		return nil, a.errorStatus(err, "failed to post silence")
+	}
+
+	return &kiorapb.PostSilenceResponse{Silence: newSilence(&silence)}, nil
+}
+
+func (a *apigrpc) AckAlert(ctx context.Context, req *kiorapb.AckAlertRequest) (*kiorapb.AckAlertResponse, error) {
+	if err := requirePermission(ctx, auth.PermissionWriteAcks); err != nil {
+		return nil, err
+	}
+
+	if req.GetAlertId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing alert ID")
+	}
+
+	if err := a.api.AckAlert(ctx, req.GetAlertId(), fromAck(req.GetAcknowledgement())); err != nil {
+		return nil, a.errorStatus(err, "failed to handle alert acknowledgment")
+	}
+
+	return &kiorapb.AckAlertResponse{}, nil
+}
+
+func (a *apigrpc) GetClusterStatus(ctx context.Context, req *kiorapb.GetClusterStatusRequest) (*kiorapb.GetClusterStatusResponse, error) {
+	if err := requirePermission(ctx, auth.PermissionReadCluster); err != nil {
+		return nil, err
+	}
+
+	nodes, err := a.api.GetClusterStatus(ctx)
+	if err != nil {
+		return nil, a.errorStatus(err, "failed to get cluster status")
+	}
+
+	response := &kiorapb.GetClusterStatusResponse{Nodes: make([]*structpb.Struct, 0, len(nodes))}
+	for _, node := range nodes {
+		pbNode, err := newNode(node)
+		if err != nil {
+			return nil, a.errorStatus(err, "failed to marshal cluster node")
+		}
+
+		response.Nodes = append(response.Nodes, pbNode)
+	}
+
+	return response, nil
+}
+
+func (a *apigrpc) WatchAlerts(req *kiorapb.WatchAlertsRequest, stream kiorapb.Kiora_WatchAlertsServer) error {
+	ctx := stream.Context()
+	if err := requirePermission(ctx, auth.PermissionReadAlerts); err != nil {
+		return err
+	}
+
+	var filter query.AlertFilter
+	if len(req.GetFilter()) > 0 {
+		var err error
+		if filter, err = query.UnmarshalAlertFilter(req.GetFilter()); err != nil || filter == nil {
+			return status.Error(codes.InvalidArgument, "invalid filter")
+		}
+	}
+
+	if req.GetQuery() != "" {
+		alertQuery, err := query.ParseAlertFilter(req.GetQuery())
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid query: %s", err.Error())
+		}
+
+		if filter == nil {
+			filter = alertQuery
+		} else {
+			filter = query.AllAlerts(filter, alertQuery)
+		}
+	}
+
+	types := []model.EventType{model.EventTypeAlert, model.EventTypeAck}
+	if req.GetAcksOnly() {
+		types = []model.EventType{model.EventTypeAck}
+	}
+
+	return a.watch(ctx, types, filter, req.GetLastEventId(), func(event *model.Event) error {
+		if event == nil {
+			return stream.Send(&kiorapb.AlertEvent{Reset_: true})
+		}
+
+		return stream.Send(&kiorapb.AlertEvent{
+			Id:              event.ID,
+			Time:            newTimestamp(event.Time),
+			Alert:           newAlert(event.Alert),
+			Acknowledgement: newAck(event.Acknowledgement),
+		})
+	})
+}
+
+func (a *apigrpc) WatchSilences(req *kiorapb.WatchSilencesRequest, stream kiorapb.Kiora_WatchSilencesServer) error {
+	ctx := stream.Context()
+	if err := requirePermission(ctx, auth.PermissionReadSilences); err != nil {
+		return err
+	}
+
+	return a.watch(ctx, []model.EventType{model.EventTypeSilence}, nil, req.GetLastEventId(), func(event *model.Event) error {
+		if event == nil {
+			return stream.Send(&kiorapb.SilenceEvent{Reset_: true})
+		}
+
+		return stream.Send(&kiorapb.SilenceEvent{
+			Id:      event.ID,
+			Time:    newTimestamp(event.Time),
+			Silence: newSilence(event.Silence),
+		})
+	})
+}
+
+// watch subscribes to the given types of events, sending each of them with the given function until the call ends. If the events after
+// lastEventID have been missed, a nil event is sent first.
+func (a *apigrpc) watch(ctx context.Context, types []model.EventType, filter query.AlertFilter, lastEventID string, send func(event *model.Event) error) error {
+	subscription, err := a.api.SubscribeEvents(ctx, types, filter, lastEventID)
+	if err != nil {
+		return a.errorStatus(err, "failed to subscribe to events")
+	}
+
+	defer subscription.Close()
+
+	if subscription.Missed {
+		if err := send(nil); err != nil {
+			return err
+		}
+	}
+
+	return a.forwardEvents(ctx, subscription, send)
+}
+
+func (a *apigrpc) forwardEvents(ctx context.Context, subscription *events.Subscription, send func(event *model.Event) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-subscription.Events():
+			if !ok {
+				// The client fell too far behind, so we end the stream. It can resume from the last event it saw.
+				return status.Error(codes.ResourceExhausted, "fell too far behind the event stream")
+			}
+
+			if err := send(&event); err != nil {
+				return fmt.Errorf("failed to send event: %w", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package apigrpc_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/internal/events"
+	"github.com/sinkingpoint/kiora/internal/server/api"
+	"github.com/sinkingpoint/kiora/internal/server/api/apigrpc"
+	"github.com/sinkingpoint/kiora/internal/services"
+	"github.com/sinkingpoint/kiora/internal/stubs"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/kiorapb"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/sinkingpoint/kiora/mocks/mock_clustering"
+	"github.com/sinkingpoint/kiora/mocks/mock_config"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newClient serves the gRPC API over the given DB and config, scoping calls to the tenant in their metadata after running them through the
+// given middleware, and returns a client connected to it.
+func newClient(t *testing.T, db kioradb.DB, conf config.Config, stream *events.Stream, middleware ...mux.MiddlewareFunc) kiorapb.KioraClient {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+
+	broadcaster := mock_clustering.NewMockBroadcaster(ctrl)
+	broadcaster.EXPECT().BroadcastSilences(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, silences ...model.Silence) error {
+		return db.StoreSilences(ctx, silences...)
+	}).AnyTimes()
+
+	middleware = append(middleware, api.TenantMiddleware(api.DefaultTenantHeader, "admin", conf))
+	unary, streamInterceptor := apigrpc.Interceptors(middleware...)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(unary), grpc.ChainStreamInterceptor(streamInterceptor))
+	apiImpl := api.NewAPIImpl(services.NewKioraBus(db, broadcaster, zerolog.New(os.Stderr), conf), nil, api.WithEventStream(stream))
+	apigrpc.Register(server, apiImpl, zerolog.New(os.Stderr))
+
+	listener := bufconn.Listen(1024 * 1024)
+	go server.Serve(listener) //nolint:errcheck // Serve returns when the server is stopped.
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufconn", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return kiorapb.NewKioraClient(conn)
+}
+
+// newTenantedConfig returns a config that accepts everything, with alerts belonging to the tenant in their `team` label.
+func newTenantedConfig(t *testing.T) config.Config {
+	t.Helper()
+	tenanter := config.NewTemplateTenanter(template.Must(template.New("tenant").Parse("{{ .team }}")))
+	conf := mock_config.NewMockConfigAllowingEverything(gomock.NewController(t))
+	conf.EXPECT().Globals().Return(config.NewGlobals(config.WithTenanter(tenanter))).AnyTimes()
+
+	return conf
+}
+
+func withTenant(ctx context.Context, tenant string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, api.DefaultTenantHeader, tenant)
+}
+
+func TestGetAlerts(t *testing.T) {
+	db := kioradb.NewInMemoryDB()
+	for _, team := range []string{"foo", "bar"} {
+		alert := model.Alert{Labels: model.Labels{"alertname": team, "team": team}, Status: model.AlertStatusFiring, StartTime: stubs.Time.Now()}
+		require.NoError(t, alert.Materialise())
+		require.NoError(t, db.StoreAlerts(context.Background(), alert))
+	}
+
+	client := newClient(t, db, newTenantedConfig(t), nil)
+
+	tests := []struct {
+		name           string
+		tenant         string
+		matchers       []string
+		query          string
+		expectedCode   codes.Code
+		expectedAlerts []string
+	}{
+		{
+			name:           "tenants only see their own alerts",
+			tenant:         "foo",
+			expectedAlerts: []string{"foo"},
+		},
+		{
+			name:           "admins see every alert",
+			tenant:         "admin",
+			expectedAlerts: []string{"bar", "foo"},
+		},
+		{
+			name:           "matchers filter alerts",
+			tenant:         "admin",
+			matchers:       []string{`alertname="bar"`},
+			expectedAlerts: []string{"bar"},
+		},
+		{
+			name:           "queries filter alerts",
+			tenant:         "admin",
+			query:          `team in (foo, baz) or alertname =~ "^baz"`,
+			expectedAlerts: []string{"foo"},
+		},
+		{
+			name:         "invalid queries are rejected",
+			tenant:       "admin",
+			query:        `team in foo`,
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "invalid matchers are rejected",
+			tenant:       "admin",
+			matchers:     []string{`alertname`},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.GetAlerts(withTenant(context.Background(), tt.tenant), &kiorapb.GetAlertsRequest{Matchers: tt.matchers, Query: tt.query, Sort: []string{"alertname"}})
+			require.Equal(t, tt.expectedCode, status.Code(err))
+			if err != nil {
+				return
+			}
+
+			alertNames := []string{}
+			for _, alert := range resp.Alerts {
+				alertNames = append(alertNames, alert.Labels["alertname"])
+			}
+
+			require.Equal(t, tt.expectedAlerts, alertNames)
+		})
+	}
+}
+
+func TestPostSilence(t *testing.T) {
+	db := kioradb.NewInMemoryDB()
+	client := newClient(t, db, newTenantedConfig(t), nil)
+
+	resp, err := client.PostSilence(withTenant(context.Background(), "foo"), &kiorapb.PostSilenceRequest{Silence: &kiorapb.Silence{
+		Creator:  "foo",
+		Comment:  "bar",
+		StartsAt: timestamppb.New(stubs.Time.Now()),
+		EndsAt:   timestamppb.New(stubs.Time.Now().Add(time.Hour)),
+		Matchers: []*kiorapb.Matcher{{Label: "alertname", Value: "foo.*", IsRegex: true}},
+	}})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Silence.Id)
+	require.Equal(t, "foo", resp.Silence.Tenant)
+
+	silences, err := client.GetSilences(withTenant(context.Background(), "foo"), &kiorapb.GetSilencesRequest{})
+	require.NoError(t, err)
+	require.Len(t, silences.Silences, 1)
+	require.Equal(t, resp.Silence.Id, silences.Silences[0].Id)
+	require.True(t, silences.Silences[0].Matchers[0].IsRegex)
+
+	_, err = client.PostSilence(withTenant(context.Background(), "foo"), &kiorapb.PostSilenceRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestPostSilenceValidationError(t *testing.T) {
+	conf := mock_config.NewMockConfig(gomock.NewController(t))
+	conf.EXPECT().Globals().Return(config.NewGlobals()).AnyTimes()
+	conf.EXPECT().ValidateData(gomock.Any(), gomock.Any()).Return(&config.ValidationError{Leaf: "silences"})
+
+	db := kioradb.NewInMemoryDB()
+	client := newClient(t, db, conf, nil)
+
+	_, err := client.PostSilence(context.Background(), &kiorapb.PostSilenceRequest{Silence: &kiorapb.Silence{
+		Creator:  "foo",
+		Comment:  "bar",
+		StartsAt: timestamppb.New(stubs.Time.Now()),
+		EndsAt:   timestamppb.New(stubs.Time.Now().Add(time.Hour)),
+		Matchers: []*kiorapb.Matcher{{Label: "alertname", Value: "foo"}},
+	}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Empty(t, db.QuerySilences(context.Background(), query.NewSilenceQuery(query.MatchAll())))
+}
+
+func TestMiddlewareRejection(t *testing.T) {
+	requireAuthorization := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	client := newClient(t, kioradb.NewInMemoryDB(), newTenantedConfig(t), nil, requireAuthorization)
+
+	_, err := client.GetAlerts(context.Background(), &kiorapb.GetAlertsRequest{})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	require.Equal(t, "unauthorized", status.Convert(err).Message())
+
+	ctx := metadata.AppendToOutgoingContext(withTenant(context.Background(), "foo"), "authorization", "Bearer secret")
+	_, err = client.GetAlerts(ctx, &kiorapb.GetAlertsRequest{})
+	require.NoError(t, err)
+
+	watch, err := client.WatchSilences(context.Background(), &kiorapb.WatchSilencesRequest{})
+	require.NoError(t, err)
+	_, err = watch.Recv()
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestWatchAlerts(t *testing.T) {
+	stream := events.NewStream(events.DefaultHistorySize)
+	client := newClient(t, kioradb.NewInMemoryDB(), newTenantedConfig(t), stream)
+
+	ctx, cancel := context.WithCancel(withTenant(context.Background(), "foo"))
+	defer cancel()
+
+	// Resuming from an event that we don't have resets the client, which also tells us that the subscription has been made.
+	watch, err := client.WatchAlerts(ctx, &kiorapb.WatchAlertsRequest{
+		Query:       "status = firing",
+		LastEventId: "foo-1",
+	})
+	require.NoError(t, err)
+
+	event, err := watch.Recv()
+	require.NoError(t, err)
+	require.True(t, event.Reset_)
+
+	alert := func(team string, status model.AlertStatus) model.Event {
+		return model.Event{Type: model.EventTypeAlert, Alert: &model.Alert{Labels: model.Labels{"team": team}, Status: status}}
+	}
+
+	stream.Publish(
+		alert("foo", model.AlertStatusResolved),
+		alert("bar", model.AlertStatusFiring),
+		model.Event{Type: model.EventTypeSilence, Silence: &model.Silence{ID: "silence", Tenant: "foo"}},
+		alert("foo", model.AlertStatusFiring),
+	)
+
+	event, err = watch.Recv()
+	require.NoError(t, err)
+	require.NotEmpty(t, event.Id)
+	require.Equal(t, "foo", event.Alert.Labels["team"])
+	require.Equal(t, string(model.AlertStatusFiring), event.Alert.Status)
+
+	watch, err = client.WatchAlerts(ctx, &kiorapb.WatchAlertsRequest{Filter: map[string]string{"filter_type": "foo"}})
+	require.NoError(t, err)
+	_, err = watch.Recv()
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
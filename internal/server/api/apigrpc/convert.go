@@ -0,0 +1,209 @@
+package apigrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/sinkingpoint/kiora/internal/server/api"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/kiorapb"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newTimestamp converts a time into a timestamp, leaving zero times unset.
+func newTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
+
+// fromTimestamp converts a timestamp into a time, with unset timestamps becoming zero times.
+func fromTimestamp(t *timestamppb.Timestamp) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+
+	return t.AsTime()
+}
+
+func newAck(ack *model.AlertAcknowledgement) *kiorapb.AlertAcknowledgement {
+	if ack == nil {
+		return nil
+	}
+
+	return &kiorapb.AlertAcknowledgement{
+		Creator: ack.Creator,
+		Comment: ack.Comment,
+	}
+}
+
+func fromAck(ack *kiorapb.AlertAcknowledgement) model.AlertAcknowledgement {
+	return model.AlertAcknowledgement{
+		Creator: ack.GetCreator(),
+		Comment: ack.GetComment(),
+	}
+}
+
+func newAlert(alert *model.Alert) *kiorapb.Alert {
+	return &kiorapb.Alert{
+		Id:              alert.ID,
+		Labels:          alert.Labels,
+		Annotations:     alert.Annotations,
+		Status:          string(alert.Status),
+		Acknowledgement: newAck(alert.Acknowledgement),
+		StartsAt:        newTimestamp(alert.StartTime),
+		EndsAt:          newTimestamp(alert.EndTime),
+		TimeoutDeadline: newTimestamp(alert.TimeOutDeadline),
+	}
+}
+
+// fromAlert converts a posted alert into a model one. Like the HTTP API, only the fields that clients can set are used.
+func fromAlert(alert *kiorapb.Alert) model.Alert {
+	labels := alert.GetLabels()
+	if labels == nil {
+		labels = model.Labels{}
+	}
+
+	annotations := alert.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	return model.Alert{
+		Labels:      labels,
+		Annotations: annotations,
+		Status:      model.AlertStatus(alert.GetStatus()),
+		StartTime:   fromTimestamp(alert.GetStartsAt()),
+		EndTime:     fromTimestamp(alert.GetEndsAt()),
+	}
+}
+
+func newSilence(silence *model.Silence) *kiorapb.Silence {
+	matchers := make([]*kiorapb.Matcher, 0, len(silence.Matchers))
+	for _, m := range silence.Matchers {
+		matchers = append(matchers, &kiorapb.Matcher{
+			Label:      m.Label,
+			Value:      m.Value,
+			IsRegex:    m.IsRegex,
+			IsNegative: m.IsNegative,
+		})
+	}
+
+	return &kiorapb.Silence{
+		Id:       silence.ID,
+		Creator:  silence.Creator,
+		Comment:  silence.Comment,
+		StartsAt: newTimestamp(silence.StartTime),
+		EndsAt:   newTimestamp(silence.EndTime),
+		Matchers: matchers,
+		Tenant:   silence.Tenant,
+	}
+}
+
+// fromSilence converts a posted silence into a model one, with a new ID.
+func fromSilence(silence *kiorapb.Silence) (model.Silence, error) {
+	matchers := make([]model.Matcher, 0, len(silence.GetMatchers()))
+	for _, m := range silence.GetMatchers() {
+		matcher := model.LabelValueEqualMatcher(m.GetLabel(), m.GetValue())
+		if m.GetIsRegex() {
+			var err error
+			if matcher, err = model.LabelValueRegexMatcher(m.GetLabel(), m.GetValue()); err != nil {
+				return model.Silence{}, err
+			}
+		}
+
+		matcher.IsNegative = m.GetIsNegative()
+		matchers = append(matchers, matcher)
+	}
+
+	newSilence, err := model.NewSilence(silence.GetCreator(), silence.GetComment(), matchers, fromTimestamp(silence.GetStartsAt()), fromTimestamp(silence.GetEndsAt()))
+	if err != nil {
+		return model.Silence{}, err
+	}
+
+	newSilence.Tenant = silence.GetTenant()
+	return newSilence, nil
+}
+
+func newValidationError(err *config.ValidationError) *kiorapb.ValidationError {
+	validationErr := &kiorapb.ValidationError{
+		Message: err.Error(),
+		Leaf:    err.Leaf,
+		Paths:   make([]*kiorapb.RejectedPath, 0, len(err.Paths)),
+	}
+
+	for _, path := range err.Paths {
+		validationErr.Paths = append(validationErr.Paths, &kiorapb.RejectedPath{
+			Path:   path.Path,
+			To:     path.To,
+			Filter: path.Filter,
+			Reason: path.Reason,
+		})
+	}
+
+	return validationErr
+}
+
+func newPostAlertsResponse(result api.PostAlertsResult) *kiorapb.PostAlertsResponse {
+	response := &kiorapb.PostAlertsResponse{
+		Accepted: make([]*kiorapb.AcceptedAlert, 0, len(result.Accepted)),
+		Rejected: make([]*kiorapb.RejectedAlert, 0, len(result.Rejected)),
+	}
+
+	for _, accepted := range result.Accepted {
+		response.Accepted = append(response.Accepted, &kiorapb.AcceptedAlert{
+			Index: int32(accepted.Index),
+			Id:    accepted.Alert.ID,
+		})
+	}
+
+	for _, rejected := range result.Rejected {
+		rejectedAlert := &kiorapb.RejectedAlert{
+			Index:  int32(rejected.Index),
+			Id:     rejected.Alert.ID,
+			Reason: rejected.Err.Error(),
+		}
+
+		var validationErr *config.ValidationError
+		if errors.As(rejected.Err, &validationErr) {
+			rejectedAlert.Validation = newValidationError(validationErr)
+		}
+
+		response.Rejected = append(response.Rejected, rejectedAlert)
+	}
+
+	return response
+}
+
+func newStatsResult(result query.StatsResult) *kiorapb.StatsResult {
+	frames := make([]*kiorapb.StatsFrame, 0, len(result.Frames))
+	for _, frame := range result.Frames {
+		frames = append(frames, &kiorapb.StatsFrame{Values: frame})
+	}
+
+	return &kiorapb.StatsResult{
+		Labels: result.Labels,
+		Frames: frames,
+	}
+}
+
+// newNode converts a cluster node into a Struct. Nodes are opaque, so we convert them through their JSON representation.
+func newNode(node any) (*structpb.Struct, error) {
+	bytes, err := json.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]any{}
+	if err := json.Unmarshal(bytes, &fields); err != nil {
+		return nil, err
+	}
+
+	return structpb.NewStruct(fields)
+}
@@ -0,0 +1,126 @@
+package apigrpc
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Interceptors return interceptors that run every call through the given HTTP middleware, in order, so that calls are authenticated,
+// authorized, and scoped to tenants in exactly the same way as HTTP requests. The middleware sees a request with the call's metadata as
+// its headers, and if it rejects the request, the call fails with the equivalent status.
+func Interceptors(middleware ...mux.MiddlewareFunc) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := applyMiddleware(ctx, info.FullMethod, middleware)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := applyMiddleware(ss.Context(), info.FullMethod, middleware)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+
+	return unary, stream
+}
+
+// contextStream is a ServerStream with the context replaced.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (c *contextStream) Context() context.Context {
+	return c.ctx
+}
+
+// applyMiddleware runs a request for the given call through the given middleware, returning the context that the middleware passes on.
+func applyMiddleware(ctx context.Context, method string, middleware []mux.MiddlewareFunc) (context.Context, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, method, nil)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		// Pseudo-headers like :authority aren't real headers.
+		if strings.HasPrefix(key, ":") {
+			continue
+		}
+
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+	}
+
+	var passedOn context.Context
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		passedOn = r.Context()
+	})
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+
+	w := &responseRecorder{header: http.Header{}, code: http.StatusOK}
+	handler.ServeHTTP(w, r)
+
+	if passedOn == nil {
+		return nil, status.Error(codeFromHTTPStatus(w.code), strings.TrimSpace(w.body.String()))
+	}
+
+	return passedOn, nil
+}
+
+// responseRecorder records the response written by middleware that rejects a request.
+type responseRecorder struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	r.code = code
+}
+
+func codeFromHTTPStatus(code int) codes.Code {
+	switch code {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	default:
+		return codes.Unknown
+	}
+}
@@ -28,18 +28,62 @@ const (
 	TimedOut AlertStatus = "timed out"
 )
 
+// Defines values for AuditEntryAction.
+const (
+	AuditEntryActionAckAlert     AuditEntryAction = "ack_alert"
+	AuditEntryActionPostAlerts   AuditEntryAction = "post_alerts"
+	AuditEntryActionPostSilence  AuditEntryAction = "post_silence"
+	AuditEntryActionReloadConfig AuditEntryAction = "reload_config"
+)
+
+// Defines values for AuditEntryOutcome.
+const (
+	Accepted          AuditEntryOutcome = "accepted"
+	Failed            AuditEntryOutcome = "failed"
+	PartiallyAccepted AuditEntryOutcome = "partially_accepted"
+	Rejected          AuditEntryOutcome = "rejected"
+)
+
+// Defines values for EventType.
+const (
+	EventTypeAck     EventType = "ack"
+	EventTypeAlert   EventType = "alert"
+	EventTypeSilence EventType = "silence"
+)
+
 // Defines values for GetAlertsParamsOrder.
 const (
 	GetAlertsParamsOrderASC  GetAlertsParamsOrder = "ASC"
 	GetAlertsParamsOrderDESC GetAlertsParamsOrder = "DESC"
 )
 
+// Defines values for GetConfigGraphParamsFormat.
+const (
+	Dot  GetConfigGraphParamsFormat = "dot"
+	Json GetConfigGraphParamsFormat = "json"
+)
+
+// Defines values for GetEventsParamsTypes.
+const (
+	GetEventsParamsTypesAck     GetEventsParamsTypes = "ack"
+	GetEventsParamsTypesAlert   GetEventsParamsTypes = "alert"
+	GetEventsParamsTypesSilence GetEventsParamsTypes = "silence"
+)
+
 // Defines values for GetSilencesParamsOrder.
 const (
 	GetSilencesParamsOrderASC  GetSilencesParamsOrder = "ASC"
 	GetSilencesParamsOrderDESC GetSilencesParamsOrder = "DESC"
 )
 
+// AcceptedAlert defines model for AcceptedAlert.
+type AcceptedAlert struct {
+	Id string `json:"id"`
+
+	// Index The position of the alert in the posted batch
+	Index int `json:"index"`
+}
+
 // Alert defines model for Alert.
 type Alert struct {
 	Acknowledgement *AlertAcknowledgement `json:"acknowledgement,omitempty"`
@@ -62,6 +106,104 @@ type AlertAcknowledgement struct {
 	Creator string  `json:"creator"`
 }
 
+// AlertList defines model for AlertList.
+type AlertList struct {
+	Items []Alert `json:"items"`
+
+	// NextCursor The cursor to get the next page with. Only set if there are more results
+	NextCursor *string `json:"nextCursor,omitempty"`
+
+	// Total The number of alerts that match the query, across every page
+	Total int `json:"total"`
+}
+
+// AlertRoute defines model for AlertRoute.
+type AlertRoute struct {
+	GroupLabels []string `json:"groupLabels"`
+
+	// GroupWait The group wait, as a Go duration string
+	GroupWait string `json:"groupWait"`
+
+	// Notifier The name of the notifier node
+	Notifier string `json:"notifier"`
+
+	// Path The nodes that the alert passed through to reach the notifier, in order
+	Path []string `json:"path"`
+}
+
+// AuditEntry defines model for AuditEntry.
+type AuditEntry struct {
+	Action AuditEntryAction `json:"action"`
+
+	// Actor The user that made the change, if the request was authenticated
+	Actor *string `json:"actor,omitempty"`
+
+	// AuthMethod How the actor was authenticated
+	AuthMethod *string `json:"authMethod,omitempty"`
+
+	// Broadcast The data that was broadcast to the cluster as a result of the change
+	Broadcast *interface{} `json:"broadcast,omitempty"`
+
+	// Errors Why (some of) the change was rejected, or failed
+	Errors *[]string `json:"errors,omitempty"`
+
+	// ForwardedFor The X-Forwarded-For header of the request, if it was made through a proxy
+	ForwardedFor *string           `json:"forwardedFor,omitempty"`
+	Id           string            `json:"id"`
+	Outcome      AuditEntryOutcome `json:"outcome"`
+
+	// Request The change that was requested, before it was validated
+	Request  *interface{} `json:"request,omitempty"`
+	SourceIP *string      `json:"sourceIP,omitempty"`
+	Tenant   *string      `json:"tenant,omitempty"`
+	Time     time.Time    `json:"time"`
+}
+
+// AuditEntryAction defines model for AuditEntry.Action.
+type AuditEntryAction string
+
+// AuditEntryOutcome defines model for AuditEntry.Outcome.
+type AuditEntryOutcome string
+
+// ConfigGraph defines model for ConfigGraph.
+type ConfigGraph struct {
+	Edges []ConfigGraphEdge `json:"edges"`
+	Nodes []ConfigGraphNode `json:"nodes"`
+}
+
+// ConfigGraphEdge defines model for ConfigGraphEdge.
+type ConfigGraphEdge struct {
+	// Description A human readable description of the filter on the edge
+	Description *string `json:"description,omitempty"`
+
+	// Filter The type of the filter on the edge, if there is one
+	Filter      *string            `json:"filter,omitempty"`
+	FilterAttrs *map[string]string `json:"filterAttrs,omitempty"`
+	From        string             `json:"from"`
+	Highlighted bool               `json:"highlighted"`
+	To          string             `json:"to"`
+}
+
+// ConfigGraphNode defines model for ConfigGraphNode.
+type ConfigGraphNode struct {
+	Highlighted bool   `json:"highlighted"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+}
+
+// Event defines model for Event.
+type Event struct {
+	Acknowledgement *AlertAcknowledgement `json:"acknowledgement,omitempty"`
+	Alert           *Alert                `json:"alert,omitempty"`
+	Id              string                `json:"id"`
+	Silence         *Silence              `json:"silence,omitempty"`
+	Time            time.Time             `json:"time"`
+	Type            EventType             `json:"type"`
+}
+
+// EventType defines model for Event.Type.
+type EventType string
+
 // Matcher defines model for Matcher.
 type Matcher struct {
 	IsNegative bool   `json:"isNegative"`
@@ -70,6 +212,55 @@ type Matcher struct {
 	Value      string `json:"value"`
 }
 
+// PostAlertsResponse defines model for PostAlertsResponse.
+type PostAlertsResponse struct {
+	Accepted []AcceptedAlert `json:"accepted"`
+	Rejected []RejectedAlert `json:"rejected"`
+}
+
+// RejectedAlert defines model for RejectedAlert.
+type RejectedAlert struct {
+	Id *string `json:"id,omitempty"`
+
+	// Index The position of the alert in the posted batch
+	Index int `json:"index"`
+
+	// Reason Why the alert was rejected
+	Reason string `json:"reason"`
+
+	// Validation An explanation of why data was rejected by the config, listing every path through the config that was tried
+	Validation *ValidationError `json:"validation,omitempty"`
+}
+
+// RejectedPath defines model for RejectedPath.
+type RejectedPath struct {
+	// Filter The type of the filter that rejected the data
+	Filter *string `json:"filter,omitempty"`
+
+	// Path The nodes that the data reached before it was rejected, in order
+	Path   []string `json:"path"`
+	Reason string   `json:"reason"`
+
+	// To The node at the end of the edge whose filter rejected the data. Missing if the path had no way forward
+	To *string `json:"to,omitempty"`
+}
+
+// RouteAlertRequest defines model for RouteAlertRequest.
+type RouteAlertRequest struct {
+	Annotations *map[string]string `json:"annotations,omitempty"`
+	Labels      map[string]string  `json:"labels"`
+}
+
+// RouteAlertResponse defines model for RouteAlertResponse.
+type RouteAlertResponse struct {
+	// Annotations The annotations of the alert, after the config's transformations were applied
+	Annotations map[string]string `json:"annotations"`
+
+	// Labels The labels of the alert, after the config's transformations were applied
+	Labels map[string]string `json:"labels"`
+	Routes []AlertRoute      `json:"routes"`
+}
+
 // Silence defines model for Silence.
 type Silence struct {
 	Comment  string    `json:"comment"`
@@ -78,6 +269,20 @@ type Silence struct {
 	Id       *string   `json:"id,omitempty"`
 	Matchers []Matcher `json:"matchers"`
 	StartsAt time.Time `json:"startsAt"`
+
+	// Tenant The tenant that the silence belongs to. Only admins can set this - everyone else creates silences in their own tenant.
+	Tenant *string `json:"tenant,omitempty"`
+}
+
+// SilenceList defines model for SilenceList.
+type SilenceList struct {
+	Items []Silence `json:"items"`
+
+	// NextCursor The cursor to get the next page with. Only set if there are more results
+	NextCursor *string `json:"nextCursor,omitempty"`
+
+	// Total The number of silences that match the query, across every page
+	Total int `json:"total"`
 }
 
 // StatsResult defines model for StatsResult.
@@ -86,6 +291,14 @@ type StatsResult struct {
 	Labels map[string]string `json:"labels"`
 }
 
+// ValidationError An explanation of why data was rejected by the config, listing every path through the config that was tried
+type ValidationError struct {
+	// Leaf The pseudo-node that the data needed to find a path into
+	Leaf    string         `json:"leaf"`
+	Message string         `json:"message"`
+	Paths   []RejectedPath `json:"paths"`
+}
+
 // AcknowledgeAlert defines model for AcknowledgeAlert.
 type AcknowledgeAlert = AlertAcknowledgement
 
@@ -95,6 +308,9 @@ type PostAlerts = []Alert
 // PostSilence defines model for PostSilence.
 type PostSilence = Silence
 
+// RouteAlert defines model for RouteAlert.
+type RouteAlert = RouteAlertRequest
+
 // GetAlertsParams defines parameters for GetAlerts.
 type GetAlertsParams struct {
 	// Limit The maximum number of results to return
@@ -111,6 +327,15 @@ type GetAlertsParams struct {
 
 	// Matchers The matchers used to filter the returned list. Only alerts that match all the matchers will be returned.
 	Matchers *[]string `form:"matchers,omitempty" json:"matchers,omitempty"`
+
+	// Query An alert query (e.g. `severity="critical" and status in (firing, acked) and __starts_at__ > now-1h`). Only alerts that match it, and the matchers, will be returned.
+	Query *string `form:"query,omitempty" json:"query,omitempty"`
+
+	// Cursor Return the results after the last result of a previous page, using the `nextCursor` from that page. Unlike `offset`, results don't shift between pages as alerts are added or removed. The sort must be the same as the previous page
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// IfNoneMatch The ETag of a previous response. If the response would be the same, a 304 is returned without a body
+	IfNoneMatch *string `json:"If-None-Match,omitempty"`
 }
 
 // GetAlertsParamsOrder defines parameters for GetAlerts.
@@ -127,6 +352,51 @@ type GetAlertsStatsParams struct {
 	Args *map[string]string `form:"args,omitempty" json:"args,omitempty"`
 }
 
+// GetAuditParams defines parameters for GetAudit.
+type GetAuditParams struct {
+	// From Only return changes made at or after this time
+	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
+
+	// To Only return changes made before this time
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+
+	// Actor Only return changes made by this user
+	Actor *string `form:"actor,omitempty" json:"actor,omitempty"`
+
+	// Limit The maximum number of results to return
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetConfigGraphParams defines parameters for GetConfigGraph.
+type GetConfigGraphParams struct {
+	// Format The format to return the graph in
+	Format *GetConfigGraphParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+
+	// Labels The labels of an alert to highlight the routes of, in the form `name=value`
+	Labels *[]string `form:"labels,omitempty" json:"labels,omitempty"`
+}
+
+// GetConfigGraphParamsFormat defines parameters for GetConfigGraph.
+type GetConfigGraphParamsFormat string
+
+// GetEventsParams defines parameters for GetEvents.
+type GetEventsParams struct {
+	// Types The types of events to stream. Defaults to all of them
+	Types *[]GetEventsParamsTypes `form:"types,omitempty" json:"types,omitempty"`
+
+	// Filter An alert filter (e.g. `filter[filter_type]=status&filter[status]=firing`). Alert and ack events are only sent if their alert matches it.
+	Filter *map[string]string `json:"filter,omitempty"`
+
+	// Query An alert query (e.g. `severity="critical" and status in (firing, acked)`). Alert and ack events are only sent if their alert matches it, and the filter.
+	Query *string `form:"query,omitempty" json:"query,omitempty"`
+
+	// LastEventID Resume the stream after this event. The Last-Event-ID header takes precedence over this
+	LastEventID *string `form:"lastEventID,omitempty" json:"lastEventID,omitempty"`
+}
+
+// GetEventsParamsTypes defines parameters for GetEvents.
+type GetEventsParamsTypes string
+
 // GetSilencesParams defines parameters for GetSilences.
 type GetSilencesParams struct {
 	// Limit The maximum number of results to return
@@ -145,6 +415,12 @@ type GetSilencesParams struct {
 	// Note that matchers are applied literally to silences, e.g. sending `instance=~foo` will _only_ return silences
 	// which contain the matcher `instance=~foo`
 	Matchers *[]string `form:"matchers,omitempty" json:"matchers,omitempty"`
+
+	// Cursor Return the results after the last result of a previous page, using the `nextCursor` from that page. Unlike `offset`, results don't shift between pages as silences are added or removed. The sort must be the same as the previous page
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// IfNoneMatch The ETag of a previous response. If the response would be the same, a 304 is returned without a body
+	IfNoneMatch *string `json:"If-None-Match,omitempty"`
 }
 
 // GetSilencesParamsOrder defines parameters for GetSilences.
@@ -156,6 +432,9 @@ type PostAlertsJSONRequestBody = PostAlertsJSONBody
 // PostAlertsAckJSONRequestBody defines body for PostAlertsAck for application/json ContentType.
 type PostAlertsAckJSONRequestBody = AlertAcknowledgement
 
+// PostConfigRouteJSONRequestBody defines body for PostConfigRoute for application/json ContentType.
+type PostConfigRouteJSONRequestBody = RouteAlertRequest
+
 // PostSilencesJSONRequestBody defines body for PostSilences for application/json ContentType.
 type PostSilencesJSONRequestBody = Silence
 
@@ -173,6 +452,21 @@ type ServerInterface interface {
 	// Query aggregated stats about alerts in the system
 	// (GET /alerts/stats)
 	GetAlertsStats(w http.ResponseWriter, r *http.Request, params GetAlertsStatsParams)
+	// Get the audit log
+	// (GET /audit)
+	GetAudit(w http.ResponseWriter, r *http.Request, params GetAuditParams)
+	// Get the loaded config graph
+	// (GET /config/graph)
+	GetConfigGraph(w http.ResponseWriter, r *http.Request, params GetConfigGraphParams)
+	// Reload the config from disk
+	// (POST /config/reload)
+	PostConfigReload(w http.ResponseWriter, r *http.Request)
+	// Show which notifiers an alert would be sent to
+	// (POST /config/route)
+	PostConfigRoute(w http.ResponseWriter, r *http.Request)
+	// Stream changes to alerts, silences, and acknowledgements
+	// (GET /events)
+	GetEvents(w http.ResponseWriter, r *http.Request, params GetEventsParams)
 	// Get silences
 	// (GET /silences)
 	GetSilences(w http.ResponseWriter, r *http.Request, params GetSilencesParams)
@@ -239,6 +533,43 @@ func (siw *ServerInterfaceWrapper) GetAlerts(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	// ------------- Optional query parameter "query" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "query", r.URL.Query(), &params.Query)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "query", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-None-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-None-Match", runtime.ParamLocationHeader, valueList[0], &IfNoneMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-None-Match", Err: err})
+			return
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+
+	}
+
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		siw.Handler.GetAlerts(w, r, params)
 	}
@@ -323,6 +654,176 @@ func (siw *ServerInterfaceWrapper) GetAlertsStats(w http.ResponseWriter, r *http
 	handler(w, r.WithContext(ctx))
 }
 
+// GetAudit operation middleware
+func (siw *ServerInterfaceWrapper) GetAudit(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAuditParams
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", r.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "actor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "actor", r.URL.Query(), &params.Actor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "actor", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetAudit(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetConfigGraph operation middleware
+func (siw *ServerInterfaceWrapper) GetConfigGraph(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetConfigGraphParams
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", r.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "format", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "labels" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "labels", r.URL.Query(), &params.Labels)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "labels", Err: err})
+		return
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetConfigGraph(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PostConfigReload operation middleware
+func (siw *ServerInterfaceWrapper) PostConfigReload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostConfigReload(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PostConfigRoute operation middleware
+func (siw *ServerInterfaceWrapper) PostConfigRoute(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostConfigRoute(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetEvents operation middleware
+func (siw *ServerInterfaceWrapper) GetEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetEventsParams
+
+	// ------------- Optional query parameter "types" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "types", r.URL.Query(), &params.Types)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "types", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "filter" -------------
+
+	err = runtime.BindQueryParameter("deepObject", true, false, "filter", r.URL.Query(), &params.Filter)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "filter", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "query" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "query", r.URL.Query(), &params.Query)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "query", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "lastEventID" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "lastEventID", r.URL.Query(), &params.LastEventID)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "lastEventID", Err: err})
+		return
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetEvents(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
 // GetSilences operation middleware
 func (siw *ServerInterfaceWrapper) GetSilences(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -372,6 +873,35 @@ func (siw *ServerInterfaceWrapper) GetSilences(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-None-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-None-Match", runtime.ParamLocationHeader, valueList[0], &IfNoneMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-None-Match", Err: err})
+			return
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+
+	}
+
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		siw.Handler.GetSilences(w, r, params)
 	}
@@ -519,6 +1049,16 @@ func HandlerWithOptions(si ServerInterface, options GorillaServerOptions) http.H
 
 	r.HandleFunc(options.BaseURL+"/alerts/stats", wrapper.GetAlertsStats).Methods("GET")
 
+	r.HandleFunc(options.BaseURL+"/audit", wrapper.GetAudit).Methods("GET")
+
+	r.HandleFunc(options.BaseURL+"/config/graph", wrapper.GetConfigGraph).Methods("GET")
+
+	r.HandleFunc(options.BaseURL+"/config/reload", wrapper.PostConfigReload).Methods("POST")
+
+	r.HandleFunc(options.BaseURL+"/config/route", wrapper.PostConfigRoute).Methods("POST")
+
+	r.HandleFunc(options.BaseURL+"/events", wrapper.GetEvents).Methods("GET")
+
 	r.HandleFunc(options.BaseURL+"/silences", wrapper.GetSilences).Methods("GET")
 
 	r.HandleFunc(options.BaseURL+"/silences", wrapper.PostSilences).Methods("POST")
@@ -529,31 +1069,75 @@ func HandlerWithOptions(si ServerInterface, options GorillaServerOptions) http.H
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xYXY/bthL9KwTvBe6LrneTe/tioA9OtggWRdI0TvuSDbJjcWQzS5EKSa1jBO5vL4ai",
-	"vlZU9iNNigJ5syVy5syZ4eGMPvHclJXRqL3jy0/c4ocanX9ihMTwYJVfabNXKLa4Umg9PcuN9qjDT6gq",
-	"JXPw0uiT985oeubyHZZAv/5tseBL/q+T3slJ89adBGsD6yVZPB6PGRfocisrssmX/Dl6EOCB7XeoGXQb",
-	"pN4y0AwCqGPGXxrng013L4jSY+nuhJWc+EOFfMnBWjikwDYAmDcMhMiYsayuBHhkUjO/Q+YOzmO5aPGu",
-	"pUKd41/GaWsvhYy55mUEx2lJ3Bfy3Ca3sqZC62P64UaCHpTUjIPWxoeIGqtCSPoD6uXIW6TXeSv1dsC3",
-	"2bzHPBhCLdwqwCiMLcHzJSd+/+tliTybGpCiqWoQv2h14Etva0wsU7BB9YXQnAfr7wXOefB1sI66Lvny",
-	"DS9keJcR7yh4xi06o67DT7IimKk9z3jMpeBvE2Zpoan9GYJQUuMsnltYOWZBDaRFQdAkYYg8jRPaBTLg",
-	"YIribYKyZLlMa5BWnZ9Ns5DxvZUe+xiOGclZa2VCTG4RvLGJdzdibT32W3rDqTieg893aKfQpXuBW/Dy",
-	"GgdON8YoBB3K073CLX5MvwxkJwO5BlXj7WE0BtrlvbdsiCsVz0CXxvE8jN2vdmzLhnd3ZyFvEzWR8vsf",
-	"39TxmFbL6EhEFga4k+R78O4VulolzkJhocRxvN2PDnWhDPgesa7LTTrmm/+/XAZTFUjCEHFP46UdUhcm",
-	"GJde0bvfHzFTsJ+lsfAfx1Yvz6mG0brmJnu0OF2ckmtToYZK8iX/X3iU8Qr8LsA8ga4T2GKgcXwbvoYr",
-	"dNQ+mKqJkhVSebQZU7KUnm5ugRRixkALVkhUwoWfFn1ttWv6Dsc24EiU6XY3Di80D7BsUMZzwZf8GbZd",
-	"CcEjFnwo2DcTSDtkJXyUZV2yJmNEgg11EDqKxjMdXlr+oUZ74BnXUBJlATbPBn1C5Flqj1vK/jFLeTRF",
-	"4dAzqb0JDcrE34L9RhEWxrIKtlKHyGZANMYegCLy6w1zxvoRkM1hxhmtHLnqzsFcjbZdW5oISjgxPnC+",
-	"YKQ+7BqUFEwW7JJ8XjJJ2XeGuQpzWUgUizk+yOYIY3vPr9ZPecbPflo/TdzgaYCtZrCa8uFNrNiIl1KF",
-	"ginpfEQdC9TvwDd7GSgVVneW9lIptum3z8XRydWD6H4bupjKaNdIyOPT07+xQ39mfKSGVv+/wTJecq6b",
-	"jAcW2ODQHjP+Q2rDE8ivaBw5e8IKkApjc12XJdhDowFtOgR6kCqYqowL0Y/1YjDGZINp7DBHwGhgOxns",
-	"Pk5ofzxFHucVyHOsfDzmrfT340vMa5Ks1oKlGSfwNkvTGrUgmqgGoRuU6F+uake1nGRvNZ6k2uQds1bl",
-	"TyC/CjflLYSu8quHcDqZgKfMPkrcMG2QbA+OuTpH54pa0cHs7YlZWtemRNaMveBYKZ0j5gpryoa+zkY7",
-	"YaUL0xoQOTjf0T7aN8N3j28wYA/opm5/eLXOXHihj5neeimBCYd22Dk0/d7kGvm8QoLd1hRWV1bBS8YE",
-	"VrHyjO6fM7I5p3hgt2O1u3M/hB+hrFQzdAWBftct6WekMPW1w16iifomkjnsM+8gnOtBCRNbEgVrKmGu",
-	"hsc5qay5lgIF22OvFUFwximZLWYyV2uBVh0ol3Q4qPuKRdx8HgpmwpehXiX6yv41OIHt1tLw0+JnsDF1",
-	"J9CjDzVN2cdh+7Mlv27XfO/yvnd537DLa2vzfn3ehX5hPA720Cq6woOsBA8eLdBRp2xFHxnDxXbBXBTT",
-	"S6mdB53jj38Uxlw2Xt4ZrQ7voqdu54Xe72S+YyRgEE9Y9HvTzIX+5zah3efX29X0VTdGNrnqNGbaOfav",
-	"PtczDgToQV3jAHuqufnan6df9xyEjid8SZm0JtFAr+7H458BAAD//zY+uAXBGAAA",
+	"H4sIAAAAAAAC/+w8a2/ctpZ/hdAu0BaQHfex+8FAPrhJbte7TZqNe+9doC48HPHMiNcSqUtSM5ktvL99",
+	"cQ5JPUaUZ/xIei/QL8FYIg8Pz/ul/JYVum60AuVsdv5bZuDvLVj3vRYS6MFFcav0tgKxhosKjMNnhVYO",
+	"FP3kTVPJgjup1Yu/Wa3wmS1KqDn++lcDq+w8+5cX/SEv/Fv7gqANoNcI8e7uLs8E2MLIBmFm59lbcFxw",
+	"x9m2BMV4t0GqNeOKcULqLs/ea+sIpn0QitJBbY/CFQ9xuway84wbw3cpZD0CzGnGhciZNqxtBHfApGKu",
+	"BGZ31kF9GvG9khWoAp6NphFeCjNm/cuAHKLwQbfumbnag/zgJSmJS2AbomJwA3Ol0e26JBoVWq3kOsNt",
+	"AaqXwwIaB6JDtzG6AeOCmEqB/wb2WGekWuMNpRLwEd+MEfi5BNZoK/FPpld0rMco8KnR1oFgS+6KMuvY",
+	"LpWDNRhCDRVFGhDZ+S/hlByR+LVbrJd/g4KEZgZlvif7j9KXPONKaUes8lCFoGvx6v3otAlpJmiCEvaC",
+	"0FhpU3OXnWcouidO1pDlUwCe5Aa4+ElVu+zcmRYSyyq+hOqJqFnHjXsQctZx1xJ0UG2NTFpJepcj3UFk",
+	"yEGrqw39RCiC6dZleRbUZMjKAWayBt2618BFJRXM4nOAKvvyI7KOTmOGdhcZ0GCKxazQXUxFbE8GcdXl",
+	"6ykX8mxrpIP+Dnc5eooIZUKYwgB32iTe7d01nthv6QHP3uNHaVM6H033U2x4nin46F61xmqTNhQFvUNb",
+	"tQZH1gF3sIavgW2lK08ZUolZcEySKTHAuAFWawPMgG0rZ1Mi6rTjVfpI1dZLMGiZeHAoJXesRmtECPy9",
+	"BbPLGS+MtpbBBsyO8DnCVBGp4uGzBCczPqX42ui2+bFT6I7ucxrc0Zg2/pVLl74vvWZbLl3OuGWc/aCZ",
+	"aA0pAeskcnKK0k6uJMzwTfEaonGPK5nSImkvGu7KGTBaQGBA7yYabi2I3m1pZoAX5eisHJ2JNgJMlh9N",
+	"qj12dTfMR7QfEjTgnmRlK6R7o5zZpbyPv2NvINHn3Xh5y3L/V7CF3mj6d2TcKs3FTfDTKTPJCzenTa0F",
+	"E8VZgHf4JVdryIP2sBCCsi1KQutKUA5jEW+oJye1rnwLrtRietx/6K1nGWJzHLil0VwU3M7IKcWhhDxC",
+	"6xYj/+kiVWsdGC/CXvOjAPo74hFgjDZ2Cv+v5Y59aTXJ7FeDPXSUAeQp+JByxWUF4gFClaOT2nIjQPxp",
+	"ji//c/KnuAZ/sRK48CZowBPikfS3D+zzCsBZY/TH3XyYMHmsW1foGoYCyEOIRyJtnORVtbsZPIxEyPIs",
+	"kCAlewHVGVvuSdqxMCxGwi5hpQ3E2214JQVJCQYTujUFXL5PkxoUn3GKFAwcGbKkQoKwMqhqT7OUrr8i",
+	"bfzB8KacKjuINRzvKQeg3ggvtBOfiUbxMQDfaQFHWD2Enge0D9yWUJzceMT63ya5UNnWXDEDXPBlBWzw",
+	"Okr8SlYO5d/nA4hJSrj9qrSs4eJ5aHkfLEjLtLoH/IVz5okB9MroOrmylOuykuvSwVBNl1pXwBWB0odj",
+	"OoJOS8cAD3COZGHCuYMYoV+/59aHkKXtYfFhdN9s0nHzM+VuMTM8KnCdsaS2LyIcVRt4mF3qydqZ6RAG",
+	"jIKDhClOWjRPdToqRe63GOKCmRJc2new5k5uIC0U0n6ANXxMv6TUKkm7Da/aI2TGA4jL+9PyIV6p+/RF",
+	"qQ9gG60spGQpeLijM5lRKSRhnTtHeSzED2HDDMT9FC7hklOXH0P9nUs2lJNbrdKBVw9tGG2ltCEEBsGv",
+	"3EfUv3Qr3xij56tGAa/7KPg+ZCdjAj7Q+VDQE6/GXAhnn5QMIQCf+YDYC6D6iPURWdCQWdOleh43FhAD",
+	"JeLt0d6ybaltR4gJDU7ZW2ktFpRDCoIEYCUXTGm25TsWwueDURvuO8DRSX10ag+eq6D39Mpbygzag/ea",
+	"NXSPvNiU1QNII1uQM77yoh7LyF9Y5gxX1rs62rClAg1WuIca/iSyTTH0QJ4fOSqXP7DoRbw5aNVn6o/h",
+	"wBTPB92LMaMfVyj8ZBXo2gcVx1MtRiEJu/TwSnSfHyasNL3r7Wls0Syh0mptmdOhuMhFLZVlBVdUZ3Sl",
+	"tOzEF/60AgaVBUaUBRuB2OAYpWF6q8JRp8clntPS7Kj+HPg0oOw94vEctdth7PrPVL3tWPEZ67dXjlO0",
+	"2VYJsq8Mr+cY0InzqtLc9aj466Rov//3p3I4ecQ7dd/9MGua7SsGH5uKKx5jx22586HLMFBhy93ANOes",
+	"ktZhSBC548pEl7KvJDnjLfaY3hXw1Uwsa6EV+oSClnE8pQAECJTYlVSCcX+2VJReT40bWMvX6YQYN9oH",
+	"JwEUbB7yFvHY3F8xnjVl0B2F9CtfRZCuwnd/+Rq58F9SG/6FZRfvLzG1AmM9db4+PTs9QwR0A4o3MjvP",
+	"vqVHgwu94F2nfw0p48pvwTKumG68GIbYD9laS5f7iFSqdc64EmwloRKWfhpwrVE2Nl6W3ILwdRtt4Zqq",
+	"cA34zsSlyM6zHyBOHSB6KKaOXM0vKabX/KOs23pgIYKF8R0EPDlDcmXnGZmILFY7MkI7ywdd+KmhSJ2o",
+	"VysybSqUqCfnnbI/4w1X2qAFkl5JZpDwwB6BRaCv08xq40aILHczh+HK0VHHt1CShDCjcjYdHmw/pXRo",
+	"/Rd45oJJ5L7VzDZQYPdFnM7RI6Q1PY6xRnJx9SrLs9dvrl4lCyNp2fC+lLU2an8Vw0XPKhBklGJIMOkM",
+	"8qqi1R2krawqtuy3z90jbngmcnfjHXQM+xJO16dsYdGQSrd7eZ0VRmIPprrOSOV8ixvDlS99kx494y2I",
+	"r+jtzY2PPG64u7lh1+3Z2bfAlN6efF0uvpqlBXUSlRjRIz+aIPHPiZzPs/ADgRyJdh/uV9y6QUOIs8bA",
+	"RurWktfPWUvJJ65c9PHMgmFd1V8Kl52yP6tK3gJbeD1c5N1JQqsvHLOlXDm2BLcFULTFUivKE4cbYFyg",
+	"Z9GGGaj1BsQpQ8kjnaxbi3sJCctrwJ34e4TpDLl8jPUweuHBb37m6z16mJA9nrLLTlXpAdvqthJDDHPG",
+	"2bdn36G6dvqBEZ1uHeNsqUVnWXwvq8f3cnXyTis4eRtqRfNo/5pnEQHShW/Ozp53AI6i48SI1A/asa4X",
+	"6/EnBJBkyYYGt+XAuhHGORlcUAJVa3RnfFGUUNyGfl7JbWiNiXvJgXh+e/ZdOqIJclbyDaA0BnjMSho6",
+	"G7FSOh9EkQDsI4cm5ruzs+khl8pbam9WBs72Ls/+LbXhe17comK9/j72TPECtq1rbnbed0esBTguKwLV",
+	"aJ+wjP38YLwwH0xJ7ua4PBqkfDHYfTcRqW+eTaQS5eaEbF1pVB5t0GHkoxJFrD+EAi9FBTGU76cZpVqf",
+	"souByfXEZX1plHED1wqdFaDwCbmRosV2bs96XyPDnzt/aIzDr9VAAD4jVS6qakgIboBJL3Cz8nUFSkTD",
+	"zbvJz+EkQFLsLsajoTzIxV0ew9oX2FA5/+2gJF4Ut48RxslI71Qkv75HxSndsW0B1q5a5OmgGSaem3fT",
+	"InpSnPt0rg6V3OA7YYBdDYoi//0S9Tjzm7cmcdyjY/kI8gyvuzUwmFYesNo6PspjZrILyuqnKUbKG4ce",
+	"W5+t+bLYA30zN+sWr9WJdKhZCGiC1GvVP6d+w1w0xc16HFoeXR2Aj7xuKuibHTfdkn4qEn+cx/HOREnh",
+	"qT78uArVoOpyxJz41UB9kFoSfBhsZ53fmCeN0RspQHjTGewUmesxS2aFGcG1SoCpdshLVB9MdaMpp1l7",
+	"AoNvBxaql+z/pkP4em1gzV3En/ElxV+0Yzz1HsQeZ9Jm8/YPIfvux5/2Bo3wxcX7y5wp2IJ1bCUNZkSh",
+	"mRL8ETcUgdhCNz6VIm3FEmqsuGpf84PpQTNF06la0j0O5PyUnPjgdHwKd+R/Q4IgLQvV45T6hOmKXiCP",
+	"Gyc6GpfQtzuEhtOfFImdR6C1vlGYMiOFe0yS8UlrLp/FtgzGOI8wLT8PJDroH+kcq/T6kaH1XDQQwTJp",
+	"Ud9A4TCVj20Kjk+WEK1bIvLewwyNg/fAL9Zxiu2gjfD9aEz0aVasy4BapdBweXiUS4aGHDfA1nIDKk/v",
+	"V13/N9qSWMwIkau2XXPPZ6SO3/qOwWCUKGkxhhN6R9QKvYL1QkqIEWWYnJNYvydZkSLZyzOh3dEVqb6H",
+	"yQcf7HTX9JSm9iDTqzwKG+LAFojQSxqXWcxpV6ztP6Lk9CnT8iGbUCgdfHQvNkqcEu038n/HoCYpclIf",
+	"Cabn3vPqYIDcqZtfsQThh5DX3TWmuodD3CD2cBtooR/zHuYh4+N/1DzUzOOwrB01UOyWNw0NVdCMDlUa",
+	"vgiTtV15R8E2YiBtDGS8buqqQ+4WoLFRq7FoRSOT5EoqwqI/N6pw31gxUIDcBORCJjRVUMyqPOs/+Hun",
+	"RWyWAz6h8DS9N+qauTHSEWEo7dgQypF8748e8drfZUgeyouEtLdjXnffeyRZfYHaBPb+0YUYZ4VZB198",
+	"VWzLq9sRg2Jja/itRDC20gWjugRmQTlqgPeJp3cz1mkDgWJKu5JEzEZQ4l7W0jUfkTIPvpS8+4S2JzFF",
+	"M2NRgt3d+yyl90j35xGBmr30ocfGvUGaOtZOBOqq1Fu2LWVRDnjX+8g95nkZg038ujjpz6+cAV7bLmjh",
+	"ll2B2YA5uUIoNIFrc0bf15AT5ootpFiQAHD2n1c/vfOLcKd0Nox0vaqkT1+DDdBKQRHcOGeLH7l1J7Tt",
+	"5PL14lqF7x2+DCnUAkv29Pry9aI3xl9R8FhDF7pzx+h6nUGjv2LtX1IuwpRmOM8BhvENlxVGSTniYADL",
+	"+H4Lk/ZaEdkopem7FwXdg9mSaGtgBXGKoGiNoVeOO3hCDjSOFsepT7rj6XlyTBSD7pFCiEAXp5klfp+y",
+	"17DiMQ7H1pWP3eq59AMBpaOFhw4mP6CDFXpwoYXl//plUIr49aUvQGBb6pt/D+/9o19f+pIENqlIpb28",
+	"FrediBiIPFBx+ESacLDvWaE8oz3D+QUalvelnGToR2f/fmUWXLNDGJkAaH6KTz9Zb/CpZO0VzF/5WVuB",
+	"ZCIQtpf1YaIfrAXqxsgExQ+uHE0uNAYKECjFTG/C1tk4ujNUT2xlUaRL+J14vI93XITAzH874EnQmYDn",
+	"i3/fdAz3YQGeE6u50jJLTmTff3lsoslzOpSq8m5WK48CNfxYI5Tn45r76rVXcc0f8yB/zIN8xnmQ1Kzh",
+	"4YmQa/VOOxjsAeNNaJhBZpV0YKh153R3Rs7IbtvQCVhIZR1XBbz8v5XWC3/KDRrhm3BSt/Na+eARzQ4P",
+	"FYNw7j6Ya/V5xlX+cSc3Opb+MbvxDza7MZxtTni9YYnS9v7gn2OOo5O632OSY1Kp6sh372TGwOk+ajZj",
+	"MGCe6oR/6v+caUB2KgL5Uf7fo5m+j8lcj3wcVIUNXZ/w7u7/BwDbiIvhWEwAAA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
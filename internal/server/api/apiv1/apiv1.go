@@ -2,12 +2,17 @@ package apiv1
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/internal/audit"
+	"github.com/sinkingpoint/kiora/internal/auth"
 	"github.com/sinkingpoint/kiora/internal/server/api"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
 	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
 	"github.com/sinkingpoint/kiora/lib/kiora/model"
 
@@ -20,6 +25,7 @@ import (
 const (
 	CONTENT_TYPE_JSON  = "application/json"
 	CONTENT_TYPE_PROTO = "application/vnd.google.protobuf"
+	CONTENT_TYPE_DOT   = "text/vnd.graphviz"
 )
 
 func Register(router *mux.Router, api api.API, logger zerolog.Logger) {
@@ -37,15 +43,20 @@ func Register(router *mux.Router, api api.API, logger zerolog.Logger) {
 
 	subRouter := router.PathPrefix("/api/v1").Subrouter()
 
-	subRouter.Path("/alerts").Methods(http.MethodPost).Handler(otelhttp.NewHandler(http.HandlerFunc(apiv1.PostAlerts), "POST api/v1/alerts"))
-	subRouter.Path("/alerts").Methods(http.MethodGet).Handler(otelhttp.NewHandler(http.HandlerFunc(apiv1.GetAlerts), "GET /api/v1/alerts"))
-	subRouter.Path("/alerts/stats").Methods(http.MethodGet).Handler(otelhttp.NewHandler(http.HandlerFunc(apiv1.GetAlertsStats), "GET /api/v1/alerts/stats"))
-	subRouter.Path("/alerts/ack").Methods(http.MethodPost).Handler(otelhttp.NewHandler(http.HandlerFunc(apiv1.PostAlertsAck), "POST /api/v1/alerts/ack"))
-	subRouter.Path("/silences").Methods(http.MethodPost).Handler(otelhttp.NewHandler(http.HandlerFunc(apiv1.PostSilences), "POST /api/v1/silences"))
-	subRouter.Path("/silences").Methods(http.MethodGet).Handler(otelhttp.NewHandler(http.HandlerFunc(apiv1.GetSilences), "GET /api/v1/silences"))
+	subRouter.Path("/alerts").Methods(http.MethodPost).Handler(otelhttp.NewHandler(auth.RequirePermission(auth.PermissionWriteAlerts, http.HandlerFunc(apiv1.PostAlerts)), "POST api/v1/alerts"))
+	subRouter.Path("/alerts").Methods(http.MethodGet).Handler(otelhttp.NewHandler(auth.RequirePermission(auth.PermissionReadAlerts, http.HandlerFunc(apiv1.GetAlerts)), "GET /api/v1/alerts"))
+	subRouter.Path("/alerts/stats").Methods(http.MethodGet).Handler(otelhttp.NewHandler(auth.RequirePermission(auth.PermissionReadAlerts, http.HandlerFunc(apiv1.GetAlertsStats)), "GET /api/v1/alerts/stats"))
+	subRouter.Path("/alerts/ack").Methods(http.MethodPost).Handler(otelhttp.NewHandler(auth.RequirePermission(auth.PermissionWriteAcks, http.HandlerFunc(apiv1.PostAlertsAck)), "POST /api/v1/alerts/ack"))
+	subRouter.Path("/silences").Methods(http.MethodPost).Handler(otelhttp.NewHandler(auth.RequirePermission(auth.PermissionWriteSilences, http.HandlerFunc(apiv1.PostSilences)), "POST /api/v1/silences"))
+	subRouter.Path("/silences").Methods(http.MethodGet).Handler(otelhttp.NewHandler(auth.RequirePermission(auth.PermissionReadSilences, http.HandlerFunc(apiv1.GetSilences)), "GET /api/v1/silences"))
+	subRouter.Path("/config/route").Methods(http.MethodPost).Handler(otelhttp.NewHandler(auth.RequirePermission(auth.PermissionReadConfig, http.HandlerFunc(apiv1.PostConfigRoute)), "POST /api/v1/config/route"))
+	subRouter.Path("/config/graph").Methods(http.MethodGet).Handler(otelhttp.NewHandler(auth.RequirePermission(auth.PermissionReadConfig, http.HandlerFunc(apiv1.GetConfigGraph)), "GET /api/v1/config/graph"))
+	subRouter.Path("/config/reload").Methods(http.MethodPost).Handler(otelhttp.NewHandler(auth.RequirePermission(auth.PermissionReloadConfig, http.HandlerFunc(apiv1.PostConfigReload)), "POST /api/v1/config/reload"))
+	subRouter.Path("/events").Methods(http.MethodGet).Handler(otelhttp.NewHandler(auth.RequirePermission(auth.PermissionReadAlerts, http.HandlerFunc(apiv1.GetEvents)), "GET /api/v1/events"))
+	subRouter.Path("/audit").Methods(http.MethodGet).Handler(otelhttp.NewHandler(auth.RequirePermission(auth.PermissionReadAudit, http.HandlerFunc(apiv1.GetAudit)), "GET /api/v1/audit"))
 
 	// This is technically not in the spec.
-	subRouter.Path("/cluster/status").Methods(http.MethodGet).Handler(otelhttp.NewHandler(http.HandlerFunc(baseAPI.getClusterStatus), "GET /api/v1/cluster/status"))
+	subRouter.Path("/cluster/status").Methods(http.MethodGet).Handler(otelhttp.NewHandler(auth.RequirePermission(auth.PermissionReadCluster, http.HandlerFunc(baseAPI.getClusterStatus)), "GET /api/v1/cluster/status"))
 }
 
 var _ = ServerInterface(&apiv1{})
@@ -109,27 +120,130 @@ func (a *apiv1) PostAlerts(w http.ResponseWriter, r *http.Request) {
 		if alert.EndsAt != nil {
 			alerts[i].EndTime = *alert.EndsAt
 		}
-
-		if err := alerts[i].Materialise(); err != nil {
-			span.RecordError(err)
-			http.Error(w, fmt.Sprintf("failed to materialise alert: %q", err.Error()), http.StatusBadRequest)
-			return
-		}
 	}
 
-	if err := a.api.PostAlerts(r.Context(), alerts); err != nil {
+	result, err := a.api.PostAlerts(r.Context(), alerts)
+	if err != nil {
 		a.logger.Debug().Err(err).Msg("failed to post alerts")
 		span.SetStatus(codes.Error, err.Error())
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusAccepted)
+	responseBytes, err := json.Marshal(NewPostAlertsResponse(result))
+	if err != nil {
+		a.logger.Debug().Err(err).Msg("failed to marshal post alerts response")
+		span.SetStatus(codes.Error, err.Error())
+		http.Error(w, "failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusAccepted
+	if len(result.Rejected) > 0 {
+		a.logger.Debug().Int("rejected", len(result.Rejected)).Int("accepted", len(result.Accepted)).Msg("rejected some posted alerts")
+		span.SetStatus(codes.Error, "rejected some posted alerts")
+
+		// We only fail the request if there was nothing in it that we could accept.
+		if len(result.Accepted) == 0 {
+			status = http.StatusBadRequest
+		}
+	}
+
+	w.Header().Set("Content-Type", CONTENT_TYPE_JSON)
+	w.WriteHeader(status)
+	w.Write(responseBytes) // nolint:errcheck
+}
+
+// NewPostAlertsResponse converts the result of posting alerts into the body returned to clients.
+func NewPostAlertsResponse(result api.PostAlertsResult) PostAlertsResponse {
+	response := PostAlertsResponse{
+		Accepted: make([]AcceptedAlert, 0, len(result.Accepted)),
+		Rejected: make([]RejectedAlert, 0, len(result.Rejected)),
+	}
+
+	for _, accepted := range result.Accepted {
+		response.Accepted = append(response.Accepted, AcceptedAlert{
+			Index: accepted.Index,
+			Id:    accepted.Alert.ID,
+		})
+	}
+
+	for _, rejected := range result.Rejected {
+		rejectedAlert := RejectedAlert{
+			Index:  rejected.Index,
+			Reason: rejected.Err.Error(),
+		}
+
+		if rejected.Alert.ID != "" {
+			id := rejected.Alert.ID
+			rejectedAlert.Id = &id
+		}
+
+		var validationErr *config.ValidationError
+		if errors.As(rejected.Err, &validationErr) {
+			explanation := NewValidationError(validationErr)
+			rejectedAlert.Validation = &explanation
+		}
+
+		response.Rejected = append(response.Rejected, rejectedAlert)
+	}
+
+	return response
+}
+
+// NewValidationError converts an explanation of why the config rejected some data into the body returned to clients.
+func NewValidationError(err *config.ValidationError) ValidationError {
+	validationErr := ValidationError{
+		Message: err.Error(),
+		Leaf:    err.Leaf,
+		Paths:   make([]RejectedPath, 0, len(err.Paths)),
+	}
+
+	for _, path := range err.Paths {
+		rejectedPath := RejectedPath{
+			Path:   path.Path,
+			Reason: path.Reason,
+		}
+
+		if path.To != "" {
+			to, filter := path.To, path.Filter
+			rejectedPath.To = &to
+			rejectedPath.Filter = &filter
+		}
+
+		validationErr.Paths = append(validationErr.Paths, rejectedPath)
+	}
+
+	return validationErr
+}
+
+// writeValidationError writes a 400 explaining why the config rejected some data, returning false
+// if the given error isn't a validation error.
+func writeValidationError(w http.ResponseWriter, err error) bool {
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+
+	responseBytes, err := json.Marshal(NewValidationError(validationErr))
+	if err != nil {
+		http.Error(w, validationErr.Error(), http.StatusBadRequest)
+		return true
+	}
+
+	w.Header().Set("Content-Type", CONTENT_TYPE_JSON)
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(responseBytes) // nolint:errcheck
+	return true
 }
 
-func constructQueryOpts(limit, offset *int, sort *[]string, order string) ([]query.QueryOption, error) {
+func constructQueryOpts(limit, offset *int, sort *[]string, order string, cursor *string) ([]query.QueryOption, error) {
 	opts := []query.QueryOption{}
 
+	if cursor != nil && *cursor != "" {
+		opts = append(opts, query.StartAfter(*cursor))
+	}
+
 	if limit != nil {
 		opts = append(opts, query.Limit(*limit))
 	}
@@ -152,7 +266,7 @@ func constructQueryOpts(limit, offset *int, sort *[]string, order string) ([]que
 	return opts, nil
 }
 
-// getAlerts returns all the alerts in the system as a JSON array.
+// GetAlerts returns a page of the alerts that match the request, along with the total number of them.
 func (a *apiv1) GetAlerts(w http.ResponseWriter, r *http.Request, params GetAlertsParams) {
 	span := trace.SpanFromContext(r.Context())
 
@@ -177,12 +291,24 @@ func (a *apiv1) GetAlerts(w http.ResponseWriter, r *http.Request, params GetAler
 		}
 	}
 
+	if params.Query != nil && *params.Query != "" {
+		alertQuery, err := query.ParseAlertFilter(*params.Query)
+		if err != nil {
+			a.logger.Debug().Err(err).Msgf("failed to parse query %q", *params.Query)
+			span.RecordError(err)
+			http.Error(w, fmt.Sprintf("invalid query: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+
+		queries = append(queries, alertQuery)
+	}
+
 	order := ""
 	if params.Order != nil {
 		order = string(*params.Order)
 	}
 
-	opts, err := constructQueryOpts(params.Limit, params.Offset, params.Sort, order)
+	opts, err := constructQueryOpts(params.Limit, params.Offset, params.Sort, order, params.Cursor)
 	if err != nil {
 		a.logger.Debug().Err(err).Msg("failed to construct query options")
 		span.SetStatus(codes.Error, err.Error())
@@ -201,11 +327,16 @@ func (a *apiv1) GetAlerts(w http.ResponseWriter, r *http.Request, params GetAler
 	if err != nil {
 		a.logger.Debug().Err(err).Msg("failed to get alerts")
 		span.SetStatus(codes.Error, err.Error())
-		http.Error(w, "failed to get alerts", http.StatusInternalServerError)
+		if errors.Is(err, query.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "failed to get alerts", http.StatusInternalServerError)
+		}
+
 		return
 	}
 
-	bytes, err := json.Marshal(alerts)
+	bytes, err := json.Marshal(newListResponse(alerts))
 	if err != nil {
 		a.logger.Debug().Err(err).Msg("failed to marshal alerts")
 		span.SetStatus(codes.Error, err.Error())
@@ -213,9 +344,7 @@ func (a *apiv1) GetAlerts(w http.ResponseWriter, r *http.Request, params GetAler
 		return
 	}
 
-	w.Header().Set("Content-Type", CONTENT_TYPE_JSON)
-	w.WriteHeader(http.StatusOK)
-	w.Write(bytes) //nolint:errcheck
+	writeJSONWithETag(w, params.IfNoneMatch, bytes)
 }
 
 func (a *apiv1) GetAlertsStats(w http.ResponseWriter, r *http.Request, params GetAlertsStatsParams) {
@@ -310,6 +439,10 @@ func (a *apiv1) PostAlertsAck(w http.ResponseWriter, r *http.Request) {
 	if err := a.api.AckAlert(r.Context(), *ack.AlertID, alertAck); err != nil {
 		a.logger.Debug().Err(err).Msg("failed to broadcast alert acknowledgment")
 		span.SetStatus(codes.Error, err.Error())
+		if writeValidationError(w, err) {
+			return
+		}
+
 		http.Error(w, fmt.Sprintf("failed to handle alert acknowledgment: %q", err.Error()), http.StatusInternalServerError)
 		return
 	}
@@ -345,9 +478,22 @@ func (a *apiv1) PostSilences(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if err := a.api.PostSilence(r.Context(), silence); err != nil {
+	if silenceBody.Tenant != nil {
+		silence.Tenant = *silenceBody.Tenant
+	}
+
+	if err := a.api.PostSilence(r.Context(), &silence); err != nil {
 		a.logger.Debug().Err(err).Msg("failed to post silence")
 		span.SetStatus(codes.Error, err.Error())
+		if writeValidationError(w, err) {
+			return
+		}
+
+		if errors.Is(err, api.ErrSilenceTenantRequired) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+
 		http.Error(w, fmt.Sprintf("failed to post silence: %q", err.Error()), http.StatusInternalServerError)
 		return
 	}
@@ -397,7 +543,7 @@ func (a *apiv1) GetSilences(w http.ResponseWriter, r *http.Request, params GetSi
 		order = string(*params.Order)
 	}
 
-	opts, err := constructQueryOpts(params.Limit, params.Offset, params.Sort, order)
+	opts, err := constructQueryOpts(params.Limit, params.Offset, params.Sort, order, params.Cursor)
 	if err != nil {
 		a.logger.Debug().Err(err).Msg("failed to construct query options")
 		span.SetStatus(codes.Error, err.Error())
@@ -405,17 +551,22 @@ func (a *apiv1) GetSilences(w http.ResponseWriter, r *http.Request, params GetSi
 		return
 	}
 
-	query := query.NewSilenceQuery(query.AllSilences(queries...), opts...)
+	q := query.NewSilenceQuery(query.AllSilences(queries...), opts...)
 
-	silences, err := a.api.GetSilences(r.Context(), query)
+	silences, err := a.api.GetSilences(r.Context(), q)
 	if err != nil {
 		a.logger.Debug().Err(err).Msg("failed to get silences")
 		span.SetStatus(codes.Error, err.Error())
-		http.Error(w, "failed to get silences", http.StatusInternalServerError)
+		if errors.Is(err, query.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "failed to get silences", http.StatusInternalServerError)
+		}
+
 		return
 	}
 
-	responseBytes, err := json.Marshal(silences)
+	responseBytes, err := json.Marshal(newListResponse(silences))
 	if err != nil {
 		a.logger.Debug().Err(err).Msg("failed to marshal silences")
 		span.SetStatus(codes.Error, err.Error())
@@ -423,7 +574,216 @@ func (a *apiv1) GetSilences(w http.ResponseWriter, r *http.Request, params GetSi
 		return
 	}
 
+	writeJSONWithETag(w, params.IfNoneMatch, responseBytes)
+}
+
+func (a *apiv1) GetConfigGraph(w http.ResponseWriter, r *http.Request, params GetConfigGraphParams) {
+	span := trace.SpanFromContext(r.Context())
+
+	var alert *model.Alert
+	if params.Labels != nil {
+		alert = &model.Alert{
+			Labels:      model.Labels{},
+			Annotations: map[string]string{},
+			Status:      model.AlertStatusFiring,
+		}
+
+		for _, label := range *params.Labels {
+			name, value, ok := strings.Cut(label, "=")
+			if !ok {
+				span.SetStatus(codes.Error, "invalid label")
+				http.Error(w, fmt.Sprintf("invalid label %q, expected name=value", label), http.StatusBadRequest)
+				return
+			}
+
+			alert.Labels[name] = value
+		}
+	}
+
+	graph, err := a.api.GetConfigGraph(r.Context(), alert)
+	if err != nil {
+		a.logger.Debug().Err(err).Msg("failed to get config graph")
+		span.SetStatus(codes.Error, err.Error())
+
+		if errors.Is(err, api.ErrConfigNotGraphable) {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+
+		return
+	}
+
+	if params.Format != nil && *params.Format == Dot {
+		w.Header().Set("Content-Type", CONTENT_TYPE_DOT)
+		w.Write([]byte(graph.DOT())) // nolint:errcheck
+		return
+	}
+
+	responseBytes, err := json.Marshal(NewConfigGraph(graph))
+	if err != nil {
+		a.logger.Debug().Err(err).Msg("failed to marshal config graph")
+		span.SetStatus(codes.Error, err.Error())
+		http.Error(w, "failed to marshal config graph", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", CONTENT_TYPE_JSON)
+	w.Write(responseBytes) // nolint:errcheck
+}
+
+// NewConfigGraph converts a config graph into its API representation.
+func NewConfigGraph(graph config.Graph) ConfigGraph {
+	response := ConfigGraph{
+		Nodes: make([]ConfigGraphNode, 0, len(graph.Nodes)),
+		Edges: make([]ConfigGraphEdge, 0, len(graph.Edges)),
+	}
+
+	for _, node := range graph.Nodes {
+		response.Nodes = append(response.Nodes, ConfigGraphNode{
+			Name:        node.Name,
+			Type:        node.Type,
+			Highlighted: node.Highlighted,
+		})
+	}
+
+	for _, edge := range graph.Edges {
+		apiEdge := ConfigGraphEdge{
+			From:        edge.From,
+			To:          edge.To,
+			Highlighted: edge.Highlighted,
+		}
+
+		if edge.Filter != "" {
+			filter, attrs, description := edge.Filter, edge.FilterAttrs, edge.FilterDescription()
+			apiEdge.Filter = &filter
+			apiEdge.FilterAttrs = &attrs
+			apiEdge.Description = &description
+		}
+
+		response.Edges = append(response.Edges, apiEdge)
+	}
+
+	return response
+}
+
+func (a *apiv1) PostConfigReload(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+
+	if err := a.api.ReloadConfig(r.Context()); err != nil {
+		a.logger.Debug().Err(err).Msg("failed to reload config")
+		span.SetStatus(codes.Error, err.Error())
+
+		if errors.Is(err, api.ErrConfigNotReloadable) {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (a *apiv1) PostConfigRoute(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	body := PostConfigRouteJSONRequestBody{}
+
+	if err := decodeFromContentType(r, &body); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		http.Error(w, fmt.Sprintf("failed to decode body: %q", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	alert := model.Alert{
+		Labels:      body.Labels,
+		Annotations: map[string]string{},
+		Status:      model.AlertStatusFiring,
+	}
+
+	if body.Annotations != nil {
+		alert.Annotations = *body.Annotations
+	}
+
+	routes, err := a.api.RouteAlert(r.Context(), &alert)
+	if err != nil {
+		a.logger.Debug().Err(err).Msg("failed to route alert")
+		span.SetStatus(codes.Error, err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := RouteAlertResponse{
+		Labels:      alert.Labels,
+		Annotations: alert.Annotations,
+		Routes:      make([]AlertRoute, 0, len(routes)),
+	}
+
+	for _, route := range routes {
+		response.Routes = append(response.Routes, AlertRoute{
+			Notifier:    string(route.Name()),
+			GroupLabels: route.GroupLabels,
+			GroupWait:   route.GroupWait.String(),
+			Path:        route.Path,
+		})
+	}
+
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		a.logger.Debug().Err(err).Msg("failed to marshal routes")
+		span.SetStatus(codes.Error, err.Error())
+		http.Error(w, "failed to marshal routes", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", CONTENT_TYPE_JSON)
+	w.Write(responseBytes) // nolint:errcheck
+}
+
+func (a *apiv1) GetAudit(w http.ResponseWriter, r *http.Request, params GetAuditParams) {
+	span := trace.SpanFromContext(r.Context())
+
+	q := query.AuditQuery{}
+	if params.From != nil {
+		q.From = *params.From
+	}
+
+	if params.To != nil {
+		q.To = *params.To
+	}
+
+	if params.Actor != nil {
+		q.Actor = *params.Actor
+	}
+
+	if params.Limit != nil {
+		q.Limit = *params.Limit
+	}
+
+	entries, err := a.api.GetAuditEntries(r.Context(), q)
+	if err != nil {
+		a.logger.Debug().Err(err).Msg("failed to get audit entries")
+		span.SetStatus(codes.Error, err.Error())
+
+		if errors.Is(err, audit.ErrNotQueryable) {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+		} else {
+			http.Error(w, "failed to get audit entries", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	responseBytes, err := json.Marshal(entries)
+	if err != nil {
+		a.logger.Debug().Err(err).Msg("failed to marshal audit entries")
+		span.SetStatus(codes.Error, err.Error())
+		http.Error(w, "failed to marshal audit entries", http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", CONTENT_TYPE_JSON)
 	w.WriteHeader(http.StatusOK)
-	w.Write(responseBytes) // nolint:errcheck // Errors writing here are not recoverable.
+	w.Write(responseBytes) // nolint:errcheck
 }
@@ -1,24 +1,35 @@
 package apiv1_test
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/internal/audit"
+	"github.com/sinkingpoint/kiora/internal/auth"
+	"github.com/sinkingpoint/kiora/internal/events"
 	"github.com/sinkingpoint/kiora/internal/server/api"
 	"github.com/sinkingpoint/kiora/internal/server/api/apiv1"
 	"github.com/sinkingpoint/kiora/internal/services"
 	"github.com/sinkingpoint/kiora/internal/stubs"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
 	"github.com/sinkingpoint/kiora/lib/kiora/kioradb"
 	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
 	"github.com/sinkingpoint/kiora/lib/kiora/model"
@@ -28,6 +39,13 @@ import (
 
 var _ kioradb.DB = &mockDB{}
 
+// list is the envelope that the alert and silence lists are returned in.
+type list[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"nextCursor"`
+}
+
 type mockDB struct {
 	alerts   []model.Alert
 	silences []model.Silence
@@ -51,7 +69,7 @@ func (m *mockDB) QueryAlertStats(ctx context.Context, query query.AlertStatsQuer
 }
 
 func (m *mockDB) BroadcastSilences(ctx context.Context, silences ...model.Silence) error {
-	return nil
+	return m.StoreSilences(ctx, silences...)
 }
 
 func (m *mockDB) QueryAlerts(ctx context.Context, query query.AlertQuery) []model.Alert {
@@ -175,6 +193,272 @@ func TestPostAlerts(t *testing.T) {
 	}
 }
 
+func TestPostAlertsPartialRejection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	db := &mockDB{}
+
+	conf := mock_config.NewMockConfig(ctrl)
+	conf.EXPECT().TransformAlert(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	conf.EXPECT().ValidateData(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data config.Fielder) error {
+		if _, err := data.Field("bad"); err == nil {
+			return errors.New("bad alert")
+		}
+
+		return nil
+	}).AnyTimes()
+
+	api := apiv1.New(
+		api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), conf), nil),
+		zerolog.New(os.Stderr),
+	)
+
+	body := []byte(`[
+	{"labels": {"alertname": "good"}, "annotations": {}, "status": "firing", "startsAt": "2022-12-13T21:55:12Z"},
+	{"labels": {"alertname": "bad", "bad": "true"}, "annotations": {}, "status": "firing", "startsAt": "2022-12-13T21:55:12Z"}
+]`)
+
+	request, err := http.NewRequest(http.MethodPost, "localhost/api/v1/alerts", bytes.NewReader(body))
+	require.NoError(t, err)
+	request.Header.Add("content-type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	api.PostAlerts(recorder, request)
+
+	response := recorder.Result()
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusAccepted, response.StatusCode, string(responseBody))
+
+	postResponse := apiv1.PostAlertsResponse{}
+	require.NoError(t, json.Unmarshal(responseBody, &postResponse))
+
+	require.Len(t, postResponse.Accepted, 1)
+	require.Equal(t, 0, postResponse.Accepted[0].Index)
+	require.NotEmpty(t, postResponse.Accepted[0].Id)
+
+	require.Len(t, postResponse.Rejected, 1)
+	require.Equal(t, 1, postResponse.Rejected[0].Index)
+	require.Contains(t, postResponse.Rejected[0].Reason, "bad alert")
+
+	// The good alert should still have made it through.
+	require.Len(t, db.alerts, 1)
+	require.Equal(t, "good", db.alerts[0].Labels["alertname"])
+}
+
+func TestPostAlertsAllRejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	db := &mockDB{}
+
+	conf := mock_config.NewMockConfig(ctrl)
+	conf.EXPECT().TransformAlert(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	conf.EXPECT().ValidateData(gomock.Any(), gomock.Any()).Return(errors.New("bad alert")).AnyTimes()
+
+	api := apiv1.New(
+		api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), conf), nil),
+		zerolog.New(os.Stderr),
+	)
+
+	body := []byte(`[{"labels": {"alertname": "bad"}, "annotations": {}, "status": "firing", "startsAt": "2022-12-13T21:55:12Z"}]`)
+	request, err := http.NewRequest(http.MethodPost, "localhost/api/v1/alerts", bytes.NewReader(body))
+	require.NoError(t, err)
+	request.Header.Add("content-type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	api.PostAlerts(recorder, request)
+
+	response := recorder.Result()
+	defer response.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	postResponse := apiv1.PostAlertsResponse{}
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&postResponse))
+	require.Empty(t, postResponse.Accepted)
+	require.Len(t, postResponse.Rejected, 1)
+	require.Empty(t, db.alerts)
+}
+
+func TestPostConfigRoute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	db := &mockDB{}
+
+	notifier := mock_config.NewMockNotifier(ctrl)
+	notifier.EXPECT().Name().Return(config.NotifierName("console")).AnyTimes()
+
+	conf := mock_config.NewMockConfig(ctrl)
+	conf.EXPECT().TransformAlert(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, alert *model.Alert) error {
+		alert.Labels["team"] = "foo"
+		return nil
+	})
+
+	conf.EXPECT().RouteAlert(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, alert *model.Alert) []config.Route {
+		require.Equal(t, "foo", alert.Labels["team"])
+		return []config.Route{{
+			NotifierSettings: config.NewNotifier(notifier).WithGroupWait(time.Minute),
+			Path:             []string{"alerts", "console"},
+		}}
+	})
+
+	api := apiv1.New(
+		api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), conf), nil),
+		zerolog.New(os.Stderr),
+	)
+
+	request, err := http.NewRequest(http.MethodPost, "localhost/api/v1/config/route", bytes.NewReader([]byte(`{"labels": {"alertname": "foo"}}`)))
+	require.NoError(t, err)
+	request.Header.Add("content-type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	api.PostConfigRoute(recorder, request)
+
+	response := recorder.Result()
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	routeResponse := apiv1.RouteAlertResponse{}
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&routeResponse))
+	require.Equal(t, map[string]string{"alertname": "foo", "team": "foo"}, routeResponse.Labels)
+	require.Equal(t, []apiv1.AlertRoute{{
+		Notifier:    "console",
+		GroupLabels: []string{"alertname"},
+		GroupWait:   "1m0s",
+		Path:        []string{"alerts", "console"},
+	}}, routeResponse.Routes)
+
+	// Routing an alert shouldn't store it.
+	require.Empty(t, db.alerts)
+}
+
+// graphConfig is a mock config that can also describe itself as a graph.
+type graphConfig struct {
+	*mock_config.MockConfig
+	graph config.Graph
+}
+
+func (g *graphConfig) Graph() config.Graph {
+	return config.Graph{
+		Nodes: append([]config.GraphNode{}, g.graph.Nodes...),
+		Edges: append([]config.GraphEdge{}, g.graph.Edges...),
+	}
+}
+
+func TestGetConfigGraph(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	db := &mockDB{}
+
+	notifier := mock_config.NewMockNotifier(ctrl)
+	notifier.EXPECT().Name().Return(config.NotifierName("console")).AnyTimes()
+
+	conf := &graphConfig{
+		MockConfig: mock_config.NewMockConfig(ctrl),
+		graph: config.Graph{
+			Nodes: []config.GraphNode{{Name: "alerts", Type: "pseudo"}, {Name: "console", Type: "stdout"}, {Name: "pager", Type: "stdout"}},
+			Edges: []config.GraphEdge{
+				{From: "alerts", To: "console"},
+				{From: "alerts", To: "pager", Filter: "regex", FilterAttrs: map[string]string{"field": "severity", "regex": "critical"}},
+			},
+		},
+	}
+
+	conf.EXPECT().TransformAlert(gomock.Any(), gomock.Any()).Return(nil)
+	conf.EXPECT().RouteAlert(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, alert *model.Alert) []config.Route {
+		require.Equal(t, model.Labels{"severity": "warning"}, alert.Labels)
+		return []config.Route{{
+			NotifierSettings: config.NewNotifier(notifier),
+			Path:             []string{"alerts", "console"},
+		}}
+	})
+
+	router := mux.NewRouter()
+	apiv1.Register(router, api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), conf), nil), zerolog.New(os.Stderr))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/config/graph?labels=severity=warning", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	graph := apiv1.ConfigGraph{}
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&graph))
+	require.Equal(t, []apiv1.ConfigGraphNode{
+		{Name: "alerts", Type: "pseudo", Highlighted: true},
+		{Name: "console", Type: "stdout", Highlighted: true},
+		{Name: "pager", Type: "stdout", Highlighted: false},
+	}, graph.Nodes)
+
+	require.Len(t, graph.Edges, 2)
+	require.True(t, graph.Edges[0].Highlighted)
+	require.Nil(t, graph.Edges[0].Description)
+	require.False(t, graph.Edges[1].Highlighted)
+	require.Equal(t, `regex(field="severity", regex="critical")`, *graph.Edges[1].Description)
+
+	// Without labels, nothing should be highlighted.
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/config/graph?format=dot", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, apiv1.CONTENT_TYPE_DOT, recorder.Header().Get("Content-Type"))
+	require.NotContains(t, recorder.Body.String(), "red")
+	require.Contains(t, recorder.Body.String(), `"alerts" -> "pager" [field="severity" regex="critical" type="regex"];`)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/config/graph?labels=severity", nil))
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestGetConfigGraphNotGraphable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	db := &mockDB{}
+
+	router := mux.NewRouter()
+	apiv1.Register(router, api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), mock_config.NewMockConfig(ctrl)), nil), zerolog.New(os.Stderr))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/config/graph", nil))
+	require.Equal(t, http.StatusNotImplemented, recorder.Code)
+}
+
+func TestPostSilenceValidationError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	db := &mockDB{}
+
+	conf := mock_config.NewMockConfig(ctrl)
+	conf.EXPECT().ValidateData(gomock.Any(), gomock.Any()).Return(&config.ValidationError{
+		Leaf: "silences",
+		Paths: []config.RejectedPath{{
+			Path:   []string{"short_silences"},
+			To:     "silences",
+			Filter: "duration",
+			Reason: "duration is too long",
+		}},
+	})
+
+	api := apiv1.New(
+		api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), conf), nil),
+		zerolog.New(os.Stderr),
+	)
+
+	body := []byte(`{"creator": "foo", "comment": "bar", "startsAt": "2022-12-13T21:55:12Z", "endsAt": "2022-12-14T21:55:12Z", "matchers": [{"label": "alertname", "value": "foo", "isRegex": false, "isNegative": false}]}`)
+	request, err := http.NewRequest(http.MethodPost, "localhost/api/v1/silences", bytes.NewReader(body))
+	require.NoError(t, err)
+	request.Header.Add("content-type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	api.PostSilences(recorder, request)
+
+	response := recorder.Result()
+	defer response.Body.Close()
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	validationErr := apiv1.ValidationError{}
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&validationErr))
+	require.Equal(t, "silences", validationErr.Leaf)
+	require.Len(t, validationErr.Paths, 1)
+	require.Equal(t, []string{"short_silences"}, validationErr.Paths[0].Path)
+	require.Equal(t, "duration", *validationErr.Paths[0].Filter)
+	require.Equal(t, "duration is too long", validationErr.Paths[0].Reason)
+	require.Empty(t, db.silences)
+}
+
 // TestGetAlerts tests the /api/v1/alerts endpoint with various matchers
 // to make sure that it properly parses and applies them.
 func TestGetAlertsMatchers(t *testing.T) {
@@ -215,6 +499,7 @@ func TestGetAlertsMatchers(t *testing.T) {
 	tests := []struct {
 		name           string
 		matchers       []string
+		query          string
 		expectedAlerts []int
 	}{
 		{
@@ -247,6 +532,17 @@ func TestGetAlertsMatchers(t *testing.T) {
 			matchers:       []string{"instance!~test[23]"},
 			expectedAlerts: []int{0},
 		},
+		{
+			name:           "test query",
+			query:          `notify="bar" and instance in (test, test3)`,
+			expectedAlerts: []int{2},
+		},
+		{
+			name:           "test query and matchers",
+			matchers:       []string{"instance=~test[23]"},
+			query:          `status = firing and not instance = test2`,
+			expectedAlerts: []int{2},
+		},
 	}
 
 	for _, tt := range tests {
@@ -267,6 +563,10 @@ func TestGetAlertsMatchers(t *testing.T) {
 				q.Add("matchers", m)
 			}
 
+			if tt.query != "" {
+				q.Set("query", tt.query)
+			}
+
 			request.URL.RawQuery = q.Encode()
 
 			router.ServeHTTP(resp, request)
@@ -279,14 +579,113 @@ func TestGetAlertsMatchers(t *testing.T) {
 
 			require.Equal(t, http.StatusOK, response.StatusCode, string(responseBody))
 
-			alerts := []model.Alert{}
+			alerts := list[model.Alert]{}
 			require.NoError(t, json.Unmarshal(responseBody, &alerts))
 
-			require.Equal(t, len(tt.expectedAlerts), len(alerts), "expected %d alerts, got %d", len(tt.expectedAlerts), len(alerts))
+			require.Equal(t, len(tt.expectedAlerts), len(alerts.Items), "expected %d alerts, got %d", len(tt.expectedAlerts), len(alerts.Items))
+			require.Equal(t, len(tt.expectedAlerts), alerts.Total)
 		})
 	}
 }
 
+func TestGetAlertsPagination(t *testing.T) {
+	db := &mockDB{}
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		alert := model.Alert{Labels: model.Labels{"alertname": name}, Status: model.AlertStatusFiring, StartTime: stubs.Time.Now()}
+		require.NoError(t, alert.Materialise())
+		db.alerts = append(db.alerts, alert)
+	}
+
+	router := mux.NewRouter()
+	apiv1.Register(router, api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), nil), nil), zerolog.New(os.Stderr))
+
+	get := func(cursor string) list[model.Alert] {
+		q := url.Values{"limit": {"2"}, "sort": {"alertname"}}
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/v1/alerts?"+q.Encode(), nil))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		page := list[model.Alert]{}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &page))
+		return page
+	}
+
+	names := []string{}
+	cursor := ""
+	for {
+		page := get(cursor)
+		require.Equal(t, 5, page.Total)
+		for _, alert := range page.Items {
+			names = append(names, alert.Labels["alertname"])
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+
+		cursor = page.NextCursor
+	}
+
+	require.Equal(t, []string{"a", "b", "c", "d", "e"}, names)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/v1/alerts?cursor=foo", nil))
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestGetAlertsETag(t *testing.T) {
+	alert := model.Alert{Labels: model.Labels{"alertname": "foo"}, Status: model.AlertStatusFiring, StartTime: stubs.Time.Now()}
+	require.NoError(t, alert.Materialise())
+	db := &mockDB{alerts: []model.Alert{alert}}
+
+	router := mux.NewRouter()
+	apiv1.Register(router, api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), nil), nil), zerolog.New(os.Stderr))
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/alerts", nil)
+		if ifNoneMatch != "" {
+			request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, request)
+		return resp
+	}
+
+	resp := get("")
+	require.Equal(t, http.StatusOK, resp.Code)
+	etag := resp.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	resp = get(etag)
+	require.Equal(t, http.StatusNotModified, resp.Code)
+	require.Empty(t, resp.Body.Bytes())
+
+	// Changing the alerts changes the ETag.
+	other := model.Alert{Labels: model.Labels{"alertname": "bar"}, Status: model.AlertStatusFiring, StartTime: stubs.Time.Now()}
+	require.NoError(t, other.Materialise())
+	db.alerts = append(db.alerts, other)
+
+	resp = get(etag)
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.NotEqual(t, etag, resp.Header().Get("ETag"))
+}
+
+func TestGetAlertsInvalidQuery(t *testing.T) {
+	db := &mockDB{}
+	router := mux.NewRouter()
+	apiv1.Register(router, api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), nil), nil), zerolog.New(os.Stderr))
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/v1/alerts?query="+url.QueryEscape(`severity="critical" and`), nil))
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+	require.Equal(t, "invalid query: unexpected end of query at position 23, expected a field\n", resp.Body.String())
+}
+
 func TestGetSilencesMatches(t *testing.T) {
 	referenceSilences := []model.Silence{
 		{
@@ -403,10 +802,619 @@ func TestGetSilencesMatches(t *testing.T) {
 
 			require.Equal(t, http.StatusOK, response.StatusCode, string(responseBody))
 
-			alerts := []model.Silence{}
-			require.NoError(t, json.Unmarshal(responseBody, &alerts))
+			silences := list[model.Silence]{}
+			require.NoError(t, json.Unmarshal(responseBody, &silences))
+
+			require.Equal(t, len(tt.expected), len(silences.Items), "expected %d silences, got %d", len(tt.expected), len(silences.Items))
+		})
+	}
+}
+
+// newTenantedRouter returns a router serving the v1 API, with tenants assigned by the `team` label and `admin` as the admin tenant.
+func newTenantedRouter(t *testing.T, db *mockDB) *mux.Router {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+
+	tenanter := config.NewTemplateTenanter(template.Must(template.New("tenant").Parse("{{ .team }}")))
+	conf := mock_config.NewMockConfigAllowingEverything(ctrl)
+	conf.EXPECT().Globals().Return(config.NewGlobals(config.WithTenanter(tenanter))).AnyTimes()
+
+	router := mux.NewRouter()
+	router.Use(api.TenantMiddleware(api.DefaultTenantHeader, "admin", conf))
+	apiv1.Register(router, api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), conf), nil), zerolog.New(os.Stderr))
+
+	return router
+}
+
+func TestTenantScoping(t *testing.T) {
+	alerts := []model.Alert{
+		{Labels: model.Labels{"alertname": "foo", "team": "foo"}, Status: model.AlertStatusFiring, StartTime: stubs.Time.Now()},
+		{Labels: model.Labels{"alertname": "bar", "team": "bar"}, Status: model.AlertStatusFiring, StartTime: stubs.Time.Now()},
+	}
+
+	for i := range alerts {
+		require.NoError(t, alerts[i].Materialise())
+	}
+
+	silences := []model.Silence{
+		{ID: "foo", Tenant: "foo", Matchers: []model.Matcher{{Label: "alertname", Value: "foo"}}},
+		{ID: "bar", Tenant: "bar", Matchers: []model.Matcher{{Label: "alertname", Value: "bar"}}},
+	}
+
+	tests := []struct {
+		name             string
+		tenant           string
+		expectedAlerts   []string
+		expectedSilences []string
+	}{
+		{
+			name:             "tenants only see their own data",
+			tenant:           "foo",
+			expectedAlerts:   []string{"foo"},
+			expectedSilences: []string{"foo"},
+		},
+		{
+			name:             "admins see every tenant",
+			tenant:           "admin",
+			expectedAlerts:   []string{"foo", "bar"},
+			expectedSilences: []string{"foo", "bar"},
+		},
+	}
+
+	get := func(t *testing.T, router *mux.Router, tenant, path string, into any) {
+		t.Helper()
+		request := httptest.NewRequest(http.MethodGet, "http://localhost"+path, nil)
+		if tenant != "" {
+			request.Header.Set(api.DefaultTenantHeader, tenant)
+		}
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, request)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), into))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTenantedRouter(t, &mockDB{alerts: alerts, silences: silences})
+
+			gotAlerts := list[model.Alert]{}
+			get(t, router, tt.tenant, "/api/v1/alerts", &gotAlerts)
+			alertNames := []string{}
+			for _, alert := range gotAlerts.Items {
+				alertNames = append(alertNames, alert.Labels["alertname"])
+			}
+
+			require.ElementsMatch(t, tt.expectedAlerts, alertNames)
+
+			gotSilences := list[model.Silence]{}
+			get(t, router, tt.tenant, "/api/v1/silences", &gotSilences)
+			silenceIDs := []string{}
+			for _, silence := range gotSilences.Items {
+				silenceIDs = append(silenceIDs, silence.ID)
+			}
+
+			require.ElementsMatch(t, tt.expectedSilences, silenceIDs)
+		})
+	}
+}
+
+func TestTenantHeaderRequired(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost/api/v1/alerts", nil)
+
+	// Without a tenant header we don't know whose data to show, so rather than showing everything we refuse the request.
+	resp := httptest.NewRecorder()
+	newTenantedRouter(t, &mockDB{}).ServeHTTP(resp, request)
+	require.Equal(t, http.StatusBadRequest, resp.Code, resp.Body.String())
+
+	// Without tenants, there's only one tenant to see.
+	db := &mockDB{}
+	router := mux.NewRouter()
+	router.Use(api.TenantMiddleware(api.DefaultTenantHeader, "admin", nil))
+	apiv1.Register(router, api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), nil), nil), zerolog.New(os.Stderr))
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, request)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+}
+
+func TestTenantScopingWithAuth(t *testing.T) {
+	alerts := []model.Alert{
+		{Labels: model.Labels{"alertname": "foo", "team": "foo"}, Status: model.AlertStatusFiring, StartTime: stubs.Time.Now()},
+		{Labels: model.Labels{"alertname": "bar", "team": "bar"}, Status: model.AlertStatusFiring, StartTime: stubs.Time.Now()},
+		{Labels: model.Labels{"alertname": "baz"}, Status: model.AlertStatusFiring, StartTime: stubs.Time.Now()},
+	}
+
+	for i := range alerts {
+		require.NoError(t, alerts[i].Materialise())
+	}
+
+	policy, err := auth.NewPolicy(nil, []auth.RoleBinding{
+		{Role: "oncall", Users: []string{"alice"}},
+		{Role: "admin", Users: []string{"root"}},
+	})
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	tenanter := config.NewTemplateTenanter(template.Must(template.New("tenant").Parse("{{ .team }}")))
+	conf := mock_config.NewMockConfigAllowingEverything(ctrl)
+	conf.EXPECT().Globals().Return(config.NewGlobals(config.WithTenanter(tenanter))).AnyTimes()
+
+	db := &mockDB{alerts: alerts}
+	router := mux.NewRouter()
+
+	// Pretend that every request was authenticated as the user in the X-User header.
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity := auth.Identity{Name: r.Header.Get("X-User"), Method: "basic"}
+			next.ServeHTTP(w, r.WithContext(auth.ContextWithIdentity(r.Context(), identity)))
+		})
+	})
+
+	router.Use(auth.PolicyMiddleware(policy))
+	router.Use(api.TenantMiddleware(api.DefaultTenantHeader, "admin", conf))
+	apiv1.Register(router, api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), conf), nil), zerolog.New(os.Stderr))
+
+	tests := []struct {
+		name           string
+		user           string
+		tenant         string
+		expectedStatus int
+		expectedAlerts []string
+	}{
+		{
+			name:           "authenticated requests without a tenant only see the default tenant",
+			user:           "alice",
+			expectedStatus: http.StatusOK,
+			expectedAlerts: []string{"baz"},
+		},
+		{
+			name:           "authenticated requests can't pick a tenant without the tenants:all permission",
+			user:           "alice",
+			tenant:         "foo",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "the admin tenant isn't an admin once requests are authenticated",
+			user:           "alice",
+			tenant:         "admin",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "requests with the tenants:all permission see every tenant",
+			user:           "root",
+			expectedStatus: http.StatusOK,
+			expectedAlerts: []string{"foo", "bar", "baz"},
+		},
+		{
+			name:           "requests with the tenants:all permission can pick a tenant",
+			user:           "root",
+			tenant:         "foo",
+			expectedStatus: http.StatusOK,
+			expectedAlerts: []string{"foo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "http://localhost/api/v1/alerts", nil)
+			request.Header.Set("X-User", tt.user)
+			request.Header.Set(api.DefaultTenantHeader, tt.tenant)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, request)
+			require.Equal(t, tt.expectedStatus, resp.Code, resp.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			gotAlerts := list[model.Alert]{}
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &gotAlerts))
+
+			alertNames := []string{}
+			for _, alert := range gotAlerts.Items {
+				alertNames = append(alertNames, alert.Labels["alertname"])
+			}
+
+			require.ElementsMatch(t, tt.expectedAlerts, alertNames)
+		})
+	}
+}
+
+func TestPostSilenceTenant(t *testing.T) {
+	tests := []struct {
+		name           string
+		tenant         string
+		bodyTenant     string
+		expectedTenant string
+	}{
+		{
+			name:           "silences are created in the requesting tenant",
+			tenant:         "foo",
+			expectedTenant: "foo",
+		},
+		{
+			name:           "tenants can't create silences in other tenants",
+			tenant:         "foo",
+			bodyTenant:     "bar",
+			expectedTenant: "foo",
+		},
+		{
+			name:           "admins can create silences in any tenant",
+			tenant:         "admin",
+			bodyTenant:     "bar",
+			expectedTenant: "bar",
+		},
+		{
+			name:           "admins without a tenant create silences in every tenant",
+			tenant:         "admin",
+			expectedTenant: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &mockDB{}
+			router := newTenantedRouter(t, db)
+
+			body := map[string]any{
+				"creator":  "test",
+				"comment":  "test",
+				"startsAt": stubs.Time.Now(),
+				"endsAt":   stubs.Time.Now().Add(time.Hour),
+				"matchers": []map[string]any{{"label": "alertname", "value": "foo", "isRegex": false, "isNegative": false}},
+			}
+
+			if tt.bodyTenant != "" {
+				body["tenant"] = tt.bodyTenant
+			}
+
+			bodyBytes, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			request := httptest.NewRequest(http.MethodPost, "http://localhost/api/v1/silences", bytes.NewReader(bodyBytes))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set(api.DefaultTenantHeader, tt.tenant)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, request)
+			require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+
+			silence := model.Silence{}
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &silence))
+			require.Equal(t, tt.expectedTenant, silence.Tenant)
+
+			require.Len(t, db.silences, 1)
+			require.Equal(t, tt.expectedTenant, db.silences[0].Tenant)
+		})
+	}
+}
+
+func TestGlobalSilencesAreVisibleToTenants(t *testing.T) {
+	db := &mockDB{silences: []model.Silence{
+		{ID: "bar", Tenant: "bar", Matchers: []model.Matcher{{Label: "alertname", Value: "bar"}}},
+	}}
+
+	router := newTenantedRouter(t, db)
+
+	// Silences created by admins without a tenant silence every tenant's alerts, so every tenant has to be able to see them.
+	body, err := json.Marshal(map[string]any{
+		"creator":  "test",
+		"comment":  "test",
+		"startsAt": stubs.Time.Now(),
+		"endsAt":   stubs.Time.Now().Add(time.Hour),
+		"matchers": []map[string]any{{"label": "alertname", "value": "foo", "isRegex": false, "isNegative": false}},
+	})
+	require.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "http://localhost/api/v1/silences", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(api.DefaultTenantHeader, "admin")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, request)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+
+	global := model.Silence{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &global))
+	require.Equal(t, "", global.Tenant)
+
+	request = httptest.NewRequest(http.MethodGet, "http://localhost/api/v1/silences", nil)
+	request.Header.Set(api.DefaultTenantHeader, "foo")
 
-			require.Equal(t, len(tt.expected), len(alerts), "expected %d silences, got %d", len(tt.expected), len(alerts))
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, request)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	silences := list[model.Silence]{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &silences))
+	require.Len(t, silences.Items, 1)
+	require.Equal(t, global.ID, silences.Items[0].ID)
+}
+
+func TestPostSilenceIdentity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	tenanter := config.NewTemplateTenanter(template.Must(template.New("tenant").Parse("{{ .team }}")))
+	conf := mock_config.NewMockConfigAllowingEverything(ctrl)
+	conf.EXPECT().Globals().Return(config.NewGlobals(config.WithTenanter(tenanter))).AnyTimes()
+
+	db := &mockDB{}
+	router := mux.NewRouter()
+
+	// Pretend that the request was authenticated as alice, in the foo tenant.
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity := auth.Identity{Name: "alice", Tenant: "foo", Method: "token"}
+			next.ServeHTTP(w, r.WithContext(auth.ContextWithIdentity(r.Context(), identity)))
+		})
+	})
+
+	router.Use(api.TenantMiddleware(api.DefaultTenantHeader, "admin", conf))
+	apiv1.Register(router, api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), conf), nil), zerolog.New(os.Stderr))
+
+	body := `{"creator": "mallory", "comment": "test", "startsAt": "2023-01-01T00:00:00Z", "endsAt": "2023-01-02T00:00:00Z", "matchers": [{"label": "alertname", "value": "foo", "isRegex": false, "isNegative": false}]}`
+	request := httptest.NewRequest(http.MethodPost, "http://localhost/api/v1/silences", bytes.NewReader([]byte(body)))
+	request.Header.Set("Content-Type", "application/json")
+
+	// The identity's tenant takes precedence over the header, so this can't be used to become an admin.
+	request.Header.Set(api.DefaultTenantHeader, "admin")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, request)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+
+	require.Len(t, db.silences, 1)
+	require.Equal(t, "alice", db.silences[0].Creator)
+	require.Equal(t, "foo", db.silences[0].Tenant)
+}
+
+func TestPostSilenceWithoutTenant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	tenanter := config.NewTemplateTenanter(template.Must(template.New("tenant").Parse("{{ .team }}")))
+	conf := mock_config.NewMockConfigAllowingEverything(ctrl)
+	conf.EXPECT().Globals().Return(config.NewGlobals(config.WithTenanter(tenanter))).AnyTimes()
+
+	db := &mockDB{}
+	router := mux.NewRouter()
+
+	// Pretend that the request was authenticated as alice, who doesn't belong to a tenant.
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity := auth.Identity{Name: "alice", Method: "token"}
+			next.ServeHTTP(w, r.WithContext(auth.ContextWithIdentity(r.Context(), identity)))
+		})
+	})
+
+	router.Use(api.TenantMiddleware(api.DefaultTenantHeader, "admin", conf))
+	apiv1.Register(router, api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), conf), nil), zerolog.New(os.Stderr))
+
+	// A silence without a tenant would silence every tenant, which alice can't see.
+	body := `{"creator": "alice", "comment": "test", "startsAt": "2023-01-01T00:00:00Z", "endsAt": "2023-01-02T00:00:00Z", "matchers": [{"label": "alertname", "value": "foo", "isRegex": false, "isNegative": false}]}`
+	request := httptest.NewRequest(http.MethodPost, "http://localhost/api/v1/silences", bytes.NewReader([]byte(body)))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, request)
+	require.Equal(t, http.StatusForbidden, resp.Code, resp.Body.String())
+	require.Empty(t, db.silences)
+}
+
+func TestAuditLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conf := mock_config.NewMockConfigAllowingEverything(ctrl)
+	conf.EXPECT().Globals().Return(config.NewGlobals()).AnyTimes()
+
+	db := &mockDB{}
+	sink := audit.NewDBSink(kioradb.NewInMemoryDB())
+	router := mux.NewRouter()
+	router.Use(audit.Middleware())
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity := auth.Identity{Name: "alice", Method: "basic"}
+			next.ServeHTTP(w, r.WithContext(auth.ContextWithIdentity(r.Context(), identity)))
 		})
+	})
+
+	apiv1.Register(router, api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), conf), nil, api.WithAuditSink(sink)), zerolog.New(os.Stderr))
+
+	post := func(path, body string) int {
+		request := httptest.NewRequest(http.MethodPost, "http://localhost"+path, bytes.NewReader([]byte(body)))
+		request.Header.Set("Content-Type", "application/json")
+		request.RemoteAddr = "192.0.2.1:1234"
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, request)
+		return resp.Code
+	}
+
+	require.Equal(t, http.StatusCreated, post("/api/v1/silences", `{"creator": "mallory", "comment": "test", "startsAt": "2023-01-01T00:00:00Z", "endsAt": "2023-01-02T00:00:00Z", "matchers": [{"label": "alertname", "value": "foo", "isRegex": false, "isNegative": false}]}`))
+	require.Equal(t, http.StatusInternalServerError, post("/api/v1/alerts/ack", `{"alertID": "missing", "creator": "alice", "comment": "test"}`))
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost/api/v1/audit?actor=alice", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, request)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	entries := []model.AuditEntry{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &entries))
+	require.Len(t, entries, 2)
+
+	// The ack was for an alert that doesn't exist, so it was never broadcast.
+	require.Equal(t, model.AuditActionAckAlert, entries[0].Action)
+	require.Equal(t, model.AuditOutcomeRejected, entries[0].Outcome)
+	require.Equal(t, []string{`alert "missing" not found`}, entries[0].Errors)
+	require.Empty(t, entries[0].Broadcast)
+
+	// The silence records what was asked for, as well as what was actually broadcast.
+	require.Equal(t, model.AuditActionPostSilence, entries[1].Action)
+	require.Equal(t, model.AuditOutcomeAccepted, entries[1].Outcome)
+	require.Equal(t, "alice", entries[1].Actor)
+	require.Equal(t, "basic", entries[1].AuthMethod)
+	require.Equal(t, "192.0.2.1", entries[1].SourceIP)
+
+	requested, broadcast := model.Silence{}, model.Silence{}
+	require.NoError(t, json.Unmarshal(entries[1].Request, &requested))
+	require.NoError(t, json.Unmarshal(entries[1].Broadcast, &broadcast))
+	require.Equal(t, "mallory", requested.Creator)
+	require.Equal(t, "alice", broadcast.Creator)
+	require.Equal(t, db.silences[0].ID, broadcast.ID)
+}
+
+// readEvents reads the data of the given number of events from a Server-Sent Events stream.
+func readEvents(t *testing.T, scanner *bufio.Scanner, n int) []model.Event {
+	t.Helper()
+
+	received := []model.Event{}
+	for len(received) < n && scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+
+		event := model.Event{}
+		require.NoError(t, json.Unmarshal([]byte(data), &event))
+		received = append(received, event)
 	}
+
+	require.Len(t, received, n)
+	return received
+}
+
+func TestGetEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	tenanter := config.NewTemplateTenanter(template.Must(template.New("tenant").Parse("{{ .team }}")))
+	conf := mock_config.NewMockConfigAllowingEverything(ctrl)
+	conf.EXPECT().Globals().Return(config.NewGlobals(config.WithTenanter(tenanter))).AnyTimes()
+
+	stream := events.NewStream(events.DefaultHistorySize)
+	db := &mockDB{}
+
+	router := mux.NewRouter()
+	router.Use(api.TenantMiddleware(api.DefaultTenantHeader, "admin", conf))
+	apiv1.Register(router, api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), conf), nil, api.WithEventStream(stream)), zerolog.New(os.Stderr))
+
+	// Streams are closed when the test is cleaned up, which has to happen before the server is closed.
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	subscribe := func(path string, headers map[string]string) (*http.Response, *bufio.Scanner) {
+		request, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		for k, v := range headers {
+			request.Header.Set(k, v)
+		}
+
+		resp, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+
+		return resp, bufio.NewScanner(resp.Body)
+	}
+
+	alert := func(team string, status model.AlertStatus) model.Event {
+		return model.Event{Type: model.EventTypeAlert, Alert: &model.Alert{Labels: model.Labels{"team": team}, Status: status}}
+	}
+
+	// foo only sees its own firing alerts.
+	resp, scanner := subscribe("/api/v1/events?types=alert&filter[filter_type]=status&filter[status]=firing", map[string]string{api.DefaultTenantHeader: "foo"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	stream.Publish(
+		alert("foo", model.AlertStatusResolved),
+		alert("bar", model.AlertStatusFiring),
+		model.Event{Type: model.EventTypeSilence, Silence: &model.Silence{ID: "silence", Tenant: "foo"}},
+		alert("foo", model.AlertStatusFiring),
+	)
+
+	received := readEvents(t, scanner, 1)
+	require.Equal(t, "foo", received[0].Alert.Labels["team"])
+	require.Equal(t, model.AlertStatusFiring, received[0].Alert.Status)
+
+	// Reconnecting with the ID of the first event resumes after it.
+	resp, scanner = subscribe("/api/v1/events", map[string]string{api.DefaultTenantHeader: "admin", "Last-Event-ID": received[0].ID})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	stream.Publish(alert("bar", model.AlertStatusAcked))
+	resumed := readEvents(t, scanner, 1)
+	require.Equal(t, "bar", resumed[0].Alert.Labels["team"])
+	require.Equal(t, model.AlertStatusAcked, resumed[0].Alert.Status)
+
+	// Resuming from an event that we don't have tells the client to start over.
+	resp, scanner = subscribe("/api/v1/events?lastEventID=foo-1", map[string]string{api.DefaultTenantHeader: "admin"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.True(t, scanner.Scan())
+	require.Equal(t, "event: reset", scanner.Text())
+
+	resp, _ = subscribe("/api/v1/events?filter[filter_type]=foo", map[string]string{api.DefaultTenantHeader: "admin"})
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = subscribe("/api/v1/events?query=team", map[string]string{api.DefaultTenantHeader: "admin"})
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestGetEventsNotStreamed(t *testing.T) {
+	db := &mockDB{}
+	router := mux.NewRouter()
+	apiv1.Register(router, api.NewAPIImpl(services.NewKioraBus(db, db, zerolog.New(os.Stderr), nil), nil), zerolog.New(os.Stderr))
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/v1/events", nil))
+	require.Equal(t, http.StatusNotImplemented, resp.Code)
+}
+
+func TestGetAlertsPaginatesInTheDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kiora.sqlite")
+	db, err := kioradb.NewSQLiteDB(path, zerolog.Nop())
+	require.NoError(t, err)
+	defer db.Close()
+
+	start := time.Unix(1000, 0)
+	alerts := []model.Alert{
+		{Labels: model.Labels{"alertname": "foo-1", "team": "foo"}, Status: model.AlertStatusFiring, StartTime: start},
+		{Labels: model.Labels{"alertname": "foo-2", "team": "foo"}, Status: model.AlertStatusFiring, StartTime: start.Add(time.Minute)},
+		{Labels: model.Labels{"alertname": "foo-3", "team": "foo"}, Status: model.AlertStatusFiring, StartTime: start.Add(2 * time.Minute)},
+		{Labels: model.Labels{"alertname": "bar", "team": "bar"}, Status: model.AlertStatusFiring, StartTime: start},
+	}
+
+	for i := range alerts {
+		require.NoError(t, alerts[i].Materialise())
+	}
+
+	require.NoError(t, db.StoreAlerts(context.Background(), alerts...))
+
+	// Break the alerts that aren't in the page, so that the query fails if they're loaded, i.e. if the tenant
+	// or the page can't be applied in SQL.
+	raw, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	_, err = raw.Exec("UPDATE alerts SET data = x'c1' WHERE id IN (?, ?)", alerts[2].ID, alerts[3].ID)
+	require.NoError(t, err)
+	require.NoError(t, raw.Close())
+
+	ctrl := gomock.NewController(t)
+	tenanter := config.NewTemplateTenanter(template.Must(template.New("tenant").Parse("{{ .team }}")))
+	conf := mock_config.NewMockConfigAllowingEverything(ctrl)
+	conf.EXPECT().Globals().Return(config.NewGlobals(config.WithTenanter(tenanter))).AnyTimes()
+
+	router := mux.NewRouter()
+	router.Use(api.TenantMiddleware(api.DefaultTenantHeader, "admin", conf))
+	apiv1.Register(router, api.NewAPIImpl(services.NewKioraBus(db, &mockDB{}, zerolog.New(os.Stderr), conf), nil), zerolog.New(os.Stderr))
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/alerts?limit=2&sort=__starts_at__", nil)
+	request.Header.Set(api.DefaultTenantHeader, "foo")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, request)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	page := list[model.Alert]{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &page))
+
+	alertNames := []string{}
+	for _, alert := range page.Items {
+		alertNames = append(alertNames, alert.Labels["alertname"])
+	}
+
+	require.Equal(t, []string{"foo-1", "foo-2"}, alertNames)
+	require.Equal(t, 3, page.Total)
+	require.NotEmpty(t, page.NextCursor)
 }
@@ -0,0 +1,147 @@
+package apiv1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sinkingpoint/kiora/internal/auth"
+	"github.com/sinkingpoint/kiora/internal/server/api"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const CONTENT_TYPE_EVENT_STREAM = "text/event-stream"
+
+// eventStreamKeepAlive is how often a comment is sent on an idle event stream, so that proxies don't close it.
+var eventStreamKeepAlive = 15 * time.Second
+
+// GetEvents handles the GET /events request, streaming changes to alerts, silences, and acknowledgements as Server-Sent Events.
+func (a *apiv1) GetEvents(w http.ResponseWriter, r *http.Request, params GetEventsParams) {
+	span := trace.SpanFromContext(r.Context())
+
+	var filter query.AlertFilter
+	if params.Filter != nil && len(*params.Filter) > 0 {
+		var err error
+		if filter, err = query.UnmarshalAlertFilter(*params.Filter); err != nil || filter == nil {
+			span.SetStatus(codes.Error, "invalid filter")
+			http.Error(w, "invalid filter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if params.Query != nil && *params.Query != "" {
+		alertQuery, err := query.ParseAlertFilter(*params.Query)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			http.Error(w, fmt.Sprintf("invalid query: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+
+		if filter == nil {
+			filter = alertQuery
+		} else {
+			filter = query.AllAlerts(filter, alertQuery)
+		}
+	}
+
+	types := []model.EventType{model.EventTypeAlert, model.EventTypeAck, model.EventTypeSilence}
+	if params.Types != nil && len(*params.Types) > 0 {
+		types = types[:0]
+		for _, ty := range *params.Types {
+			types = append(types, model.EventType(ty))
+		}
+	}
+
+	// The stream requires permission to read alerts, but silences have their own permission.
+	if !auth.Allowed(r.Context(), auth.PermissionReadSilences) {
+		allowedTypes := make([]model.EventType, 0, len(types))
+		for _, ty := range types {
+			if ty != model.EventTypeSilence {
+				allowedTypes = append(allowedTypes, ty)
+			}
+		}
+
+		if len(allowedTypes) == 0 {
+			http.Error(w, fmt.Sprintf("permission %q is required", auth.PermissionReadSilences), http.StatusForbidden)
+			return
+		}
+
+		types = allowedTypes
+	}
+
+	// Browsers send the Last-Event-ID header when they reconnect, but can't set it on the first connection, so we accept either.
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" && params.LastEventID != nil {
+		lastEventID = *params.LastEventID
+	}
+
+	subscription, err := a.api.SubscribeEvents(r.Context(), types, filter, lastEventID)
+	if err != nil {
+		a.logger.Debug().Err(err).Msg("failed to subscribe to events")
+		span.SetStatus(codes.Error, err.Error())
+
+		if errors.Is(err, api.ErrEventsNotStreamed) {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+		} else {
+			http.Error(w, "failed to subscribe to events", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	defer subscription.Close()
+
+	// The stream stays open for as long as the client is connected, so the server's write timeout can't apply to it.
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		a.logger.Warn().Err(err).Msg("failed to clear write deadline for event stream")
+	}
+
+	w.Header().Set("Content-Type", CONTENT_TYPE_EVENT_STREAM)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	if subscription.Missed {
+		// The events after the client's last one are gone, so it has to refetch everything to catch up.
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n") // nolint:errcheck
+	}
+
+	if err := controller.Flush(); err != nil {
+		a.logger.Debug().Err(err).Msg("event stream doesn't support flushing")
+		return
+	}
+
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keepalive\n\n") // nolint:errcheck
+		case event, ok := <-subscription.Events():
+			if !ok {
+				// The client fell too far behind, so we close the stream. It can reconnect, and resume from the last event it saw.
+				return
+			}
+
+			bytes, err := json.Marshal(event)
+			if err != nil {
+				a.logger.Debug().Err(err).Msg("failed to marshal event")
+				continue
+			}
+
+			fmt.Fprintf(w, "id: %s\ndata: %s\n\n", event.ID, bytes) // nolint:errcheck
+		}
+
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package apiv1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+)
+
+// listResponse is the envelope that pages of results are returned in, matching the AlertList and SilenceList schemas.
+type listResponse[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+func newListResponse[T any](page query.Page[T]) listResponse[T] {
+	items := page.Items
+	if items == nil {
+		items = []T{}
+	}
+
+	return listResponse[T]{
+		Items:      items,
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
+	}
+}
+
+// writeJSONWithETag writes the given JSON body with an ETag, so that clients that poll can send it back in If-None-Match to get a
+// 304 with no body if nothing has changed.
+func writeJSONWithETag(w http.ResponseWriter, ifNoneMatch *string, body []byte) {
+	hash := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(hash[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	if ifNoneMatch != nil && etagMatches(*ifNoneMatch, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", CONTENT_TYPE_JSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(body) //nolint:errcheck // Errors writing here are not recoverable.
+}
+
+// etagMatches returns true if the given If-None-Match header contains the given ETag.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		// If-None-Match uses weak comparison, so weak ETags match too.
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/internal/audit"
+	"github.com/sinkingpoint/kiora/internal/auth"
+	"github.com/sinkingpoint/kiora/internal/stubs"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+// auditedAck is an acknowledgement, along with the alert that it acknowledges, as recorded in the audit log.
+type auditedAck struct {
+	AlertID string `json:"alertID"`
+	model.AlertAcknowledgement
+}
+
+// auditor records a change made through the API in the audit log. A nil auditor records nothing, so that the API
+// doesn't have to check whether auditing is enabled.
+type auditor struct {
+	sink   audit.Sink
+	logger *zerolog.Logger
+	entry  model.AuditEntry
+
+	// broadcasting is true if the change got as far as being broadcast, i.e. it passed validation.
+	broadcasting bool
+}
+
+// startAudit starts recording the given change, made by the request in the given context. The request is recorded
+// as is, so this should be called before the request is validated or modified.
+func (a *APIImpl) startAudit(ctx context.Context, action model.AuditAction, request any) *auditor {
+	if a.auditSink == nil {
+		return nil
+	}
+
+	auditor := &auditor{
+		sink:   a.auditSink,
+		logger: a.bus.Logger("audit"),
+		entry: model.AuditEntry{
+			ID:     uuid.New().String(),
+			Time:   stubs.Time.Now(),
+			Action: action,
+			Tenant: string(TenantScopeFromContext(ctx).Tenant),
+		},
+	}
+
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		auditor.entry.Actor = identity.Name
+		auditor.entry.AuthMethod = identity.Method
+	}
+
+	if source, ok := audit.SourceFromContext(ctx); ok {
+		auditor.entry.SourceIP = source.IP
+		auditor.entry.ForwardedFor = source.ForwardedFor
+	}
+
+	if request != nil {
+		auditor.entry.Request = auditor.marshal(request)
+	}
+
+	return auditor
+}
+
+// marshal encodes the given data for the audit log, logging (rather than failing the change) if it can't be.
+func (au *auditor) marshal(data any) json.RawMessage {
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		au.logger.Error().Err(err).Str("action", string(au.entry.Action)).Msg("failed to marshal audit data")
+		return nil
+	}
+
+	return bytes
+}
+
+// broadcast records the data that's about to be broadcast as a result of the change.
+func (au *auditor) broadcast(data any) {
+	if au == nil {
+		return
+	}
+
+	au.entry.Broadcast = au.marshal(data)
+	au.broadcasting = true
+}
+
+// finish records the outcome of the change and writes it to the audit log. err is the error that the change failed with (if any),
+// and rejected are the errors of any parts of the change that were rejected without failing the whole thing.
+func (au *auditor) finish(ctx context.Context, err error, rejected ...error) {
+	if au == nil {
+		return
+	}
+
+	for _, rejection := range rejected {
+		au.entry.Errors = append(au.entry.Errors, rejection.Error())
+	}
+
+	switch {
+	case err != nil && au.broadcasting:
+		au.entry.Outcome = model.AuditOutcomeFailed
+		au.entry.Errors = append(au.entry.Errors, err.Error())
+	case err != nil:
+		au.entry.Outcome = model.AuditOutcomeRejected
+		au.entry.Errors = append(au.entry.Errors, err.Error())
+	case len(rejected) == 0:
+		au.entry.Outcome = model.AuditOutcomeAccepted
+	case au.broadcasting:
+		au.entry.Outcome = model.AuditOutcomePartiallyAccepted
+	default:
+		au.entry.Outcome = model.AuditOutcomeRejected
+	}
+
+	// The change has already happened by now, so failing to record it shouldn't fail the request.
+	if err := au.sink.Write(ctx, au.entry); err != nil {
+		au.logger.Error().Err(err).Str("action", string(au.entry.Action)).Str("actor", au.entry.Actor).Msg("failed to write audit entry")
+	}
+}
@@ -0,0 +1,334 @@
+package promcompat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/regexp"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	kmodel "github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+// parseFilters parses the `filter` query parameters of a request into matchers, anchoring any regexes like Alertmanager does.
+func parseFilters(r *http.Request) ([]kmodel.Matcher, error) {
+	matchers := []kmodel.Matcher{}
+	for _, filter := range r.URL.Query()["filter"] {
+		m := kmodel.Matcher{}
+		if err := m.UnmarshalText(strings.TrimSpace(filter)); err != nil {
+			return nil, fmt.Errorf("invalid filter %q: %w", filter, err)
+		}
+
+		if m.IsRegex {
+			anchored, err := kmodel.LabelValueRegexMatcher(m.Label, anchorRegex(m.Value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter %q: %w", filter, err)
+			}
+
+			anchored.IsNegative = m.IsNegative
+			m = anchored
+		}
+
+		matchers = append(matchers, m)
+	}
+
+	return matchers, nil
+}
+
+// boolParam returns the value of the given boolean query parameter, or the default if it isn't set.
+func boolParam(r *http.Request, name string, def bool) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s parameter %q", name, value)
+	}
+
+	return b, nil
+}
+
+// alertParams are the query parameters that filter the alerts returned by the Alertmanager API.
+type alertParams struct {
+	matchers []kmodel.Matcher
+	receiver *regexp.Regexp
+
+	// active, silenced, and inhibited include alerts in each state. Acknowledged alerts are treated as inhibited, because like
+	// inhibited alerts, they're suppressed by something other than a silence. Kiora doesn't have unprocessed alerts, so that parameter is ignored.
+	active, silenced, inhibited bool
+}
+
+func parseAlertParams(r *http.Request) (alertParams, error) {
+	params := alertParams{}
+
+	var err error
+	if params.matchers, err = parseFilters(r); err != nil {
+		return params, err
+	}
+
+	if receiver := r.URL.Query().Get("receiver"); receiver != "" {
+		if params.receiver, err = regexp.Compile(anchorRegex(receiver)); err != nil {
+			return params, fmt.Errorf("invalid receiver regex %q: %w", receiver, err)
+		}
+	}
+
+	if params.active, err = boolParam(r, "active", true); err != nil {
+		return params, err
+	}
+
+	if params.silenced, err = boolParam(r, "silenced", true); err != nil {
+		return params, err
+	}
+
+	if params.inhibited, err = boolParam(r, "inhibited", true); err != nil {
+		return params, err
+	}
+
+	return params, nil
+}
+
+// routedAlert is an alert in the Alertmanager API, along with the routes it takes through the config.
+type routedAlert struct {
+	alert  gettableAlert
+	routes []config.Route
+}
+
+// queryAlerts returns the alerts matching the given params, in the form that Alertmanager returns them.
+func (p *promCompat) queryAlerts(ctx context.Context, params alertParams) ([]routedAlert, error) {
+	filter := query.AlertFilter(query.MatchAll())
+	if len(params.matchers) > 0 {
+		filters := make([]query.AlertFilter, 0, len(params.matchers))
+		for _, m := range params.matchers {
+			filters = append(filters, query.Matcher(m))
+		}
+
+		filter = query.AllAlerts(filters...)
+	}
+
+	alerts, err := p.api.GetAlerts(ctx, query.NewAlertQuery(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	silences, err := p.api.GetSilences(ctx, query.NewSilenceQuery(query.SilenceIsActive()))
+	if err != nil {
+		return nil, err
+	}
+
+	routed := make([]routedAlert, 0, len(alerts.Items))
+	for _, alert := range alerts.Items {
+		status := alertStatus{
+			State:       alertStateActive,
+			SilencedBy:  []string{},
+			InhibitedBy: []string{},
+		}
+
+		include := params.active
+		switch alert.Status {
+		case kmodel.AlertStatusResolved, kmodel.AlertStatusTimedOut:
+			// Alertmanager only returns alerts that are still firing.
+			continue
+		case kmodel.AlertStatusSilenced:
+			include = params.silenced
+			status.State = alertStateSuppressed
+			for _, silence := range silences.Items {
+				if silence.Matches(alert.Labels) {
+					status.SilencedBy = append(status.SilencedBy, silence.ID)
+				}
+			}
+		case kmodel.AlertStatusAcked:
+			include = params.inhibited
+			status.State = alertStateSuppressed
+		}
+
+		if !include {
+			continue
+		}
+
+		routes := p.routeAlert(ctx, alert)
+		receivers := []receiver{}
+		seen := map[string]struct{}{}
+		for _, route := range routes {
+			name := string(route.Name())
+			if _, ok := seen[name]; ok {
+				continue
+			}
+
+			seen[name] = struct{}{}
+			receivers = append(receivers, receiver{Name: name})
+		}
+
+		if params.receiver != nil && !matchesAnyReceiver(params.receiver, receivers) {
+			continue
+		}
+
+		routed = append(routed, routedAlert{
+			alert:  newGettableAlert(alert, receivers, status),
+			routes: routes,
+		})
+	}
+
+	return routed, nil
+}
+
+// routeAlert returns the routes that the given alert takes through the config, without modifying it.
+func (p *promCompat) routeAlert(ctx context.Context, alert kmodel.Alert) []config.Route {
+	// Routing transforms the alert, so we give it a copy.
+	labels := make(kmodel.Labels, len(alert.Labels))
+	for k, v := range alert.Labels {
+		labels[k] = v
+	}
+
+	annotations := make(map[string]string, len(alert.Annotations))
+	for k, v := range alert.Annotations {
+		annotations[k] = v
+	}
+
+	alert.Labels, alert.Annotations = labels, annotations
+
+	routes, err := p.api.RouteAlert(ctx, &alert)
+	if err != nil {
+		p.logger.Warn().Err(err).Str("alert", alert.ID).Msg("failed to route alert")
+		return nil
+	}
+
+	return routes
+}
+
+func matchesAnyReceiver(regex *regexp.Regexp, receivers []receiver) bool {
+	for _, receiver := range receivers {
+		if regex.MatchString(receiver.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newGettableAlert converts a Kiora alert into an Alertmanager one.
+func newGettableAlert(alert kmodel.Alert, receivers []receiver, status alertStatus) gettableAlert {
+	// Alertmanager always has an end time for firing alerts - when it'll consider the alert resolved if it doesn't hear about
+	// it again. For Kiora, that's when the alert times out.
+	endsAt := alert.EndTime
+	if endsAt.IsZero() {
+		endsAt = alert.TimeOutDeadline
+	}
+
+	updatedAt := alert.StartTime
+	if alert.LastNotifyTime.After(updatedAt) {
+		updatedAt = alert.LastNotifyTime
+	}
+
+	return gettableAlert{
+		Labels:      alert.Labels,
+		Annotations: alert.Annotations,
+		StartsAt:    alert.StartTime,
+		EndsAt:      endsAt,
+		UpdatedAt:   updatedAt,
+		Fingerprint: alert.ID,
+		Receivers:   receivers,
+		Status:      status,
+	}
+}
+
+// GetAlerts handles the GET /api/v2/alerts request, returning the alerts that are still firing.
+func (p *promCompat) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	params, err := parseAlertParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	routed, err := p.queryAlerts(r.Context(), params)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to get alerts")
+		http.Error(w, "failed to get alerts", http.StatusInternalServerError)
+		return
+	}
+
+	alerts := make([]gettableAlert, 0, len(routed))
+	for _, alert := range routed {
+		alerts = append(alerts, alert.alert)
+	}
+
+	p.writeJSON(w, http.StatusOK, alerts)
+}
+
+// GetAlertGroups handles the GET /api/v2/alerts/groups request, grouping the firing alerts by the notifiers that they're sent to
+// and the group labels of each notifier.
+func (p *promCompat) GetAlertGroups(w http.ResponseWriter, r *http.Request) {
+	params, err := parseAlertParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	routed, err := p.queryAlerts(r.Context(), params)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to get alerts")
+		http.Error(w, "failed to get alerts", http.StatusInternalServerError)
+		return
+	}
+
+	groups := map[string]*alertGroup{}
+	keys := []string{}
+	for _, alert := range routed {
+		for _, route := range alert.routes {
+			name := string(route.Name())
+			if params.receiver != nil && !params.receiver.MatchString(name) {
+				continue
+			}
+
+			labels := kmodel.Labels{}
+			for _, label := range route.GroupLabels {
+				if value, ok := alert.alert.Labels[label]; ok {
+					labels[label] = value
+				}
+			}
+
+			key := name + "\x00" + string(labels.Bytes())
+			group, ok := groups[key]
+			if !ok {
+				group = &alertGroup{
+					Labels:   labels,
+					Receiver: receiver{Name: name},
+					Alerts:   []gettableAlert{},
+				}
+
+				groups[key] = group
+				keys = append(keys, key)
+			}
+
+			group.Alerts = append(group.Alerts, alert.alert)
+		}
+	}
+
+	sort.Strings(keys)
+	response := make([]alertGroup, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, *groups[key])
+	}
+
+	p.writeJSON(w, http.StatusOK, response)
+}
+
+// writeJSON writes the given value to the response as JSON, with the given status.
+func (p *promCompat) writeJSON(w http.ResponseWriter, status int, v any) {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to marshal response")
+		http.Error(w, "failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(bytes) // nolint:errcheck
+}
@@ -3,11 +3,15 @@ package promcompat
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/prometheus/common/model"
 	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/internal/auth"
 	"github.com/sinkingpoint/kiora/internal/server/api"
+	"github.com/sinkingpoint/kiora/internal/server/api/apiv1"
+	"github.com/sinkingpoint/kiora/internal/stubs"
 	kmodel "github.com/sinkingpoint/kiora/lib/kiora/model"
 )
 
@@ -16,19 +20,32 @@ func Register(router *mux.Router, api api.API, logger zerolog.Logger) {
 
 	subRouter := router.PathPrefix("/api/prom-compat").Subrouter()
 
-	subRouter.Path("/api/v2/alerts").Methods(http.MethodPost).HandlerFunc(promCompat.PostAlerts)
+	subRouter.Path("/api/v2/alerts").Methods(http.MethodPost).Handler(auth.RequirePermission(auth.PermissionWriteAlerts, http.HandlerFunc(promCompat.PostAlerts)))
+	subRouter.Path("/api/v2/alerts").Methods(http.MethodGet).Handler(auth.RequirePermission(auth.PermissionReadAlerts, http.HandlerFunc(promCompat.GetAlerts)))
+	subRouter.Path("/api/v2/alerts/groups").Methods(http.MethodGet).Handler(auth.RequirePermission(auth.PermissionReadAlerts, http.HandlerFunc(promCompat.GetAlertGroups)))
+	subRouter.Path("/api/v2/silences").Methods(http.MethodGet).Handler(auth.RequirePermission(auth.PermissionReadSilences, http.HandlerFunc(promCompat.GetSilences)))
+	subRouter.Path("/api/v2/silences").Methods(http.MethodPost).Handler(auth.RequirePermission(auth.PermissionWriteSilences, http.HandlerFunc(promCompat.PostSilences)))
+	subRouter.Path("/api/v2/silence/{silenceID}").Methods(http.MethodGet).Handler(auth.RequirePermission(auth.PermissionReadSilences, http.HandlerFunc(promCompat.GetSilence)))
+	subRouter.Path("/api/v2/silence/{silenceID}").Methods(http.MethodDelete).Handler(auth.RequirePermission(auth.PermissionWriteSilences, http.HandlerFunc(promCompat.DeleteSilence)))
+	subRouter.Path("/api/v2/status").Methods(http.MethodGet).Handler(auth.RequirePermission(auth.PermissionReadConfig, http.HandlerFunc(promCompat.GetStatus)))
+	subRouter.Path("/api/v2/receivers").Methods(http.MethodGet).Handler(auth.RequirePermission(auth.PermissionReadConfig, http.HandlerFunc(promCompat.GetReceivers)))
 }
 
-// promCompat provides an API that is able to ingest alerts from Prometheus.
+// promCompat provides an API that is compatible with Alertmanager's v2 API, so that Prometheus can send alerts to Kiora,
+// and tools built for Alertmanager can read them back.
 type promCompat struct {
 	api    api.API
 	logger zerolog.Logger
+
+	// startTime is when the API was started, which is reported as the uptime of Kiora.
+	startTime time.Time
 }
 
 func New(api api.API, logger zerolog.Logger) *promCompat {
 	return &promCompat{
-		api:    api,
-		logger: logger.With().Str("component", "promcompat").Logger(),
+		api:       api,
+		logger:    logger.With().Str("component", "promcompat").Logger(),
+		startTime: stubs.Time.Now(),
 	}
 }
 
@@ -45,25 +62,43 @@ func (p *promCompat) PostAlerts(w http.ResponseWriter, r *http.Request) {
 
 	alerts := make([]kmodel.Alert, len(promAlerts))
 	for i, promAlert := range promAlerts {
-		alert, err := marshalPromAlertToKioraAlert(promAlert)
-		if err != nil {
-			http.Error(w, "failed to unmarshal prometheus alert", http.StatusBadRequest)
-			return
-		}
-		alerts[i] = alert
+		alerts[i] = marshalPromAlertToKioraAlert(promAlert)
 	}
 
-	if err := p.api.PostAlerts(r.Context(), alerts); err != nil {
+	result, err := p.api.PostAlerts(r.Context(), alerts)
+	if err != nil {
 		http.Error(w, "failed to post alerts", http.StatusInternalServerError)
 		p.logger.Error().Err(err).Msg("failed to post alerts")
 		return
 	}
 
-	w.WriteHeader(http.StatusAccepted)
+	responseBytes, err := json.Marshal(apiv1.NewPostAlertsResponse(result))
+	if err != nil {
+		http.Error(w, "failed to marshal response", http.StatusInternalServerError)
+		p.logger.Error().Err(err).Msg("failed to marshal post alerts response")
+		return
+	}
+
+	// Prometheus resends the whole batch if we fail the request, so we only do that if none of the alerts could be accepted.
+	// Otherwise the good alerts are accepted, and the rejected ones are logged.
+	status := http.StatusAccepted
+	if len(result.Rejected) > 0 {
+		for _, rejected := range result.Rejected {
+			p.logger.Warn().Err(rejected.Err).Int("index", rejected.Index).Interface("labels", rejected.Alert.Labels).Msg("rejected alert")
+		}
+
+		if len(result.Accepted) == 0 {
+			status = http.StatusBadRequest
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(responseBytes) // nolint:errcheck
 }
 
-// marshalPromAlertToKioraAlert converts a Prometheus alert to a Kiora alert.
-func marshalPromAlertToKioraAlert(p model.Alert) (kmodel.Alert, error) {
+// marshalPromAlertToKioraAlert converts a Prometheus alert to a Kiora alert. The alert is materialised when it is posted.
+func marshalPromAlertToKioraAlert(p model.Alert) kmodel.Alert {
 	labels := kmodel.Labels{}
 	for k, v := range p.Labels {
 		labels[string(k)] = string(v)
@@ -74,13 +109,11 @@ func marshalPromAlertToKioraAlert(p model.Alert) (kmodel.Alert, error) {
 		annotations[string(k)] = string(v)
 	}
 
-	alert := kmodel.Alert{
+	return kmodel.Alert{
 		Status:      kmodel.AlertStatus(p.Status()),
 		Labels:      labels,
 		Annotations: annotations,
 		StartTime:   p.StartsAt,
 		EndTime:     p.EndsAt,
 	}
-
-	return alert, alert.Materialise()
 }
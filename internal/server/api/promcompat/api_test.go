@@ -0,0 +1,252 @@
+package promcompat_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/internal/server/api"
+	"github.com/sinkingpoint/kiora/internal/server/api/promcompat"
+	"github.com/sinkingpoint/kiora/internal/services"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/sinkingpoint/kiora/mocks/mock_clustering"
+	"github.com/sinkingpoint/kiora/mocks/mock_config"
+	"github.com/stretchr/testify/require"
+)
+
+// newRouter returns a router serving the Alertmanager API over the given DB, where every alert is routed to a `pager` notifier
+// that groups by alertname.
+func newRouter(t *testing.T, db kioradb.DB) *mux.Router {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+
+	notifier := mock_config.NewMockNotifier(ctrl)
+	notifier.EXPECT().Name().Return(config.NotifierName("pager")).AnyTimes()
+
+	conf := mock_config.NewMockConfigAllowingEverything(ctrl)
+	conf.EXPECT().RouteAlert(gomock.Any(), gomock.Any()).Return([]config.Route{
+		{NotifierSettings: config.NewNotifier(notifier).WithGroupLabels("alertname")},
+	}).AnyTimes()
+
+	broadcaster := mock_clustering.NewMockBroadcaster(ctrl)
+	broadcaster.EXPECT().BroadcastSilences(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, silences ...model.Silence) error {
+		return db.StoreSilences(ctx, silences...)
+	}).AnyTimes()
+
+	router := mux.NewRouter()
+	promcompat.Register(router, api.NewAPIImpl(services.NewKioraBus(db, broadcaster, zerolog.New(os.Stderr), conf), nil), zerolog.New(os.Stderr))
+	return router
+}
+
+// do makes a request to the Alertmanager API, decoding the response into the given value if it isn't nil.
+func do(t *testing.T, router *mux.Router, method, path, body string, into any) int {
+	t.Helper()
+	request := httptest.NewRequest(method, "http://localhost/api/prom-compat/api/v2"+path, bytes.NewReader([]byte(body)))
+	request.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, request)
+	if into != nil && resp.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), into), resp.Body.String())
+	}
+
+	return resp.Code
+}
+
+type gettableAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Fingerprint string            `json:"fingerprint"`
+	Receivers   []struct {
+		Name string `json:"name"`
+	} `json:"receivers"`
+	Status struct {
+		State      string   `json:"state"`
+		SilencedBy []string `json:"silencedBy"`
+	} `json:"status"`
+}
+
+func TestGetAlerts(t *testing.T) {
+	now := time.Now()
+	silence, err := model.NewSilence("alice", "maintenance", []model.Matcher{model.LabelValueEqualMatcher("alertname", "silenced")}, now.Add(-time.Hour), now.Add(time.Hour))
+	require.NoError(t, err)
+
+	alerts := []model.Alert{
+		{Labels: model.Labels{"alertname": "firing", "severity": "critical"}, Status: model.AlertStatusFiring},
+		{Labels: model.Labels{"alertname": "silenced", "severity": "warning"}, Status: model.AlertStatusSilenced},
+		{Labels: model.Labels{"alertname": "acked", "severity": "critical"}, Status: model.AlertStatusAcked},
+		{Labels: model.Labels{"alertname": "resolved", "severity": "critical"}, Status: model.AlertStatusResolved},
+	}
+
+	db := kioradb.NewInMemoryDB()
+	for i := range alerts {
+		alerts[i].Annotations = map[string]string{}
+		alerts[i].StartTime = now.Add(-time.Hour)
+		require.NoError(t, alerts[i].Materialise())
+	}
+
+	require.NoError(t, db.StoreAlerts(context.Background(), alerts...))
+	require.NoError(t, db.StoreSilences(context.Background(), silence))
+
+	tests := []struct {
+		name           string
+		params         url.Values
+		expectedStates map[string]string
+	}{
+		{
+			name:           "alerts that are still firing are returned",
+			params:         url.Values{},
+			expectedStates: map[string]string{"firing": "active", "silenced": "suppressed", "acked": "suppressed"},
+		},
+		{
+			name:           "filters match labels",
+			params:         url.Values{"filter": {`severity="critical"`}},
+			expectedStates: map[string]string{"firing": "active", "acked": "suppressed"},
+		},
+		{
+			name:           "regex filters are anchored",
+			params:         url.Values{"filter": {`alertname=~"fir"`}},
+			expectedStates: map[string]string{},
+		},
+		{
+			name:           "silenced alerts can be excluded",
+			params:         url.Values{"silenced": {"false"}},
+			expectedStates: map[string]string{"firing": "active", "acked": "suppressed"},
+		},
+		{
+			name:           "acked alerts are treated as inhibited",
+			params:         url.Values{"inhibited": {"false"}, "active": {"false"}},
+			expectedStates: map[string]string{"silenced": "suppressed"},
+		},
+		{
+			name:           "receivers filter alerts",
+			params:         url.Values{"receiver": {"slack"}},
+			expectedStates: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newRouter(t, db)
+
+			got := []gettableAlert{}
+			require.Equal(t, http.StatusOK, do(t, router, http.MethodGet, "/alerts?"+tt.params.Encode(), "", &got))
+
+			states := map[string]string{}
+			for _, alert := range got {
+				states[alert.Labels["alertname"]] = alert.Status.State
+				require.Len(t, alert.Receivers, 1)
+				require.Equal(t, "pager", alert.Receivers[0].Name)
+
+				if alert.Labels["alertname"] == "silenced" {
+					require.Equal(t, []string{silence.ID}, alert.Status.SilencedBy)
+				}
+			}
+
+			require.Equal(t, tt.expectedStates, states)
+		})
+	}
+
+	t.Run("invalid filters are rejected", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, do(t, newRouter(t, db), http.MethodGet, "/alerts?filter=foo", "", nil))
+	})
+}
+
+func TestGetAlertGroups(t *testing.T) {
+	db := kioradb.NewInMemoryDB()
+	alerts := []model.Alert{
+		{Labels: model.Labels{"alertname": "foo", "instance": "a"}, Status: model.AlertStatusFiring},
+		{Labels: model.Labels{"alertname": "foo", "instance": "b"}, Status: model.AlertStatusFiring},
+		{Labels: model.Labels{"alertname": "bar", "instance": "a"}, Status: model.AlertStatusFiring},
+	}
+
+	for i := range alerts {
+		alerts[i].Annotations = map[string]string{}
+		alerts[i].StartTime = time.Now()
+		require.NoError(t, alerts[i].Materialise())
+	}
+
+	require.NoError(t, db.StoreAlerts(context.Background(), alerts...))
+
+	groups := []struct {
+		Labels   map[string]string `json:"labels"`
+		Receiver struct {
+			Name string `json:"name"`
+		} `json:"receiver"`
+		Alerts []gettableAlert `json:"alerts"`
+	}{}
+
+	require.Equal(t, http.StatusOK, do(t, newRouter(t, db), http.MethodGet, "/alerts/groups", "", &groups))
+	require.Len(t, groups, 2)
+
+	require.Equal(t, map[string]string{"alertname": "bar"}, groups[0].Labels)
+	require.Equal(t, "pager", groups[0].Receiver.Name)
+	require.Len(t, groups[0].Alerts, 1)
+
+	require.Equal(t, map[string]string{"alertname": "foo"}, groups[1].Labels)
+	require.Len(t, groups[1].Alerts, 2)
+}
+
+func TestSilences(t *testing.T) {
+	db := kioradb.NewInMemoryDB()
+	router := newRouter(t, db)
+
+	type matcher struct {
+		Name    string `json:"name"`
+		Value   string `json:"value"`
+		IsRegex bool   `json:"isRegex"`
+		IsEqual bool   `json:"isEqual"`
+	}
+
+	type silence struct {
+		ID       string    `json:"id"`
+		Matchers []matcher `json:"matchers"`
+		EndsAt   time.Time `json:"endsAt"`
+		Status   struct {
+			State string `json:"state"`
+		} `json:"status"`
+	}
+
+	startsAt, endsAt := time.Now().Add(-time.Minute).Format(time.RFC3339), time.Now().Add(time.Hour).Format(time.RFC3339)
+	body := `{"matchers": [{"name": "instance", "value": "foo.*", "isRegex": true, "isEqual": false}], "startsAt": "` + startsAt + `", "endsAt": "` + endsAt + `", "createdBy": "alice", "comment": "maintenance"}`
+
+	created := struct {
+		SilenceID string `json:"silenceID"`
+	}{}
+
+	require.Equal(t, http.StatusOK, do(t, router, http.MethodPost, "/silences", body, &created))
+	require.NotEmpty(t, created.SilenceID)
+
+	// Regexes are anchored in Kiora, and returned as they were posted.
+	stored := db.QuerySilences(context.Background(), query.NewSilenceQuery(query.MatchAll()))
+	require.Len(t, stored, 1)
+	require.Equal(t, "^(?:foo.*)$", stored[0].Matchers[0].Value)
+	require.True(t, stored[0].Matches(model.Labels{"instance": "bar-foo"}))
+	require.False(t, stored[0].Matches(model.Labels{"instance": "foo-bar"}))
+
+	got := silence{}
+	require.Equal(t, http.StatusOK, do(t, router, http.MethodGet, "/silence/"+created.SilenceID, "", &got))
+	require.Equal(t, []matcher{{Name: "instance", Value: "foo.*", IsRegex: true, IsEqual: false}}, got.Matchers)
+	require.Equal(t, "active", got.Status.State)
+
+	require.Equal(t, http.StatusOK, do(t, router, http.MethodDelete, "/silence/"+created.SilenceID, "", nil))
+
+	silences := []silence{}
+	require.Equal(t, http.StatusOK, do(t, router, http.MethodGet, "/silences", "", &silences))
+	require.Len(t, silences, 1)
+	require.Equal(t, "expired", silences[0].Status.State)
+
+	require.Equal(t, http.StatusNotFound, do(t, router, http.MethodGet, "/silence/missing", "", nil))
+	require.Equal(t, http.StatusNotFound, do(t, router, http.MethodPost, "/silences", `{"id": "missing", "matchers": [{"name": "foo", "value": "bar", "isRegex": false}], "startsAt": "`+startsAt+`", "endsAt": "`+endsAt+`", "createdBy": "alice", "comment": ""}`, nil))
+}
@@ -0,0 +1,201 @@
+package promcompat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/gorilla/mux"
+	"github.com/sinkingpoint/kiora/internal/server/api"
+	"github.com/sinkingpoint/kiora/internal/stubs"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	kmodel "github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+// silenceStateOrder is the order that Alertmanager returns silences in - active, then pending, then expired.
+var silenceStateOrder = map[string]int{
+	silenceStateActive:  0,
+	silenceStatePending: 1,
+	silenceStateExpired: 2,
+}
+
+// GetSilences handles the GET /api/v2/silences request.
+func (p *promCompat) GetSilences(w http.ResponseWriter, r *http.Request) {
+	matchers, err := parseFilters(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter := query.SilenceFilter(query.MatchAll())
+	if len(matchers) > 0 {
+		filters := make([]query.SilenceFilter, 0, len(matchers))
+		for _, m := range matchers {
+			filters = append(filters, query.Matcher(m))
+		}
+
+		filter = query.AllSilences(filters...)
+	}
+
+	silences, err := p.api.GetSilences(r.Context(), query.NewSilenceQuery(filter))
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to get silences")
+		http.Error(w, "failed to get silences", http.StatusInternalServerError)
+		return
+	}
+
+	now := stubs.Time.Now()
+	response := make([]gettableSilence, 0, len(silences.Items))
+	for _, silence := range silences.Items {
+		response = append(response, newGettableSilence(silence, now))
+	}
+
+	sort.SliceStable(response, func(i, j int) bool {
+		if response[i].Status.State != response[j].Status.State {
+			return silenceStateOrder[response[i].Status.State] < silenceStateOrder[response[j].Status.State]
+		}
+
+		return response[i].EndsAt.Before(response[j].EndsAt)
+	})
+
+	p.writeJSON(w, http.StatusOK, response)
+}
+
+// getSilence returns the silence with the given ID, if it's visible to the request in the given context.
+func (p *promCompat) getSilence(ctx context.Context, id string) (*kmodel.Silence, error) {
+	silences, err := p.api.GetSilences(ctx, query.NewSilenceQuery(query.ID(id)))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(silences.Items) == 0 {
+		return nil, nil
+	}
+
+	return &silences.Items[0], nil
+}
+
+// GetSilence handles the GET /api/v2/silence/{silenceID} request.
+func (p *promCompat) GetSilence(w http.ResponseWriter, r *http.Request) {
+	silence, err := p.getSilence(r.Context(), mux.Vars(r)["silenceID"])
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to get silence")
+		http.Error(w, "failed to get silence", http.StatusInternalServerError)
+		return
+	}
+
+	if silence == nil {
+		http.Error(w, "silence not found", http.StatusNotFound)
+		return
+	}
+
+	p.writeJSON(w, http.StatusOK, newGettableSilence(*silence, stubs.Time.Now()))
+}
+
+// PostSilences handles the POST /api/v2/silences request, creating a silence, or updating an existing one if an ID is given.
+func (p *promCompat) PostSilences(w http.ResponseWriter, r *http.Request) {
+	body := postableSilence{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "failed to decode body", http.StatusBadRequest)
+		return
+	}
+
+	matchers := make([]kmodel.Matcher, 0, len(body.Matchers))
+	for _, m := range body.Matchers {
+		kioraMatcher, err := m.toKioraMatcher()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		matchers = append(matchers, kioraMatcher)
+	}
+
+	silence, err := kmodel.NewSilence(body.CreatedBy, body.Comment, matchers, body.StartsAt, body.EndsAt)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if body.ID != "" {
+		existing, err := p.getSilence(r.Context(), body.ID)
+		if err != nil {
+			p.logger.Error().Err(err).Msg("failed to get silence")
+			http.Error(w, "failed to get silence", http.StatusInternalServerError)
+			return
+		}
+
+		if existing == nil {
+			http.Error(w, "silence not found", http.StatusNotFound)
+			return
+		}
+
+		silence.ID = existing.ID
+		silence.Tenant = existing.Tenant
+	}
+
+	if !p.postSilence(w, r, &silence) {
+		return
+	}
+
+	p.writeJSON(w, http.StatusOK, postSilenceResponse{SilenceID: silence.ID})
+}
+
+// DeleteSilence handles the DELETE /api/v2/silence/{silenceID} request, expiring the silence.
+func (p *promCompat) DeleteSilence(w http.ResponseWriter, r *http.Request) {
+	silence, err := p.getSilence(r.Context(), mux.Vars(r)["silenceID"])
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to get silence")
+		http.Error(w, "failed to get silence", http.StatusInternalServerError)
+		return
+	}
+
+	if silence == nil {
+		http.Error(w, "silence not found", http.StatusNotFound)
+		return
+	}
+
+	now := stubs.Time.Now()
+	if !silence.EndTime.IsZero() && !silence.EndTime.After(now) {
+		// The silence has already expired, so there's nothing to do.
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if silence.StartTime.After(now) {
+		silence.StartTime = now
+	}
+
+	silence.EndTime = now
+	if !p.postSilence(w, r, silence) {
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// postSilence posts the given silence, writing an error to the response and returning false if it fails.
+func (p *promCompat) postSilence(w http.ResponseWriter, r *http.Request, silence *kmodel.Silence) bool {
+	err := p.api.PostSilence(r.Context(), silence)
+	if err == nil {
+		return true
+	}
+
+	var validationErr *config.ValidationError
+	if errors.As(err, &validationErr) {
+		http.Error(w, validationErr.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	if errors.Is(err, api.ErrSilenceTenantRequired) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return false
+	}
+
+	p.logger.Error().Err(err).Msg("failed to post silence")
+	http.Error(w, "failed to post silence", http.StatusInternalServerError)
+	return false
+}
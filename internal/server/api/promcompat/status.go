@@ -0,0 +1,125 @@
+package promcompat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/sinkingpoint/kiora/internal/server/api"
+	"gopkg.in/yaml.v3"
+)
+
+// receivers returns the notifiers in the config, or nothing if the config can't describe itself.
+func (p *promCompat) receivers(ctx context.Context) ([]receiver, error) {
+	graph, err := p.api.GetConfigGraph(ctx, nil)
+	if errors.Is(err, api.ErrConfigNotGraphable) {
+		return []receiver{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	receivers := []receiver{}
+	for _, node := range graph.Nodes {
+		if node.Notifier {
+			receivers = append(receivers, receiver{Name: node.Name})
+		}
+	}
+
+	return receivers, nil
+}
+
+// GetReceivers handles the GET /api/v2/receivers request, returning the notifiers in the config.
+func (p *promCompat) GetReceivers(w http.ResponseWriter, r *http.Request) {
+	receivers, err := p.receivers(r.Context())
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to get receivers")
+		http.Error(w, "failed to get receivers", http.StatusInternalServerError)
+		return
+	}
+
+	p.writeJSON(w, http.StatusOK, receivers)
+}
+
+// GetStatus handles the GET /api/v2/status request.
+func (p *promCompat) GetStatus(w http.ResponseWriter, r *http.Request) {
+	receivers, err := p.receivers(r.Context())
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to get receivers")
+		http.Error(w, "failed to get status", http.StatusInternalServerError)
+		return
+	}
+
+	// Tools read the config as an Alertmanager config, which Kiora's isn't, so we give them one with just the receivers.
+	amConfig := struct {
+		Receivers []receiver `yaml:"receivers"`
+	}{
+		Receivers: receivers,
+	}
+
+	original, err := yaml.Marshal(amConfig)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to marshal config")
+		http.Error(w, "failed to get status", http.StatusInternalServerError)
+		return
+	}
+
+	p.writeJSON(w, http.StatusOK, alertmanagerStatus{
+		Cluster:     p.clusterStatus(r.Context()),
+		VersionInfo: buildVersionInfo(),
+		Config:      alertmanagerConfig{Original: string(original)},
+		Uptime:      p.startTime,
+	})
+}
+
+// clusterStatus returns the members of the cluster, or a disabled status if there isn't a cluster.
+func (p *promCompat) clusterStatus(ctx context.Context) clusterStatus {
+	nodes, err := p.api.GetClusterStatus(ctx)
+	if err != nil || nodes == nil {
+		return clusterStatus{Status: "disabled", Peers: []peerStatus{}}
+	}
+
+	status := clusterStatus{Status: "ready", Peers: make([]peerStatus, 0, len(nodes))}
+	for _, node := range nodes {
+		// Nodes are opaque, so we read their names and addresses from their JSON representation.
+		bytes, err := json.Marshal(node)
+		if err != nil {
+			continue
+		}
+
+		peer := peerStatus{}
+		if err := json.Unmarshal(bytes, &peer); err != nil {
+			continue
+		}
+
+		status.Peers = append(status.Peers, peer)
+	}
+
+	return status
+}
+
+// buildVersionInfo describes the running binary from its build info.
+func buildVersionInfo() versionInfo {
+	info := versionInfo{
+		GoVersion: runtime.Version(),
+	}
+
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.Version = buildInfo.Main.Version
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.time":
+			info.BuildDate = setting.Value
+		}
+	}
+
+	return info
+}
@@ -0,0 +1,198 @@
+package promcompat
+
+import (
+	"strings"
+	"time"
+
+	kmodel "github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+// The types in this file mirror the JSON bodies of the Alertmanager v2 API, so that tools built for Alertmanager (like amtool, Grafana,
+// and Karma) can talk to Kiora.
+
+const (
+	alertStateActive     = "active"
+	alertStateSuppressed = "suppressed"
+
+	silenceStateActive  = "active"
+	silenceStatePending = "pending"
+	silenceStateExpired = "expired"
+)
+
+type receiver struct {
+	Name string `json:"name"`
+}
+
+type alertStatus struct {
+	State       string   `json:"state"`
+	SilencedBy  []string `json:"silencedBy"`
+	InhibitedBy []string `json:"inhibitedBy"`
+}
+
+type gettableAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+	Fingerprint  string            `json:"fingerprint"`
+	GeneratorURL string            `json:"generatorURL"`
+	Receivers    []receiver        `json:"receivers"`
+	Status       alertStatus       `json:"status"`
+}
+
+type alertGroup struct {
+	Labels   map[string]string `json:"labels"`
+	Receiver receiver          `json:"receiver"`
+	Alerts   []gettableAlert   `json:"alerts"`
+}
+
+type matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+
+	// IsEqual is optional, and defaults to true, for compatibility with old clients.
+	IsEqual *bool `json:"isEqual,omitempty"`
+}
+
+type silenceStatus struct {
+	State string `json:"state"`
+}
+
+type postableSilence struct {
+	ID        string    `json:"id,omitempty"`
+	Matchers  []matcher `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+}
+
+type gettableSilence struct {
+	ID        string        `json:"id"`
+	Matchers  []matcher     `json:"matchers"`
+	StartsAt  time.Time     `json:"startsAt"`
+	EndsAt    time.Time     `json:"endsAt"`
+	CreatedBy string        `json:"createdBy"`
+	Comment   string        `json:"comment"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+	Status    silenceStatus `json:"status"`
+}
+
+type postSilenceResponse struct {
+	SilenceID string `json:"silenceID"`
+}
+
+type peerStatus struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type clusterStatus struct {
+	Name   string       `json:"name,omitempty"`
+	Status string       `json:"status"`
+	Peers  []peerStatus `json:"peers"`
+}
+
+type versionInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision"`
+	Branch    string `json:"branch"`
+	BuildUser string `json:"buildUser"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+}
+
+type alertmanagerConfig struct {
+	Original string `json:"original"`
+}
+
+type alertmanagerStatus struct {
+	Cluster     clusterStatus      `json:"cluster"`
+	VersionInfo versionInfo        `json:"versionInfo"`
+	Config      alertmanagerConfig `json:"config"`
+	Uptime      time.Time          `json:"uptime"`
+}
+
+// Alertmanager regexes are anchored, but Kiora's aren't, so we anchor them on the way in, and strip the anchors on the way out.
+const (
+	regexAnchorPrefix = "^(?:"
+	regexAnchorSuffix = ")$"
+)
+
+// anchorRegex converts an Alertmanager regex into the equivalent Kiora regex.
+func anchorRegex(regex string) string {
+	return regexAnchorPrefix + regex + regexAnchorSuffix
+}
+
+// unanchorRegex converts a Kiora regex back into an Alertmanager one, if it was anchored by anchorRegex.
+func unanchorRegex(regex string) string {
+	if strings.HasPrefix(regex, regexAnchorPrefix) && strings.HasSuffix(regex, regexAnchorSuffix) {
+		return regex[len(regexAnchorPrefix) : len(regex)-len(regexAnchorSuffix)]
+	}
+
+	return regex
+}
+
+// toKioraMatcher converts an Alertmanager matcher into a Kiora one.
+func (m matcher) toKioraMatcher() (kmodel.Matcher, error) {
+	var kioraMatcher kmodel.Matcher
+	if m.IsRegex {
+		var err error
+		if kioraMatcher, err = kmodel.LabelValueRegexMatcher(m.Name, anchorRegex(m.Value)); err != nil {
+			return kmodel.Matcher{}, err
+		}
+	} else {
+		kioraMatcher = kmodel.LabelValueEqualMatcher(m.Name, m.Value)
+	}
+
+	if m.IsEqual != nil && !*m.IsEqual {
+		kioraMatcher.Negate()
+	}
+
+	return kioraMatcher, nil
+}
+
+// newMatcher converts a Kiora matcher into an Alertmanager one.
+func newMatcher(m kmodel.Matcher) matcher {
+	isEqual := !m.IsNegative
+	value := m.Value
+	if m.IsRegex {
+		value = unanchorRegex(value)
+	}
+
+	return matcher{
+		Name:    m.Label,
+		Value:   value,
+		IsRegex: m.IsRegex,
+		IsEqual: &isEqual,
+	}
+}
+
+// newGettableSilence converts a Kiora silence into an Alertmanager one.
+func newGettableSilence(silence kmodel.Silence, now time.Time) gettableSilence {
+	matchers := make([]matcher, 0, len(silence.Matchers))
+	for _, m := range silence.Matchers {
+		matchers = append(matchers, newMatcher(m))
+	}
+
+	state := silenceStateActive
+	switch {
+	case silence.StartTime.After(now):
+		state = silenceStatePending
+	case !silence.EndTime.IsZero() && !silence.EndTime.After(now):
+		state = silenceStateExpired
+	}
+
+	return gettableSilence{
+		ID:        silence.ID,
+		Matchers:  matchers,
+		StartsAt:  silence.StartTime,
+		EndsAt:    silence.EndTime,
+		CreatedBy: silence.Creator,
+		Comment:   silence.Comment,
+		UpdatedAt: silence.StartTime,
+		Status:    silenceStatus{State: state},
+	}
+}
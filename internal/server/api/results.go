@@ -0,0 +1,35 @@
+package api
+
+import (
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+// AlertAcceptance is an alert that was accepted when posted.
+type AlertAcceptance struct {
+	// Index is the position of the alert in the posted batch.
+	Index int
+
+	// Alert is the alert that was accepted, after any transformations were applied to it.
+	Alert model.Alert
+}
+
+// AlertRejection is an alert that was rejected when posted, along with the reason it was rejected.
+type AlertRejection struct {
+	// Index is the position of the alert in the posted batch.
+	Index int
+
+	// Alert is the alert that was rejected.
+	Alert model.Alert
+
+	// Err is the reason the alert was rejected.
+	Err error
+}
+
+// PostAlertsResult is the outcome of posting a batch of alerts, with every alert in the batch either accepted or rejected.
+type PostAlertsResult struct {
+	// Accepted are the alerts that were accepted, in the order they were posted.
+	Accepted []AlertAcceptance
+
+	// Rejected are the alerts that were rejected, in the order they were posted.
+	Rejected []AlertRejection
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sinkingpoint/kiora/internal/auth"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+// DefaultTenantHeader is the header that requests set the tenant they're scoped to with, by default.
+const DefaultTenantHeader = "X-Kiora-Tenant"
+
+// TenantScope is the tenant that a request is allowed to see and modify the data of.
+type TenantScope struct {
+	// Tenant is the tenant that the request is scoped to.
+	Tenant config.Tenant
+
+	// Admin is true if the request can see and modify the data of every tenant.
+	Admin bool
+}
+
+type tenantScopeKey struct{}
+
+// ContextWithTenantScope returns a copy of the given context, scoped to the given tenant.
+func ContextWithTenantScope(ctx context.Context, scope TenantScope) context.Context {
+	return context.WithValue(ctx, tenantScopeKey{}, scope)
+}
+
+// TenantScopeFromContext returns the tenant scope of the given context. Contexts that haven't been scoped (e.g. internal calls
+// that didn't come from a request) can see every tenant.
+func TenantScopeFromContext(ctx context.Context) TenantScope {
+	if scope, ok := ctx.Value(tenantScopeKey{}).(TenantScope); ok {
+		return scope
+	}
+
+	return TenantScope{Admin: true}
+}
+
+// TenantMiddleware scopes every request to the tenant of its identity, or to the tenant in the given header. Authenticated requests
+// without a tenant are scoped to the default (empty) tenant, unless they have the `tenants:all` permission, which lets them see every
+// tenant, or pick one with the header. If the config assigns data to tenants, unauthenticated requests have to set the header, and can
+// only see every tenant if they pick the admin tenant (if one is set). Otherwise, unauthenticated requests without the header can see
+// everything, as they could before requests were scoped.
+func TenantMiddleware(header string, adminTenant config.Tenant, conf config.Config) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenant := config.Tenant(r.Header.Get(header))
+			scope := TenantScope{Tenant: tenant}
+
+			if identity, authenticated := auth.IdentityFromContext(r.Context()); authenticated {
+				// Anyone can set the header, so once requests are authenticated, only the permission to see every tenant lets them pick one.
+				scope.Admin = auth.HasPermission(r.Context(), auth.PermissionAllTenants)
+				switch {
+				case identity.Tenant != "":
+					scope.Tenant = config.Tenant(identity.Tenant)
+				case tenant != "" && !scope.Admin:
+					http.Error(w, fmt.Sprintf("permission %q is required to set the %s header", auth.PermissionAllTenants, header), http.StatusForbidden)
+					return
+				case tenant != "":
+					// Admins that pick a tenant only see that tenant.
+					scope.Admin = false
+				}
+			} else {
+				if tenant == "" && hasTenants(conf) {
+					http.Error(w, fmt.Sprintf("the %s header is required", header), http.StatusBadRequest)
+					return
+				}
+
+				scope.Admin = tenant == "" || (adminTenant != "" && tenant == adminTenant)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ContextWithTenantScope(r.Context(), scope)))
+		})
+	}
+}
+
+// hasTenants returns true if alerts can be assigned to different tenants, i.e. if the config sets a `tenant_key`. Otherwise, every
+// alert is in the same tenant.
+func hasTenants(conf config.Config) bool {
+	if conf == nil {
+		return false
+	}
+
+	_, static := conf.Globals().Tenanter.(*config.StaticTenanter)
+	return !static
+}
+
+// alertTenantFilter returns a filter that matches the alerts in the tenant that the given context is scoped to, or nil if it can see every tenant.
+func (a *APIImpl) alertTenantFilter(ctx context.Context) query.AlertFilter {
+	scope := TenantScopeFromContext(ctx)
+	if scope.Admin {
+		return nil
+	}
+
+	// Where we can, we use filters that databases understand, so that they can filter on the tenant themselves.
+	tenanter := a.bus.Config().Globals().Tenanter
+	switch tenanter := tenanter.(type) {
+	case *config.StaticTenanter:
+		if tenanter.Tenant == scope.Tenant {
+			return query.MatchAll()
+		}
+
+		return query.AnyAlerts()
+	case *config.TemplateTenanter:
+		if label, ok := tenanter.Label(); ok {
+			if scope.Tenant == "" {
+				// Alerts without the label (or with an empty value) are in the default tenant.
+				hasValue := model.LabelValueEqualMatcher(label, "")
+				return query.NotAlerts(query.Matcher(*hasValue.Negate()))
+			}
+
+			return query.PartialLabelMatch(model.Labels{label: string(scope.Tenant)})
+		}
+	}
+
+	return query.AlertFilterFunc(func(ctx context.Context, alert *model.Alert) bool {
+		tenant, err := tenanter.GetTenant(ctx, alert)
+		return err == nil && tenant == scope.Tenant
+	})
+}
+
+// scopeAlertFilter restricts the given filter to the alerts in the tenant that the given context is scoped to.
+func (a *APIImpl) scopeAlertFilter(ctx context.Context, filter query.AlertFilter) query.AlertFilter {
+	tenantFilter := a.alertTenantFilter(ctx)
+	switch {
+	case tenantFilter == nil:
+		return filter
+	case filter == nil:
+		return tenantFilter
+	default:
+		return query.AllAlerts(filter, tenantFilter)
+	}
+}
+
+// scopedStatsQuery is a stats query that only processes alerts in a single tenant.
+type scopedStatsQuery struct {
+	query.AlertStatsQuery
+	filter query.AlertFilter
+}
+
+func (s *scopedStatsQuery) Filter() query.AlertFilter {
+	return s.filter
+}
@@ -4,6 +4,9 @@ import (
 	"time"
 
 	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/internal/audit"
+	"github.com/sinkingpoint/kiora/internal/auth"
+	"github.com/sinkingpoint/kiora/internal/server/api"
 	"github.com/sinkingpoint/kiora/lib/kiora/config"
 )
 
@@ -20,6 +23,9 @@ type serverConfig struct {
 	// HTTPListenAddress is the address for the server to listen on. Defaults to localhost:4278.
 	HTTPListenAddress string
 
+	// GRPCListenAddress is the address for the gRPC API to listen on. Defaults to empty, which disables the gRPC API.
+	GRPCListenAddress string
+
 	ClusterListenAddress string
 
 	// ClusterShardLabels is the set of labels that will be used to determine which node in a cluster will send a given alert.
@@ -42,6 +48,26 @@ type serverConfig struct {
 	// TLS is an optional pair of cert and key files that will be used to serve TLS connections.
 	TLS *TLSPair
 
+	// TenantHeader is the header that API requests set the tenant they're scoped to with. Defaults to X-Kiora-Tenant.
+	TenantHeader string
+
+	// AdminTenant is the tenant that can see and modify the data of every tenant, if the API is unauthenticated. Defaults to empty, which disables
+	// the admin tenant.
+	AdminTenant string
+
+	// Authenticators authenticate requests to the API, in order. Defaults to an empty list, which leaves the API unauthenticated.
+	Authenticators []auth.Authenticator
+
+	// Policy restricts what each authenticated identity can do with the API. Defaults to nil, which allows everything.
+	Policy *auth.Policy
+
+	// AuditSink is where every change made through the API is recorded. Defaults to nil, which disables the audit log.
+	AuditSink audit.Sink
+
+	// CORSOrigins are the origins that browsers can make requests to the API from. Defaults to any origin, in which case
+	// browsers won't send credentials with their requests.
+	CORSOrigins []string
+
 	Logger zerolog.Logger
 }
 
@@ -52,5 +78,7 @@ func NewServerConfig() serverConfig {
 		ReadTimeout:       5 * time.Second,
 		WriteTimeout:      60 * time.Second,
 		TLS:               nil,
+		TenantHeader:      api.DefaultTenantHeader,
+		CORSOrigins:       []string{"*"},
 	}
 }
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+)
+
+var _ = prometheus.Collector(&ConfigReloadCollector{})
+
+var (
+	configReloadsDesc = prometheus.NewDesc("kiora_config_reloads_total", "Number of config reloads", []string{
+		"result",
+	}, nil)
+
+	configLastReloadSuccessfulDesc = prometheus.NewDesc("kiora_config_last_reload_successful", "Whether the last config reload succeeded", nil, nil)
+
+	configLastReloadSuccessTimeDesc = prometheus.NewDesc("kiora_config_last_reload_success_timestamp_seconds", "Timestamp of the last successful config load", nil, nil)
+)
+
+// ConfigReloadCollector is a prometheus.Collector that exposes the success and failure of config reloads.
+type ConfigReloadCollector struct {
+	conf *config.ReloadableConfig
+}
+
+func NewConfigReloadCollector(conf *config.ReloadableConfig) *ConfigReloadCollector {
+	return &ConfigReloadCollector{
+		conf: conf,
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *ConfigReloadCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.conf.Stats()
+
+	lastReloadSuccessful := 0.0
+	if stats.LastReloadSuccessful {
+		lastReloadSuccessful = 1.0
+	}
+
+	ch <- prometheus.MustNewConstMetric(configReloadsDesc, prometheus.CounterValue, float64(stats.Successes), "success")
+	ch <- prometheus.MustNewConstMetric(configReloadsDesc, prometheus.CounterValue, float64(stats.Failures), "failure")
+	ch <- prometheus.MustNewConstMetric(configLastReloadSuccessfulDesc, prometheus.GaugeValue, lastReloadSuccessful)
+	ch <- prometheus.MustNewConstMetric(configLastReloadSuccessTimeDesc, prometheus.GaugeValue, float64(stats.LastSuccessTime.Unix()))
+}
+
+// Describe implements prometheus.Collector.
+func (*ConfigReloadCollector) Describe(c chan<- *prometheus.Desc) {
+	c <- configReloadsDesc
+	c <- configLastReloadSuccessfulDesc
+	c <- configLastReloadSuccessTimeDesc
+}
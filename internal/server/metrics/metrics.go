@@ -12,6 +12,10 @@ func Register(router *mux.Router) {
 	router.Handle("/metrics", promhttp.Handler())
 }
 
-func RegisterMetricsCollectors(globals *config.Globals, db kioradb.DB) {
-	prometheus.MustRegister(NewTenantCountCollector(globals, db))
+func RegisterMetricsCollectors(conf config.Config, db kioradb.DB) {
+	prometheus.MustRegister(NewTenantCountCollector(conf, db))
+
+	if reloadable, ok := conf.(*config.ReloadableConfig); ok {
+		prometheus.MustRegister(NewConfigReloadCollector(reloadable))
+	}
 }
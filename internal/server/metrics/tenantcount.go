@@ -22,14 +22,14 @@ var alertCountDesc = prometheus.NewDesc("kiora_alert_state", "Number of alerts i
 // TenantCountCollector is a prometheus.Collector that collects the number of alerts in the system,
 // broken down by tenant and state.
 type TenantCountCollector struct {
-	globals *config.Globals
-	db      kioradb.DB
+	conf config.Config
+	db   kioradb.DB
 }
 
-func NewTenantCountCollector(globals *config.Globals, db kioradb.DB) *TenantCountCollector {
+func NewTenantCountCollector(conf config.Config, db kioradb.DB) *TenantCountCollector {
 	return &TenantCountCollector{
-		globals: globals,
-		db:      db,
+		conf: conf,
+		db:   db,
 	}
 }
 
@@ -42,9 +42,12 @@ func (t *TenantCountCollector) Collect(ch chan<- prometheus.Metric) {
 
 	states := map[state]int64{}
 
+	// Grab the tenanter once, so that a config reload mid-collection doesn't split the counts across two tenant keys.
+	tenanter := t.conf.Globals().Tenanter
+
 	alerts := t.db.QueryAlerts(context.Background(), query.NewAlertQuery(query.MatchAll()))
 	for _, alert := range alerts {
-		tenant, err := t.globals.Tenanter.GetTenant(context.Background(), &alert)
+		tenant, err := tenanter.GetTenant(context.Background(), &alert)
 		if err != nil {
 			log.Debug().Err(err).Interface("alert", alert).Msg("Failed to get tenant")
 			tenant = "error"
@@ -13,10 +13,14 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/cors"
 	"github.com/rs/zerolog/log"
+	"github.com/sinkingpoint/kiora/internal/audit"
+	"github.com/sinkingpoint/kiora/internal/auth"
 	"github.com/sinkingpoint/kiora/internal/clustering"
 	"github.com/sinkingpoint/kiora/internal/clustering/serf"
+	"github.com/sinkingpoint/kiora/internal/events"
 	"github.com/sinkingpoint/kiora/internal/pipeline"
 	"github.com/sinkingpoint/kiora/internal/server/api"
+	"github.com/sinkingpoint/kiora/internal/server/api/apigrpc"
 	"github.com/sinkingpoint/kiora/internal/server/api/apiv1"
 	"github.com/sinkingpoint/kiora/internal/server/api/promcompat"
 	"github.com/sinkingpoint/kiora/internal/server/frontend"
@@ -25,7 +29,10 @@ import (
 	"github.com/sinkingpoint/kiora/internal/services/notify"
 	"github.com/sinkingpoint/kiora/internal/services/notify/notify_config"
 	"github.com/sinkingpoint/kiora/internal/services/timeout"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
 	"github.com/sinkingpoint/kiora/lib/kiora/kioradb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // KioraServer is a server that serves the main Kiora API.
@@ -33,10 +40,17 @@ type KioraServer struct {
 	serverConfig
 
 	httpServer *http.Server
+	grpcServer *grpc.Server
+
+	// api is shared by the HTTP and gRPC APIs.
+	api api.API
 
 	bus       services.Bus
 	clusterer clustering.Clusterer
 
+	// events is the stream of changes processed by this node, which API consumers can subscribe to.
+	events *events.Stream
+
 	backgroundServices *services.BackgroundServices
 
 	shutdownOnce sync.Once
@@ -59,7 +73,8 @@ func NewKioraServer(conf serverConfig, db kioradb.DB) (*KioraServer, error) {
 		ringClusterer.SetShardLabels(conf.ClusterShardLabels)
 	}
 
-	delegate := pipeline.NewDBEventDelegate(db)
+	events := events.NewStream(events.DefaultHistorySize)
+	delegate := pipeline.NewDBEventDelegate(db, conf.ServiceConfig, pipeline.WithEventStream(events))
 	config.EventDelegate = delegate
 	config.ListenURL = conf.ClusterListenAddress
 	config.BootstrapPeers = conf.BootstrapPeers
@@ -82,8 +97,10 @@ func NewKioraServer(conf serverConfig, db kioradb.DB) (*KioraServer, error) {
 
 	return &KioraServer{
 		serverConfig:       conf,
+		api:                api.NewAPIImpl(bus, ringClusterer, api.WithAuditSink(conf.AuditSink), api.WithEventStream(events)),
 		bus:                bus,
 		clusterer:          ringClusterer,
+		events:             events,
 		backgroundServices: services,
 	}, nil
 }
@@ -97,11 +114,35 @@ func (k *KioraServer) Shutdown() {
 
 // ListenAndServe starts the server, using TLS if set in the config. This method blocks until the server ends.
 func (k *KioraServer) ListenAndServe() error {
+	// The servers are constructed up front, so that shutting down doesn't race with starting them.
+	k.httpServer = k.newHTTPServer()
+
+	var grpcListener net.Listener
+	if k.GRPCListenAddress != "" {
+		var err error
+		if k.grpcServer, grpcListener, err = k.newGRPCServer(); err != nil {
+			return err
+		}
+	}
+
 	wg := sync.WaitGroup{}
 	wg.Add(2)
 
+	if k.grpcServer != nil {
+		wg.Add(1)
+		go func() {
+			if err := k.serveGRPC(grpcListener); err != nil {
+				log.Err(err).Msg("Error shutting down gRPC server")
+			}
+
+			wg.Done()
+
+			log.Info().Msg("gRPC Server Shut Down")
+		}()
+	}
+
 	go func() {
-		if err := k.listenAndServeHTTP(context.Background()); err != nil {
+		if err := k.listenAndServeHTTP(); err != nil {
 			log.Err(err).Msg("Error shutting down HTTP server")
 		}
 
@@ -116,8 +157,10 @@ func (k *KioraServer) ListenAndServe() error {
 			k.Shutdown()
 		}
 
-		if k.httpServer != nil {
-			k.httpServer.Shutdown(context.Background()) //nolint:errcheck // Shutting down the server is best effort.
+		k.httpServer.Shutdown(context.Background()) //nolint:errcheck // Shutting down the server is best effort.
+
+		if k.grpcServer != nil {
+			k.grpcServer.GracefulStop()
 		}
 
 		wg.Done()
@@ -128,33 +171,48 @@ func (k *KioraServer) ListenAndServe() error {
 	return nil
 }
 
-func (k *KioraServer) listenAndServeHTTP(ctx context.Context) error {
+// newHTTPServer constructs the server for the HTTP API, metrics, and frontend.
+func (k *KioraServer) newHTTPServer() *http.Server {
 	router := mux.NewRouter()
 	router.PathPrefix("/debug/").Handler(http.DefaultServeMux)
 
-	api := api.NewAPIImpl(k.bus, k.clusterer)
-	apiv1.Register(router, api, k.serverConfig.Logger)
-	promcompat.Register(router, api, k.serverConfig.Logger)
+	// The API is authenticated and scoped to tenants, but metrics and the frontend aren't.
+	apiRouter := router.NewRoute().Subrouter()
+	apiRouter.Use(k.apiMiddleware()...)
 
-	metrics.RegisterMetricsCollectors(k.ServiceConfig.Globals(), k.bus.DB())
+	apiv1.Register(apiRouter, k.api, k.serverConfig.Logger)
+	promcompat.Register(apiRouter, k.api, k.serverConfig.Logger)
+
+	metrics.RegisterMetricsCollectors(k.ServiceConfig, k.bus.DB())
 	metrics.Register(router)
 
 	frontend.Register(router)
 
 	runtime.SetMutexProfileFraction(5)
 
+	// Browsers only send credentials to origins that we explicitly trust, rather than to anything when any origin is allowed.
+	allowCredentials := len(k.CORSOrigins) > 0
+	for _, origin := range k.CORSOrigins {
+		if origin == "*" {
+			allowCredentials = false
+		}
+	}
+
 	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowCredentials: true,
+		AllowedOrigins:   k.CORSOrigins,
+		AllowedHeaders:   []string{"Authorization", "Content-Type", k.TenantHeader},
+		AllowCredentials: allowCredentials,
 	})
 
-	k.httpServer = &http.Server{
+	return &http.Server{
 		Addr:         k.HTTPListenAddress,
 		ReadTimeout:  k.ReadTimeout,
 		WriteTimeout: k.WriteTimeout,
 		Handler:      c.Handler(router),
 	}
+}
 
+func (k *KioraServer) listenAndServeHTTP() error {
 	var err error
 
 	if k.TLS != nil {
@@ -172,6 +230,57 @@ func (k *KioraServer) listenAndServeHTTP(ctx context.Context) error {
 	return err
 }
 
+// apiMiddleware returns the middleware that every API request goes through, over both HTTP and gRPC.
+func (k *KioraServer) apiMiddleware() []mux.MiddlewareFunc {
+	middleware := []mux.MiddlewareFunc{audit.Middleware()}
+	if len(k.Authenticators) > 0 {
+		middleware = append(middleware, auth.Middleware(k.Authenticators, k.serverConfig.Logger))
+	}
+
+	if k.Policy != nil {
+		middleware = append(middleware, auth.PolicyMiddleware(k.Policy))
+	}
+
+	return append(middleware, api.TenantMiddleware(k.TenantHeader, config.Tenant(k.AdminTenant), k.ServiceConfig))
+}
+
+// newGRPCServer constructs the server for the gRPC API, and the listener that it serves on.
+func (k *KioraServer) newGRPCServer() (*grpc.Server, net.Listener, error) {
+	unary, stream := apigrpc.Interceptors(k.apiMiddleware()...)
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary),
+		grpc.ChainStreamInterceptor(stream),
+	}
+
+	if k.TLS != nil {
+		creds, err := credentials.NewServerTLSFromFile(k.TLS.CertPath, k.TLS.KeyPath)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to load TLS credentials")
+		}
+
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	listener, err := net.Listen("tcp", k.GRPCListenAddress)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to listen for gRPC connections")
+	}
+
+	server := grpc.NewServer(opts...)
+	apigrpc.Register(server, k.api, k.serverConfig.Logger)
+
+	return server, listener, nil
+}
+
+func (k *KioraServer) serveGRPC(listener net.Listener) error {
+	// Like ListenAndServe, Serve returns an error if the server was stopped (even if that happened before it started), which we map into a nil.
+	if err := k.grpcServer.Serve(listener); !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+
+	return nil
+}
+
 // resolveConcreteAddress takes a listen address like `localhost:4278` and resolves
 // it into a concrete address like `[::]:4278`.
 func resolveConcreteAddress(address string) (string, error) {
@@ -1,35 +1,37 @@
 package config
 
 import (
-	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/lib/kiora/config/unmarshal"
 )
 
 func init() {
 	RegisterNode("group_wait", func(name string, globals *Globals, attrs map[string]string) (Node, error) {
-		rawDuration, ok := attrs["duration"]
-		if !ok {
-			return nil, errors.New("missing duration attribute for group_wait node")
-		}
-		duration, err := time.ParseDuration(rawDuration)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to parse duration in group_wait node")
+		delete(attrs, "type")
+		rawNode := struct {
+			Duration time.Duration `config:"duration" required:"true"`
+		}{}
+
+		if err := unmarshal.UnmarshalConfig(attrs, &rawNode, unmarshal.UnmarshalOpts{}); err != nil {
+			return nil, errors.Wrap(err, "failed to parse group_wait node")
 		}
 
-		return NotifierGroupWait(duration), nil
+		return NotifierGroupWait(rawNode.Duration), nil
 	})
 
 	RegisterNode("group_labels", func(name string, globals *Globals, attrs map[string]string) (Node, error) {
-		rawLabels, ok := attrs["labels"]
-		if !ok {
-			return nil, errors.New("missing labels attribute for group_labels node")
-		}
+		delete(attrs, "type")
+		rawNode := struct {
+			Labels []string `config:"labels" required:"true"`
+		}{}
 
-		labels := strings.Split(rawLabels, ",")
+		if err := unmarshal.UnmarshalConfig(attrs, &rawNode, unmarshal.UnmarshalOpts{}); err != nil {
+			return nil, errors.Wrap(err, "failed to parse group_labels node")
+		}
 
-		return NotifierGroupLabels(labels), nil
+		return NotifierGroupLabels(rawNode.Labels), nil
 	})
 }
 
@@ -35,7 +35,7 @@ func (d *DurationFilter) Type() string {
 }
 
 func (d *DurationFilter) Filter(ctx context.Context, fielder config.Fielder) error {
-	field, err := fielder.Field(d.Field)
+	field, err := config.RequestField(ctx, fielder, d.Field)
 	if err != nil {
 		return fmt.Errorf("failed to get field %q: %w", d.Field, err)
 	}
@@ -9,12 +9,15 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sinkingpoint/kiora/lib/kiora/config"
 	"github.com/sinkingpoint/kiora/lib/kiora/config/unmarshal"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
 )
 
 // RegexFilter is a filter that matches if the given alert a) has the given label and b) that label matches a regex.
+// If Negate is set, the filter instead matches if the alert doesn't have the label, or the label doesn't match the regex.
 type RegexFilter struct {
-	Label string         `config:"field" required:"true"`
-	Regex *regexp.Regexp `config:"regex" required:"true"`
+	Label  string         `config:"field" required:"true"`
+	Regex  *regexp.Regexp `config:"regex" required:"true"`
+	Negate bool           `config:"negate"`
 }
 
 func NewFilter(globals *config.Globals, attrs map[string]string) (config.Filter, error) {
@@ -32,18 +35,32 @@ func (r *RegexFilter) Type() string {
 }
 
 func (r *RegexFilter) Filter(ctx context.Context, f config.Fielder) error {
-	value, err := f.Field(r.Label)
+	value, err := config.RequestField(ctx, f, r.Label)
 	if err != nil {
+		if r.Negate {
+			return nil
+		}
+
 		return fmt.Errorf("failed to get field %q: %w", r.Label, err)
 	}
 
-	if label, ok := value.(string); ok {
-		if r.Regex.MatchString(label) {
-			return nil
+	var label string
+	switch value := value.(type) {
+	case string:
+		label = value
+	case model.AlertStatus:
+		label = string(value)
+	default:
+		return fmt.Errorf("label %q is not a string", r.Label)
+	}
+
+	if r.Regex.MatchString(label) == r.Negate {
+		if r.Negate {
+			return fmt.Errorf("label %q matches negated regex %q", label, r.Regex.String())
 		}
 
 		return fmt.Errorf("label %q does not match regex %q", label, r.Regex.String())
 	}
 
-	return fmt.Errorf("label %q is not a string", r.Label)
+	return nil
 }
@@ -2,6 +2,7 @@ package regex_test
 
 import (
 	"context"
+	"strconv"
 	"testing"
 
 	"github.com/sinkingpoint/kiora/lib/kiora/config/filters/regex"
@@ -14,6 +15,7 @@ func TestRegexFilter(t *testing.T) {
 		Name        string
 		Label       string
 		Regex       string
+		Negate      bool
 		Alert       model.Alert
 		ShouldMatch bool
 	}{
@@ -50,13 +52,91 @@ func TestRegexFilter(t *testing.T) {
 			},
 			ShouldMatch: false,
 		},
+		{
+			Name:  "annotation match",
+			Label: "__annotation_runbook__",
+			Regex: "^https://",
+			Alert: model.Alert{
+				Labels: model.Labels{},
+				Annotations: map[string]string{
+					"runbook": "https://example.com/runbook",
+				},
+			},
+			ShouldMatch: true,
+		},
+		{
+			Name:  "ack creator match",
+			Label: "__ack_creator__",
+			Regex: ".+@example.com",
+			Alert: model.Alert{
+				Labels: model.Labels{},
+				Acknowledgement: &model.AlertAcknowledgement{
+					Creator: "colin@example.com",
+				},
+			},
+			ShouldMatch: true,
+		},
+		{
+			Name:  "ack creator on unacked alert",
+			Label: "__ack_creator__",
+			Regex: ".*",
+			Alert: model.Alert{
+				Labels: model.Labels{},
+			},
+			ShouldMatch: false,
+		},
+		{
+			Name:  "status match",
+			Label: "__status__",
+			Regex: "firing",
+			Alert: model.Alert{
+				Labels: model.Labels{},
+				Status: model.AlertStatusFiring,
+			},
+			ShouldMatch: true,
+		},
+		{
+			Name:   "negated match",
+			Label:  "test",
+			Regex:  "^test$",
+			Negate: true,
+			Alert: model.Alert{
+				Labels: model.Labels{
+					"test": "test",
+				},
+			},
+			ShouldMatch: false,
+		},
+		{
+			Name:   "negated non match",
+			Label:  "test",
+			Regex:  "^test$",
+			Negate: true,
+			Alert: model.Alert{
+				Labels: model.Labels{
+					"test": "not test",
+				},
+			},
+			ShouldMatch: true,
+		},
+		{
+			Name:   "negated non existent label",
+			Label:  "some_weird_non_existent_label",
+			Regex:  "test",
+			Negate: true,
+			Alert: model.Alert{
+				Labels: model.Labels{},
+			},
+			ShouldMatch: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.Name, func(t *testing.T) {
 			filter, err := regex.NewFilter(nil, map[string]string{
-				"field": tt.Label,
-				"regex": tt.Regex,
+				"field":  tt.Label,
+				"regex":  tt.Regex,
+				"negate": strconv.FormatBool(tt.Negate),
 			})
 
 			require.NoError(t, err)
@@ -0,0 +1,136 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/config/unmarshal"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+// allowedValuesPrefix is the prefix of attributes that restrict the values of a label, e.g. `allowed_severity="critical,warning"`.
+const allowedValuesPrefix = "allowed_"
+
+// SchemaFilter is a filter that checks that alerts conform to a declarative schema - that they have all the required labels,
+// that labels only take allowed values, and that they don't have too many, or too large, labels and annotations.
+type SchemaFilter struct {
+	// RequiredLabels is the list of labels that every alert must have.
+	RequiredLabels []string `config:"required_labels"`
+
+	// MaxLabels is the maximum number of labels an alert can have.
+	MaxLabels int `config:"max_labels"`
+
+	// MaxLabelSize is the maximum size, in bytes, of the name plus the value of any label.
+	MaxLabelSize int `config:"max_label_size"`
+
+	// MaxAnnotationSize is the maximum size, in bytes, of the name plus the value of any annotation.
+	MaxAnnotationSize int `config:"max_annotation_size"`
+
+	// allowedValues maps label names to the set of values that the label is allowed to take, if it's present.
+	allowedValues map[string]map[string]struct{}
+}
+
+func NewFilter(globals *config.Globals, attrs map[string]string) (config.Filter, error) {
+	delete(attrs, "type")
+
+	schemaFilter := SchemaFilter{
+		allowedValues: map[string]map[string]struct{}{},
+	}
+
+	for name, value := range attrs {
+		if !strings.HasPrefix(name, allowedValuesPrefix) {
+			continue
+		}
+
+		label := strings.TrimPrefix(name, allowedValuesPrefix)
+		if label == "" {
+			return nil, fmt.Errorf("invalid attribute %q in schema filter", name)
+		}
+
+		values := map[string]struct{}{}
+		for _, v := range strings.Split(value, ",") {
+			values[v] = struct{}{}
+		}
+
+		schemaFilter.allowedValues[label] = values
+		delete(attrs, name)
+	}
+
+	if err := unmarshal.UnmarshalConfig(attrs, &schemaFilter, unmarshal.UnmarshalOpts{DisallowUnknownFields: true}); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal schema filter")
+	}
+
+	if schemaFilter.MaxLabels < 0 || schemaFilter.MaxLabelSize < 0 || schemaFilter.MaxAnnotationSize < 0 {
+		return nil, errors.New("schema filter limits cannot be negative")
+	}
+
+	return &schemaFilter, nil
+}
+
+func (s *SchemaFilter) Type() string {
+	return "schema"
+}
+
+// Filter returns an error describing every way in which the given alert doesn't match the schema.
+func (s *SchemaFilter) Filter(ctx context.Context, f config.Fielder) error {
+	alert, ok := f.(*model.Alert)
+	if !ok {
+		return fmt.Errorf("schema filter can only validate alerts, got %T", f)
+	}
+
+	var schemaErr error
+	for _, label := range s.RequiredLabels {
+		if _, ok := alert.Labels[label]; !ok {
+			schemaErr = multierror.Append(schemaErr, fmt.Errorf("missing required label %q", label))
+		}
+	}
+
+	for _, label := range sortedKeys(s.allowedValues) {
+		value, ok := alert.Labels[label]
+		if !ok {
+			continue
+		}
+
+		if _, ok := s.allowedValues[label][value]; !ok {
+			schemaErr = multierror.Append(schemaErr, fmt.Errorf("label %q has value %q, which is not one of %q", label, value, sortedKeys(s.allowedValues[label])))
+		}
+	}
+
+	if s.MaxLabels > 0 && len(alert.Labels) > s.MaxLabels {
+		schemaErr = multierror.Append(schemaErr, fmt.Errorf("alert has %d labels, more than the maximum of %d", len(alert.Labels), s.MaxLabels))
+	}
+
+	if s.MaxLabelSize > 0 {
+		for _, name := range sortedKeys(alert.Labels) {
+			if size := len(name) + len(alert.Labels[name]); size > s.MaxLabelSize {
+				schemaErr = multierror.Append(schemaErr, fmt.Errorf("label %q is %d bytes, more than the maximum of %d", name, size, s.MaxLabelSize))
+			}
+		}
+	}
+
+	if s.MaxAnnotationSize > 0 {
+		for _, name := range sortedKeys(alert.Annotations) {
+			if size := len(name) + len(alert.Annotations[name]); size > s.MaxAnnotationSize {
+				schemaErr = multierror.Append(schemaErr, fmt.Errorf("annotation %q is %d bytes, more than the maximum of %d", name, size, s.MaxAnnotationSize))
+			}
+		}
+	}
+
+	return schemaErr
+}
+
+// sortedKeys returns the keys of the given map in order, so that errors are deterministic.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
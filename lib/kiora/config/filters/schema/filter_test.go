@@ -0,0 +1,141 @@
+package schema_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sinkingpoint/kiora/lib/kiora/config/filters/schema"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		attrs       map[string]string
+		alert       model.Alert
+		shouldMatch bool
+	}{
+		{
+			name: "has required labels",
+			attrs: map[string]string{
+				"required_labels": "alertname,severity",
+			},
+			alert: model.Alert{
+				Labels: model.Labels{"alertname": "foo", "severity": "critical"},
+			},
+			shouldMatch: true,
+		},
+		{
+			name: "missing required label",
+			attrs: map[string]string{
+				"required_labels": "alertname,severity",
+			},
+			alert: model.Alert{
+				Labels: model.Labels{"alertname": "foo"},
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "allowed value",
+			attrs: map[string]string{
+				"allowed_severity": "critical,warning",
+			},
+			alert: model.Alert{
+				Labels: model.Labels{"severity": "warning"},
+			},
+			shouldMatch: true,
+		},
+		{
+			name: "disallowed value",
+			attrs: map[string]string{
+				"allowed_severity": "critical,warning",
+			},
+			alert: model.Alert{
+				Labels: model.Labels{"severity": "page-everyone"},
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "allowed values don't require the label",
+			attrs: map[string]string{
+				"allowed_severity": "critical,warning",
+			},
+			alert: model.Alert{
+				Labels: model.Labels{"alertname": "foo"},
+			},
+			shouldMatch: true,
+		},
+		{
+			name: "too many labels",
+			attrs: map[string]string{
+				"max_labels": "1",
+			},
+			alert: model.Alert{
+				Labels: model.Labels{"alertname": "foo", "severity": "critical"},
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "label too large",
+			attrs: map[string]string{
+				"max_label_size": "16",
+			},
+			alert: model.Alert{
+				Labels: model.Labels{"alertname": strings.Repeat("a", 16)},
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "annotation too large",
+			attrs: map[string]string{
+				"max_annotation_size": "16",
+			},
+			alert: model.Alert{
+				Labels:      model.Labels{},
+				Annotations: map[string]string{"description": strings.Repeat("a", 16)},
+			},
+			shouldMatch: false,
+		},
+		{
+			name: "within limits",
+			attrs: map[string]string{
+				"max_labels":          "2",
+				"max_label_size":      "32",
+				"max_annotation_size": "32",
+			},
+			alert: model.Alert{
+				Labels:      model.Labels{"alertname": "foo"},
+				Annotations: map[string]string{"description": "bar"},
+			},
+			shouldMatch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := schema.NewFilter(nil, tt.attrs)
+			require.NoError(t, err)
+
+			err = filter.Filter(context.TODO(), &tt.alert)
+			if tt.shouldMatch {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestSchemaFilterOnlyValidatesAlerts(t *testing.T) {
+	filter, err := schema.NewFilter(nil, map[string]string{"required_labels": "alertname"})
+	require.NoError(t, err)
+
+	require.Error(t, filter.Filter(context.TODO(), &model.Silence{}))
+}
+
+func TestSchemaFilterUnknownAttribute(t *testing.T) {
+	_, err := schema.NewFilter(nil, map[string]string{"required_label": "alertname"})
+	require.Error(t, err)
+}
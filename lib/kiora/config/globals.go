@@ -2,6 +2,7 @@ package config
 
 import (
 	"net/http"
+	"path/filepath"
 	"text/template"
 
 	"github.com/rs/zerolog"
@@ -16,6 +17,12 @@ type Globals struct {
 	logger     zerolog.Logger
 	templates  *template.Template
 
+	// configDir is the directory of the config file that defined the node, that relative paths are resolved against.
+	configDir string
+
+	// validateOnly is true if the config is only being loaded to check it, and will never process anything.
+	validateOnly bool
+
 	Tenanter Tenanter
 }
 
@@ -51,6 +58,13 @@ func WithTenanter(t Tenanter) GlobalsOpt {
 	}
 }
 
+// WithValidateOnly marks the config as only being loaded to check it, so nodes shouldn't have side effects like creating files.
+func WithValidateOnly() GlobalsOpt {
+	return func(g *Globals) {
+		g.validateOnly = true
+	}
+}
+
 // NewGlobals creates a new Globals object with the given options.
 func NewGlobals(opts ...GlobalsOpt) *Globals {
 	g := &Globals{
@@ -80,6 +94,29 @@ func (g *Globals) Logger(component string) zerolog.Logger {
 	return g.logger.With().Str("component", component).Logger()
 }
 
+// ValidateOnly returns true if the config is only being loaded to check it (e.g. by `kiora check`). Nodes should still validate
+// their attributes, but skip anything with side effects, like creating files, as they'll never be used.
+func (g *Globals) ValidateOnly() bool {
+	return g.validateOnly
+}
+
+// InConfigDir returns a copy of the globals that resolves relative paths against the given directory, for nodes defined in the config
+// file in that directory.
+func (g *Globals) InConfigDir(dir string) *Globals {
+	globals := *g
+	globals.configDir = dir
+	return &globals
+}
+
+// ResolvePath returns the given path, resolved against the directory of the config file that defined the node if it's relative.
+func (g *Globals) ResolvePath(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(g.configDir, path)
+}
+
 // Template returns a template that can be used to render templates.
 func (g *Globals) Template(name string) *template.Template {
 	return g.templates.Lookup(name)
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Grapher is an interface that can be implemented by configs that can describe themselves as a graph, for visualisation.
+type Grapher interface {
+	// Graph returns a description of the nodes and edges in the config.
+	Graph() Graph
+}
+
+// Graph is a description of a config graph, with enough detail to render it.
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// GraphNode is a node in a config graph.
+type GraphNode struct {
+	// Name is the name of the node in the config.
+	Name string
+
+	// Type is the type of the node, e.g. `slack`, or `anchor`.
+	Type string
+
+	// Notifier is true if alerts that reach the node are notified.
+	Notifier bool
+
+	// Highlighted is true if the node is on the path of an alert that the graph has been annotated with.
+	Highlighted bool
+}
+
+// GraphEdge is an edge in a config graph, along with the filter on it.
+type GraphEdge struct {
+	From string
+	To   string
+
+	// Filter is the type of the filter on the edge, or empty if the edge doesn't filter anything.
+	Filter string
+
+	// FilterAttrs are the attributes that the filter was configured with.
+	FilterAttrs map[string]string
+
+	// Highlighted is true if the edge is on the path of an alert that the graph has been annotated with.
+	Highlighted bool
+}
+
+// FilterDescription returns a human readable description of the filter on the edge, e.g. `regex(field="severity", regex="critical")`.
+func (g GraphEdge) FilterDescription() string {
+	if g.Filter == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s(%s)", g.Filter, formatAttrs(g.FilterAttrs, ", "))
+}
+
+// Highlight marks every node and edge along the paths of the given routes.
+func (g *Graph) Highlight(routes []Route) {
+	nodes := map[string]struct{}{}
+	edges := map[[2]string]struct{}{}
+	for _, route := range routes {
+		for i, name := range route.Path {
+			nodes[name] = struct{}{}
+			if i > 0 {
+				edges[[2]string{route.Path[i-1], name}] = struct{}{}
+			}
+		}
+	}
+
+	for i := range g.Nodes {
+		_, g.Nodes[i].Highlighted = nodes[g.Nodes[i].Name]
+	}
+
+	for i := range g.Edges {
+		_, g.Edges[i].Highlighted = edges[[2]string{g.Edges[i].From, g.Edges[i].To}]
+	}
+}
+
+// DOT renders the graph in the Graphviz DOT language, colouring any highlighted nodes and edges.
+func (g *Graph) DOT() string {
+	dot := strings.Builder{}
+	dot.WriteString("digraph config {\n")
+	for _, node := range g.Nodes {
+		attrs := map[string]string{"type": node.Type}
+		if node.Highlighted {
+			attrs["color"] = "red"
+			attrs["penwidth"] = "2"
+		}
+
+		fmt.Fprintf(&dot, "\t%s [%s];\n", quoteDOT(node.Name), formatAttrs(attrs, " "))
+	}
+
+	for _, edge := range g.Edges {
+		attrs := map[string]string{}
+		for k, v := range edge.FilterAttrs {
+			attrs[k] = v
+		}
+
+		if edge.Filter != "" {
+			attrs["type"] = edge.Filter
+		}
+
+		if edge.Highlighted {
+			attrs["color"] = "red"
+			attrs["penwidth"] = "2"
+		}
+
+		if len(attrs) == 0 {
+			fmt.Fprintf(&dot, "\t%s -> %s;\n", quoteDOT(edge.From), quoteDOT(edge.To))
+		} else {
+			fmt.Fprintf(&dot, "\t%s -> %s [%s];\n", quoteDOT(edge.From), quoteDOT(edge.To), formatAttrs(attrs, " "))
+		}
+	}
+
+	dot.WriteString("}\n")
+	return dot.String()
+}
+
+// formatAttrs formats the given attributes as `key="value"` pairs in order, joined by the given separator.
+func formatAttrs(attrs map[string]string, sep string) string {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, quoteDOT(attrs[k])))
+	}
+
+	return strings.Join(pairs, sep)
+}
+
+// quoteDOT quotes the given string as a DOT ID. Double quotes are the only thing that DOT escapes in quoted strings.
+func quoteDOT(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
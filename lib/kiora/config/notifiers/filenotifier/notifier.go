@@ -11,6 +11,7 @@ import (
 	"github.com/hashicorp/go-multierror"
 	"github.com/sinkingpoint/kiora/internal/encoding"
 	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/config/unmarshal"
 	"github.com/sinkingpoint/kiora/lib/kiora/model"
 	"go.opentelemetry.io/otel"
 )
@@ -38,9 +39,21 @@ type FileNotifier struct {
 }
 
 func New(name string, globals *config.Globals, attrs map[string]string) (config.Node, error) {
+	nodeType := attrs["type"]
+	delete(attrs, "type")
+
+	rawNode := struct {
+		Encoding string `config:"encoding"`
+		Path     string `config:"path"`
+	}{}
+
+	if err := unmarshal.UnmarshalConfig(attrs, &rawNode, unmarshal.UnmarshalOpts{}); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal file node")
+	}
+
 	encodingName := DEFAULT_ENCODING
-	if enc, ok := attrs["encoding"]; ok {
-		encodingName = enc
+	if rawNode.Encoding != "" {
+		encodingName = rawNode.Encoding
 	}
 
 	encoder := encoding.LookupEncoding(encodingName)
@@ -48,7 +61,7 @@ func New(name string, globals *config.Globals, attrs map[string]string) (config.
 		return nil, fmt.Errorf("invalid encoding: %q", encodingName)
 	}
 
-	switch attrs["type"] {
+	switch nodeType {
 	case "stdout":
 		return &FileNotifier{
 			name:    config.NotifierName(name),
@@ -62,11 +75,20 @@ func New(name string, globals *config.Globals, attrs map[string]string) (config.
 			file:    os.Stderr,
 		}, nil
 	case "", "file":
-		fileName := attrs["path"]
+		fileName := rawNode.Path
 		if fileName == "" {
 			return nil, errors.New("missing `path` in file node")
 		}
 
+		// Opening the file creates it, which checking a config shouldn't do.
+		if globals != nil && globals.ValidateOnly() {
+			return &FileNotifier{
+				name:    config.NotifierName(name),
+				encoder: encoder,
+				file:    nopWriteCloser{},
+			}, nil
+		}
+
 		file, err := os.OpenFile(fileName, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o644)
 		if err != nil {
 			return nil, fmt.Errorf("failed to open file %q in file node: %w", fileName, err)
@@ -78,10 +100,21 @@ func New(name string, globals *config.Globals, attrs map[string]string) (config.
 			file:    file,
 		}, nil
 	default:
-		return nil, fmt.Errorf("invalid type for file node: %q", attrs["type"])
+		return nil, fmt.Errorf("invalid type for file node: %q", nodeType)
 	}
 }
 
+// nopWriteCloser discards everything written to it, for file notifiers that are never going to notify anything.
+type nopWriteCloser struct{}
+
+func (nopWriteCloser) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
 func (f *FileNotifier) Name() config.NotifierName {
 	return f.name
 }
@@ -3,6 +3,7 @@ package filenotifier_test
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/sinkingpoint/kiora/lib/kiora/config"
@@ -38,3 +39,15 @@ func TestFileNotifierNode(t *testing.T) {
 	require.Contains(t, string(fileContents), "alertname")
 	require.Contains(t, string(fileContents), "foo")
 }
+
+func TestFileNotifierNodeValidateOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.json")
+
+	// Checking a config shouldn't create the files that it would write to.
+	_, err := filenotifier.New("", config.NewGlobals(config.WithValidateOnly()), map[string]string{
+		"type": "file",
+		"path": path,
+	})
+	require.NoError(t, err)
+	require.NoFileExists(t, path)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"strings"
 	"text/template"
 
@@ -47,7 +48,8 @@ func New(name string, globals *config.Globals, attrs map[string]string) (config.
 		TemplateFile *unmarshal.MaybeFile       `config:"template_file"`
 	}{}
 
-	if err := unmarshal.UnmarshalConfig(attrs, rawNode, unmarshal.UnmarshalOpts{
+	delete(attrs, "type")
+	if err := unmarshal.UnmarshalConfig(attrs, &rawNode, unmarshal.UnmarshalOpts{
 		DisallowUnknownFields: true,
 	}); err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal config")
@@ -99,7 +101,7 @@ func (s *SlackNotifier) Notify(ctx context.Context, alerts ...model.Alert) *conf
 	request, err := http.NewRequest(http.MethodPost, string(s.apiURL.Value()), bytes.NewBuffer(payloadBytes))
 	if err != nil {
 		return &config.NotificationError{
-			Err:       err,
+			Err:       redactURL(err),
 			Retryable: false,
 		}
 	}
@@ -107,7 +109,7 @@ func (s *SlackNotifier) Notify(ctx context.Context, alerts ...model.Alert) *conf
 	resp, err := s.client.Do(request)
 	if err != nil {
 		return &config.NotificationError{
-			Err:       err,
+			Err:       redactURL(err),
 			Retryable: true,
 		}
 	}
@@ -123,3 +125,13 @@ func (s *SlackNotifier) Notify(ctx context.Context, alerts ...model.Alert) *conf
 
 	return nil
 }
+
+// redactURL removes the URL from the given error, if it has one, because slack webhook URLs are secrets.
+func redactURL(err error) error {
+	urlErr := &url.Error{}
+	if errors.As(err, &urlErr) {
+		urlErr.URL = unmarshal.Secret(urlErr.URL).String()
+	}
+
+	return err
+}
@@ -0,0 +1,50 @@
+package slack_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/config/notifiers/slack"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackNotifierNode(t *testing.T) {
+	payloads := make(chan map[string]string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := map[string]string{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		payloads <- payload
+	}))
+	defer server.Close()
+
+	node, err := slack.New("slack", config.NewGlobals(), map[string]string{
+		"type":    "slack",
+		"api_url": server.URL,
+	})
+
+	require.NoError(t, err)
+
+	notifier := node.(config.Notifier)
+	require.Nil(t, notifier.Notify(context.Background(), model.Alert{
+		Labels: model.Labels{
+			"alertname": "foo",
+		},
+	}))
+
+	require.Equal(t, "[FIRING: 1] foo", (<-payloads)["text"])
+}
+
+func TestSlackNotifierNodeUnknownFields(t *testing.T) {
+	_, err := slack.New("slack", config.NewGlobals(), map[string]string{
+		"type":    "slack",
+		"api_url": "http://localhost",
+		"channel": "#alerts",
+	})
+
+	require.ErrorContains(t, err, "channel")
+}
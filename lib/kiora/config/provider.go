@@ -4,6 +4,7 @@ import (
 	"context"
 	"strings"
 	"text/template"
+	"text/template/parse"
 	"time"
 
 	"github.com/sinkingpoint/kiora/lib/kiora/model"
@@ -52,10 +53,18 @@ type Config interface {
 	// should be processed as if it should be considered to be properly notified.
 	GetNotifiersForAlert(ctx context.Context, alert *model.Alert) []NotifierSettings
 
+	// RouteAlert returns the notifiers that the given alert would be sent to, along with the path through
+	// the config that the alert took to reach each of them. Unlike GetNotifiersForAlert, this ignores clustering.
+	RouteAlert(ctx context.Context, alert *model.Alert) []Route
+
 	// ValidateData returns an error that can be displayed to the user if the
 	// data is invalid according to whatever rules the config has.
 	ValidateData(ctx context.Context, data Fielder) error
 
+	// TransformAlert applies any transformations (e.g. relabeling) that the config defines to an alert
+	// as it is ingested, before it is validated and stored.
+	TransformAlert(ctx context.Context, alert *model.Alert) error
+
 	// Globals returns the global settings for the config.
 	Globals() *Globals
 }
@@ -71,6 +80,14 @@ type NotifierSettings struct {
 	GroupWait time.Duration
 }
 
+// Route is a notifier that an alert would be sent to, along with the path it took through the config to get there.
+type Route struct {
+	NotifierSettings
+
+	// Path is the names of the nodes that the alert passed through, in order, from the root to the notifier.
+	Path []string
+}
+
 func DefaultNotifierSettings() NotifierSettings {
 	return NotifierSettings{
 		GroupLabels: []string{"alertname"},
@@ -108,22 +125,59 @@ type TemplateTenanter struct {
 	Template *template.Template
 }
 
-// NewTemplateTenanter creates a new TemplateTenanter with the given template.
+// NewTemplateTenanter creates a new TemplateTenanter with the given template. Data that doesn't have every field
+// that the template references goes in the default (empty) tenant.
 func NewTemplateTenanter(t *template.Template) *TemplateTenanter {
 	return &TemplateTenanter{
-		Template: t,
+		Template: t.Option("missingkey=error"),
 	}
 }
 
 func (t *TemplateTenanter) GetTenant(ctx context.Context, data Fielder) (Tenant, error) {
+	// Silences don't have labels to template, so they record the tenant that created them instead.
+	if silence, ok := data.(*model.Silence); ok {
+		return Tenant(silence.Tenant), nil
+	}
+
 	var tenant strings.Builder
 	if err := t.Template.Execute(&tenant, data.Fields()); err != nil {
+		// Otherwise, missing fields would be templated as "<no value>", putting everything without them in a tenant with that name.
+		if strings.Contains(err.Error(), "map has no entry for key") {
+			return "", nil
+		}
+
 		return "", err
 	}
 
 	return Tenant(tenant.String()), nil
 }
 
+// Label returns the label that the tenant of an alert is the value of, if the template is just a reference to it (e.g. `{{ .team }}`), so that
+// queries for a tenant can be turned into queries on that label.
+func (t *TemplateTenanter) Label() (string, bool) {
+	if t.Template.Tree == nil || len(t.Template.Tree.Root.Nodes) != 1 {
+		return "", false
+	}
+
+	action, ok := t.Template.Tree.Root.Nodes[0].(*parse.ActionNode)
+	if !ok || len(action.Pipe.Decl) > 0 || len(action.Pipe.Cmds) != 1 || len(action.Pipe.Cmds[0].Args) != 1 {
+		return "", false
+	}
+
+	field, ok := action.Pipe.Cmds[0].Args[0].(*parse.FieldNode)
+	if !ok || len(field.Ident) != 1 {
+		return "", false
+	}
+
+	// Special fields, like `__status__` and annotations, aren't labels.
+	label := field.Ident[0]
+	if strings.HasPrefix(label, "__") {
+		return "", false
+	}
+
+	return label, true
+}
+
 // StaticTenanter is a Tenanter that always returns the same tenant.
 type StaticTenanter struct {
 	Tenant Tenant
@@ -0,0 +1,86 @@
+package config_test
+
+import (
+	"context"
+	"testing"
+	"text/template"
+
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateTenanter(t *testing.T) {
+	tenanter := config.NewTemplateTenanter(template.Must(template.New("tenant").Parse("{{ .team }}")))
+
+	tests := []struct {
+		name     string
+		data     config.Fielder
+		expected config.Tenant
+	}{
+		{
+			name:     "alert with the label",
+			data:     &model.Alert{Labels: model.Labels{"team": "foo"}},
+			expected: "foo",
+		},
+		{
+			name:     "alert without the label",
+			data:     &model.Alert{Labels: model.Labels{"alertname": "foo"}},
+			expected: "",
+		},
+		{
+			name:     "silence",
+			data:     &model.Silence{Tenant: "bar"},
+			expected: "bar",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant, err := tenanter.GetTenant(context.Background(), tt.data)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tenant)
+		})
+	}
+}
+
+func TestTemplateTenanterLabel(t *testing.T) {
+	tests := []struct {
+		name          string
+		template      string
+		expectedLabel string
+		expectedOK    bool
+	}{
+		{
+			name:          "label",
+			template:      "{{ .team }}",
+			expectedLabel: "team",
+			expectedOK:    true,
+		},
+		{
+			name:     "label with a prefix",
+			template: "team-{{ .team }}",
+		},
+		{
+			name:     "multiple labels",
+			template: "{{ .team }}{{ .env }}",
+		},
+		{
+			name:     "function",
+			template: "{{ .team | printf \"%s\" }}",
+		},
+		{
+			name:     "special field",
+			template: "{{ .__status__ }}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenanter := config.NewTemplateTenanter(template.Must(template.New("tenant").Parse(tt.template)))
+			label, ok := tenanter.Label()
+			require.Equal(t, tt.expectedOK, ok)
+			require.Equal(t, tt.expectedLabel, label)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/internal/stubs"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+var _ = Config(&ReloadableConfig{})
+var _ = Grapher(&ReloadableConfig{})
+
+// Reloader is an interface that can be implemented by configs that can be reloaded at runtime.
+type Reloader interface {
+	// Reload loads a new version of the config, swapping it in if it's valid. If the new config is invalid,
+	// an error is returned and the old config keeps running.
+	Reload(ctx context.Context) error
+}
+
+// ConfigLoader loads a fresh, validated, Config, e.g. by re-reading a config file.
+type ConfigLoader func() (Config, error)
+
+// ReloadStats describes the history of reloads of a ReloadableConfig.
+type ReloadStats struct {
+	// Successes is the number of times the config has been successfully reloaded.
+	Successes uint64
+
+	// Failures is the number of times a reload has been rejected.
+	Failures uint64
+
+	// LastReloadSuccessful is true if the last reload succeeded (or if there haven't been any reloads yet).
+	LastReloadSuccessful bool
+
+	// LastSuccessTime is the last time a config was successfully loaded.
+	LastSuccessTime time.Time
+}
+
+// ReloadableConfig is a Config that delegates to an underlying Config that can be atomically swapped out at runtime.
+// Things that hold a ReloadableConfig (like the notify service) pick up the new config without being restarted, so they
+// don't lose any in-memory state.
+type ReloadableConfig struct {
+	loader  ConfigLoader
+	current atomic.Pointer[Config]
+
+	// reloadLock serialises reloads, and protects stats.
+	reloadLock sync.Mutex
+	stats      ReloadStats
+}
+
+// NewReloadableConfig loads the initial config with the given loader, returning an error if it fails.
+func NewReloadableConfig(loader ConfigLoader) (*ReloadableConfig, error) {
+	conf, err := loader()
+	if err != nil {
+		return nil, err
+	}
+
+	reloadable := &ReloadableConfig{
+		loader: loader,
+		stats: ReloadStats{
+			LastReloadSuccessful: true,
+			LastSuccessTime:      stubs.Time.Now(),
+		},
+	}
+
+	reloadable.current.Store(&conf)
+
+	return reloadable, nil
+}
+
+// Reload loads a new config with the loader and swaps it in. If the loader fails, the current config is kept.
+func (r *ReloadableConfig) Reload(ctx context.Context) error {
+	r.reloadLock.Lock()
+	defer r.reloadLock.Unlock()
+
+	conf, err := r.loader()
+	if err != nil {
+		r.stats.Failures++
+		r.stats.LastReloadSuccessful = false
+		return errors.Wrap(err, "failed to reload config")
+	}
+
+	r.current.Store(&conf)
+	r.stats.Successes++
+	r.stats.LastReloadSuccessful = true
+	r.stats.LastSuccessTime = stubs.Time.Now()
+
+	return nil
+}
+
+// Stats returns a snapshot of the reload history of the config.
+func (r *ReloadableConfig) Stats() ReloadStats {
+	r.reloadLock.Lock()
+	defer r.reloadLock.Unlock()
+
+	return r.stats
+}
+
+// Current returns the config that is currently in use.
+func (r *ReloadableConfig) Current() Config {
+	return *r.current.Load()
+}
+
+func (r *ReloadableConfig) GetNotifiersForAlert(ctx context.Context, alert *model.Alert) []NotifierSettings {
+	return r.Current().GetNotifiersForAlert(ctx, alert)
+}
+
+func (r *ReloadableConfig) RouteAlert(ctx context.Context, alert *model.Alert) []Route {
+	return r.Current().RouteAlert(ctx, alert)
+}
+
+func (r *ReloadableConfig) ValidateData(ctx context.Context, data Fielder) error {
+	return r.Current().ValidateData(ctx, data)
+}
+
+func (r *ReloadableConfig) TransformAlert(ctx context.Context, alert *model.Alert) error {
+	return r.Current().TransformAlert(ctx, alert)
+}
+
+func (r *ReloadableConfig) Globals() *Globals {
+	return r.Current().Globals()
+}
+
+// Graph describes the current config, if it can be described. Otherwise, an empty graph is returned.
+func (r *ReloadableConfig) Graph() Graph {
+	if grapher, ok := r.Current().(Grapher); ok {
+		return grapher.Graph()
+	}
+
+	return Graph{}
+}
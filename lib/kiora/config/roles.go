@@ -0,0 +1,34 @@
+package config
+
+import (
+	"context"
+	"strings"
+)
+
+// ROLES_FIELD is the field that exposes the roles of the identity that made a request to filters, as a comma separated list.
+const ROLES_FIELD = "__roles__"
+
+type rolesKey struct{}
+
+// ContextWithRoles returns a copy of the given context, with the roles of the identity that made the request attached.
+func ContextWithRoles(ctx context.Context, roles []string) context.Context {
+	return context.WithValue(ctx, rolesKey{}, roles)
+}
+
+// RolesFromContext returns the roles attached to the given context, if the request was authorized.
+func RolesFromContext(ctx context.Context) ([]string, bool) {
+	roles, ok := ctx.Value(rolesKey{}).([]string)
+	return roles, ok
+}
+
+// RequestField returns the given field of the given data, or of the request in the given context (e.g. the roles of the identity that
+// made it). Filters should look up fields with this, rather than with the Fielder directly, so that they can check who made the request.
+func RequestField(ctx context.Context, data Fielder, name string) (any, error) {
+	if name == ROLES_FIELD {
+		if roles, ok := RolesFromContext(ctx); ok {
+			return strings.Join(roles, ","), nil
+		}
+	}
+
+	return data.Field(name)
+}
@@ -0,0 +1,13 @@
+package config
+
+import (
+	"context"
+
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+// AlertTransformerNode is an interface that can be implemented by config nodes that modify alerts as they are ingested.
+type AlertTransformerNode interface {
+	// TransformAlert modifies the given alert in place, returning an error if the alert can't be transformed.
+	TransformAlert(ctx context.Context, alert *model.Alert) error
+}
@@ -0,0 +1,310 @@
+package enrich
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/internal/stubs"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/config/unmarshal"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	config.RegisterNode(ENRICH_NODE_NAME, New)
+}
+
+const ENRICH_NODE_NAME = "enrich"
+
+// DefaultReloadInterval is the default minimum amount of time between checks of the lookup file for changes.
+const DefaultReloadInterval = 10 * time.Second
+
+// keySep separates the values of the key labels when building lookup keys.
+const keySep = "\xff"
+
+var _ = config.AlertTransformerNode(&EnrichTransformer{})
+
+// EnrichTransformer is a node that adds labels and annotations to alerts as they are ingested, by looking up
+// the values of one or more of their labels in a static table loaded from a CSV, JSON, or YAML file.
+type EnrichTransformer struct {
+	logger zerolog.Logger
+
+	path           string
+	format         string
+	keys           []string
+	labels         []string
+	annotations    []string
+	overwrite      bool
+	reloadInterval time.Duration
+
+	// lock protects everything below it.
+	lock sync.RWMutex
+
+	// table maps the joined values of the key fields to the record with those values.
+	table map[string]map[string]string
+
+	// modTime is the modification time of the lookup file when it was last loaded.
+	modTime time.Time
+
+	// lastCheck is the last time that we checked the lookup file for changes.
+	lastCheck time.Time
+}
+
+func New(name string, globals *config.Globals, attrs map[string]string) (config.Node, error) {
+	delete(attrs, "type")
+
+	rawNode := struct {
+		Path           string         `config:"path" required:"true"`
+		Format         string         `config:"format"`
+		Keys           []string       `config:"keys" required:"true"`
+		Labels         []string       `config:"labels"`
+		Annotations    []string       `config:"annotations"`
+		Overwrite      bool           `config:"overwrite"`
+		ReloadInterval *time.Duration `config:"reload_interval"`
+	}{}
+
+	if err := unmarshal.UnmarshalConfig(attrs, &rawNode, unmarshal.UnmarshalOpts{DisallowUnknownFields: true}); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal enrich node")
+	}
+
+	if len(rawNode.Labels) == 0 && len(rawNode.Annotations) == 0 {
+		return nil, errors.New("enrich node must have at least one of labels or annotations")
+	}
+
+	format := rawNode.Format
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(rawNode.Path), ".")
+	}
+
+	switch format {
+	case "csv", "json":
+	case "yaml", "yml":
+		format = "yaml"
+	default:
+		return nil, fmt.Errorf("invalid format in enrich node: %q", format)
+	}
+
+	if globals == nil {
+		globals = config.NewGlobals()
+	}
+
+	transformer := &EnrichTransformer{
+		logger:         globals.Logger(ENRICH_NODE_NAME).With().Str("node", name).Logger(),
+		path:           globals.ResolvePath(rawNode.Path),
+		format:         format,
+		keys:           rawNode.Keys,
+		labels:         rawNode.Labels,
+		annotations:    rawNode.Annotations,
+		overwrite:      rawNode.Overwrite,
+		reloadInterval: DefaultReloadInterval,
+	}
+
+	if rawNode.ReloadInterval != nil {
+		transformer.reloadInterval = *rawNode.ReloadInterval
+	}
+
+	// Load the table up front so that a broken lookup file fails the config load.
+	if err := transformer.load(); err != nil {
+		return nil, err
+	}
+
+	return transformer, nil
+}
+
+func (e *EnrichTransformer) Type() string {
+	return ENRICH_NODE_NAME
+}
+
+// TransformAlert adds the configured labels and annotations to the alert from the record matching its key labels.
+// Alerts that are missing a key label, or that don't have a record in the table, are left untouched.
+func (e *EnrichTransformer) TransformAlert(ctx context.Context, alert *model.Alert) error {
+	e.maybeReload()
+
+	values := make([]string, 0, len(e.keys))
+	for _, key := range e.keys {
+		value, ok := alert.Labels[key]
+		if !ok {
+			return nil
+		}
+
+		values = append(values, value)
+	}
+
+	e.lock.RLock()
+	record, ok := e.table[strings.Join(values, keySep)]
+	e.lock.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	if len(e.labels) > 0 && alert.Labels == nil {
+		alert.Labels = model.Labels{}
+	}
+
+	if len(e.annotations) > 0 && alert.Annotations == nil {
+		alert.Annotations = map[string]string{}
+	}
+
+	copyFields(record, alert.Labels, e.labels, e.overwrite)
+	copyFields(record, alert.Annotations, e.annotations, e.overwrite)
+
+	return nil
+}
+
+// copyFields copies the given fields from the record into dest, only overwriting existing values if overwrite is set.
+func copyFields(record, dest map[string]string, fields []string, overwrite bool) {
+	for _, field := range fields {
+		value, ok := record[field]
+		if !ok || value == "" {
+			continue
+		}
+
+		if _, exists := dest[field]; exists && !overwrite {
+			continue
+		}
+
+		dest[field] = value
+	}
+}
+
+// maybeReload reloads the lookup file if it has changed since it was last loaded, at most once per reload interval.
+// If the reload fails, we keep serving the last good table.
+func (e *EnrichTransformer) maybeReload() {
+	e.lock.Lock()
+	now := stubs.Time.Now()
+	if now.Sub(e.lastCheck) < e.reloadInterval {
+		e.lock.Unlock()
+		return
+	}
+
+	e.lastCheck = now
+	modTime := e.modTime
+	e.lock.Unlock()
+
+	info, err := os.Stat(e.path)
+	if err != nil {
+		e.logger.Warn().Err(err).Msg("failed to stat lookup file")
+		return
+	}
+
+	if info.ModTime().Equal(modTime) {
+		return
+	}
+
+	if err := e.load(); err != nil {
+		e.logger.Warn().Err(err).Msg("failed to reload lookup file, using the previous version")
+		return
+	}
+
+	e.logger.Info().Str("path", e.path).Msg("reloaded lookup file")
+}
+
+// load reads the lookup file and replaces the table with its contents.
+func (e *EnrichTransformer) load() error {
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to stat lookup file")
+	}
+
+	body, err := os.ReadFile(e.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read lookup file")
+	}
+
+	var records []map[string]string
+	switch e.format {
+	case "csv":
+		records, err = parseCSV(body)
+	case "json":
+		records, err = parseStructured(body, json.Unmarshal)
+	case "yaml":
+		records, err = parseStructured(body, yaml.Unmarshal)
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse lookup file %q", e.path)
+	}
+
+	table := make(map[string]map[string]string, len(records))
+	for i, record := range records {
+		values := make([]string, 0, len(e.keys))
+		for _, key := range e.keys {
+			value, ok := record[key]
+			if !ok {
+				return fmt.Errorf("record %d in lookup file %q is missing key %q", i, e.path, key)
+			}
+
+			values = append(values, value)
+		}
+
+		table[strings.Join(values, keySep)] = record
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.table = table
+	e.modTime = info.ModTime()
+
+	return nil
+}
+
+// parseCSV parses a CSV file with a header row into a list of records keyed by the header names.
+func parseCSV(body []byte) ([]map[string]string, error) {
+	rows, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	records := make([]map[string]string, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := make(map[string]string, len(header))
+		for i, name := range header {
+			record[name] = row[i]
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// parseStructured parses a JSON or YAML file containing a list of objects into a list of records,
+// formatting any non-string values as strings.
+func parseStructured(body []byte, unmarshal func([]byte, any) error) ([]map[string]string, error) {
+	raw := []map[string]any{}
+	if err := unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+
+	records := make([]map[string]string, 0, len(raw))
+	for _, rawRecord := range raw {
+		record := make(map[string]string, len(rawRecord))
+		for k, v := range rawRecord {
+			if v == nil {
+				continue
+			}
+
+			record[k] = fmt.Sprint(v)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,214 @@
+package enrich_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/config/transformers/enrich"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLookupFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	return path
+}
+
+func TestEnrichTransformer(t *testing.T) {
+	tests := []struct {
+		name                string
+		fileName            string
+		contents            string
+		attrs               map[string]string
+		alert               model.Alert
+		expectedLabels      model.Labels
+		expectedAnnotations map[string]string
+	}{
+		{
+			name:     "csv",
+			fileName: "services.csv",
+			contents: "service,team,runbook\nfoo,team-foo,https://example.com/foo\nbar,team-bar,https://example.com/bar\n",
+			attrs: map[string]string{
+				"keys":        "service",
+				"labels":      "team",
+				"annotations": "runbook",
+			},
+			alert: model.Alert{
+				Labels:      model.Labels{"service": "bar"},
+				Annotations: map[string]string{},
+			},
+			expectedLabels:      model.Labels{"service": "bar", "team": "team-bar"},
+			expectedAnnotations: map[string]string{"runbook": "https://example.com/bar"},
+		},
+		{
+			name:     "json with multiple keys",
+			fileName: "services.json",
+			contents: `[{"service": "foo", "env": "prod", "team": "team-foo-prod"}, {"service": "foo", "env": "dev", "team": "team-foo-dev"}]`,
+			attrs: map[string]string{
+				"keys":   "service,env",
+				"labels": "team",
+			},
+			alert: model.Alert{
+				Labels: model.Labels{"service": "foo", "env": "dev"},
+			},
+			expectedLabels: model.Labels{"service": "foo", "env": "dev", "team": "team-foo-dev"},
+		},
+		{
+			name:     "yaml",
+			fileName: "services.yaml",
+			contents: "- service: foo\n  slack_channel: \"#foo\"\n",
+			attrs: map[string]string{
+				"keys":        "service",
+				"annotations": "slack_channel",
+			},
+			alert: model.Alert{
+				Labels:      model.Labels{"service": "foo"},
+				Annotations: map[string]string{},
+			},
+			expectedLabels:      model.Labels{"service": "foo"},
+			expectedAnnotations: map[string]string{"slack_channel": "#foo"},
+		},
+		{
+			name:     "missing key label",
+			fileName: "services.csv",
+			contents: "service,team\nfoo,team-foo\n",
+			attrs: map[string]string{
+				"keys":   "service",
+				"labels": "team",
+			},
+			alert: model.Alert{
+				Labels: model.Labels{"alertname": "foo"},
+			},
+			expectedLabels: model.Labels{"alertname": "foo"},
+		},
+		{
+			name:     "doesn't overwrite by default",
+			fileName: "services.csv",
+			contents: "service,team\nfoo,team-foo\n",
+			attrs: map[string]string{
+				"keys":   "service",
+				"labels": "team",
+			},
+			alert: model.Alert{
+				Labels: model.Labels{"service": "foo", "team": "existing"},
+			},
+			expectedLabels: model.Labels{"service": "foo", "team": "existing"},
+		},
+		{
+			name:     "overwrite",
+			fileName: "services.csv",
+			contents: "service,team\nfoo,team-foo\n",
+			attrs: map[string]string{
+				"keys":      "service",
+				"labels":    "team",
+				"overwrite": "true",
+			},
+			alert: model.Alert{
+				Labels: model.Labels{"service": "foo", "team": "existing"},
+			},
+			expectedLabels: model.Labels{"service": "foo", "team": "team-foo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.attrs["path"] = writeLookupFile(t, tt.fileName, tt.contents)
+			node, err := enrich.New("enrich", nil, tt.attrs)
+			require.NoError(t, err)
+
+			require.NoError(t, node.(*enrich.EnrichTransformer).TransformAlert(context.Background(), &tt.alert))
+			require.Equal(t, tt.expectedLabels, tt.alert.Labels)
+			if tt.expectedAnnotations != nil {
+				require.Equal(t, tt.expectedAnnotations, tt.alert.Annotations)
+			}
+		})
+	}
+}
+
+func TestEnrichTransformerReload(t *testing.T) {
+	path := writeLookupFile(t, "services.csv", "service,team\nfoo,team-foo\n")
+	node, err := enrich.New("enrich", nil, map[string]string{
+		"path":            path,
+		"keys":            "service",
+		"labels":          "team",
+		"reload_interval": "0s",
+	})
+	require.NoError(t, err)
+
+	transformer := node.(*enrich.EnrichTransformer)
+
+	alert := model.Alert{Labels: model.Labels{"service": "foo"}}
+	require.NoError(t, transformer.TransformAlert(context.Background(), &alert))
+	require.Equal(t, "team-foo", alert.Labels["team"])
+
+	require.NoError(t, os.WriteFile(path, []byte("service,team\nfoo,team-bar\n"), 0o644))
+	// Make sure the modification time changes, even on filesystems with coarse timestamps.
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+	alert = model.Alert{Labels: model.Labels{"service": "foo"}}
+	require.NoError(t, transformer.TransformAlert(context.Background(), &alert))
+	require.Equal(t, "team-bar", alert.Labels["team"])
+
+	// A broken file should leave the old table in place.
+	require.NoError(t, os.WriteFile(path, []byte("service,team\nfoo\n"), 0o644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+
+	alert = model.Alert{Labels: model.Labels{"service": "foo"}}
+	require.NoError(t, transformer.TransformAlert(context.Background(), &alert))
+	require.Equal(t, "team-bar", alert.Labels["team"])
+}
+
+func TestEnrichTransformerRelativePath(t *testing.T) {
+	path := writeLookupFile(t, "services.csv", "service,team\nfoo,team-foo\n")
+
+	// Relative paths are resolved against the directory of the config file that the node is in, not the working directory.
+	node, err := enrich.New("enrich", config.NewGlobals().InConfigDir(filepath.Dir(path)), map[string]string{
+		"path":   "services.csv",
+		"keys":   "service",
+		"labels": "team",
+	})
+	require.NoError(t, err)
+
+	alert := model.Alert{Labels: model.Labels{"service": "foo"}}
+	require.NoError(t, node.(*enrich.EnrichTransformer).TransformAlert(context.Background(), &alert))
+	require.Equal(t, "team-foo", alert.Labels["team"])
+}
+
+func TestEnrichTransformerInvalidConfig(t *testing.T) {
+	path := writeLookupFile(t, "services.csv", "service,team\nfoo,team-foo\n")
+
+	tests := []struct {
+		name  string
+		attrs map[string]string
+	}{
+		{
+			name:  "no outputs",
+			attrs: map[string]string{"path": path, "keys": "service"},
+		},
+		{
+			name:  "missing file",
+			attrs: map[string]string{"path": path + ".missing", "keys": "service", "labels": "team"},
+		},
+		{
+			name:  "unknown format",
+			attrs: map[string]string{"path": path, "format": "toml", "keys": "service", "labels": "team"},
+		},
+		{
+			name:  "key missing from the file",
+			attrs: map[string]string{"path": path, "keys": "cluster", "labels": "team"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := enrich.New("enrich", nil, tt.attrs)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package relabel
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/regexp"
+	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/lib/kiora/config"
+	"github.com/sinkingpoint/kiora/lib/kiora/config/unmarshal"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+func init() {
+	config.RegisterNode(RELABEL_NODE_NAME, New)
+}
+
+const RELABEL_NODE_NAME = "relabel"
+
+// Action is the action that a relabel node performs, modelled on Prometheus' relabel_configs.
+type Action string
+
+const (
+	// ActionReplace sets `target_label` to `replacement`, expanded against the matches of `regex` on the concatenated `source_labels`.
+	ActionReplace Action = "replace"
+
+	// ActionLabelMap copies the value of every label matching `regex` to a label named by expanding `replacement` against the label name.
+	ActionLabelMap Action = "labelmap"
+
+	// ActionLabelDrop removes every label whose name matches `regex`.
+	ActionLabelDrop Action = "labeldrop"
+
+	// ActionLabelKeep removes every label whose name doesn't match `regex`.
+	ActionLabelKeep Action = "labelkeep"
+
+	// ActionHashMod sets `target_label` to the `modulus` of a hash of the concatenated `source_labels`.
+	ActionHashMod Action = "hashmod"
+)
+
+const (
+	defaultSeparator   = ";"
+	defaultRegex       = "(.*)"
+	defaultReplacement = "$1"
+)
+
+var _ = config.AlertTransformerNode(&RelabelTransformer{})
+
+// RelabelTransformer is a node that rewrites the labels of alerts as they are ingested, before they are
+// validated and deduplicated. Each node applies a single rule - chain nodes together to apply multiple.
+type RelabelTransformer struct {
+	action       Action
+	sourceLabels []string
+	separator    string
+	targetLabel  string
+	regex        *regexp.Regexp
+	replacement  string
+	modulus      uint64
+}
+
+func New(name string, globals *config.Globals, attrs map[string]string) (config.Node, error) {
+	delete(attrs, "type")
+
+	rawNode := struct {
+		Action       string   `config:"action"`
+		SourceLabels []string `config:"source_labels"`
+		Separator    *string  `config:"separator"`
+		TargetLabel  string   `config:"target_label"`
+		Regex        *string  `config:"regex"`
+		Replacement  *string  `config:"replacement"`
+		Modulus      int      `config:"modulus"`
+	}{}
+
+	if err := unmarshal.UnmarshalConfig(attrs, &rawNode, unmarshal.UnmarshalOpts{DisallowUnknownFields: true}); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal relabel node")
+	}
+
+	transformer := &RelabelTransformer{
+		action:       Action(rawNode.Action),
+		sourceLabels: rawNode.SourceLabels,
+		separator:    defaultSeparator,
+		targetLabel:  rawNode.TargetLabel,
+		replacement:  defaultReplacement,
+	}
+
+	if transformer.action == "" {
+		transformer.action = ActionReplace
+	}
+
+	if rawNode.Separator != nil {
+		transformer.separator = *rawNode.Separator
+	}
+
+	if rawNode.Replacement != nil {
+		transformer.replacement = *rawNode.Replacement
+	}
+
+	rawRegex := defaultRegex
+	if rawNode.Regex != nil {
+		rawRegex = *rawNode.Regex
+	}
+
+	// Like Prometheus, regexes are fully anchored.
+	regex, err := regexp.Compile("^(?:" + rawRegex + ")$")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compile regex in relabel node")
+	}
+
+	transformer.regex = regex
+
+	switch transformer.action {
+	case ActionReplace:
+		if transformer.targetLabel == "" {
+			return nil, errors.New("relabel node with action replace requires a target_label")
+		}
+	case ActionHashMod:
+		if transformer.targetLabel == "" {
+			return nil, errors.New("relabel node with action hashmod requires a target_label")
+		}
+
+		if rawNode.Modulus <= 0 {
+			return nil, errors.New("relabel node with action hashmod requires a positive modulus")
+		}
+
+		transformer.modulus = uint64(rawNode.Modulus)
+	case ActionLabelMap, ActionLabelDrop, ActionLabelKeep:
+		if rawNode.Regex == nil {
+			return nil, fmt.Errorf("relabel node with action %s requires a regex", transformer.action)
+		}
+	default:
+		return nil, fmt.Errorf("invalid action in relabel node: %q", transformer.action)
+	}
+
+	return transformer, nil
+}
+
+func (r *RelabelTransformer) Type() string {
+	return RELABEL_NODE_NAME
+}
+
+// TransformAlert applies the relabel rule to the labels of the given alert.
+func (r *RelabelTransformer) TransformAlert(ctx context.Context, alert *model.Alert) error {
+	labels := alert.Labels
+	if labels == nil {
+		labels = model.Labels{}
+	}
+
+	switch r.action {
+	case ActionReplace:
+		value := r.sourceValue(labels)
+		indexes := r.regex.FindStringSubmatchIndex(value)
+		if indexes == nil {
+			return nil
+		}
+
+		target := string(r.regex.ExpandString(nil, r.targetLabel, value, indexes))
+		if target == "" {
+			return nil
+		}
+
+		replaced := string(r.regex.ExpandString(nil, r.replacement, value, indexes))
+		if replaced == "" {
+			delete(labels, target)
+		} else {
+			labels[target] = replaced
+		}
+	case ActionHashMod:
+		hash := md5.Sum([]byte(r.sourceValue(labels)))
+		labels[r.targetLabel] = fmt.Sprintf("%d", binary.BigEndian.Uint64(hash[8:])%r.modulus)
+	case ActionLabelMap:
+		mapped := model.Labels{}
+		for name, value := range labels {
+			if r.regex.MatchString(name) {
+				mapped[r.regex.ReplaceAllString(name, r.replacement)] = value
+			}
+		}
+
+		for name, value := range mapped {
+			labels[name] = value
+		}
+	case ActionLabelDrop:
+		for name := range labels {
+			if r.regex.MatchString(name) {
+				delete(labels, name)
+			}
+		}
+	case ActionLabelKeep:
+		for name := range labels {
+			if !r.regex.MatchString(name) {
+				delete(labels, name)
+			}
+		}
+	}
+
+	alert.Labels = labels
+	return nil
+}
+
+// sourceValue returns the values of the source labels, joined by the separator.
+func (r *RelabelTransformer) sourceValue(labels model.Labels) string {
+	values := make([]string, 0, len(r.sourceLabels))
+	for _, name := range r.sourceLabels {
+		values = append(values, labels[name])
+	}
+
+	return strings.Join(values, r.separator)
+}
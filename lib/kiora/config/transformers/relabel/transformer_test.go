@@ -0,0 +1,243 @@
+package relabel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sinkingpoint/kiora/lib/kiora/config/transformers/relabel"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelabelTransformer(t *testing.T) {
+	tests := []struct {
+		name           string
+		attrs          map[string]string
+		labels         model.Labels
+		expectedLabels model.Labels
+	}{
+		{
+			name: "replace",
+			attrs: map[string]string{
+				"source_labels": "instance",
+				"regex":         "([^:]+):.*",
+				"target_label":  "host",
+			},
+			labels: model.Labels{
+				"instance": "foo:9090",
+			},
+			expectedLabels: model.Labels{
+				"instance": "foo:9090",
+				"host":     "foo",
+			},
+		},
+		{
+			name: "replace with a group reference",
+			attrs: map[string]string{
+				"source_labels": "instance",
+				"regex":         "([^:]+):.*",
+				"target_label":  "host",
+				"replacement":   "${1}.example.com",
+			},
+			labels: model.Labels{
+				"instance": "foo:9090",
+			},
+			expectedLabels: model.Labels{
+				"instance": "foo:9090",
+				"host":     "foo.example.com",
+			},
+		},
+		{
+			name: "replace with an escaped named group reference",
+			attrs: map[string]string{
+				"source_labels": "instance",
+				"regex":         "(?P<host>[^:]+):.*",
+				"target_label":  "host",
+				"replacement":   "$${host}.example.com",
+			},
+			labels: model.Labels{
+				"instance": "foo:9090",
+			},
+			expectedLabels: model.Labels{
+				"instance": "foo:9090",
+				"host":     "foo.example.com",
+			},
+		},
+		{
+			name: "replace with multiple source labels",
+			attrs: map[string]string{
+				"action":        "replace",
+				"source_labels": "cluster,namespace",
+				"separator":     "/",
+				"target_label":  "location",
+			},
+			labels: model.Labels{
+				"cluster":   "prod",
+				"namespace": "default",
+			},
+			expectedLabels: model.Labels{
+				"cluster":   "prod",
+				"namespace": "default",
+				"location":  "prod/default",
+			},
+		},
+		{
+			name: "replace with no match",
+			attrs: map[string]string{
+				"source_labels": "instance",
+				"regex":         "bar:.*",
+				"target_label":  "host",
+			},
+			labels: model.Labels{
+				"instance": "foo:9090",
+			},
+			expectedLabels: model.Labels{
+				"instance": "foo:9090",
+			},
+		},
+		{
+			name: "replace with empty value deletes the label",
+			attrs: map[string]string{
+				"source_labels": "missing",
+				"target_label":  "severity",
+			},
+			labels: model.Labels{
+				"severity": "critical",
+			},
+			expectedLabels: model.Labels{},
+		},
+		{
+			name: "labelmap",
+			attrs: map[string]string{
+				"action": "labelmap",
+				"regex":  "__meta_(.+)",
+			},
+			labels: model.Labels{
+				"__meta_team": "foo",
+			},
+			expectedLabels: model.Labels{
+				"__meta_team": "foo",
+				"team":        "foo",
+			},
+		},
+		{
+			name: "labeldrop",
+			attrs: map[string]string{
+				"action": "labeldrop",
+				"regex":  "pod|container",
+			},
+			labels: model.Labels{
+				"alertname": "foo",
+				"pod":       "foo-1234",
+				"container": "foo",
+			},
+			expectedLabels: model.Labels{
+				"alertname": "foo",
+			},
+		},
+		{
+			name: "labelkeep",
+			attrs: map[string]string{
+				"action": "labelkeep",
+				"regex":  "alertname|severity",
+			},
+			labels: model.Labels{
+				"alertname": "foo",
+				"severity":  "critical",
+				"pod":       "foo-1234",
+			},
+			expectedLabels: model.Labels{
+				"alertname": "foo",
+				"severity":  "critical",
+			},
+		},
+		{
+			name: "hashmod",
+			attrs: map[string]string{
+				"action":        "hashmod",
+				"source_labels": "alertname",
+				"target_label":  "shard",
+				"modulus":       "1",
+			},
+			labels: model.Labels{
+				"alertname": "foo",
+			},
+			expectedLabels: model.Labels{
+				"alertname": "foo",
+				"shard":     "0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := relabel.New("relabel", nil, tt.attrs)
+			require.NoError(t, err)
+
+			alert := model.Alert{
+				Labels: tt.labels,
+			}
+
+			require.NoError(t, node.(*relabel.RelabelTransformer).TransformAlert(context.Background(), &alert))
+			require.Equal(t, tt.expectedLabels, alert.Labels)
+		})
+	}
+}
+
+func TestRelabelTransformerInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]string
+	}{
+		{
+			// Named group references look like environment variables, so they have to be escaped.
+			name: "unescaped named group reference",
+			attrs: map[string]string{
+				"source_labels": "instance",
+				"regex":         "(?P<instance_host>[^:]+):.*",
+				"target_label":  "host",
+				"replacement":   "${instance_host}.example.com",
+			},
+		},
+		{
+			name: "replace without target",
+			attrs: map[string]string{
+				"source_labels": "instance",
+			},
+		},
+		{
+			name: "hashmod without modulus",
+			attrs: map[string]string{
+				"action":        "hashmod",
+				"source_labels": "instance",
+				"target_label":  "shard",
+			},
+		},
+		{
+			name: "labeldrop without regex",
+			attrs: map[string]string{
+				"action": "labeldrop",
+			},
+		},
+		{
+			name: "unknown action",
+			attrs: map[string]string{
+				"action": "foo",
+			},
+		},
+		{
+			name: "invalid regex",
+			attrs: map[string]string{
+				"target_label": "foo",
+				"regex":        "(",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := relabel.New("relabel", nil, tt.attrs)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package unmarshal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grafana/regexp"
+)
+
+// interpolationRegex matches references to environment variables (`${FOO}`) and files (`${file:/path/to/file}`) in config values.
+// References prefixed with an extra `$` (e.g. `$${FOO}`) are escaped, and left as a literal `${FOO}`. Anything else in `${}`, like the
+// `${1}` group references in relabel replacements, isn't a valid environment variable name, so it's left alone.
+var interpolationRegex = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*|file:[^}]*)\}`)
+
+// Interpolate replaces references to environment variables (`${FOO}`) and the contents of files (`${file:/path/to/file}`) in
+// the given value. It returns true if anything was replaced, in which case the value may contain secrets and shouldn't be logged.
+func Interpolate(value string) (string, bool, error) {
+	interpolated := false
+	var lastErr error
+	result := interpolationRegex.ReplaceAllStringFunc(value, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+
+		reference := match[2 : len(match)-1]
+		if path, ok := strings.CutPrefix(reference, "file:"); ok {
+			contents, err := os.ReadFile(path)
+			if err != nil {
+				lastErr = fmt.Errorf("failed to read %q: %w", path, err)
+				return match
+			}
+
+			interpolated = true
+			return strings.TrimRight(string(contents), "\r\n")
+		}
+
+		envValue, ok := os.LookupEnv(reference)
+		if !ok {
+			lastErr = fmt.Errorf("environment variable %q is not set", reference)
+			return match
+		}
+
+		interpolated = true
+		return envValue
+	})
+
+	if lastErr != nil {
+		return "", false, lastErr
+	}
+
+	return result, interpolated, nil
+}
@@ -0,0 +1,106 @@
+package unmarshal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sinkingpoint/kiora/lib/kiora/config/unmarshal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("KIORA_TEST_VAR", "foo")
+
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("hunter2\n"), 0o600))
+
+	tests := []struct {
+		name                 string
+		value                string
+		expected             string
+		expectedInterpolated bool
+		expectError          bool
+	}{
+		{
+			name:     "no references",
+			value:    "foo $bar {baz}",
+			expected: "foo $bar {baz}",
+		},
+		{
+			name:                 "environment variable",
+			value:                "https://${KIORA_TEST_VAR}.example.com",
+			expected:             "https://foo.example.com",
+			expectedInterpolated: true,
+		},
+		{
+			name:                 "file",
+			value:                "${file:" + secretFile + "}",
+			expected:             "hunter2",
+			expectedInterpolated: true,
+		},
+		{
+			name:     "escaped reference",
+			value:    "$${KIORA_TEST_VAR}",
+			expected: "${KIORA_TEST_VAR}",
+		},
+		{
+			name:     "not an environment variable",
+			value:    "${1}-${foo.bar}",
+			expected: "${1}-${foo.bar}",
+		},
+		{
+			name:        "missing environment variable",
+			value:       "${KIORA_TEST_MISSING_VAR}",
+			expectError: true,
+		},
+		{
+			name:        "missing file",
+			value:       "${file:" + secretFile + ".missing}",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, interpolated, err := unmarshal.Interpolate(tt.value)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, value)
+			require.Equal(t, tt.expectedInterpolated, interpolated)
+		})
+	}
+}
+
+func TestUnmarshalConfigInterpolation(t *testing.T) {
+	t.Setenv("KIORA_TEST_VAR", "42")
+	t.Setenv("KIORA_TEST_SECRET", "not a number")
+
+	type Config struct {
+		Count  int                        `config:"count"`
+		Token  unmarshal.Secret           `config:"token"`
+		APIURL *unmarshal.MaybeSecretFile `config:"api_url"`
+	}
+
+	data := map[string]string{
+		"count":   "${KIORA_TEST_VAR}",
+		"token":   "${KIORA_TEST_SECRET}",
+		"api_url": "https://example.com/${KIORA_TEST_VAR}",
+	}
+
+	var config Config
+	require.NoError(t, unmarshal.UnmarshalConfig(data, &config, unmarshal.UnmarshalOpts{DisallowUnknownFields: true}))
+	require.Equal(t, 42, config.Count)
+	require.Equal(t, unmarshal.Secret("not a number"), config.Token)
+	require.Equal(t, unmarshal.Secret("https://example.com/42"), config.APIURL.Value())
+
+	// Errors parsing interpolated values shouldn't leak them.
+	err := unmarshal.UnmarshalConfig(map[string]string{"count": "${KIORA_TEST_SECRET}"}, &config, unmarshal.UnmarshalOpts{})
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "not a number")
+	require.Contains(t, err.Error(), "<redacted>")
+}
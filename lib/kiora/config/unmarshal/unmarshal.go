@@ -18,6 +18,7 @@ type UnmarshalOpts struct {
 
 // UnmarshalConfig unmarshals a struct from a map of strings to strings using the tags on the exported fields of the struct.
 // When unmarshaling MaybeFiles, it checks both the field name, and the field name suffixed with _file for literal values and files specifically.
+// References to environment variables and files in values are interpolated (see Interpolate) before they're unmarshaled, without modifying the map.
 func UnmarshalConfig(data map[string]string, v interface{}, opts UnmarshalOpts) error {
 	value := reflect.ValueOf(v)
 	if value.Kind() != reflect.Ptr || value.IsNil() {
@@ -29,6 +30,7 @@ func UnmarshalConfig(data map[string]string, v interface{}, opts UnmarshalOpts)
 		return fmt.Errorf("UnmarshalConfig: invalid argument, must be a struct pointer")
 	}
 
+	fileFields := []string{}
 	typ := value.Type()
 	for i := 0; i < typ.NumField(); i++ {
 		field := typ.Field(i)
@@ -61,8 +63,18 @@ func UnmarshalConfig(data map[string]string, v interface{}, opts UnmarshalOpts)
 				}
 			}
 
+			path, _, err := interpolateField(data, fileFieldName)
+			if err != nil {
+				return err
+			}
+
+			literal, _, err := interpolateField(data, fieldName)
+			if err != nil {
+				return err
+			}
+
 			if fieldType == reflect.TypeOf(MaybeFile{}) || fieldType == reflect.TypeOf(&MaybeFile{}) {
-				val, err := NewMaybeFile(data[fileFieldName], data[fieldName])
+				val, err := NewMaybeFile(path, literal)
 				if err != nil {
 					return err
 				}
@@ -73,7 +85,7 @@ func UnmarshalConfig(data map[string]string, v interface{}, opts UnmarshalOpts)
 					fieldValue.Set(reflect.ValueOf(val))
 				}
 			} else if fieldType == reflect.TypeOf(MaybeSecretFile{}) || fieldType == reflect.TypeOf(&MaybeSecretFile{}) {
-				val, err := NewMaybeSecretFile(data[fileFieldName], Secret(data[fieldName]))
+				val, err := NewMaybeSecretFile(path, Secret(literal))
 				if err != nil {
 					return err
 				}
@@ -85,24 +97,39 @@ func UnmarshalConfig(data map[string]string, v interface{}, opts UnmarshalOpts)
 				}
 			}
 
+			// Don't delete these yet, as several fields can be loaded from the same file.
+			fileFields = append(fileFields, fieldName, fileFieldName)
 			continue
 		}
 
-		fieldValueStr, ok := data[fieldName]
-		if !ok {
+		if _, ok := data[fieldName]; !ok {
 			if _, ok := field.Tag.Lookup("required"); ok {
 				return fmt.Errorf("UnmarshalConfig: field %s is required but not found in the config", field.Name)
 			}
 			continue
 		}
 
+		fieldValueStr, interpolated, err := interpolateField(data, fieldName)
+		if err != nil {
+			return err
+		}
+
 		if err := unmarshalValue(fieldValueStr, fieldValueTag); err != nil {
+			// Errors from parsing values generally include the value, which might be a secret if it was interpolated.
+			if interpolated {
+				return fmt.Errorf("UnmarshalConfig: field %s has an invalid value %s", fieldName, Secret(fieldValueStr))
+			}
+
 			return err
 		}
 
 		delete(data, fieldName)
 	}
 
+	for _, fieldName := range fileFields {
+		delete(data, fieldName)
+	}
+
 	if opts.DisallowUnknownFields && len(data) > 0 {
 		return fmt.Errorf("found extra fields while unmarshaling: %v", data)
 	}
@@ -110,6 +137,16 @@ func UnmarshalConfig(data map[string]string, v interface{}, opts UnmarshalOpts)
 	return nil
 }
 
+// interpolateField returns the value of the given field in the data, with any references interpolated.
+func interpolateField(data map[string]string, fieldName string) (string, bool, error) {
+	value, interpolated, err := Interpolate(data[fieldName])
+	if err != nil {
+		return "", false, fmt.Errorf("UnmarshalConfig: failed to interpolate field %s: %w", fieldName, err)
+	}
+
+	return value, interpolated, nil
+}
+
 func unmarshalValue(valueStr string, v interface{}) error {
 	if reflect.TypeOf(v).Kind() != reflect.Ptr {
 		return fmt.Errorf("expected a pointer, got %T", v)
@@ -120,6 +157,11 @@ func unmarshalValue(valueStr string, v interface{}) error {
 		*v = valueStr
 	case **string:
 		*v = &valueStr
+	case *Secret:
+		*v = Secret(valueStr)
+	case **Secret:
+		secret := Secret(valueStr)
+		*v = &secret
 	case *bool, **bool:
 		boolValue, err := strconv.ParseBool(valueStr)
 		if err != nil {
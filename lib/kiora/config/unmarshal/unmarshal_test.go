@@ -101,3 +101,23 @@ func TestUnmarshalConfig_DisallowBothFileAndLiteral(t *testing.T) {
 	err := unmarshal.UnmarshalConfig(data, &config, unmarshal.UnmarshalOpts{})
 	require.Error(t, err, "Expected error")
 }
+
+func TestUnmarshalConfig_FilesAreNotUnknownFields(t *testing.T) {
+	data := map[string]string{
+		"field1":      "value1",
+		"field2_file": "./unmarshal_test.go",
+	}
+
+	type Config struct {
+		Field1 *unmarshal.MaybeSecretFile `config:"field1"`
+		Field2 *unmarshal.MaybeFile       `config:"field2"`
+	}
+
+	var config Config
+	require.NoError(t, unmarshal.UnmarshalConfig(data, &config, unmarshal.UnmarshalOpts{
+		DisallowUnknownFields: true,
+	}))
+
+	require.Equal(t, "value1", string(config.Field1.Value()))
+	require.Contains(t, config.Field2.Value(), "package unmarshal_test")
+}
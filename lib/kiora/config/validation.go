@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError is returned when data is rejected by a config. It explains every path through the config that
+// was tried, and why each one rejected the data, so that users know what they need to change.
+type ValidationError struct {
+	// Leaf is the pseudo-node that the data needed to find a path into.
+	Leaf string
+
+	// Paths are the paths that were tried, none of which accepted the data.
+	Paths []RejectedPath
+}
+
+func (v *ValidationError) Error() string {
+	reasons := make([]string, 0, len(v.Paths))
+	for _, path := range v.Paths {
+		reasons = append(reasons, path.String())
+	}
+
+	return fmt.Sprintf("no path into %q accepted the data: %s", v.Leaf, strings.Join(reasons, "; "))
+}
+
+// RejectedPath is a path through the config that rejected some data.
+type RejectedPath struct {
+	// Path is the names of the nodes that the data reached before it was rejected, in order, starting at a root.
+	Path []string
+
+	// To is the node at the end of the edge whose filter rejected the data, or empty if the path reached a node
+	// with no way forward.
+	To string
+
+	// Filter is the type of the filter that rejected the data, if there was one.
+	Filter string
+
+	// Reason is why the data was rejected.
+	Reason string
+}
+
+func (r RejectedPath) String() string {
+	path := strings.Join(r.Path, " -> ")
+	if r.To == "" {
+		return fmt.Sprintf("%s: %s", path, r.Reason)
+	}
+
+	return fmt.Sprintf("%s -> %s: %s filter failed: %s", path, r.To, r.Filter, r.Reason)
+}
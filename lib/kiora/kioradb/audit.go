@@ -0,0 +1,233 @@
+package kioradb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/sinkingpoint/msgpack/v5"
+	"go.etcd.io/bbolt"
+)
+
+var (
+	_ AuditLog = &BoltDB{}
+	_ AuditLog = &inMemoryDB{}
+	_ AuditLog = &SQLiteDB{}
+)
+
+// AUDIT_BUCKET is the BoltDB bucket that the audit log is stored in.
+const AUDIT_BUCKET = "audit"
+
+// AuditLog is a DB that can also store a log of the changes made through the API.
+type AuditLog interface {
+	// StoreAuditEntries appends the given entries to the audit log.
+	StoreAuditEntries(ctx context.Context, entries ...model.AuditEntry) error
+
+	// QueryAuditEntries returns the entries in the audit log matching the given query, newest first.
+	QueryAuditEntries(ctx context.Context, q query.AuditQuery) ([]model.AuditEntry, error)
+}
+
+// auditKey returns the key that the given entry is stored under in BoltDB. Keys start with the time of the entry
+// so that they're sorted by time, and end with the ID to stop entries at the same time from clobbering each other.
+func auditKey(entry model.AuditEntry) []byte {
+	key := make([]byte, 8, 8+len(entry.ID))
+	binary.BigEndian.PutUint64(key, uint64(entry.Time.UnixNano()))
+	return append(key, entry.ID...)
+}
+
+func (b *BoltDB) StoreAuditEntries(ctx context.Context, entries ...model.AuditEntry) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(AUDIT_BUCKET))
+		if err != nil {
+			return errors.Wrap(err, "failed to create audit bucket")
+		}
+
+		for _, entry := range entries {
+			bytes, err := msgpack.Marshal(entry)
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal audit entry")
+			}
+
+			if err := bucket.Put(auditKey(entry), bytes); err != nil {
+				return errors.Wrap(err, "failed to store audit entry")
+			}
+		}
+
+		return nil
+	})
+}
+
+// QueryAuditEntries reads the audit log directly from BoltDB, rather than the cache, so that the (potentially large)
+// log doesn't have to be kept in memory.
+func (b *BoltDB) QueryAuditEntries(ctx context.Context, q query.AuditQuery) ([]model.AuditEntry, error) {
+	entries := []model.AuditEntry{}
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(AUDIT_BUCKET))
+		if bucket == nil {
+			return nil
+		}
+
+		// Walk backwards from the end of the range, so that we can stop as soon as we have enough entries.
+		cursor := bucket.Cursor()
+		var k, v []byte
+		if q.To.IsZero() {
+			k, v = cursor.Last()
+		} else {
+			end := make([]byte, 8)
+			binary.BigEndian.PutUint64(end, uint64(q.To.UnixNano()))
+			if k, v = cursor.Seek(end); k == nil {
+				k, v = cursor.Last()
+			}
+
+			for k != nil && bytes.Compare(k, end) >= 0 {
+				k, v = cursor.Prev()
+			}
+		}
+
+		for ; k != nil; k, v = cursor.Prev() {
+			var entry model.AuditEntry
+			if err := msgpack.Unmarshal(v, &entry); err != nil {
+				return errors.Wrap(err, "failed to unmarshal audit entry")
+			}
+
+			if !q.From.IsZero() && entry.Time.Before(q.From) {
+				return nil
+			}
+
+			if !q.MatchesEntry(&entry) {
+				continue
+			}
+
+			entries = append(entries, entry)
+			if q.Limit > 0 && len(entries) >= q.Limit {
+				return nil
+			}
+		}
+
+		return nil
+	})
+
+	return entries, err
+}
+
+func (s *SQLiteDB) StoreAuditEntries(ctx context.Context, entries ...model.AuditEntry) error {
+	return s.update(ctx, func(tx *sql.Tx) error {
+		storeEntry, err := tx.PrepareContext(ctx, "INSERT OR REPLACE INTO audit (id, time, actor, tenant, data) VALUES (?, ?, ?, ?, ?)")
+		if err != nil {
+			return errors.Wrap(err, "failed to prepare audit statement")
+		}
+
+		defer storeEntry.Close()
+
+		for _, entry := range entries {
+			bytes, err := msgpack.Marshal(entry)
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal audit entry")
+			}
+
+			if _, err := storeEntry.ExecContext(ctx, entry.ID, timeColumn(entry.Time), entry.Actor, entry.Tenant, bytes); err != nil {
+				return errors.Wrap(err, "failed to store audit entry")
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *SQLiteDB) QueryAuditEntries(ctx context.Context, q query.AuditQuery) ([]model.AuditEntry, error) {
+	conditions := []string{"1"}
+	args := []any{}
+	if !q.From.IsZero() {
+		conditions = append(conditions, "time >= ?")
+		args = append(args, timeColumn(q.From))
+	}
+
+	if !q.To.IsZero() {
+		conditions = append(conditions, "time < ?")
+		args = append(args, timeColumn(q.To))
+	}
+
+	if q.Actor != "" {
+		conditions = append(conditions, "actor = ?")
+		args = append(args, q.Actor)
+	}
+
+	if q.Tenant != nil {
+		conditions = append(conditions, "tenant = ?")
+		args = append(args, *q.Tenant)
+	}
+
+	// Entries at the same time are ordered by ID, like they are in BoltDB.
+	statement := "SELECT data FROM audit WHERE " + strings.Join(conditions, " AND ") + " ORDER BY time DESC, id DESC"
+	if q.Limit > 0 {
+		statement += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query audit log")
+	}
+
+	defer rows.Close()
+
+	entries := []model.AuditEntry{}
+	for rows.Next() {
+		var bytes []byte
+		if err := rows.Scan(&bytes); err != nil {
+			return nil, errors.Wrap(err, "failed to scan audit entry")
+		}
+
+		var entry model.AuditEntry
+		if err := msgpack.Unmarshal(bytes, &entry); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal audit entry")
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, errors.Wrap(rows.Err(), "failed to read audit log")
+}
+
+func (m *inMemoryDB) StoreAuditEntries(ctx context.Context, entries ...model.AuditEntry) error {
+	m.auditLock.Lock()
+	defer m.auditLock.Unlock()
+
+	// Keep the log sorted by time, so that queries can return the newest entries first.
+	for _, entry := range entries {
+		i := sort.Search(len(m.audit), func(i int) bool {
+			return m.audit[i].Time.After(entry.Time)
+		})
+
+		m.audit = append(m.audit, model.AuditEntry{})
+		copy(m.audit[i+1:], m.audit[i:])
+		m.audit[i] = entry
+	}
+
+	return nil
+}
+
+func (m *inMemoryDB) QueryAuditEntries(ctx context.Context, q query.AuditQuery) ([]model.AuditEntry, error) {
+	m.auditLock.RLock()
+	defer m.auditLock.RUnlock()
+
+	entries := []model.AuditEntry{}
+	for i := len(m.audit) - 1; i >= 0; i-- {
+		if !q.MatchesEntry(&m.audit[i]) {
+			continue
+		}
+
+		entries = append(entries, m.audit[i])
+		if q.Limit > 0 && len(entries) >= q.Limit {
+			break
+		}
+	}
+
+	return entries, nil
+}
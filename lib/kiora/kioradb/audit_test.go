@@ -0,0 +1,92 @@
+package kioradb_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	start := time.Unix(1000, 0)
+	entries := []model.AuditEntry{
+		{ID: "1", Time: start, Actor: "alice", Tenant: "foo", Action: model.AuditActionPostSilence},
+		{ID: "2", Time: start.Add(time.Minute), Actor: "bob", Tenant: "bar", Action: model.AuditActionAckAlert},
+		{ID: "3", Time: start.Add(2 * time.Minute), Actor: "alice", Tenant: "foo", Action: model.AuditActionPostAlerts},
+		// Entries at the same time shouldn't clobber each other.
+		{ID: "4", Time: start.Add(2 * time.Minute), Actor: "bob", Tenant: "bar", Action: model.AuditActionReloadConfig},
+	}
+
+	bar := "bar"
+	tests := []struct {
+		name        string
+		query       query.AuditQuery
+		expectedIDs []string
+	}{
+		{
+			name:        "everything is returned newest first",
+			query:       query.AuditQuery{},
+			expectedIDs: []string{"4", "3", "2", "1"},
+		},
+		{
+			name:        "time ranges include the start and exclude the end",
+			query:       query.AuditQuery{From: start.Add(time.Minute), To: start.Add(2 * time.Minute)},
+			expectedIDs: []string{"2"},
+		},
+		{
+			name:        "actors are filtered",
+			query:       query.AuditQuery{Actor: "alice"},
+			expectedIDs: []string{"3", "1"},
+		},
+		{
+			name:        "tenants are filtered",
+			query:       query.AuditQuery{Tenant: &bar},
+			expectedIDs: []string{"4", "2"},
+		},
+		{
+			name:        "limits return the newest entries",
+			query:       query.AuditQuery{Actor: "alice", Limit: 1},
+			expectedIDs: []string{"3"},
+		},
+	}
+
+	boltDB, err := kioradb.NewBoltDB(filepath.Join(t.TempDir(), "kiora.db"), zerolog.Nop())
+	require.NoError(t, err)
+	defer boltDB.Close()
+
+	sqliteDB, err := kioradb.NewSQLiteDB(filepath.Join(t.TempDir(), "kiora.sqlite"), zerolog.Nop())
+	require.NoError(t, err)
+	defer sqliteDB.Close()
+
+	dbs := map[string]kioradb.AuditLog{
+		"inmemory": kioradb.NewInMemoryDB(),
+		"boltdb":   boltDB,
+		"sqlite":   sqliteDB,
+	}
+
+	for dbName, db := range dbs {
+		// Store the entries out of order, to make sure that they're sorted by time.
+		require.NoError(t, db.StoreAuditEntries(context.Background(), entries[2], entries[0], entries[3]))
+		require.NoError(t, db.StoreAuditEntries(context.Background(), entries[1]))
+
+		for _, tt := range tests {
+			t.Run(dbName+"/"+tt.name, func(t *testing.T) {
+				got, err := db.QueryAuditEntries(context.Background(), tt.query)
+				require.NoError(t, err)
+
+				ids := []string{}
+				for _, entry := range got {
+					ids = append(ids, entry.ID)
+				}
+
+				require.Equal(t, tt.expectedIDs, ids)
+			})
+		}
+	}
+}
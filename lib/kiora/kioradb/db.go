@@ -24,6 +24,23 @@ type DB interface {
 	Close() error
 }
 
+// AlertPager is implemented by DBs that can sort and paginate queries for alerts themselves, so that they don't have to return every
+// alert that matches just for it to be counted.
+type AlertPager interface {
+	// QueryAlertPage returns the page of alerts that the given query asks for, and the total number that match it. Returns
+	// query.ErrInvalidCursor if the query starts after a cursor that didn't come from the same query.
+	QueryAlertPage(ctx context.Context, q query.AlertQuery) (query.Page[model.Alert], error)
+}
+
+// QueryAlertPage returns the page of alerts that the given query asks for, letting the DB paginate it if it can.
+func QueryAlertPage(ctx context.Context, db DB, q query.AlertQuery) (query.Page[model.Alert], error) {
+	if pager, ok := db.(AlertPager); ok {
+		return pager.QueryAlertPage(ctx, q)
+	}
+
+	return query.PaginateAlerts(db.QueryAlerts(ctx, query.NewAlertQuery(q.Filter)), q.Query)
+}
+
 func QueryAlertStats(ctx context.Context, db DB, q query.AlertStatsQuery) ([]query.StatsResult, error) {
 	alerts := db.QueryAlerts(ctx, query.NewAlertQuery(q.Filter()))
 	for i := range alerts {
@@ -0,0 +1,114 @@
+package kioradb
+
+import (
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+// set is a set of keys, like alert label hashes, or silence IDs.
+type set[K comparable] map[K]struct{}
+
+// intersect returns the keys that are in every one of the given sets. If there's only one set, it's returned as is, so the result
+// must not be modified.
+func intersect[K comparable](sets ...set[K]) set[K] {
+	if len(sets) == 1 {
+		return sets[0]
+	}
+
+	smallest := 0
+	for i := range sets {
+		if len(sets[i]) < len(sets[smallest]) {
+			smallest = i
+		}
+	}
+
+	result := set[K]{}
+outer:
+	for key := range sets[smallest] {
+		for i := range sets {
+			if _, ok := sets[i][key]; !ok {
+				continue outer
+			}
+		}
+
+		result[key] = struct{}{}
+	}
+
+	return result
+}
+
+// union adds all the keys in the given sets to dest.
+func union[K comparable](dest set[K], sets ...set[K]) set[K] {
+	for _, s := range sets {
+		for key := range s {
+			dest[key] = struct{}{}
+		}
+	}
+
+	return dest
+}
+
+// labelIndex is an inverted index from label names and values to the keys of the things that have them.
+type labelIndex[K comparable] struct {
+	labels map[string]map[string]set[K]
+}
+
+func newLabelIndex[K comparable]() *labelIndex[K] {
+	return &labelIndex[K]{
+		labels: map[string]map[string]set[K]{},
+	}
+}
+
+func (l *labelIndex[K]) add(name, value string, key K) {
+	values, ok := l.labels[name]
+	if !ok {
+		values = map[string]set[K]{}
+		l.labels[name] = values
+	}
+
+	keys, ok := values[value]
+	if !ok {
+		keys = set[K]{}
+		values[value] = keys
+	}
+
+	keys[key] = struct{}{}
+}
+
+func (l *labelIndex[K]) remove(name, value string, key K) {
+	values := l.labels[name]
+	keys := values[value]
+	delete(keys, key)
+
+	// Clean up after ourselves so that labels with lots of unique values (like IDs) don't leak.
+	if len(keys) == 0 {
+		delete(values, value)
+	}
+
+	if len(values) == 0 {
+		delete(l.labels, name)
+	}
+}
+
+// get returns the keys of the things with the given label. The result must not be modified.
+func (l *labelIndex[K]) get(name, value string) set[K] {
+	return l.labels[name][value]
+}
+
+// matching returns the keys of the things with labels that the given matcher matches. The result must not be modified.
+func (l *labelIndex[K]) matching(matcher *model.Matcher) set[K] {
+	values := l.labels[matcher.Label]
+	if !matcher.IsRegex && !matcher.IsNegative {
+		return values[matcher.Value]
+	}
+
+	// Regex and negative matchers can still only match things that have the label, so we only have to check each of its values
+	// rather than everything.
+	result := set[K]{}
+	for value, keys := range values {
+		if matcher.Matches(model.Labels{matcher.Label: value}) {
+			union(result, keys)
+		}
+	}
+
+	return result
+}
@@ -16,17 +16,34 @@ type inMemoryDB struct {
 	aLock  sync.RWMutex
 	alerts map[model.LabelsHash]model.Alert
 
+	// alertLabels and alertStatuses index alerts by their labels and statuses, so that queries on them don't have to scan every alert.
+	alertLabels   *labelIndex[model.LabelsHash]
+	alertStatuses map[model.AlertStatus]set[model.LabelsHash]
+
 	sLock    sync.RWMutex
 	silences map[string]model.Silence
+
+	// silenceLabels indexes silences by the labels that their equality matchers require an alert to have. unindexedSilences are the
+	// silences without any equality matchers, which could match any alert.
+	silenceLabels     *labelIndex[string]
+	unindexedSilences set[string]
+
+	// audit is the audit log, sorted by time.
+	auditLock sync.RWMutex
+	audit     []model.AuditEntry
 }
 
 func NewInMemoryDB() *inMemoryDB {
 	return &inMemoryDB{
-		aLock:  sync.RWMutex{},
-		alerts: make(map[model.LabelsHash]model.Alert),
+		aLock:         sync.RWMutex{},
+		alerts:        make(map[model.LabelsHash]model.Alert),
+		alertLabels:   newLabelIndex[model.LabelsHash](),
+		alertStatuses: make(map[model.AlertStatus]set[model.LabelsHash]),
 
-		sLock:    sync.RWMutex{},
-		silences: make(map[string]model.Silence),
+		sLock:             sync.RWMutex{},
+		silences:          make(map[string]model.Silence),
+		silenceLabels:     newLabelIndex[string](),
+		unindexedSilences: make(set[string]),
 	}
 }
 
@@ -34,10 +51,14 @@ func (m *inMemoryDB) Clear() {
 	m.aLock.Lock()
 	defer m.aLock.Unlock()
 	m.alerts = make(map[model.LabelsHash]model.Alert)
+	m.alertLabels = newLabelIndex[model.LabelsHash]()
+	m.alertStatuses = make(map[model.AlertStatus]set[model.LabelsHash])
 
 	m.sLock.Lock()
 	defer m.sLock.Unlock()
 	m.silences = make(map[string]model.Silence)
+	m.silenceLabels = newLabelIndex[string]()
+	m.unindexedSilences = make(set[string])
 }
 
 func (m *inMemoryDB) storeAlert(alert model.Alert) {
@@ -45,7 +66,36 @@ func (m *inMemoryDB) storeAlert(alert model.Alert) {
 
 	m.aLock.Lock()
 	defer m.aLock.Unlock()
+	if existing, ok := m.alerts[labelsHash]; ok {
+		m.unindexAlert(labelsHash, &existing)
+	}
+
 	m.alerts[labelsHash] = alert
+	m.indexAlert(labelsHash, &alert)
+}
+
+// indexAlert adds the given alert to the label and status indexes. aLock must be held.
+func (m *inMemoryDB) indexAlert(hash model.LabelsHash, alert *model.Alert) {
+	for name, value := range alert.Labels {
+		m.alertLabels.add(name, value, hash)
+	}
+
+	statuses, ok := m.alertStatuses[alert.Status]
+	if !ok {
+		statuses = set[model.LabelsHash]{}
+		m.alertStatuses[alert.Status] = statuses
+	}
+
+	statuses[hash] = struct{}{}
+}
+
+// unindexAlert removes the given alert from the label and status indexes. aLock must be held.
+func (m *inMemoryDB) unindexAlert(hash model.LabelsHash, alert *model.Alert) {
+	for name, value := range alert.Labels {
+		m.alertLabels.remove(name, value, hash)
+	}
+
+	delete(m.alertStatuses[alert.Status], hash)
 }
 
 func (m *inMemoryDB) StoreAlerts(ctx context.Context, alerts ...model.Alert) error {
@@ -69,9 +119,19 @@ func (m *inMemoryDB) QueryAlerts(ctx context.Context, q query.AlertQuery) []mode
 		return []model.Alert{}
 	default:
 		alerts := []model.Alert{}
-		for _, alert := range m.alerts {
-			if filter == nil || filter.MatchesAlert(ctx, &alert) {
-				alerts = append(alerts, alert)
+		if candidates, ok := m.alertCandidates(filter); ok {
+			// The indexes only narrow down the alerts that could match, so we still have to check each candidate against the whole filter.
+			for hash := range candidates {
+				alert := m.alerts[hash]
+				if filter.MatchesAlert(ctx, &alert) {
+					alerts = append(alerts, alert)
+				}
+			}
+		} else {
+			for _, alert := range m.alerts {
+				if filter == nil || filter.MatchesAlert(ctx, &alert) {
+					alerts = append(alerts, alert)
+				}
 			}
 		}
 
@@ -84,22 +144,123 @@ func (m *inMemoryDB) QueryAlerts(ctx context.Context, q query.AlertQuery) []mode
 	}
 }
 
+// alertCandidates returns the alerts that could match the given filter, using the indexes. It returns false if the indexes can't narrow
+// the alerts down, and every alert has to be checked. aLock must be held, and the result must not be modified.
+func (m *inMemoryDB) alertCandidates(filter query.AlertFilter) (set[model.LabelsHash], bool) {
+	switch filter := filter.(type) {
+	case *query.ExactLabelMatchFilter:
+		hash := filter.Labels.Hash()
+		if _, ok := m.alerts[hash]; ok {
+			return set[model.LabelsHash]{hash: {}}, true
+		}
+
+		return set[model.LabelsHash]{}, true
+	case *query.PartialLabelMatchFilter:
+		if len(filter.Labels) == 0 {
+			return nil, false
+		}
+
+		sets := make([]set[model.LabelsHash], 0, len(filter.Labels))
+		for name, value := range filter.Labels {
+			sets = append(sets, m.alertLabels.get(name, value))
+		}
+
+		return intersect(sets...), true
+	case *query.StatusFilter:
+		return m.alertStatuses[filter.Status], true
+	case *query.MatcherFilter:
+		matcher := filter.Matcher()
+		return m.alertLabels.matching(&matcher), true
+	case *query.AllFilter:
+		// Every filter has to match, so we only need to check the alerts that every indexable filter allows.
+		sets := []set[model.LabelsHash]{}
+		for _, f := range filter.AlertFilters() {
+			if candidates, ok := m.alertCandidates(f); ok {
+				sets = append(sets, candidates)
+			}
+		}
+
+		if len(sets) == 0 {
+			return nil, false
+		}
+
+		return intersect(sets...), true
+	case *query.AnyFilter:
+		// Any of the filters can match, so if one of them can't be indexed, we have to check every alert.
+		candidates := set[model.LabelsHash]{}
+		for _, f := range filter.AlertFilters() {
+			filterCandidates, ok := m.alertCandidates(f)
+			if !ok {
+				return nil, false
+			}
+
+			union(candidates, filterCandidates)
+		}
+
+		return candidates, true
+	}
+
+	return nil, false
+}
+
 func (m *inMemoryDB) StoreSilences(ctx context.Context, silences ...model.Silence) error {
 	m.sLock.Lock()
 	defer m.sLock.Unlock()
 	for i := range silences {
+		if existing, ok := m.silences[silences[i].ID]; ok {
+			m.unindexSilence(&existing)
+		}
+
 		m.silences[silences[i].ID] = silences[i]
+		m.indexSilence(&silences[i])
 	}
 
 	return nil
 }
 
-func (m *inMemoryDB) QuerySilences(ctx context.Context, query query.SilenceQuery) []model.Silence {
+// indexSilence adds the given silence to the label index. sLock must be held.
+func (m *inMemoryDB) indexSilence(silence *model.Silence) {
+	indexed := false
+	for _, matcher := range silence.Matchers {
+		if !matcher.IsRegex && !matcher.IsNegative {
+			m.silenceLabels.add(matcher.Label, matcher.Value, silence.ID)
+			indexed = true
+		}
+	}
+
+	if !indexed {
+		m.unindexedSilences[silence.ID] = struct{}{}
+	}
+}
+
+// unindexSilence removes the given silence from the label index. sLock must be held.
+func (m *inMemoryDB) unindexSilence(silence *model.Silence) {
+	for _, matcher := range silence.Matchers {
+		if !matcher.IsRegex && !matcher.IsNegative {
+			m.silenceLabels.remove(matcher.Label, matcher.Value, silence.ID)
+		}
+	}
+
+	delete(m.unindexedSilences, silence.ID)
+}
+
+func (m *inMemoryDB) QuerySilences(ctx context.Context, q query.SilenceQuery) []model.Silence {
 	m.sLock.RLock()
 	defer m.sLock.RUnlock()
 	silences := []model.Silence{}
+	if candidates, ok := m.silenceCandidates(q.Filter); ok {
+		for id := range candidates {
+			silence := m.silences[id]
+			if q.Filter.MatchesSilence(ctx, &silence) {
+				silences = append(silences, silence)
+			}
+		}
+
+		return silences
+	}
+
 	for _, silence := range m.silences {
-		if query.Filter.MatchesSilence(ctx, &silence) {
+		if q.Filter.MatchesSilence(ctx, &silence) {
 			silences = append(silences, silence)
 		}
 	}
@@ -107,6 +268,50 @@ func (m *inMemoryDB) QuerySilences(ctx context.Context, query query.SilenceQuery
 	return silences
 }
 
+// silenceCandidates returns the silences that could match the given filter, using the index. It returns false if the index can't narrow
+// the silences down, and every silence has to be checked. sLock must be held, and the result must not be modified.
+func (m *inMemoryDB) silenceCandidates(filter query.SilenceFilter) (set[string], bool) {
+	switch filter := filter.(type) {
+	case *query.IDFilter:
+		if _, ok := m.silences[filter.ID]; ok {
+			return set[string]{filter.ID: {}}, true
+		}
+
+		return set[string]{}, true
+	case *query.PartialLabelMatchFilter:
+		// A silence can only match the labels if every one of its equality matchers does, so it has to be indexed under one of the labels,
+		// or not be indexed at all.
+		candidates := union(set[string]{}, m.unindexedSilences)
+		for name, value := range filter.Labels {
+			union(candidates, m.silenceLabels.get(name, value))
+		}
+
+		return candidates, true
+	case *query.MatcherFilter:
+		matcher := filter.Matcher()
+		if matcher.IsRegex || matcher.IsNegative {
+			return nil, false
+		}
+
+		return m.silenceLabels.get(matcher.Label, matcher.Value), true
+	case *query.AllFilter:
+		sets := []set[string]{}
+		for _, f := range filter.SilenceFilters() {
+			if candidates, ok := m.silenceCandidates(f); ok {
+				sets = append(sets, candidates)
+			}
+		}
+
+		if len(sets) == 0 {
+			return nil, false
+		}
+
+		return intersect(sets...), true
+	}
+
+	return nil, false
+}
+
 func (m *inMemoryDB) Close() error {
 	return nil
 }
@@ -2,7 +2,9 @@ package kioradb_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/sinkingpoint/kiora/lib/kiora/kioradb"
 	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
@@ -86,3 +88,245 @@ func TestInMemoryDB(t *testing.T) {
 		}
 	}
 }
+
+// unindexed wraps a filter so that the DB can't use its indexes for it, and has to check every alert.
+func unindexed(filter query.AlertFilter) query.AlertFilter {
+	return query.AlertFilterFunc(filter.MatchesAlert)
+}
+
+func TestInMemoryDBIndexes(t *testing.T) {
+	db := kioradb.NewInMemoryDB()
+
+	statuses := []model.AlertStatus{model.AlertStatusFiring, model.AlertStatusResolved, model.AlertStatusAcked}
+	for i := 0; i < 30; i++ {
+		require.NoError(t, db.StoreAlerts(context.Background(), model.Alert{
+			Labels: model.Labels{
+				"alertname": fmt.Sprintf("alert-%d", i%5),
+				"team":      fmt.Sprintf("team-%d", i%3),
+				"instance":  fmt.Sprintf("instance-%d", i),
+			},
+			Status: statuses[i%len(statuses)],
+		}))
+	}
+
+	// Update an alert so that its old status has to be removed from the index.
+	require.NoError(t, db.StoreAlerts(context.Background(), model.Alert{
+		Labels: model.Labels{"alertname": "alert-0", "team": "team-0", "instance": "instance-0"},
+		Status: model.AlertStatusTimedOut,
+	}))
+
+	regexMatcher, err := model.LabelValueRegexMatcher("alertname", "alert-[12]")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		filter   query.AlertFilter
+		expected int
+	}{
+		{
+			name:     "exact",
+			filter:   query.ExactLabelMatch(model.Labels{"alertname": "alert-1", "team": "team-1", "instance": "instance-1"}),
+			expected: 1,
+		},
+		{
+			name:     "partial",
+			filter:   query.PartialLabelMatch(model.Labels{"alertname": "alert-1", "team": "team-1"}),
+			expected: 2,
+		},
+		{
+			name:     "partial with a missing label",
+			filter:   query.PartialLabelMatch(model.Labels{"alertname": "alert-1", "foo": "bar"}),
+			expected: 0,
+		},
+		{
+			name:     "status",
+			filter:   query.Status(model.AlertStatusFiring),
+			expected: 9,
+		},
+		{
+			name:     "updated status",
+			filter:   query.Status(model.AlertStatusTimedOut),
+			expected: 1,
+		},
+		{
+			name:     "matcher",
+			filter:   query.Matcher(model.Matcher{Label: "team", Value: "team-2"}),
+			expected: 10,
+		},
+		{
+			name:     "regex matcher",
+			filter:   query.Matcher(regexMatcher),
+			expected: 12,
+		},
+		{
+			name:     "negative matcher",
+			filter:   query.Matcher(*regexMatcher.Negate()),
+			expected: 18,
+		},
+		{
+			name:     "all",
+			filter:   query.AllAlerts(query.Status(model.AlertStatusResolved), query.PartialLabelMatch(model.Labels{"alertname": "alert-1"})),
+			expected: 2,
+		},
+		{
+			name:     "all with unindexed filters",
+			filter:   query.AllAlerts(query.Status(model.AlertStatusFiring), query.NotAlerts(query.PartialLabelMatch(model.Labels{"alertname": "alert-0"}))),
+			expected: 8,
+		},
+		{
+			name:     "any",
+			filter:   query.AnyAlerts(query.Status(model.AlertStatusAcked), query.PartialLabelMatch(model.Labels{"alertname": "alert-0"})),
+			expected: 14,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexed := db.QueryAlerts(context.Background(), query.NewAlertQuery(tt.filter))
+			scanned := db.QueryAlerts(context.Background(), query.NewAlertQuery(unindexed(tt.filter)))
+			require.Len(t, indexed, tt.expected)
+			require.ElementsMatch(t, scanned, indexed)
+		})
+	}
+}
+
+func TestInMemoryDBSilenceIndexes(t *testing.T) {
+	db := kioradb.NewInMemoryDB()
+
+	regexMatcher, err := model.LabelValueRegexMatcher("alertname", "disk.*")
+	require.NoError(t, err)
+
+	require.NoError(t, db.StoreSilences(context.Background(),
+		model.Silence{ID: "team", Matchers: []model.Matcher{{Label: "team", Value: "foo"}}},
+		model.Silence{ID: "team-and-alert", Matchers: []model.Matcher{{Label: "team", Value: "foo"}, {Label: "alertname", Value: "cpu"}}},
+		model.Silence{ID: "regex", Matchers: []model.Matcher{regexMatcher}},
+		model.Silence{ID: "updated", Matchers: []model.Matcher{{Label: "team", Value: "bar"}}},
+	))
+
+	// Move a silence to a different label, so that it has to be removed from its old one.
+	require.NoError(t, db.StoreSilences(context.Background(), model.Silence{ID: "updated", Matchers: []model.Matcher{{Label: "team", Value: "baz"}}}))
+
+	tests := []struct {
+		name     string
+		filter   query.SilenceFilter
+		expected []string
+	}{
+		{
+			name:     "partial",
+			filter:   query.PartialLabelMatch(model.Labels{"team": "foo", "alertname": "cpu"}),
+			expected: []string{"team", "team-and-alert"},
+		},
+		{
+			name:     "partial with unindexed silences",
+			filter:   query.PartialLabelMatch(model.Labels{"team": "foo", "alertname": "disk_full"}),
+			expected: []string{"team", "regex"},
+		},
+		{
+			name:     "partial with an updated silence",
+			filter:   query.PartialLabelMatch(model.Labels{"team": "bar"}),
+			expected: []string{},
+		},
+		{
+			name:     "matcher",
+			filter:   query.Matcher(model.Matcher{Label: "team", Value: "baz"}),
+			expected: []string{"updated"},
+		},
+		{
+			name:     "id",
+			filter:   query.AllSilences(query.ID("regex"), query.PartialLabelMatch(model.Labels{"alertname": "disk_full"})),
+			expected: []string{"regex"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := []string{}
+			for _, silence := range db.QuerySilences(context.Background(), query.NewSilenceQuery(tt.filter)) {
+				ids = append(ids, silence.ID)
+			}
+
+			require.ElementsMatch(t, tt.expected, ids)
+		})
+	}
+}
+
+func BenchmarkInMemoryDBQueryAlerts(b *testing.B) {
+	const numAlerts = 100000
+
+	db := kioradb.NewInMemoryDB()
+	alerts := make([]model.Alert, 0, numAlerts)
+	for i := 0; i < numAlerts; i++ {
+		status := model.AlertStatusResolved
+		if i%100 == 0 {
+			status = model.AlertStatusFiring
+		}
+
+		alerts = append(alerts, model.Alert{
+			Labels: model.Labels{
+				"alertname": fmt.Sprintf("alert-%d", i%1000),
+				"team":      fmt.Sprintf("team-%d", i%50),
+				"instance":  fmt.Sprintf("instance-%d", i),
+			},
+			Status: status,
+		})
+	}
+
+	require.NoError(b, db.StoreAlerts(context.Background(), alerts...))
+
+	regexMatcher, err := model.LabelValueRegexMatcher("alertname", "alert-1.*")
+	require.NoError(b, err)
+
+	filters := []struct {
+		name   string
+		filter query.AlertFilter
+	}{
+		{"exact", query.ExactLabelMatch(alerts[42].Labels)},
+		{"partial", query.PartialLabelMatch(model.Labels{"alertname": "alert-42", "team": "team-42"})},
+		{"status", query.Status(model.AlertStatusFiring)},
+		{"matcher", query.Matcher(model.Matcher{Label: "team", Value: "team-7"})},
+		{"regex matcher", query.Matcher(regexMatcher)},
+		{"notify firing", query.AllAlerts(query.Status(model.AlertStatusFiring), query.LastNotifyTimeMax(time.Now()))},
+	}
+
+	for _, f := range filters {
+		b.Run(f.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				db.QueryAlerts(context.Background(), query.NewAlertQuery(f.filter))
+			}
+		})
+
+		b.Run(f.name+" unindexed", func(b *testing.B) {
+			filter := unindexed(f.filter)
+			for i := 0; i < b.N; i++ {
+				db.QueryAlerts(context.Background(), query.NewAlertQuery(filter))
+			}
+		})
+	}
+}
+
+func BenchmarkInMemoryDBQuerySilences(b *testing.B) {
+	const numSilences = 100000
+
+	db := kioradb.NewInMemoryDB()
+	silences := make([]model.Silence, 0, numSilences)
+	for i := 0; i < numSilences; i++ {
+		silences = append(silences, model.Silence{
+			ID:        fmt.Sprintf("silence-%d", i),
+			StartTime: time.Now(),
+			EndTime:   time.Now().Add(time.Hour),
+			Matchers: []model.Matcher{
+				{Label: "alertname", Value: fmt.Sprintf("alert-%d", i%1000)},
+				{Label: "instance", Value: fmt.Sprintf("instance-%d", i)},
+			},
+		})
+	}
+
+	require.NoError(b, db.StoreSilences(context.Background(), silences...))
+
+	// This is the query that every firing alert is checked against when it comes in.
+	filter := query.AllSilences(query.PartialLabelMatch(model.Labels{"alertname": "alert-42", "instance": "instance-42"}), query.SilenceIsActive())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		db.QuerySilences(context.Background(), query.NewSilenceQuery(filter))
+	}
+}
@@ -0,0 +1,42 @@
+package query
+
+import (
+	"time"
+
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+// AuditQuery is a query for entries in the audit log. Entries are always returned newest first.
+type AuditQuery struct {
+	// Limit is the maximum number of entries to return. Zero means no limit.
+	Limit int
+
+	// From only returns entries at or after the given time, if it's set.
+	From time.Time
+
+	// To only returns entries before the given time, if it's set.
+	To time.Time
+
+	// Actor only returns entries made by the given actor, if it's set.
+	Actor string
+
+	// Tenant only returns entries made in the given tenant, if it's set.
+	Tenant *string
+}
+
+// MatchesEntry returns true if the given entry is in the range, and made by the actor and in the tenant, of the query.
+func (q AuditQuery) MatchesEntry(entry *model.AuditEntry) bool {
+	if !q.From.IsZero() && entry.Time.Before(q.From) {
+		return false
+	}
+
+	if !q.To.IsZero() && !entry.Time.Before(q.To) {
+		return false
+	}
+
+	if q.Tenant != nil && entry.Tenant != *q.Tenant {
+		return false
+	}
+
+	return q.Actor == "" || entry.Actor == q.Actor
+}
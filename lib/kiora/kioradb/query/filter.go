@@ -262,6 +262,75 @@ func AllSilences(queries ...SilenceFilter) *AllFilter {
 	}
 }
 
+// AlertFilters returns the filters that alerts have to match.
+func (a *AllFilter) AlertFilters() []AlertFilter {
+	return a.alertQueries
+}
+
+// SilenceFilters returns the filters that silences have to match.
+func (a *AllFilter) SilenceFilters() []SilenceFilter {
+	return a.silenceQueries
+}
+
+// AnyFilter is a filter that matches alerts that match any of the given filters.
+type AnyFilter struct {
+	alertQueries []AlertFilter
+}
+
+func AnyAlerts(queries ...AlertFilter) *AnyFilter {
+	return &AnyFilter{
+		alertQueries: queries,
+	}
+}
+
+func (a *AnyFilter) Type() string {
+	alertTypes := []string{}
+	for _, q := range a.alertQueries {
+		alertTypes = append(alertTypes, q.Type())
+	}
+
+	return fmt.Sprintf("any(%s)", strings.Join(alertTypes, ","))
+}
+
+// AlertFilters returns the filters that alerts have to match one of.
+func (a *AnyFilter) AlertFilters() []AlertFilter {
+	return a.alertQueries
+}
+
+func (a *AnyFilter) MatchesAlert(ctx context.Context, alert *model.Alert) bool {
+	for _, q := range a.alertQueries {
+		if q.MatchesAlert(ctx, alert) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NotFilter is a filter that matches alerts that don't match the given filter.
+type NotFilter struct {
+	alertQuery AlertFilter
+}
+
+func NotAlerts(query AlertFilter) *NotFilter {
+	return &NotFilter{
+		alertQuery: query,
+	}
+}
+
+func (n *NotFilter) Type() string {
+	return fmt.Sprintf("not(%s)", n.alertQuery.Type())
+}
+
+// AlertFilter returns the filter that alerts must not match.
+func (n *NotFilter) AlertFilter() AlertFilter {
+	return n.alertQuery
+}
+
+func (n *NotFilter) MatchesAlert(ctx context.Context, alert *model.Alert) bool {
+	return !n.alertQuery.MatchesAlert(ctx, alert)
+}
+
 // IDFilter is a query that matches a specific alert or silence by ID.
 type IDFilter struct {
 	ID string
@@ -292,6 +361,22 @@ func SilenceIsActive() SilenceFilter {
 	})
 }
 
+// SilenceTenantFilter is a SilenceFilter that matches the silences that apply to the given tenant - the silences in it, and the
+// silences without a tenant, which apply to every tenant.
+type SilenceTenantFilter struct {
+	Tenant string
+}
+
+func SilenceTenant(tenant string) *SilenceTenantFilter {
+	return &SilenceTenantFilter{
+		Tenant: tenant,
+	}
+}
+
+func (s *SilenceTenantFilter) MatchesSilence(ctx context.Context, silence *model.Silence) bool {
+	return silence.Tenant == s.Tenant || silence.Tenant == ""
+}
+
 // MatcherFilter is a Filter that matches alerts or silences that contain the given matcher.
 type MatcherFilter struct {
 	matcher model.Matcher
@@ -307,6 +392,11 @@ func (i *MatcherFilter) Type() string {
 	return "matcher"
 }
 
+// Matcher returns the matcher that the filter checks for.
+func (m *MatcherFilter) Matcher() model.Matcher {
+	return m.matcher
+}
+
 // MatchesAlert returns true if the given matcher matcher the given alert.
 func (m *MatcherFilter) MatchesAlert(ctx context.Context, alert *model.Alert) bool {
 	return m.matcher.Matches(alert.Labels)
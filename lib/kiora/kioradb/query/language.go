@@ -0,0 +1,557 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/internal/stubs"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+// Comparison is an operator that compares a field of an alert to a value.
+type Comparison string
+
+const (
+	ComparisonEqual          Comparison = "="
+	ComparisonNotEqual       Comparison = "!="
+	ComparisonRegex          Comparison = "=~"
+	ComparisonNotRegex       Comparison = "!~"
+	ComparisonLess           Comparison = "<"
+	ComparisonLessOrEqual    Comparison = "<="
+	ComparisonGreater        Comparison = ">"
+	ComparisonGreaterOrEqual Comparison = ">="
+)
+
+// statusField is the field that alert queries can use to refer to the status of an alert, as a shorthand for `__status__`.
+const statusField = "status"
+
+// specialFields are the fields of alerts, other than labels and annotations, that queries can refer to.
+var specialFields = map[string]struct{}{
+	"__id__":               {},
+	"__status__":           {},
+	"__starts_at__":        {},
+	"__ends_at__":          {},
+	"__timeout_deadline__": {},
+	"__last_notify_time__": {},
+	"__ack_creator__":      {},
+	"__ack_comment__":      {},
+}
+
+// timeFields are the fields of alerts that are compared as times.
+var timeFields = map[string]struct{}{
+	"__starts_at__":        {},
+	"__ends_at__":          {},
+	"__timeout_deadline__": {},
+	"__last_notify_time__": {},
+}
+
+// relativeTime is a time in a query, which is either absolute, or relative to the time that it's evaluated at.
+type relativeTime struct {
+	relative bool
+	offset   time.Duration
+	absolute time.Time
+}
+
+// parseTime parses `now`, `now-1h`, `now+30m`, or an RFC3339 time.
+func parseTime(value string) (relativeTime, error) {
+	rest, ok := strings.CutPrefix(value, "now")
+	if !ok {
+		absolute, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return relativeTime{}, fmt.Errorf("invalid time %q: expected `now`, `now-<duration>`, or an RFC3339 time", value)
+		}
+
+		return relativeTime{absolute: absolute}, nil
+	}
+
+	if rest == "" {
+		return relativeTime{relative: true}, nil
+	}
+
+	if rest[0] != '-' && rest[0] != '+' {
+		return relativeTime{}, fmt.Errorf("invalid time %q: expected `now-<duration>` or `now+<duration>`", value)
+	}
+
+	offset, err := time.ParseDuration(rest[1:])
+	if err != nil {
+		return relativeTime{}, errors.Wrapf(err, "invalid duration in time %q", value)
+	}
+
+	if rest[0] == '-' {
+		offset = -offset
+	}
+
+	return relativeTime{relative: true, offset: offset}, nil
+}
+
+func (r relativeTime) at(now time.Time) time.Time {
+	if r.relative {
+		return now.Add(r.offset)
+	}
+
+	return r.absolute
+}
+
+// FieldComparisonFilter is an AlertFilter that matches alerts where the given field compares to the given value. Time fields are compared as
+// times, with times like `now-1h` being relative to when the alert is matched, other fields are compared as numbers if both sides are numbers,
+// and as strings otherwise. Alerts without the field never match.
+type FieldComparisonFilter struct {
+	Field      string
+	Comparison Comparison
+	Value      string
+
+	regex *regexp.Regexp
+	time  *relativeTime
+}
+
+func FieldComparison(field string, comparison Comparison, value string) (*FieldComparisonFilter, error) {
+	filter := &FieldComparisonFilter{
+		Field:      field,
+		Comparison: comparison,
+		Value:      value,
+	}
+
+	_, isTime := timeFields[field]
+	switch comparison {
+	case ComparisonRegex, ComparisonNotRegex:
+		if isTime {
+			return nil, fmt.Errorf("can't match time field %q against a regex", field)
+		}
+
+		regex, err := regexp.Compile(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compile regex for field %q", field)
+		}
+
+		filter.regex = regex
+	case ComparisonEqual, ComparisonNotEqual, ComparisonLess, ComparisonLessOrEqual, ComparisonGreater, ComparisonGreaterOrEqual:
+	default:
+		return nil, fmt.Errorf("unknown comparison %q", comparison)
+	}
+
+	if isTime {
+		t, err := parseTime(value)
+		if err != nil {
+			return nil, err
+		}
+
+		filter.time = &t
+	}
+
+	return filter, nil
+}
+
+func (f *FieldComparisonFilter) Type() string {
+	return "field_comparison"
+}
+
+func (f *FieldComparisonFilter) MatchesAlert(ctx context.Context, alert *model.Alert) bool {
+	value, err := alert.Field(f.Field)
+	if err != nil {
+		return false
+	}
+
+	if f.regex != nil {
+		matches := f.regex.MatchString(fmt.Sprint(value))
+		return matches == (f.Comparison == ComparisonRegex)
+	}
+
+	var cmp int
+	switch value := value.(type) {
+	case time.Time:
+		if value.IsZero() || f.time == nil {
+			return false
+		}
+
+		cmp = value.Compare(f.time.at(stubs.Time.Now()))
+	default:
+		cmp = compareValues(fmt.Sprint(value), f.Value)
+	}
+
+	switch f.Comparison {
+	case ComparisonEqual:
+		return cmp == 0
+	case ComparisonNotEqual:
+		return cmp != 0
+	case ComparisonLess:
+		return cmp < 0
+	case ComparisonLessOrEqual:
+		return cmp <= 0
+	case ComparisonGreater:
+		return cmp > 0
+	case ComparisonGreaterOrEqual:
+		return cmp >= 0
+	default:
+		return false
+	}
+}
+
+// compareValues compares the given values as numbers if they both are, and as strings otherwise.
+func compareValues(a, b string) int {
+	aNum, aErr := strconv.ParseFloat(a, 64)
+	bNum, bErr := strconv.ParseFloat(b, 64)
+	if aErr != nil || bErr != nil {
+		return strings.Compare(a, b)
+	}
+
+	switch {
+	case aNum < bNum:
+		return -1
+	case aNum > bNum:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ParseAlertFilter parses an alert query into a filter. Queries are comparisons of fields to values, combined with `and`, `or`, `not`,
+// and parentheses, e.g. `severity="critical" and status in (firing, acked) and __starts_at__ > now-1h`. Fields are either labels, `status`,
+// annotations like `__annotation_runbook__`, or one of the special fields like `__starts_at__` that alerts can be sorted by. Comparisons
+// are one of `=`, `!=`, `=~`, `!~`, `<`, `<=`, `>`, `>=`, `in (...)`, or `not in (...)`, and values can be quoted if they contain spaces
+// or operators.
+func ParseAlertFilter(query string) (AlertFilter, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, errors.New("empty query")
+	}
+
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.unexpected(tok, "`and` or `or`")
+	}
+
+	return filter, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenComparison
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+)
+
+type queryToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t queryToken) String() string {
+	if t.kind == tokenEOF {
+		return "end of query"
+	}
+
+	return fmt.Sprintf("%q", t.text)
+}
+
+// wordBreaks are the characters that end an unquoted word.
+const wordBreaks = "()\",=!<>~"
+
+func lexQuery(query string) ([]queryToken, error) {
+	tokens := []queryToken{}
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: tokenLeftParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: tokenRightParen, text: ")", pos: i})
+			i++
+		case c == ',':
+			tokens = append(tokens, queryToken{kind: tokenComma, text: ",", pos: i})
+			i++
+		case c == '"':
+			value := strings.Builder{}
+			j := i + 1
+			for ; j < len(query) && query[j] != '"'; j++ {
+				// Only quotes and backslashes are escaped, so that regexes don't have to be double escaped.
+				if query[j] == '\\' && j+1 < len(query) && (query[j+1] == '"' || query[j+1] == '\\') {
+					j++
+				}
+
+				value.WriteByte(query[j])
+			}
+
+			if j == len(query) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+
+			tokens = append(tokens, queryToken{kind: tokenString, text: value.String(), pos: i})
+			i = j + 1
+		case strings.IndexByte("=!<>~", c) >= 0:
+			op := ""
+			for _, candidate := range []Comparison{ComparisonNotEqual, ComparisonRegex, ComparisonNotRegex, ComparisonLessOrEqual, ComparisonGreaterOrEqual, ComparisonEqual, ComparisonLess, ComparisonGreater} {
+				if strings.HasPrefix(query[i:], string(candidate)) {
+					op = string(candidate)
+					break
+				}
+			}
+
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at position %d", c, i)
+			}
+
+			tokens = append(tokens, queryToken{kind: tokenComparison, text: op, pos: i})
+			i += len(op)
+		default:
+			j := i
+			for ; j < len(query) && !strings.ContainsRune(" \t\n\r"+wordBreaks, rune(query[j])); j++ {
+			}
+
+			tokens = append(tokens, queryToken{kind: tokenWord, text: query[i:j], pos: i})
+			i = j
+		}
+	}
+
+	return append(tokens, queryToken{kind: tokenEOF, pos: len(query)}), nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+// isKeyword returns true if the given token is the given keyword. Keywords are case insensitive.
+func isKeyword(tok queryToken, keyword string) bool {
+	return tok.kind == tokenWord && strings.EqualFold(tok.text, keyword)
+}
+
+func (p *queryParser) unexpected(tok queryToken, expected string) error {
+	return fmt.Errorf("unexpected %s at position %d, expected %s", tok, tok.pos, expected)
+}
+
+func (p *queryParser) parseOr() (AlertFilter, error) {
+	filters := []AlertFilter{}
+	for {
+		filter, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, filter)
+		if !isKeyword(p.peek(), "or") {
+			break
+		}
+
+		p.next()
+	}
+
+	if len(filters) == 1 {
+		return filters[0], nil
+	}
+
+	return AnyAlerts(filters...), nil
+}
+
+func (p *queryParser) parseAnd() (AlertFilter, error) {
+	filters := []AlertFilter{}
+	for {
+		filter, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, filter)
+		if !isKeyword(p.peek(), "and") {
+			break
+		}
+
+		p.next()
+	}
+
+	if len(filters) == 1 {
+		return filters[0], nil
+	}
+
+	return AllAlerts(filters...), nil
+}
+
+func (p *queryParser) parseUnary() (AlertFilter, error) {
+	tok := p.peek()
+	switch {
+	case isKeyword(tok, "not"):
+		p.next()
+		filter, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return NotAlerts(filter), nil
+	case tok.kind == tokenLeftParen:
+		p.next()
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if tok := p.next(); tok.kind != tokenRightParen {
+			return nil, p.unexpected(tok, "`)`")
+		}
+
+		return filter, nil
+	default:
+		return p.parseComparison()
+	}
+}
+
+func (p *queryParser) parseComparison() (AlertFilter, error) {
+	fieldTok := p.next()
+	if fieldTok.kind != tokenWord {
+		return nil, p.unexpected(fieldTok, "a field")
+	}
+
+	field := fieldTok.text
+	if field == statusField {
+		field = "__status__"
+	}
+
+	if _, ok := specialFields[field]; !ok && strings.HasPrefix(field, "__") && !strings.HasPrefix(field, "__annotation_") {
+		return nil, fmt.Errorf("unknown field %q at position %d", field, fieldTok.pos)
+	}
+
+	tok := p.next()
+	switch {
+	case tok.kind == tokenComparison:
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		return comparisonFilter(field, Comparison(tok.text), value)
+	case isKeyword(tok, "in"):
+		return p.parseIn(field, false)
+	case isKeyword(tok, "not"):
+		if tok := p.next(); !isKeyword(tok, "in") {
+			return nil, p.unexpected(tok, "`in`")
+		}
+
+		return p.parseIn(field, true)
+	default:
+		return nil, p.unexpected(tok, "a comparison")
+	}
+}
+
+// parseIn parses the list of values after `in`, matching alerts where the field is any of them, or none of them if negated.
+func (p *queryParser) parseIn(field string, negated bool) (AlertFilter, error) {
+	if tok := p.next(); tok.kind != tokenLeftParen {
+		return nil, p.unexpected(tok, "`(`")
+	}
+
+	comparison := ComparisonEqual
+	if negated {
+		comparison = ComparisonNotEqual
+	}
+
+	filters := []AlertFilter{}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		filter, err := comparisonFilter(field, comparison, value)
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, filter)
+
+		tok := p.next()
+		if tok.kind == tokenRightParen {
+			break
+		}
+
+		if tok.kind != tokenComma {
+			return nil, p.unexpected(tok, "`,` or `)`")
+		}
+	}
+
+	switch {
+	case len(filters) == 1:
+		return filters[0], nil
+	case negated:
+		return AllAlerts(filters...), nil
+	default:
+		return AnyAlerts(filters...), nil
+	}
+}
+
+func (p *queryParser) parseValue() (string, error) {
+	tok := p.next()
+	if tok.kind != tokenWord && tok.kind != tokenString {
+		return "", p.unexpected(tok, "a value")
+	}
+
+	return tok.text, nil
+}
+
+// comparisonFilter returns a filter that compares the given field to the given value, using the most specific filter that we have for it.
+func comparisonFilter(field string, comparison Comparison, value string) (AlertFilter, error) {
+	switch {
+	case field == "__status__" && (comparison == ComparisonEqual || comparison == ComparisonNotEqual):
+		status := model.AlertStatus(value)
+		if !status.IsValid() {
+			return nil, fmt.Errorf("invalid status %q", value)
+		}
+
+		if comparison == ComparisonNotEqual {
+			return NotAlerts(Status(status)), nil
+		}
+
+		return Status(status), nil
+	case field == "__id__" && comparison == ComparisonEqual:
+		return ID(value), nil
+	case !strings.HasPrefix(field, "__"):
+		switch comparison {
+		case ComparisonEqual, ComparisonNotEqual:
+			matcher := model.LabelValueEqualMatcher(field, value)
+			matcher.IsNegative = comparison == ComparisonNotEqual
+			return Matcher(matcher), nil
+		case ComparisonRegex, ComparisonNotRegex:
+			matcher, err := model.LabelValueRegexMatcher(field, value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid regex for label %q", field)
+			}
+
+			matcher.IsNegative = comparison == ComparisonNotRegex
+			return Matcher(matcher), nil
+		}
+	}
+
+	return FieldComparison(field, comparison, value)
+}
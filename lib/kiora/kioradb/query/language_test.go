@@ -0,0 +1,197 @@
+package query_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sinkingpoint/kiora/internal/stubs"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAlertFilter(t *testing.T) {
+	now := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+	stubs.Time.Now = func() time.Time { return now }
+	defer func() { stubs.Time.Now = time.Now }()
+
+	alerts := []model.Alert{
+		{
+			Labels:      model.Labels{"alertname": "disk", "severity": "critical", "count": "10"},
+			Annotations: map[string]string{"runbook": "https://example.com/disk"},
+			Status:      model.AlertStatusFiring,
+			StartTime:   now.Add(-30 * time.Minute),
+		},
+		{
+			Labels:          model.Labels{"alertname": "cpu", "severity": "critical", "count": "9"},
+			Status:          model.AlertStatusAcked,
+			StartTime:       now.Add(-2 * time.Hour),
+			Acknowledgement: &model.AlertAcknowledgement{Creator: "foo"},
+		},
+		{
+			Labels:    model.Labels{"alertname": "memory", "severity": "warning"},
+			Status:    model.AlertStatusTimedOut,
+			StartTime: now.Add(-10 * time.Minute),
+			EndTime:   now.Add(-5 * time.Minute),
+		},
+	}
+
+	for i := range alerts {
+		require.NoError(t, alerts[i].Materialise())
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name:     "equality",
+			query:    `severity="critical"`,
+			expected: []string{"disk", "cpu"},
+		},
+		{
+			name:     "unquoted values",
+			query:    `severity = warning`,
+			expected: []string{"memory"},
+		},
+		{
+			name:     "the example",
+			query:    `severity="critical" and status in (firing, acked) and __starts_at__ > now-1h`,
+			expected: []string{"disk"},
+		},
+		{
+			name:     "or",
+			query:    `alertname=disk or alertname=cpu`,
+			expected: []string{"disk", "cpu"},
+		},
+		{
+			name:     "and binds tighter than or",
+			query:    `alertname=memory or alertname=disk and status=acked`,
+			expected: []string{"memory"},
+		},
+		{
+			name:     "parentheses",
+			query:    `(alertname=memory or alertname=disk) and status!=acked`,
+			expected: []string{"disk", "memory"},
+		},
+		{
+			name:     "not",
+			query:    `not severity=critical`,
+			expected: []string{"memory"},
+		},
+		{
+			name:     "not in",
+			query:    `alertname not in (disk, cpu)`,
+			expected: []string{"memory"},
+		},
+		{
+			name:     "quoted statuses",
+			query:    `status = "timed out"`,
+			expected: []string{"memory"},
+		},
+		{
+			name:     "regexes",
+			query:    `alertname =~ "^(disk|memory)$" and alertname !~ "mem.*"`,
+			expected: []string{"disk"},
+		},
+		{
+			name:     "numbers are compared as numbers",
+			query:    `count > 9`,
+			expected: []string{"disk"},
+		},
+		{
+			name:     "absolute times",
+			query:    `__starts_at__ <= 2023-07-01T10:00:00Z`,
+			expected: []string{"cpu"},
+		},
+		{
+			name:     "unset times never match",
+			query:    `__ends_at__ < now`,
+			expected: []string{"memory"},
+		},
+		{
+			name:     "annotations",
+			query:    `__annotation_runbook__ =~ "disk"`,
+			expected: []string{"disk"},
+		},
+		{
+			name:     "acknowledgements",
+			query:    `__ack_creator__ = foo`,
+			expected: []string{"cpu"},
+		},
+		{
+			name:     "keywords are case insensitive",
+			query:    `severity=critical AND NOT status IN (acked)`,
+			expected: []string{"disk"},
+		},
+		{
+			name:        "empty",
+			query:       "  ",
+			expectedErr: "empty query",
+		},
+		{
+			name:        "missing value",
+			query:       `severity=`,
+			expectedErr: "unexpected end of query at position 9, expected a value",
+		},
+		{
+			name:        "unbalanced parentheses",
+			query:       `(severity=critical`,
+			expectedErr: "unexpected end of query at position 18, expected `)`",
+		},
+		{
+			name:        "trailing tokens",
+			query:       `severity=critical status=firing`,
+			expectedErr: "unexpected \"status\" at position 18, expected `and` or `or`",
+		},
+		{
+			name:        "unterminated strings",
+			query:       `severity="critical`,
+			expectedErr: "unterminated string at position 9",
+		},
+		{
+			name:        "invalid statuses",
+			query:       `status=burning`,
+			expectedErr: "invalid status \"burning\"",
+		},
+		{
+			name:        "unknown fields",
+			query:       `__foo__=bar`,
+			expectedErr: "unknown field \"__foo__\" at position 0",
+		},
+		{
+			name:        "invalid times",
+			query:       `__starts_at__ > yesterday`,
+			expectedErr: "invalid time \"yesterday\": expected `now`, `now-<duration>`, or an RFC3339 time",
+		},
+		{
+			name:        "time regexes",
+			query:       `__starts_at__ =~ "2023"`,
+			expectedErr: "can't match time field \"__starts_at__\" against a regex",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := query.ParseAlertFilter(tt.query)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			matched := []string{}
+			for i := range alerts {
+				if filter.MatchesAlert(context.Background(), &alerts[i]) {
+					matched = append(matched, alerts[i].Labels["alertname"])
+				}
+			}
+
+			require.Equal(t, tt.expected, matched)
+		})
+	}
+}
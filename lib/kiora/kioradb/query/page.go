@@ -0,0 +1,228 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+// ErrInvalidCursor is returned when paginating with a cursor that isn't from a previous page of the same query.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page is a page of the results of a query.
+type Page[T any] struct {
+	// Items are the results in this page.
+	Items []T
+
+	// Total is the number of results that matched the query, across every page.
+	Total int
+
+	// NextCursor is the cursor to pass to StartAfter to get the next page, or empty if this is the last page.
+	NextCursor string
+}
+
+// StartAfter starts the results of a query after the result that the given cursor, from a previous page, points to.
+func StartAfter(cursor string) QueryOption {
+	return QueryOpFunc(func(q *Query) {
+		q.Cursor = cursor
+	})
+}
+
+// PaginateAlerts sorts the given alerts and returns the page of them that the given query asks for.
+func PaginateAlerts(alerts []model.Alert, q Query) (Page[model.Alert], error) {
+	sort.Sort(SortAlertsByFields(alerts, q.OrderBy, q.Order))
+	return paginate(alerts, q, func(alert *model.Alert) fielder { return alert })
+}
+
+// PaginateSilences sorts the given silences and returns the page of them that the given query asks for.
+func PaginateSilences(silences []model.Silence, q Query) (Page[model.Silence], error) {
+	sort.Sort(SortSilencesByFields(silences, q.OrderBy, q.Order))
+	return paginate(silences, q, func(silence *model.Silence) fielder { return silence })
+}
+
+// NewAlertPage returns a page of the given alerts, which have already been sorted and paginated (e.g. by a database) as the given query
+// asks for, out of the given total number that matched. The query must not start after a cursor, because the page can't tell where that is.
+func NewAlertPage(alerts []model.Alert, total int, q Query) (Page[model.Alert], error) {
+	page := Page[model.Alert]{Items: alerts, Total: total}
+	if len(alerts) > 0 && q.Offset+len(alerts) < total {
+		cursor, err := newCursor(&alerts[len(alerts)-1], q)
+		if err != nil {
+			return Page[model.Alert]{}, err
+		}
+
+		page.NextCursor = cursor
+	}
+
+	return page, nil
+}
+
+// paginate returns the page of the given sorted items that the given query asks for - the items after the cursor (if there is one),
+// skipping the offset, up to the limit.
+func paginate[T any](items []T, q Query, fields func(*T) fielder) (Page[T], error) {
+	page := Page[T]{Total: len(items)}
+
+	start := 0
+	if q.Cursor != "" {
+		cursor, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return Page[T]{}, err
+		}
+
+		if !cursor.matches(q) {
+			return Page[T]{}, fmt.Errorf("%w: cursor is for a differently sorted query", ErrInvalidCursor)
+		}
+
+		// Rather than remembering the index of the last result, we search for the first result that sorts after it, so that
+		// results being added or removed before it don't shift the page.
+		start = sort.Search(len(items), func(i int) bool {
+			return compareFields(fields(&items[i]), cursor, q.OrderBy, q.Order) > 0
+		})
+	}
+
+	start += q.Offset
+	if start > len(items) {
+		start = len(items)
+	}
+
+	end := len(items)
+	if q.Limit > 0 && start+q.Limit < end {
+		end = start + q.Limit
+	}
+
+	page.Items = items[start:end]
+	if end < len(items) && end > start {
+		cursor, err := newCursor(fields(&items[end-1]), q)
+		if err != nil {
+			return Page[T]{}, err
+		}
+
+		page.NextCursor = cursor
+	}
+
+	return page, nil
+}
+
+// cursor is a position in a sorted list of results. It holds the values of the sort fields of the result that it points to, rather than its
+// index, so that it still points to the same place when results are added or removed.
+type cursor struct {
+	OrderBy []string               `json:"orderBy,omitempty"`
+	Order   Order                  `json:"order,omitempty"`
+	Values  map[string]cursorValue `json:"values"`
+}
+
+// cursorValue is the value of a field in a cursor, with its type so that it can be compared in the same way as the field.
+type cursorValue struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func newCursor(item fielder, q Query) (string, error) {
+	c := cursor{
+		OrderBy: q.OrderBy,
+		Order:   q.Order,
+		Values:  map[string]cursorValue{},
+	}
+
+	for _, field := range append(q.OrderBy[:len(q.OrderBy):len(q.OrderBy)], idField) {
+		value, err := item.Field(field)
+		if err != nil {
+			// Missing fields are left out, which makes them missing in the cursor too.
+			continue
+		}
+
+		var encoded cursorValue
+		switch value := value.(type) {
+		case model.AlertStatus:
+			encoded = cursorValue{Type: "status", Value: string(value)}
+		case string:
+			encoded = cursorValue{Type: "string", Value: value}
+		case int:
+			encoded = cursorValue{Type: "int", Value: strconv.Itoa(value)}
+		case float64:
+			encoded = cursorValue{Type: "float", Value: strconv.FormatFloat(value, 'g', -1, 64)}
+		case time.Duration:
+			encoded = cursorValue{Type: "duration", Value: strconv.FormatInt(int64(value), 10)}
+		case time.Time:
+			encoded = cursorValue{Type: "time", Value: value.Format(time.RFC3339Nano)}
+		default:
+			return "", fmt.Errorf("can't paginate over field %q of type %T", field, value)
+		}
+
+		c.Values[field] = encoded
+	}
+
+	bytes, err := json.Marshal(c)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal cursor")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+func decodeCursor(encoded string) (*cursor, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	c := &cursor{}
+	if err := json.Unmarshal(bytes, c); err != nil || c.Values == nil {
+		return nil, ErrInvalidCursor
+	}
+
+	// Check that every value decodes now, so that comparisons against the cursor can't fail.
+	for field := range c.Values {
+		if _, err := c.Field(field); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return c, nil
+}
+
+// matches returns true if the cursor came from a query with the same sort as the given one.
+func (c *cursor) matches(q Query) bool {
+	if len(c.OrderBy) != len(q.OrderBy) || (len(q.OrderBy) > 0 && c.Order != q.Order) {
+		return false
+	}
+
+	for i := range c.OrderBy {
+		if c.OrderBy[i] != q.OrderBy[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Field returns the value of the given field of the result that the cursor points to.
+func (c *cursor) Field(name string) (any, error) {
+	value, ok := c.Values[name]
+	if !ok {
+		return nil, fmt.Errorf("field %q doesn't exist", name)
+	}
+
+	switch value.Type {
+	case "status":
+		return model.AlertStatus(value.Value), nil
+	case "string":
+		return value.Value, nil
+	case "int":
+		return strconv.Atoi(value.Value)
+	case "float":
+		return strconv.ParseFloat(value.Value, 64)
+	case "duration":
+		d, err := strconv.ParseInt(value.Value, 10, 64)
+		return time.Duration(d), err
+	case "time":
+		return time.Parse(time.RFC3339Nano, value.Value)
+	default:
+		return nil, fmt.Errorf("unknown type %q for field %q", value.Type, name)
+	}
+}
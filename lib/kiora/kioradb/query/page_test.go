@@ -0,0 +1,145 @@
+package query_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+func newPageAlert(t *testing.T, name string, start int64) model.Alert {
+	t.Helper()
+	alert := model.Alert{
+		Labels:    model.Labels{"alertname": name},
+		Status:    model.AlertStatusFiring,
+		StartTime: time.Unix(start, 0),
+	}
+
+	require.NoError(t, alert.Materialise())
+	return alert
+}
+
+func alertNames(alerts []model.Alert) []string {
+	names := []string{}
+	for _, alert := range alerts {
+		names = append(names, alert.Labels["alertname"])
+	}
+
+	return names
+}
+
+func TestPaginateAlerts(t *testing.T) {
+	alerts := []model.Alert{
+		newPageAlert(t, "c", 3),
+		newPageAlert(t, "a", 1),
+		newPageAlert(t, "e", 5),
+		newPageAlert(t, "b", 2),
+		newPageAlert(t, "d", 4),
+	}
+
+	tests := []struct {
+		name     string
+		opts     []query.QueryOption
+		expected []string
+		more     bool
+	}{
+		{
+			name:     "no limit",
+			opts:     []query.QueryOption{query.OrderBy([]string{"alertname"}, query.OrderAsc)},
+			expected: []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:     "limit",
+			opts:     []query.QueryOption{query.OrderBy([]string{"alertname"}, query.OrderAsc), query.Limit(2)},
+			expected: []string{"a", "b"},
+			more:     true,
+		},
+		{
+			name:     "offset",
+			opts:     []query.QueryOption{query.OrderBy([]string{"alertname"}, query.OrderAsc), query.Limit(2), query.Offset(3)},
+			expected: []string{"d", "e"},
+		},
+		{
+			name:     "offset past the end",
+			opts:     []query.QueryOption{query.Offset(10)},
+			expected: []string{},
+		},
+		{
+			name:     "descending times",
+			opts:     []query.QueryOption{query.OrderBy([]string{"__starts_at__"}, query.OrderDesc), query.Limit(3)},
+			expected: []string{"e", "d", "c"},
+			more:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := query.NewAlertQuery(query.MatchAll(), tt.opts...)
+			page, err := query.PaginateAlerts(append([]model.Alert{}, alerts...), q.Query)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, alertNames(page.Items))
+			require.Equal(t, len(alerts), page.Total)
+			require.Equal(t, tt.more, page.NextCursor != "")
+		})
+	}
+}
+
+func TestPaginateAlertsCursor(t *testing.T) {
+	alerts := []model.Alert{
+		newPageAlert(t, "a", 1),
+		newPageAlert(t, "b", 2),
+		newPageAlert(t, "c", 3),
+		newPageAlert(t, "d", 4),
+		newPageAlert(t, "e", 5),
+	}
+
+	sortOpt := query.OrderBy([]string{"__starts_at__"}, query.OrderAsc)
+	first, err := query.PaginateAlerts(append([]model.Alert{}, alerts...), query.NewAlertQuery(query.MatchAll(), sortOpt, query.Limit(2)).Query)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, alertNames(first.Items))
+	require.NotEmpty(t, first.NextCursor)
+
+	// Removing an alert from the first page, and adding one before it, doesn't shift the second page.
+	changed := append([]model.Alert{newPageAlert(t, "z", 0)}, alerts[1:]...)
+	second, err := query.PaginateAlerts(changed, query.NewAlertQuery(query.MatchAll(), sortOpt, query.Limit(2), query.StartAfter(first.NextCursor)).Query)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "d"}, alertNames(second.Items))
+	require.Equal(t, 5, second.Total)
+
+	third, err := query.PaginateAlerts(changed, query.NewAlertQuery(query.MatchAll(), sortOpt, query.Limit(2), query.StartAfter(second.NextCursor)).Query)
+	require.NoError(t, err)
+	require.Equal(t, []string{"e"}, alertNames(third.Items))
+	require.Empty(t, third.NextCursor)
+
+	// Cursors can't be used with a different sort.
+	_, err = query.PaginateAlerts(changed, query.NewAlertQuery(query.MatchAll(), query.Limit(2), query.StartAfter(first.NextCursor)).Query)
+	require.ErrorIs(t, err, query.ErrInvalidCursor)
+
+	_, err = query.PaginateAlerts(changed, query.NewAlertQuery(query.MatchAll(), query.StartAfter("not a cursor")).Query)
+	require.ErrorIs(t, err, query.ErrInvalidCursor)
+}
+
+func TestPaginateSilences(t *testing.T) {
+	silences := []model.Silence{
+		{ID: "b", StartTime: time.Unix(1, 0), EndTime: time.Unix(10, 0)},
+		{ID: "a", StartTime: time.Unix(2, 0), EndTime: time.Unix(10, 0)},
+		{ID: "c", StartTime: time.Unix(3, 0), EndTime: time.Unix(10, 0)},
+	}
+
+	q := query.NewSilenceQuery(query.MatchAll(), query.OrderBy([]string{"__duration__"}, query.OrderDesc), query.Limit(2))
+	first, err := query.PaginateSilences(silences, q.Query)
+	require.NoError(t, err)
+	require.Equal(t, 3, first.Total)
+	require.Len(t, first.Items, 2)
+	require.Equal(t, "b", first.Items[0].ID)
+	require.Equal(t, "a", first.Items[1].ID)
+
+	q = query.NewSilenceQuery(query.MatchAll(), query.OrderBy([]string{"__duration__"}, query.OrderDesc), query.Limit(2), query.StartAfter(first.NextCursor))
+	second, err := query.PaginateSilences(silences, q.Query)
+	require.NoError(t, err)
+	require.Len(t, second.Items, 1)
+	require.Equal(t, "c", second.Items[0].ID)
+	require.Empty(t, second.NextCursor)
+}
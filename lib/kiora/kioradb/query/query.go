@@ -20,6 +20,9 @@ type Query struct {
 
 	// Limit is the maximum number of results to return.
 	Limit int
+
+	// Cursor is a cursor from a previous page of results, which the results start after.
+	Cursor string
 }
 
 // QueryOption represents an option that can be applied to a query.
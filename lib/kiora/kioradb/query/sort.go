@@ -2,12 +2,22 @@ package query
 
 import (
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/sinkingpoint/kiora/lib/kiora/model"
 )
 
+// idField is the field that every sort falls back to, so that results that are equal in every other field are still
+// sorted the same way every time.
+const idField = "__id__"
+
+// fielder is something that exposes fields that it can be sorted by, like an alert or a silence.
+type fielder interface {
+	Field(name string) (any, error)
+}
+
 // alertsByFields implements sort.Interface for sorting a number of alerts
 // by a number of fields. If a field is not present on an alert, it is considered
 // to be less than the other alert.
@@ -33,61 +43,110 @@ func (a alertsByFields) Swap(i, j int) {
 	a.Alerts[i], a.Alerts[j] = a.Alerts[j], a.Alerts[i]
 }
 
-func (a alertsByFields) lessVal() bool {
-	return a.Order == OrderAsc
+func (a alertsByFields) Less(i, j int) bool {
+	return compareFields(&a.Alerts[i], &a.Alerts[j], a.Fields, a.Order) < 0
 }
 
-func (a alertsByFields) Less(i, j int) bool {
-	for _, field := range a.Fields {
-		iVal, iErr := a.Alerts[i].Field(field)
-		jVal, jErr := a.Alerts[j].Field(field)
+// silencesByFields implements sort.Interface for sorting a number of silences by a number of fields, in the same way as alertsByFields.
+type silencesByFields struct {
+	Silences []model.Silence
+	Fields   []string
+	Order    Order
+}
+
+func SortSilencesByFields(silences []model.Silence, fields []string, order Order) sort.Interface {
+	return silencesByFields{
+		Silences: silences,
+		Fields:   fields,
+		Order:    order,
+	}
+}
 
-		if iErr != nil && jErr != nil {
+func (s silencesByFields) Len() int {
+	return len(s.Silences)
+}
+
+func (s silencesByFields) Swap(i, j int) {
+	s.Silences[i], s.Silences[j] = s.Silences[j], s.Silences[i]
+}
+
+func (s silencesByFields) Less(i, j int) bool {
+	return compareFields(&s.Silences[i], &s.Silences[j], s.Fields, s.Order) < 0
+}
+
+// compareFields compares a and b by the given fields in order, and then by their IDs, returning a negative number if a sorts first,
+// and a positive one if b does. Missing fields are less than present ones, so they sort first in ascending order, and last in descending order.
+func compareFields(a, b fielder, fields []string, order Order) int {
+	for _, field := range append(fields[:len(fields):len(fields)], idField) {
+		aVal, aErr := a.Field(field)
+		bVal, bErr := b.Field(field)
+
+		var cmp int
+		switch {
+		case aErr != nil && bErr != nil:
 			continue
+		case aErr != nil:
+			cmp = -1
+		case bErr != nil:
+			cmp = 1
+		default:
+			cmp = compareFieldValues(field, aVal, bVal)
 		}
 
-		if iErr != nil {
-			return !a.lessVal()
+		if cmp == 0 {
+			continue
 		}
 
-		if jErr != nil {
-			return a.lessVal()
+		if order == OrderDesc {
+			return -cmp
 		}
 
-		if iVal == jVal {
-			continue
-		}
+		return cmp
+	}
 
-		switch val := iVal.(type) {
-		case string:
-			if a.Order == OrderDesc {
-				return (val > jVal.(string))
-			}
-
-			return val < jVal.(string)
-		case int:
-			if a.Order == OrderDesc {
-				return (val > jVal.(int))
-			}
-
-			return val < jVal.(int)
-		case float64:
-			if a.Order == OrderDesc {
-				return (val > jVal.(float64))
-			}
-
-			return val < jVal.(float64)
-		case time.Time:
-			if a.Order == OrderDesc {
-				return (val.After(jVal.(time.Time)))
-			}
-
-			return val.Before(jVal.(time.Time))
-		default:
-			log.Warn().Str("field", field).Interface("value", iVal).Msg("unknown field type")
-			continue
+	return 0
+}
+
+// compareFieldValues compares two values of the given field.
+func compareFieldValues(field string, a, b any) int {
+	switch val := a.(type) {
+	case model.AlertStatus:
+		if b, ok := b.(model.AlertStatus); ok {
+			return strings.Compare(string(val), string(b))
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(val, b)
+		}
+	case int:
+		if b, ok := b.(int); ok {
+			return compareOrdered(val, b)
+		}
+	case float64:
+		if b, ok := b.(float64); ok {
+			return compareOrdered(val, b)
+		}
+	case time.Duration:
+		if b, ok := b.(time.Duration); ok {
+			return compareOrdered(val, b)
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return val.Compare(b)
 		}
 	}
 
-	return true
+	log.Warn().Str("field", field).Interface("value", a).Msg("unknown field type")
+	return 0
+}
+
+func compareOrdered[T int | float64 | time.Duration](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
 }
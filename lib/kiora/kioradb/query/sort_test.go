@@ -16,6 +16,9 @@ func TestSortAlertsByFields(t *testing.T) {
 		Labels: model.Labels{
 			"foo": "bar",
 		},
+		Annotations: map[string]string{
+			"summary": "1",
+		},
 		EndTime: time.Unix(2, 0),
 		Status:  model.AlertStatusFiring,
 	}
@@ -25,6 +28,9 @@ func TestSortAlertsByFields(t *testing.T) {
 		Labels: model.Labels{
 			"foo": "baz",
 		},
+		Annotations: map[string]string{
+			"summary": "2",
+		},
 		EndTime: time.Unix(2, 0),
 		Status:  model.AlertStatusFiring,
 	}
@@ -34,10 +40,21 @@ func TestSortAlertsByFields(t *testing.T) {
 		Labels: model.Labels{
 			"foo": "qux",
 		},
+		Annotations: map[string]string{
+			"summary": "3",
+		},
 		EndTime: time.Unix(2, 0),
 		Status:  model.AlertStatusFiring,
 	}
 
+	// d is missing the label that the others are sorted by.
+	d := model.Alert{
+		StartTime: time.Unix(4, 0),
+		Labels:    model.Labels{},
+		EndTime:   time.Unix(2, 0),
+		Status:    model.AlertStatusFiring,
+	}
+
 	tests := []struct {
 		Name           string
 		Alerts         []model.Alert
@@ -89,6 +106,39 @@ func TestSortAlertsByFields(t *testing.T) {
 				c, b, a,
 			},
 		},
+		{
+			Name: "test_sort_by_annotation",
+			Alerts: []model.Alert{
+				a, c, b,
+			},
+			Fields: []string{model.AnnotationField("summary")},
+			Order:  query.OrderDesc,
+			ExpectedAlerts: []model.Alert{
+				c, b, a,
+			},
+		},
+		{
+			Name: "test_sort_by_missing_label",
+			Alerts: []model.Alert{
+				a, d, c, b,
+			},
+			Fields: []string{"foo"},
+			Order:  query.OrderAsc,
+			ExpectedAlerts: []model.Alert{
+				d, a, b, c,
+			},
+		},
+		{
+			Name: "test_sort_by_missing_label_desc",
+			Alerts: []model.Alert{
+				a, d, c, b,
+			},
+			Fields: []string{"foo"},
+			Order:  query.OrderDesc,
+			ExpectedAlerts: []model.Alert{
+				c, b, a, d,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,460 @@
+package kioradb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // Registers the sqlite3 driver.
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/sinkingpoint/msgpack/v5"
+	"go.opentelemetry.io/otel"
+)
+
+var _ = DB(&SQLiteDB{})
+var _ = AlertPager(&SQLiteDB{})
+
+// sqliteMigrations are the changes to the schema of the database, in order. Databases store how many of them have been applied in their
+// user_version, so changes to the schema have to be added as new migrations on the end, rather than by editing old ones.
+var sqliteMigrations = []string{
+	`
+	CREATE TABLE alerts (
+		labels_hash INTEGER PRIMARY KEY,
+		id TEXT NOT NULL,
+		status TEXT NOT NULL,
+		starts_at INTEGER NOT NULL,
+		ends_at INTEGER NOT NULL,
+		timeout_deadline INTEGER NOT NULL,
+		last_notify_time INTEGER NOT NULL,
+		data BLOB NOT NULL
+	);
+
+	CREATE INDEX alerts_id ON alerts (id);
+	CREATE INDEX alerts_status ON alerts (status, last_notify_time);
+	CREATE INDEX alerts_starts_at ON alerts (starts_at);
+	CREATE INDEX alerts_ends_at ON alerts (ends_at);
+
+	CREATE TABLE alert_labels (
+		labels_hash INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		value TEXT NOT NULL,
+		PRIMARY KEY (labels_hash, name)
+	) WITHOUT ROWID;
+
+	CREATE INDEX alert_labels_name_value ON alert_labels (name, value);
+
+	CREATE TABLE silences (
+		id TEXT PRIMARY KEY,
+		tenant TEXT NOT NULL,
+		starts_at INTEGER NOT NULL,
+		ends_at INTEGER NOT NULL,
+		data BLOB NOT NULL
+	);
+
+	CREATE INDEX silences_ends_at ON silences (ends_at);
+
+	CREATE TABLE silence_matchers (
+		silence_id TEXT NOT NULL,
+		label TEXT NOT NULL,
+		value TEXT NOT NULL,
+		is_regex INTEGER NOT NULL,
+		is_negative INTEGER NOT NULL
+	);
+
+	CREATE INDEX silence_matchers_silence_id ON silence_matchers (silence_id);
+	CREATE INDEX silence_matchers_label_value ON silence_matchers (label, value);
+
+	CREATE TABLE audit (
+		id TEXT PRIMARY KEY,
+		time INTEGER NOT NULL,
+		actor TEXT NOT NULL,
+		tenant TEXT NOT NULL,
+		data BLOB NOT NULL
+	);
+
+	CREATE INDEX audit_time ON audit (time);
+	CREATE INDEX audit_actor_time ON audit (actor, time);
+	`,
+}
+
+// SQLiteDB is a DB implementation that stores data in a SQLite database. Unlike BoltDB, it doesn't keep a cache of everything in memory.
+// Instead, queries are translated into SQL as far as they can be, so that only the alerts and silences they match have to be loaded.
+type SQLiteDB struct {
+	db *sql.DB
+
+	logger zerolog.Logger
+}
+
+// NewSQLiteDB opens the SQLite database at the given path, creating it if it doesn't exist, and migrates it to the latest schema.
+// Kiora has to be built with cgo to use SQLite.
+func NewSQLiteDB(path string, logger zerolog.Logger) (*SQLiteDB, error) {
+	if !sqliteSupported {
+		return nil, errors.New("sqlite storage requires kiora to be built with cgo (CGO_ENABLED=1)")
+	}
+
+	// WAL lets queries run while alerts are being written, and immediate transactions make writers wait for each other, rather than failing.
+	backingDB, err := sql.Open("sqlite3", path+"?_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open sqlite db")
+	}
+
+	db := &SQLiteDB{
+		db:     backingDB,
+		logger: logger.With().Str("component", "sqlite").Logger(),
+	}
+
+	if err := db.migrate(context.Background()); err != nil {
+		backingDB.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// migrate applies the migrations that haven't been applied to the database yet.
+func (s *SQLiteDB) migrate(ctx context.Context) error {
+	var version int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return errors.Wrap(err, "failed to get schema version")
+	}
+
+	if version > len(sqliteMigrations) {
+		return fmt.Errorf("database schema version %d is newer than the latest one this version of kiora knows about (%d)", version, len(sqliteMigrations))
+	}
+
+	for ; version < len(sqliteMigrations); version++ {
+		s.logger.Info().Int("version", version+1).Msg("migrating database schema")
+		if err := s.update(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, sqliteMigrations[version]); err != nil {
+				return errors.Wrapf(err, "failed to apply migration %d", version+1)
+			}
+
+			// PRAGMAs don't take parameters, but this is just an int.
+			_, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", version+1))
+			return errors.Wrap(err, "failed to set schema version")
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// update runs the given function in a transaction, committing it if the function succeeds, and rolling it back if it doesn't.
+func (s *SQLiteDB) update(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to start transaction")
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback() //nolint:errcheck // The original error is more interesting than a failed rollback.
+		return err
+	}
+
+	return errors.Wrap(tx.Commit(), "failed to commit transaction")
+}
+
+// timeColumn returns the value that the given time is stored as. Times are stored as unix nanoseconds, with unset times being stored
+// as the lowest possible value, so that they sort before every other time, as they do in Go.
+func timeColumn(t time.Time) int64 {
+	if t.IsZero() {
+		return math.MinInt64
+	}
+
+	return t.UnixNano()
+}
+
+func (s *SQLiteDB) StoreAlerts(ctx context.Context, alerts ...model.Alert) error {
+	ctx, span := otel.Tracer("").Start(ctx, "SQLiteDB.StoreAlerts")
+	defer span.End()
+
+	return s.update(ctx, func(tx *sql.Tx) error {
+		storeAlert, err := tx.PrepareContext(ctx, `
+			INSERT INTO alerts (labels_hash, id, status, starts_at, ends_at, timeout_deadline, last_notify_time, data)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (labels_hash) DO UPDATE SET
+				id = excluded.id,
+				status = excluded.status,
+				starts_at = excluded.starts_at,
+				ends_at = excluded.ends_at,
+				timeout_deadline = excluded.timeout_deadline,
+				last_notify_time = excluded.last_notify_time,
+				data = excluded.data
+		`)
+		if err != nil {
+			return errors.Wrap(err, "failed to prepare alert statement")
+		}
+
+		defer storeAlert.Close()
+
+		// Alerts are keyed by the hash of their labels, so their labels never change once they're stored.
+		storeLabel, err := tx.PrepareContext(ctx, "INSERT OR IGNORE INTO alert_labels (labels_hash, name, value) VALUES (?, ?, ?)")
+		if err != nil {
+			return errors.Wrap(err, "failed to prepare label statement")
+		}
+
+		defer storeLabel.Close()
+
+		for i := range alerts {
+			alert := &alerts[i]
+			bytes, err := msgpack.Marshal(alert)
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal alert")
+			}
+
+			// SQLite integers are signed, so the hash is stored with the same bits as a signed int.
+			hash := int64(alert.Labels.Hash())
+			if _, err := storeAlert.ExecContext(ctx, hash, alert.ID, string(alert.Status), timeColumn(alert.StartTime), timeColumn(alert.EndTime),
+				timeColumn(alert.TimeOutDeadline), timeColumn(alert.LastNotifyTime), bytes); err != nil {
+				return errors.Wrap(err, "failed to store alert")
+			}
+
+			for name, value := range alert.Labels {
+				if _, err := storeLabel.ExecContext(ctx, hash, name, value); err != nil {
+					return errors.Wrap(err, "failed to store alert label")
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *SQLiteDB) QueryAlerts(ctx context.Context, q query.AlertQuery) []model.Alert {
+	ctx, span := otel.Tracer("").Start(ctx, "SQLiteDB.QueryAlerts")
+	defer span.End()
+
+	alerts, err := s.queryAlerts(ctx, q)
+	if err != nil {
+		s.logger.Err(err).Msg("failed to query alerts")
+		return []model.Alert{}
+	}
+
+	return alerts
+}
+
+func (s *SQLiteDB) queryAlerts(ctx context.Context, q query.AlertQuery) ([]model.Alert, error) {
+	where := alertCondition(q.Filter)
+	statement := "SELECT data FROM alerts WHERE " + where.sql
+	args := where.args
+
+	orderBy, sortable := alertOrderBy(q.OrderBy, q.Order)
+	if sortable {
+		statement += " ORDER BY " + orderBy.sql
+		args = append(args, orderBy.args...)
+	}
+
+	// If the database returns exactly the alerts that the filter matches, in the right order, it can paginate them too.
+	paginated := where.exact && sortable
+	if paginated && (q.Limit > 0 || q.Offset > 0) {
+		limit := q.Limit
+		if limit <= 0 {
+			limit = -1
+		}
+
+		statement += " LIMIT ? OFFSET ?"
+		args = append(args, limit, q.Offset)
+	}
+
+	rows, err := s.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query alerts")
+	}
+
+	defer rows.Close()
+
+	alerts := []model.Alert{}
+	for rows.Next() {
+		var bytes []byte
+		if err := rows.Scan(&bytes); err != nil {
+			return nil, errors.Wrap(err, "failed to scan alert")
+		}
+
+		var alert model.Alert
+		if err := msgpack.Unmarshal(bytes, &alert); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal alert")
+		}
+
+		if !where.exact && !q.Filter.MatchesAlert(ctx, &alert) {
+			continue
+		}
+
+		alerts = append(alerts, alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read alerts")
+	}
+
+	if !sortable {
+		sort.Stable(query.SortAlertsByFields(alerts, q.OrderBy, q.Order))
+	}
+
+	if !paginated {
+		alerts = paginateSlice(alerts, q.Query)
+	}
+
+	return alerts, nil
+}
+
+// QueryAlertPage returns the page of alerts that the given query asks for. If the database can filter and sort the alerts exactly, it
+// only loads that page, and counts the rest. Otherwise, and for queries that start after a cursor, every matching alert is loaded.
+func (s *SQLiteDB) QueryAlertPage(ctx context.Context, q query.AlertQuery) (query.Page[model.Alert], error) {
+	ctx, span := otel.Tracer("").Start(ctx, "SQLiteDB.QueryAlertPage")
+	defer span.End()
+
+	where := alertCondition(q.Filter)
+	if _, sortable := alertOrderBy(q.OrderBy, q.Order); !where.exact || !sortable || q.Cursor != "" {
+		alerts, err := s.queryAlerts(ctx, query.NewAlertQuery(q.Filter))
+		if err != nil {
+			return query.Page[model.Alert]{}, err
+		}
+
+		return query.PaginateAlerts(alerts, q.Query)
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM alerts WHERE "+where.sql, where.args...).Scan(&total); err != nil {
+		return query.Page[model.Alert]{}, errors.Wrap(err, "failed to count alerts")
+	}
+
+	alerts, err := s.queryAlerts(ctx, q)
+	if err != nil {
+		return query.Page[model.Alert]{}, err
+	}
+
+	return query.NewAlertPage(alerts, total, q.Query)
+}
+
+// paginateSlice returns the part of the given results that the offset and limit of the given query ask for.
+func paginateSlice[T any](items []T, q query.Query) []T {
+	if q.Offset > 0 {
+		if q.Offset > len(items) {
+			return items[:0]
+		}
+
+		items = items[q.Offset:]
+	}
+
+	if q.Limit > 0 && len(items) > q.Limit {
+		items = items[:q.Limit]
+	}
+
+	return items
+}
+
+func (s *SQLiteDB) StoreSilences(ctx context.Context, silences ...model.Silence) error {
+	return s.update(ctx, func(tx *sql.Tx) error {
+		storeSilence, err := tx.PrepareContext(ctx, `
+			INSERT INTO silences (id, tenant, starts_at, ends_at, data)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET
+				tenant = excluded.tenant,
+				starts_at = excluded.starts_at,
+				ends_at = excluded.ends_at,
+				data = excluded.data
+		`)
+		if err != nil {
+			return errors.Wrap(err, "failed to prepare silence statement")
+		}
+
+		defer storeSilence.Close()
+
+		storeMatcher, err := tx.PrepareContext(ctx, "INSERT INTO silence_matchers (silence_id, label, value, is_regex, is_negative) VALUES (?, ?, ?, ?, ?)")
+		if err != nil {
+			return errors.Wrap(err, "failed to prepare matcher statement")
+		}
+
+		defer storeMatcher.Close()
+
+		for i := range silences {
+			// Silences are stored as JSON, rather than msgpack like alerts, because their regex matchers are only compiled when they're
+			// unmarshalled from JSON.
+			silence := &silences[i]
+			bytes, err := json.Marshal(silence)
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal silence")
+			}
+
+			if _, err := storeSilence.ExecContext(ctx, silence.ID, silence.Tenant, timeColumn(silence.StartTime), timeColumn(silence.EndTime), bytes); err != nil {
+				return errors.Wrap(err, "failed to store silence")
+			}
+
+			// Unlike alerts, silences can be updated with different matchers, so we replace them.
+			if _, err := tx.ExecContext(ctx, "DELETE FROM silence_matchers WHERE silence_id = ?", silence.ID); err != nil {
+				return errors.Wrap(err, "failed to clear silence matchers")
+			}
+
+			for _, matcher := range silence.Matchers {
+				if _, err := storeMatcher.ExecContext(ctx, silence.ID, matcher.Label, matcher.Value, matcher.IsRegex, matcher.IsNegative); err != nil {
+					return errors.Wrap(err, "failed to store silence matcher")
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// QuerySilences queries the database for silences matching the given query.
+func (s *SQLiteDB) QuerySilences(ctx context.Context, q query.SilenceQuery) []model.Silence {
+	ctx, span := otel.Tracer("").Start(ctx, "SQLiteDB.QuerySilences")
+	defer span.End()
+
+	silences, err := s.querySilences(ctx, q)
+	if err != nil {
+		s.logger.Err(err).Msg("failed to query silences")
+		return []model.Silence{}
+	}
+
+	return silences
+}
+
+func (s *SQLiteDB) querySilences(ctx context.Context, q query.SilenceQuery) ([]model.Silence, error) {
+	where := silenceCondition(q.Filter)
+	rows, err := s.db.QueryContext(ctx, "SELECT data FROM silences WHERE "+where.sql, where.args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query silences")
+	}
+
+	defer rows.Close()
+
+	silences := []model.Silence{}
+	for rows.Next() {
+		var bytes []byte
+		if err := rows.Scan(&bytes); err != nil {
+			return nil, errors.Wrap(err, "failed to scan silence")
+		}
+
+		var silence model.Silence
+		if err := json.Unmarshal(bytes, &silence); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal silence")
+		}
+
+		if !where.exact && !q.Filter.MatchesSilence(ctx, &silence) {
+			continue
+		}
+
+		silences = append(silences, silence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read silences")
+	}
+
+	// There are far fewer silences than alerts, so they're sorted and paginated here rather than in SQL.
+	sort.Stable(query.SortSilencesByFields(silences, q.OrderBy, q.Order))
+	return paginateSlice(silences, q.Query), nil
+}
+
+func (s *SQLiteDB) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,6 @@
+//go:build cgo
+
+package kioradb
+
+// sqliteSupported is true if the SQLite driver works in this build. It's a wrapper around the C library, so it needs cgo.
+const sqliteSupported = true
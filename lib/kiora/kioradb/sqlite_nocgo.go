@@ -0,0 +1,6 @@
+//go:build !cgo
+
+package kioradb
+
+// sqliteSupported is true if the SQLite driver works in this build. Without cgo, the driver still compiles, but fails every query.
+const sqliteSupported = false
@@ -0,0 +1,246 @@
+package kioradb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+)
+
+// sqlCondition is a SQL expression, with the arguments for its placeholders.
+type sqlCondition struct {
+	sql  string
+	args []any
+
+	// exact is true if the condition matches exactly what the filter it came from does. Otherwise, it can match more,
+	// and everything it matches still has to be checked against the filter.
+	exact bool
+}
+
+var (
+	// matchAll is a condition that matches everything, for filters that match everything.
+	matchAll = sqlCondition{sql: "1", exact: true}
+
+	// unknownCondition is a condition that matches everything, for filters that can't be translated into SQL.
+	unknownCondition = sqlCondition{sql: "1"}
+)
+
+// hasLabel is a condition that matches alerts with a label with the given name, and a value that matches the given condition on `value`,
+// if there is one. Its arguments are the name, and then the arguments of the value condition.
+func hasLabel(valueCondition string) string {
+	if valueCondition == "" {
+		return "labels_hash IN (SELECT labels_hash FROM alert_labels WHERE name = ?)"
+	}
+
+	return "labels_hash IN (SELECT labels_hash FROM alert_labels WHERE name = ? AND " + valueCondition + ")"
+}
+
+// joinConditions joins the given conditions with the given operator. The result is only exact if every condition is.
+func joinConditions(operator string, conditions []sqlCondition) sqlCondition {
+	joined := sqlCondition{exact: true}
+	parts := make([]string, 0, len(conditions))
+	for _, condition := range conditions {
+		parts = append(parts, "("+condition.sql+")")
+		joined.args = append(joined.args, condition.args...)
+		joined.exact = joined.exact && condition.exact
+	}
+
+	joined.sql = strings.Join(parts, " "+operator+" ")
+	return joined
+}
+
+// alertCondition translates the given filter into a condition on the alerts table.
+func alertCondition(filter query.AlertFilter) sqlCondition {
+	switch filter := filter.(type) {
+	case nil, *query.AllMatchFilter:
+		return matchAll
+	case *query.ExactLabelMatchFilter:
+		return sqlCondition{sql: "labels_hash = ?", args: []any{int64(filter.Labels.Hash())}, exact: true}
+	case *query.PartialLabelMatchFilter:
+		if len(filter.Labels) == 0 {
+			return matchAll
+		}
+
+		conditions := make([]sqlCondition, 0, len(filter.Labels))
+		for name, value := range filter.Labels {
+			conditions = append(conditions, sqlCondition{sql: hasLabel("value = ?"), args: []any{name, value}, exact: true})
+		}
+
+		return joinConditions("AND", conditions)
+	case *query.StatusFilter:
+		return sqlCondition{sql: "status = ?", args: []any{string(filter.Status)}, exact: true}
+	case *query.IDFilter:
+		return sqlCondition{sql: "id = ?", args: []any{filter.ID}, exact: true}
+	case *query.MatcherFilter:
+		matcher := filter.Matcher()
+		switch {
+		case matcher.IsRegex:
+			// SQLite doesn't have regexes, but matchers only ever match alerts that have the label.
+			return sqlCondition{sql: hasLabel(""), args: []any{matcher.Label}}
+		case matcher.IsNegative:
+			return sqlCondition{sql: hasLabel("value != ?"), args: []any{matcher.Label, matcher.Value}, exact: true}
+		default:
+			return sqlCondition{sql: hasLabel("value = ?"), args: []any{matcher.Label, matcher.Value}, exact: true}
+		}
+	case *query.LastNotifyTimeRangeFilter:
+		conditions := []sqlCondition{matchAll}
+		if !filter.MinTime.IsZero() {
+			conditions = append(conditions, sqlCondition{sql: "last_notify_time >= ?", args: []any{timeColumn(filter.MinTime)}, exact: true})
+		}
+
+		if !filter.MaxTime.IsZero() {
+			conditions = append(conditions, sqlCondition{sql: "last_notify_time <= ?", args: []any{timeColumn(filter.MaxTime)}, exact: true})
+		}
+
+		return joinConditions("AND", conditions)
+	case *query.FieldComparisonFilter:
+		// Alerts without the field never match, so comparisons on labels can at least be narrowed down to the alerts with the label.
+		if isSpecialField(filter.Field) {
+			return unknownCondition
+		}
+
+		return sqlCondition{sql: hasLabel(""), args: []any{filter.Field}}
+	case *query.AllFilter:
+		filters := filter.AlertFilters()
+		if len(filters) == 0 {
+			return matchAll
+		}
+
+		conditions := make([]sqlCondition, 0, len(filters))
+		for _, f := range filters {
+			conditions = append(conditions, alertCondition(f))
+		}
+
+		return joinConditions("AND", conditions)
+	case *query.AnyFilter:
+		filters := filter.AlertFilters()
+		if len(filters) == 0 {
+			return sqlCondition{sql: "0", exact: true}
+		}
+
+		// Conditions that aren't exact match everything that their filter does (and more), so ORing them still matches
+		// everything that the filter does.
+		conditions := make([]sqlCondition, 0, len(filters))
+		for _, f := range filters {
+			conditions = append(conditions, alertCondition(f))
+		}
+
+		return joinConditions("OR", conditions)
+	case *query.NotFilter:
+		// The opposite of a condition that matches too much would match too little, so only exact conditions can be negated.
+		condition := alertCondition(filter.AlertFilter())
+		if !condition.exact {
+			return unknownCondition
+		}
+
+		return sqlCondition{sql: "NOT (" + condition.sql + ")", args: condition.args, exact: true}
+	}
+
+	return unknownCondition
+}
+
+// isSpecialField returns true if the given field is one of the special `__field__`s, rather than a label.
+func isSpecialField(field string) bool {
+	return strings.HasPrefix(field, "__") && strings.HasSuffix(field, "__")
+}
+
+// alertColumns are the special fields that are stored in columns of the alerts table.
+var alertColumns = map[string]string{
+	"__id__":               "id",
+	"__status__":           "status",
+	"__starts_at__":        "starts_at",
+	"__ends_at__":          "ends_at",
+	"__timeout_deadline__": "timeout_deadline",
+	"__last_notify_time__": "last_notify_time",
+}
+
+// alertOrderBy translates the given sort into an ORDER BY clause that sorts alerts in the same way as query.SortAlertsByFields.
+// It returns false if one of the fields can't be sorted on in SQL.
+func alertOrderBy(fields []string, order query.Order) (sqlCondition, bool) {
+	direction := "ASC"
+	if order == query.OrderDesc {
+		direction = "DESC"
+	}
+
+	clauses := []string{}
+	args := []any{}
+	for _, field := range fields {
+		if column, ok := alertColumns[field]; ok {
+			clauses = append(clauses, fmt.Sprintf("%s %s", column, direction))
+			continue
+		}
+
+		if isSpecialField(field) {
+			return sqlCondition{}, false
+		}
+
+		// Like query.SortAlertsByFields, alerts without the label are less than the ones with it, which is also where SQLite sorts NULLs.
+		clauses = append(clauses, fmt.Sprintf("(SELECT value FROM alert_labels WHERE alert_labels.labels_hash = alerts.labels_hash AND alert_labels.name = ?) %s", direction))
+		args = append(args, field)
+	}
+
+	// Like query.SortAlertsByFields, fall back to the ID so that the order is stable.
+	clauses = append(clauses, "id "+direction)
+
+	return sqlCondition{sql: strings.Join(clauses, ", "), args: args, exact: true}, true
+}
+
+// silenceCondition translates the given filter into a condition on the silences table.
+func silenceCondition(filter query.SilenceFilter) sqlCondition {
+	switch filter := filter.(type) {
+	case nil, *query.AllMatchFilter:
+		return matchAll
+	case *query.IDFilter:
+		return sqlCondition{sql: "id = ?", args: []any{filter.ID}, exact: true}
+	case *query.SilenceTenantFilter:
+		return sqlCondition{sql: "tenant IN (?, '')", args: []any{filter.Tenant}, exact: true}
+	case *query.MatcherFilter:
+		matcher := filter.Matcher()
+		return sqlCondition{
+			sql:   "id IN (SELECT silence_id FROM silence_matchers WHERE label = ? AND value = ? AND is_regex = ? AND is_negative = ?)",
+			args:  []any{matcher.Label, matcher.Value, matcher.IsRegex, matcher.IsNegative},
+			exact: true,
+		}
+	case *query.PartialLabelMatchFilter:
+		// A silence can only match the labels if each of its equality matchers is one of the labels, so rule out the silences that
+		// have one that isn't. Regex and negative matchers still have to be checked afterwards.
+		return sqlCondition{
+			sql:  "id NOT IN (SELECT silence_id FROM silence_matchers WHERE is_regex = 0 AND is_negative = 0 AND " + notOneOfLabels(filter.Labels) + ")",
+			args: labelArgs(filter.Labels),
+		}
+	case *query.AllFilter:
+		filters := filter.SilenceFilters()
+		if len(filters) == 0 {
+			return matchAll
+		}
+
+		conditions := make([]sqlCondition, 0, len(filters))
+		for _, f := range filters {
+			conditions = append(conditions, silenceCondition(f))
+		}
+
+		return joinConditions("AND", conditions)
+	}
+
+	return unknownCondition
+}
+
+// notOneOfLabels is a condition on silence matchers that matches the ones whose label and value aren't in the given labels.
+func notOneOfLabels(labels model.Labels) string {
+	if len(labels) == 0 {
+		return "1"
+	}
+
+	return "(label, value) NOT IN (VALUES " + strings.TrimSuffix(strings.Repeat("(?, ?), ", len(labels)), ", ") + ")"
+}
+
+// labelArgs returns the names and values of the given labels, for the placeholders of notOneOfLabels.
+func labelArgs(labels model.Labels) []any {
+	args := make([]any, 0, 2*len(labels))
+	for name, value := range labels {
+		args = append(args, name, value)
+	}
+
+	return args
+}
@@ -0,0 +1,260 @@
+package kioradb_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb"
+	"github.com/sinkingpoint/kiora/lib/kiora/kioradb/query"
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLiteDB(t *testing.T, path string) *kioradb.SQLiteDB {
+	t.Helper()
+	db, err := kioradb.NewSQLiteDB(path, zerolog.Nop())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLiteDBQueryAlerts(t *testing.T) {
+	db := newSQLiteDB(t, filepath.Join(t.TempDir(), "kiora.sqlite"))
+
+	start := time.Unix(1000, 0)
+	statuses := []model.AlertStatus{model.AlertStatusFiring, model.AlertStatusResolved, model.AlertStatusAcked}
+	alerts := []model.Alert{}
+	for i := 0; i < 30; i++ {
+		alert := model.Alert{
+			Labels: model.Labels{
+				"alertname": fmt.Sprintf("alert-%d", i%5),
+				"instance":  fmt.Sprintf("instance-%02d", i),
+			},
+			Status:    statuses[i%len(statuses)],
+			StartTime: start.Add(time.Duration(i%7) * time.Minute),
+		}
+
+		// Some alerts are missing labels, to check that they sort the same way.
+		if i%4 != 0 {
+			alert.Labels["team"] = fmt.Sprintf("team-%d", i%3)
+		}
+
+		if i%2 == 0 {
+			alert.LastNotifyTime = start.Add(time.Duration(i) * time.Minute)
+		}
+
+		require.NoError(t, alert.Materialise())
+		alerts = append(alerts, alert)
+	}
+
+	require.NoError(t, db.StoreAlerts(context.Background(), alerts...))
+
+	// Update an alert, to make sure that it's replaced rather than duplicated.
+	alerts[0].Status = model.AlertStatusTimedOut
+	require.NoError(t, db.StoreAlerts(context.Background(), alerts[0]))
+
+	regexMatcher, err := model.LabelValueRegexMatcher("alertname", "alert-[12]")
+	require.NoError(t, err)
+
+	comparison, err := query.FieldComparison("team", query.ComparisonNotEqual, "team-1")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		filter query.AlertFilter
+		opts   []query.QueryOption
+	}{
+		{name: "everything", filter: query.MatchAll()},
+		{name: "exact", filter: query.ExactLabelMatch(alerts[3].Labels)},
+		{name: "partial", filter: query.PartialLabelMatch(model.Labels{"alertname": "alert-1", "team": "team-1"})},
+		{name: "status", filter: query.Status(model.AlertStatusTimedOut)},
+		{name: "id", filter: query.ID(alerts[7].ID)},
+		{name: "matcher", filter: query.Matcher(model.Matcher{Label: "team", Value: "team-2"})},
+		{name: "negative matcher", filter: query.Matcher(model.Matcher{Label: "team", Value: "team-2", IsNegative: true})},
+		{name: "regex matcher", filter: query.Matcher(regexMatcher)},
+		{name: "last notify time", filter: query.LastNotifyTimeWithin(start.Add(4*time.Minute), start.Add(10*time.Minute))},
+		{name: "field comparison", filter: comparison},
+		{name: "any", filter: query.AnyAlerts(query.Status(model.AlertStatusAcked), query.Matcher(regexMatcher))},
+		{name: "not", filter: query.NotAlerts(query.PartialLabelMatch(model.Labels{"alertname": "alert-0"}))},
+		{name: "not with a regex", filter: query.NotAlerts(query.Matcher(regexMatcher))},
+		{name: "missing a label", filter: query.NotAlerts(query.Matcher(model.Matcher{Label: "team", Value: "", IsNegative: true}))},
+		{
+			name:   "unindexed filters",
+			filter: query.AlertFilterFunc(func(ctx context.Context, alert *model.Alert) bool { return alert.Labels["instance"] > "instance-20" }),
+		},
+		{
+			name:   "sorted by time, paginated",
+			filter: query.AllAlerts(query.Status(model.AlertStatusFiring), query.PartialLabelMatch(model.Labels{})),
+			opts:   []query.QueryOption{query.OrderBy([]string{"__starts_at__"}, query.OrderDesc), query.Limit(3), query.Offset(2)},
+		},
+		{
+			name:   "sorted by a missing label",
+			filter: query.MatchAll(),
+			opts:   []query.QueryOption{query.OrderBy([]string{"team", "__status__"}, query.OrderAsc), query.Limit(20)},
+		},
+		{
+			name:   "sorted by a missing label, descending",
+			filter: query.MatchAll(),
+			opts:   []query.QueryOption{query.OrderBy([]string{"team"}, query.OrderDesc), query.Offset(5)},
+		},
+		{
+			name:   "sorted by an annotation, with a regex",
+			filter: query.Matcher(regexMatcher),
+			opts:   []query.QueryOption{query.OrderBy([]string{"__annotation_summary__"}, query.OrderAsc), query.Limit(4)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := query.NewAlertQuery(tt.filter, tt.opts...)
+
+			// Work out what the query should return in Go, and make sure that SQLite agrees.
+			expected := []string{}
+			matched := []model.Alert{}
+			for i := range alerts {
+				if tt.filter.MatchesAlert(context.Background(), &alerts[i]) {
+					matched = append(matched, alerts[i])
+				}
+			}
+
+			sort.Sort(query.SortAlertsByFields(matched, q.OrderBy, q.Order))
+			for i, alert := range matched {
+				if i >= q.Offset && (q.Limit == 0 || i < q.Offset+q.Limit) {
+					expected = append(expected, alert.Labels["instance"])
+				}
+			}
+
+			got := []string{}
+			for _, alert := range db.QueryAlerts(context.Background(), q) {
+				got = append(got, alert.Labels["instance"])
+			}
+
+			if len(tt.opts) == 0 {
+				require.ElementsMatch(t, expected, got)
+			} else {
+				require.Equal(t, expected, got)
+			}
+
+			// Pages should be the same whether SQLite paginates them, or we do.
+			expectedPage, err := query.PaginateAlerts(matched, q.Query)
+			require.NoError(t, err)
+
+			gotPage, err := db.QueryAlertPage(context.Background(), q)
+			require.NoError(t, err)
+			require.Equal(t, expectedPage.Total, gotPage.Total)
+			require.Equal(t, expectedPage.NextCursor, gotPage.NextCursor)
+			require.Equal(t, len(expectedPage.Items), len(gotPage.Items))
+			for i := range expectedPage.Items {
+				require.Equal(t, expectedPage.Items[i].Labels, gotPage.Items[i].Labels)
+			}
+		})
+	}
+}
+
+func TestSQLiteDBQuerySilences(t *testing.T) {
+	db := newSQLiteDB(t, filepath.Join(t.TempDir(), "kiora.sqlite"))
+
+	regexMatcher, err := model.LabelValueRegexMatcher("alertname", "disk.*")
+	require.NoError(t, err)
+
+	require.NoError(t, db.StoreSilences(context.Background(),
+		model.Silence{ID: "team", Matchers: []model.Matcher{{Label: "team", Value: "foo"}}},
+		model.Silence{ID: "team-and-alert", Matchers: []model.Matcher{{Label: "team", Value: "foo"}, {Label: "alertname", Value: "cpu"}}},
+		model.Silence{ID: "regex", Matchers: []model.Matcher{regexMatcher}},
+		model.Silence{ID: "updated", Matchers: []model.Matcher{{Label: "team", Value: "bar"}}},
+		model.Silence{ID: "tenanted", Tenant: "foo", Matchers: []model.Matcher{{Label: "service", Value: "web"}}},
+	))
+
+	// Change a silence's matchers, so that the old ones have to be removed.
+	require.NoError(t, db.StoreSilences(context.Background(), model.Silence{ID: "updated", Matchers: []model.Matcher{{Label: "team", Value: "baz"}}}))
+
+	tests := []struct {
+		name     string
+		filter   query.SilenceFilter
+		expected []string
+	}{
+		{
+			name:     "everything",
+			filter:   query.MatchAll(),
+			expected: []string{"regex", "team", "team-and-alert", "tenanted", "updated"},
+		},
+		{
+			name:     "tenant",
+			filter:   query.SilenceTenant("foo"),
+			expected: []string{"regex", "team", "team-and-alert", "tenanted", "updated"},
+		},
+		{
+			name:     "another tenant",
+			filter:   query.SilenceTenant("bar"),
+			expected: []string{"regex", "team", "team-and-alert", "updated"},
+		},
+		{
+			name:     "partial",
+			filter:   query.PartialLabelMatch(model.Labels{"team": "foo", "alertname": "cpu"}),
+			expected: []string{"team", "team-and-alert"},
+		},
+		{
+			name:     "partial with regexes",
+			filter:   query.PartialLabelMatch(model.Labels{"team": "foo", "alertname": "disk_full"}),
+			expected: []string{"regex", "team"},
+		},
+		{
+			name:     "partial with an updated silence",
+			filter:   query.PartialLabelMatch(model.Labels{"team": "bar"}),
+			expected: []string{},
+		},
+		{
+			name:     "matcher",
+			filter:   query.Matcher(model.Matcher{Label: "team", Value: "baz"}),
+			expected: []string{"updated"},
+		},
+		{
+			name:     "id with unindexed filters",
+			filter:   query.AllSilences(query.ID("regex"), query.SilenceIsActive()),
+			expected: []string{"regex"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := []string{}
+			for _, silence := range db.QuerySilences(context.Background(), query.NewSilenceQuery(tt.filter)) {
+				ids = append(ids, silence.ID)
+			}
+
+			require.Equal(t, tt.expected, ids)
+		})
+	}
+}
+
+func TestSQLiteDBMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kiora.sqlite")
+	db, err := kioradb.NewSQLiteDB(path, zerolog.Nop())
+	require.NoError(t, err)
+
+	alert := model.Alert{Labels: model.Labels{"alertname": "foo"}, Status: model.AlertStatusFiring}
+	require.NoError(t, alert.Materialise())
+	require.NoError(t, db.StoreAlerts(context.Background(), alert))
+	require.NoError(t, db.Close())
+
+	// Reopening the database shouldn't try to migrate it again.
+	db = newSQLiteDB(t, path)
+	require.Len(t, db.QueryAlerts(context.Background(), query.NewAlertQuery(query.ID(alert.ID))), 1)
+	require.NoError(t, db.Close())
+
+	// Databases from newer versions of kiora should be refused, rather than misread.
+	raw, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	_, err = raw.Exec("PRAGMA user_version = 1000")
+	require.NoError(t, err)
+	require.NoError(t, raw.Close())
+
+	_, err = kioradb.NewSQLiteDB(path, zerolog.Nop())
+	require.ErrorContains(t, err, "database schema version 1000 is newer")
+}
@@ -0,0 +1,478 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: kiora.proto
+
+package kiorapb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Kiora_GetAlerts_FullMethodName        = "/kiora.v1.Kiora/GetAlerts"
+	Kiora_PostAlerts_FullMethodName       = "/kiora.v1.Kiora/PostAlerts"
+	Kiora_QueryAlertStats_FullMethodName  = "/kiora.v1.Kiora/QueryAlertStats"
+	Kiora_GetSilences_FullMethodName      = "/kiora.v1.Kiora/GetSilences"
+	Kiora_PostSilence_FullMethodName      = "/kiora.v1.Kiora/PostSilence"
+	Kiora_AckAlert_FullMethodName         = "/kiora.v1.Kiora/AckAlert"
+	Kiora_GetClusterStatus_FullMethodName = "/kiora.v1.Kiora/GetClusterStatus"
+	Kiora_WatchAlerts_FullMethodName      = "/kiora.v1.Kiora/WatchAlerts"
+	Kiora_WatchSilences_FullMethodName    = "/kiora.v1.Kiora/WatchSilences"
+)
+
+// KioraClient is the client API for Kiora service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type KioraClient interface {
+	// GetAlerts returns the alerts matching the given matchers.
+	GetAlerts(ctx context.Context, in *GetAlertsRequest, opts ...grpc.CallOption) (*GetAlertsResponse, error)
+	// PostAlerts adds or updates the given alerts. Alerts that fail validation are rejected individually, without blocking the rest of the batch.
+	PostAlerts(ctx context.Context, in *PostAlertsRequest, opts ...grpc.CallOption) (*PostAlertsResponse, error)
+	// QueryAlertStats runs an aggregation over the alerts.
+	QueryAlertStats(ctx context.Context, in *QueryAlertStatsRequest, opts ...grpc.CallOption) (*QueryAlertStatsResponse, error)
+	// GetSilences returns the silences matching the given matchers.
+	GetSilences(ctx context.Context, in *GetSilencesRequest, opts ...grpc.CallOption) (*GetSilencesResponse, error)
+	// PostSilence adds or updates a silence.
+	PostSilence(ctx context.Context, in *PostSilenceRequest, opts ...grpc.CallOption) (*PostSilenceResponse, error)
+	// AckAlert acknowledges an alert.
+	AckAlert(ctx context.Context, in *AckAlertRequest, opts ...grpc.CallOption) (*AckAlertResponse, error)
+	// GetClusterStatus returns the nodes in the cluster.
+	GetClusterStatus(ctx context.Context, in *GetClusterStatusRequest, opts ...grpc.CallOption) (*GetClusterStatusResponse, error)
+	// WatchAlerts streams changes to alerts, and their acknowledgements.
+	WatchAlerts(ctx context.Context, in *WatchAlertsRequest, opts ...grpc.CallOption) (Kiora_WatchAlertsClient, error)
+	// WatchSilences streams changes to silences.
+	WatchSilences(ctx context.Context, in *WatchSilencesRequest, opts ...grpc.CallOption) (Kiora_WatchSilencesClient, error)
+}
+
+type kioraClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewKioraClient(cc grpc.ClientConnInterface) KioraClient {
+	return &kioraClient{cc}
+}
+
+func (c *kioraClient) GetAlerts(ctx context.Context, in *GetAlertsRequest, opts ...grpc.CallOption) (*GetAlertsResponse, error) {
+	out := new(GetAlertsResponse)
+	err := c.cc.Invoke(ctx, Kiora_GetAlerts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kioraClient) PostAlerts(ctx context.Context, in *PostAlertsRequest, opts ...grpc.CallOption) (*PostAlertsResponse, error) {
+	out := new(PostAlertsResponse)
+	err := c.cc.Invoke(ctx, Kiora_PostAlerts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kioraClient) QueryAlertStats(ctx context.Context, in *QueryAlertStatsRequest, opts ...grpc.CallOption) (*QueryAlertStatsResponse, error) {
+	out := new(QueryAlertStatsResponse)
+	err := c.cc.Invoke(ctx, Kiora_QueryAlertStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kioraClient) GetSilences(ctx context.Context, in *GetSilencesRequest, opts ...grpc.CallOption) (*GetSilencesResponse, error) {
+	out := new(GetSilencesResponse)
+	err := c.cc.Invoke(ctx, Kiora_GetSilences_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kioraClient) PostSilence(ctx context.Context, in *PostSilenceRequest, opts ...grpc.CallOption) (*PostSilenceResponse, error) {
+	out := new(PostSilenceResponse)
+	err := c.cc.Invoke(ctx, Kiora_PostSilence_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kioraClient) AckAlert(ctx context.Context, in *AckAlertRequest, opts ...grpc.CallOption) (*AckAlertResponse, error) {
+	out := new(AckAlertResponse)
+	err := c.cc.Invoke(ctx, Kiora_AckAlert_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kioraClient) GetClusterStatus(ctx context.Context, in *GetClusterStatusRequest, opts ...grpc.CallOption) (*GetClusterStatusResponse, error) {
+	out := new(GetClusterStatusResponse)
+	err := c.cc.Invoke(ctx, Kiora_GetClusterStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kioraClient) WatchAlerts(ctx context.Context, in *WatchAlertsRequest, opts ...grpc.CallOption) (Kiora_WatchAlertsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Kiora_ServiceDesc.Streams[0], Kiora_WatchAlerts_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &kioraWatchAlertsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Kiora_WatchAlertsClient interface {
+	Recv() (*AlertEvent, error)
+	grpc.ClientStream
+}
+
+type kioraWatchAlertsClient struct {
+	grpc.ClientStream
+}
+
+func (x *kioraWatchAlertsClient) Recv() (*AlertEvent, error) {
+	m := new(AlertEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *kioraClient) WatchSilences(ctx context.Context, in *WatchSilencesRequest, opts ...grpc.CallOption) (Kiora_WatchSilencesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Kiora_ServiceDesc.Streams[1], Kiora_WatchSilences_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &kioraWatchSilencesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Kiora_WatchSilencesClient interface {
+	Recv() (*SilenceEvent, error)
+	grpc.ClientStream
+}
+
+type kioraWatchSilencesClient struct {
+	grpc.ClientStream
+}
+
+func (x *kioraWatchSilencesClient) Recv() (*SilenceEvent, error) {
+	m := new(SilenceEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// KioraServer is the server API for Kiora service.
+// All implementations must embed UnimplementedKioraServer
+// for forward compatibility
+type KioraServer interface {
+	// GetAlerts returns the alerts matching the given matchers.
+	GetAlerts(context.Context, *GetAlertsRequest) (*GetAlertsResponse, error)
+	// PostAlerts adds or updates the given alerts. Alerts that fail validation are rejected individually, without blocking the rest of the batch.
+	PostAlerts(context.Context, *PostAlertsRequest) (*PostAlertsResponse, error)
+	// QueryAlertStats runs an aggregation over the alerts.
+	QueryAlertStats(context.Context, *QueryAlertStatsRequest) (*QueryAlertStatsResponse, error)
+	// GetSilences returns the silences matching the given matchers.
+	GetSilences(context.Context, *GetSilencesRequest) (*GetSilencesResponse, error)
+	// PostSilence adds or updates a silence.
+	PostSilence(context.Context, *PostSilenceRequest) (*PostSilenceResponse, error)
+	// AckAlert acknowledges an alert.
+	AckAlert(context.Context, *AckAlertRequest) (*AckAlertResponse, error)
+	// GetClusterStatus returns the nodes in the cluster.
+	GetClusterStatus(context.Context, *GetClusterStatusRequest) (*GetClusterStatusResponse, error)
+	// WatchAlerts streams changes to alerts, and their acknowledgements.
+	WatchAlerts(*WatchAlertsRequest, Kiora_WatchAlertsServer) error
+	// WatchSilences streams changes to silences.
+	WatchSilences(*WatchSilencesRequest, Kiora_WatchSilencesServer) error
+	mustEmbedUnimplementedKioraServer()
+}
+
+// UnimplementedKioraServer must be embedded to have forward compatible implementations.
+type UnimplementedKioraServer struct {
+}
+
+func (UnimplementedKioraServer) GetAlerts(context.Context, *GetAlertsRequest) (*GetAlertsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAlerts not implemented")
+}
+func (UnimplementedKioraServer) PostAlerts(context.Context, *PostAlertsRequest) (*PostAlertsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PostAlerts not implemented")
+}
+func (UnimplementedKioraServer) QueryAlertStats(context.Context, *QueryAlertStatsRequest) (*QueryAlertStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryAlertStats not implemented")
+}
+func (UnimplementedKioraServer) GetSilences(context.Context, *GetSilencesRequest) (*GetSilencesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSilences not implemented")
+}
+func (UnimplementedKioraServer) PostSilence(context.Context, *PostSilenceRequest) (*PostSilenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PostSilence not implemented")
+}
+func (UnimplementedKioraServer) AckAlert(context.Context, *AckAlertRequest) (*AckAlertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AckAlert not implemented")
+}
+func (UnimplementedKioraServer) GetClusterStatus(context.Context, *GetClusterStatusRequest) (*GetClusterStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClusterStatus not implemented")
+}
+func (UnimplementedKioraServer) WatchAlerts(*WatchAlertsRequest, Kiora_WatchAlertsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchAlerts not implemented")
+}
+func (UnimplementedKioraServer) WatchSilences(*WatchSilencesRequest, Kiora_WatchSilencesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchSilences not implemented")
+}
+func (UnimplementedKioraServer) mustEmbedUnimplementedKioraServer() {}
+
+// UnsafeKioraServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to KioraServer will
+// result in compilation errors.
+type UnsafeKioraServer interface {
+	mustEmbedUnimplementedKioraServer()
+}
+
+func RegisterKioraServer(s grpc.ServiceRegistrar, srv KioraServer) {
+	s.RegisterService(&Kiora_ServiceDesc, srv)
+}
+
+func _Kiora_GetAlerts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAlertsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KioraServer).GetAlerts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kiora_GetAlerts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KioraServer).GetAlerts(ctx, req.(*GetAlertsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kiora_PostAlerts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostAlertsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KioraServer).PostAlerts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kiora_PostAlerts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KioraServer).PostAlerts(ctx, req.(*PostAlertsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kiora_QueryAlertStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryAlertStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KioraServer).QueryAlertStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kiora_QueryAlertStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KioraServer).QueryAlertStats(ctx, req.(*QueryAlertStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kiora_GetSilences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSilencesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KioraServer).GetSilences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kiora_GetSilences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KioraServer).GetSilences(ctx, req.(*GetSilencesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kiora_PostSilence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostSilenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KioraServer).PostSilence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kiora_PostSilence_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KioraServer).PostSilence(ctx, req.(*PostSilenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kiora_AckAlert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AckAlertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KioraServer).AckAlert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kiora_AckAlert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KioraServer).AckAlert(ctx, req.(*AckAlertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kiora_GetClusterStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClusterStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KioraServer).GetClusterStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kiora_GetClusterStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KioraServer).GetClusterStatus(ctx, req.(*GetClusterStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kiora_WatchAlerts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchAlertsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KioraServer).WatchAlerts(m, &kioraWatchAlertsServer{stream})
+}
+
+type Kiora_WatchAlertsServer interface {
+	Send(*AlertEvent) error
+	grpc.ServerStream
+}
+
+type kioraWatchAlertsServer struct {
+	grpc.ServerStream
+}
+
+func (x *kioraWatchAlertsServer) Send(m *AlertEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Kiora_WatchSilences_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchSilencesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KioraServer).WatchSilences(m, &kioraWatchSilencesServer{stream})
+}
+
+type Kiora_WatchSilencesServer interface {
+	Send(*SilenceEvent) error
+	grpc.ServerStream
+}
+
+type kioraWatchSilencesServer struct {
+	grpc.ServerStream
+}
+
+func (x *kioraWatchSilencesServer) Send(m *SilenceEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Kiora_ServiceDesc is the grpc.ServiceDesc for Kiora service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Kiora_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kiora.v1.Kiora",
+	HandlerType: (*KioraServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAlerts",
+			Handler:    _Kiora_GetAlerts_Handler,
+		},
+		{
+			MethodName: "PostAlerts",
+			Handler:    _Kiora_PostAlerts_Handler,
+		},
+		{
+			MethodName: "QueryAlertStats",
+			Handler:    _Kiora_QueryAlertStats_Handler,
+		},
+		{
+			MethodName: "GetSilences",
+			Handler:    _Kiora_GetSilences_Handler,
+		},
+		{
+			MethodName: "PostSilence",
+			Handler:    _Kiora_PostSilence_Handler,
+		},
+		{
+			MethodName: "AckAlert",
+			Handler:    _Kiora_AckAlert_Handler,
+		},
+		{
+			MethodName: "GetClusterStatus",
+			Handler:    _Kiora_GetClusterStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchAlerts",
+			Handler:       _Kiora_WatchAlerts_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchSilences",
+			Handler:       _Kiora_WatchSilences_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "kiora.proto",
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -16,6 +17,9 @@ import (
 // if we haven't seen any other information about it.
 const DEFAULT_TIMEOUT_INTERVAL = 12 * time.Hour
 
+// annotationFieldPrefix is the prefix of the fields that expose annotations to filters, e.g. `__annotation_runbook__`.
+const annotationFieldPrefix = "__annotation_"
+
 // AlertStatus is the current status of an alert in Kiora.
 type AlertStatus string
 
@@ -36,7 +40,8 @@ const (
 	AlertStatusSilenced AlertStatus = "silenced"
 )
 
-func (s AlertStatus) isValid() bool {
+// IsValid returns true if the status is one of the statuses that alerts can have.
+func (s AlertStatus) IsValid() bool {
 	switch s {
 	case AlertStatusFiring, AlertStatusAcked, AlertStatusResolved, AlertStatusTimedOut, AlertStatusSilenced:
 		return true
@@ -86,7 +91,7 @@ func (a *Alert) validate() error {
 		return errors.New("missing annotations in alert")
 	}
 
-	if !a.Status.isValid() {
+	if !a.Status.IsValid() {
 		return fmt.Errorf("invalid alert status in alert: %q", a.Status)
 	}
 
@@ -170,6 +175,20 @@ func (a *Alert) Acknowledge(ack *AlertAcknowledgement) error {
 	return nil
 }
 
+// AnnotationField returns the name of the field that exposes the given annotation to filters.
+func AnnotationField(name string) string {
+	return annotationFieldPrefix + name + "__"
+}
+
+// annotationFromField returns the annotation name referenced by the given field name, if it is an annotation field.
+func annotationFromField(field string) (string, bool) {
+	if !strings.HasPrefix(field, annotationFieldPrefix) || !strings.HasSuffix(field, "__") || len(field) <= len(annotationFieldPrefix)+2 {
+		return "", false
+	}
+
+	return field[len(annotationFieldPrefix) : len(field)-2], true
+}
+
 func (a *Alert) Fields() map[string]any {
 	fields := map[string]any{}
 
@@ -177,6 +196,10 @@ func (a *Alert) Fields() map[string]any {
 		fields[k] = v
 	}
 
+	for k, v := range a.Annotations {
+		fields[AnnotationField(k)] = v
+	}
+
 	fields["__id__"] = a.ID
 	fields["__status__"] = a.Status
 	fields["__starts_at__"] = a.StartTime
@@ -184,6 +207,11 @@ func (a *Alert) Fields() map[string]any {
 	fields["__timeout_deadline__"] = a.TimeOutDeadline
 	fields["__last_notify_time__"] = a.LastNotifyTime
 
+	if a.Acknowledgement != nil {
+		fields["__ack_creator__"] = a.Acknowledgement.Creator
+		fields["__ack_comment__"] = a.Acknowledgement.Comment
+	}
+
 	return fields
 }
 
@@ -206,6 +234,26 @@ func (a *Alert) Field(name string) (any, error) {
 		return a.TimeOutDeadline, nil
 	case "__last_notify_time__":
 		return a.LastNotifyTime, nil
+	case "__ack_creator__":
+		if a.Acknowledgement == nil {
+			return "", fmt.Errorf("alert %q has not been acknowledged", a.ID)
+		}
+
+		return a.Acknowledgement.Creator, nil
+	case "__ack_comment__":
+		if a.Acknowledgement == nil {
+			return "", fmt.Errorf("alert %q has not been acknowledged", a.ID)
+		}
+
+		return a.Acknowledgement.Comment, nil
+	}
+
+	if annotation, ok := annotationFromField(name); ok {
+		if val, ok := a.Annotations[annotation]; ok {
+			return val, nil
+		}
+
+		return "", fmt.Errorf("annotation %q doesn't exist", annotation)
 	}
 
 	return "", fmt.Errorf("label %q doesn't exist", name)
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditAction is a kind of change that can be made through the API.
+type AuditAction string
+
+const (
+	AuditActionPostAlerts   AuditAction = "post_alerts"
+	AuditActionPostSilence  AuditAction = "post_silence"
+	AuditActionAckAlert     AuditAction = "ack_alert"
+	AuditActionReloadConfig AuditAction = "reload_config"
+)
+
+// AuditOutcome is what happened to a change that was made through the API.
+type AuditOutcome string
+
+const (
+	// AuditOutcomeAccepted means that the change passed validation, and was broadcast.
+	AuditOutcomeAccepted AuditOutcome = "accepted"
+
+	// AuditOutcomePartiallyAccepted means that some of the change (e.g. some of a batch of alerts) was rejected, and the rest was broadcast.
+	AuditOutcomePartiallyAccepted AuditOutcome = "partially_accepted"
+
+	// AuditOutcomeRejected means that the change failed validation, so nothing was broadcast.
+	AuditOutcomeRejected AuditOutcome = "rejected"
+
+	// AuditOutcomeFailed means that the change passed validation, but applying it failed.
+	AuditOutcomeFailed AuditOutcome = "failed"
+)
+
+// AuditEntry is a record of a change that was made through the API.
+type AuditEntry struct {
+	// ID is the unique identifier of the entry.
+	ID string `json:"id"`
+
+	// Time is the time at which the change was made.
+	Time time.Time `json:"time"`
+
+	// Actor is the name of the identity that made the change, if the request was authenticated.
+	Actor string `json:"actor,omitempty"`
+
+	// AuthMethod is the way that the actor was authenticated, e.g. `token`, `basic`, or `jwt`.
+	AuthMethod string `json:"authMethod,omitempty"`
+
+	// Tenant is the tenant that the request was scoped to.
+	Tenant string `json:"tenant,omitempty"`
+
+	// SourceIP is the address that the request came from.
+	SourceIP string `json:"sourceIP,omitempty"`
+
+	// ForwardedFor is the X-Forwarded-For header of the request, if it was made through a proxy.
+	ForwardedFor string `json:"forwardedFor,omitempty"`
+
+	// Action is the kind of change that was made.
+	Action AuditAction `json:"action"`
+
+	// Request is the change that was requested, before it was validated or modified by Kiora.
+	Request json.RawMessage `json:"request,omitempty"`
+
+	// Outcome is what happened to the change.
+	Outcome AuditOutcome `json:"outcome"`
+
+	// Errors are the reasons that (some of) the change was rejected or failed.
+	Errors []string `json:"errors,omitempty"`
+
+	// Broadcast is the data that was broadcast to the cluster as a result of the change, if any.
+	Broadcast json.RawMessage `json:"broadcast,omitempty"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// EventType is the kind of change that an Event describes.
+type EventType string
+
+const (
+	// EventTypeAlert is sent when an alert is created, or its status, annotations, or times change.
+	EventTypeAlert EventType = "alert"
+
+	// EventTypeSilence is sent when a silence is created or updated.
+	EventTypeSilence EventType = "silence"
+
+	// EventTypeAck is sent when an alert is acknowledged.
+	EventTypeAck EventType = "ack"
+)
+
+// Event is a change to the alerts, silences, or acknowledgements in Kiora.
+type Event struct {
+	// ID identifies the event, so that consumers can resume a stream of events after the last one they saw.
+	ID string `json:"id"`
+
+	// Type is the kind of change that the event describes.
+	Type EventType `json:"type"`
+
+	// Time is the time at which the change was processed.
+	Time time.Time `json:"time"`
+
+	// Alert is the alert after the change, for alert and ack events.
+	Alert *Alert `json:"alert,omitempty"`
+
+	// Silence is the silence after the change, for silence events.
+	Silence *Silence `json:"silence,omitempty"`
+
+	// Acknowledgement is the acknowledgement that was made, for ack events.
+	Acknowledgement *AlertAcknowledgement `json:"acknowledgement,omitempty"`
+}
@@ -43,6 +43,21 @@ func (m *Matcher) Negate() *Matcher {
 	return m
 }
 
+// String returns the matcher in the same form that UnmarshalText accepts, e.g. `foo=~"ba.*"`.
+func (m *Matcher) String() string {
+	op := "="
+	switch {
+	case m.IsRegex && m.IsNegative:
+		op = "!~"
+	case m.IsRegex:
+		op = "=~"
+	case m.IsNegative:
+		op = "!="
+	}
+
+	return m.Label + op + "\"" + strings.ReplaceAll(m.Value, "\"", "\\\"") + "\""
+}
+
 func (m *Matcher) UnmarshalText(raw string) error {
 	var parts []string
 
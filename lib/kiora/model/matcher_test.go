@@ -64,3 +64,21 @@ func TestMatchers(t *testing.T) {
 		})
 	}
 }
+
+func TestMatcherStringRoundTrip(t *testing.T) {
+	matchers := []string{
+		`foo="bar"`,
+		`foo!="bar"`,
+		`foo=~"ba.*"`,
+		`foo!~"ba.*"`,
+		`foo="some \"quoted\" value"`,
+	}
+
+	for _, raw := range matchers {
+		t.Run(raw, func(t *testing.T) {
+			matcher := model.Matcher{}
+			require.NoError(t, matcher.UnmarshalText(raw))
+			require.Equal(t, raw, matcher.String())
+		})
+	}
+}
@@ -3,6 +3,7 @@ package model
 import (
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,6 +11,9 @@ import (
 	"github.com/sinkingpoint/kiora/internal/stubs"
 )
 
+// matcherFieldPrefix is the prefix of the fields that expose silence matchers to filters, e.g. `__matcher_alertname__`.
+const matcherFieldPrefix = "__matcher_"
+
 type Silence struct {
 	// ID is the unique identifier of the silence.
 	ID string `json:"id"`
@@ -28,6 +32,9 @@ type Silence struct {
 
 	// Matchers is a list of matchers that must all match an alert for it to be silenced.
 	Matchers []Matcher `json:"matchers"`
+
+	// Tenant is the tenant that created the silence. Silences only match alerts in the same tenant.
+	Tenant string `json:"tenant,omitempty"`
 }
 
 func (s *Silence) validate() error {
@@ -75,15 +82,53 @@ func (s *Silence) Matches(l Labels) bool {
 	return true
 }
 
+// MatcherField returns the name of the field that exposes the value of the silence matcher on the given label to filters.
+func MatcherField(label string) string {
+	return matcherFieldPrefix + label + "__"
+}
+
+// equalityMatcher returns the first matcher in the silence that only matches alerts where the given label is a single value. Regex and
+// negative matchers can match other values, so exposing their values to filters would let silences through that are broader than the
+// filters allow.
+func (s *Silence) equalityMatcher(label string) (*Matcher, bool) {
+	for i := range s.Matchers {
+		if s.Matchers[i].Label == label && !s.Matchers[i].IsRegex && !s.Matchers[i].IsNegative {
+			return &s.Matchers[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// matcherString returns the matchers of the silence in their textual form, comma separated.
+func (s *Silence) matcherString() string {
+	matchers := make([]string, 0, len(s.Matchers))
+	for i := range s.Matchers {
+		matchers = append(matchers, s.Matchers[i].String())
+	}
+
+	return strings.Join(matchers, ",")
+}
+
 func (s *Silence) Fields() map[string]any {
-	return map[string]any{
+	fields := map[string]any{
 		"__id__":        s.ID,
 		"__creator__":   s.Creator,
 		"__comment__":   s.Comment,
 		"__starts_at__": s.StartTime,
 		"__ends_at__":   s.EndTime,
 		"__duration__":  s.EndTime.Sub(s.StartTime),
+		"__matchers__":  s.matcherString(),
+		"__tenant__":    s.Tenant,
+	}
+
+	for _, matcher := range s.Matchers {
+		if equal, ok := s.equalityMatcher(matcher.Label); ok {
+			fields[MatcherField(matcher.Label)] = equal.Value
+		}
 	}
+
+	return fields
 }
 
 func (s *Silence) Field(name string) (any, error) {
@@ -104,6 +149,19 @@ func (s *Silence) Field(name string) (any, error) {
 		}
 
 		return s.EndTime.Sub(s.StartTime), nil
+	case "__matchers__":
+		return s.matcherString(), nil
+	case "__tenant__":
+		return s.Tenant, nil
+	}
+
+	if strings.HasPrefix(name, matcherFieldPrefix) && strings.HasSuffix(name, "__") && len(name) > len(matcherFieldPrefix)+2 {
+		label := name[len(matcherFieldPrefix) : len(name)-2]
+		if matcher, ok := s.equalityMatcher(label); ok {
+			return matcher.Value, nil
+		}
+
+		return "", fmt.Errorf("silence has no equality matcher on label %q", label)
 	}
 
 	return "", fmt.Errorf("silence %q doesn't exist", name)
@@ -0,0 +1,58 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/sinkingpoint/kiora/lib/kiora/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSilenceMatcherFields(t *testing.T) {
+	tests := []struct {
+		name          string
+		matchers      []model.Matcher
+		expectedValue string
+		expectedFound bool
+	}{
+		{
+			name:          "equality matchers are exposed",
+			matchers:      []model.Matcher{model.LabelValueEqualMatcher("env", "dev")},
+			expectedValue: "dev",
+			expectedFound: true,
+		},
+		{
+			name:     "regex matchers aren't exposed",
+			matchers: []model.Matcher{{Label: "env", Value: "dev", IsRegex: true}},
+		},
+		{
+			name:     "negative matchers aren't exposed",
+			matchers: []model.Matcher{{Label: "env", Value: "dev", IsNegative: true}},
+		},
+		{
+			name: "the first equality matcher is exposed",
+			matchers: []model.Matcher{
+				{Label: "env", Value: "prod", IsNegative: true},
+				model.LabelValueEqualMatcher("env", "dev"),
+				model.LabelValueEqualMatcher("env", "prod"),
+			},
+			expectedValue: "dev",
+			expectedFound: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			silence := model.Silence{Matchers: tt.matchers}
+			name := model.MatcherField("env")
+
+			value, err := silence.Field(name)
+			fieldValue, found := silence.Fields()[name]
+			require.Equal(t, tt.expectedFound, err == nil)
+			require.Equal(t, tt.expectedFound, found)
+			if tt.expectedFound {
+				require.Equal(t, tt.expectedValue, value)
+				require.Equal(t, tt.expectedValue, fieldValue)
+			}
+		})
+	}
+}
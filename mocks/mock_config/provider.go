@@ -120,6 +120,34 @@ func (mr *MockConfigMockRecorder) Globals() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Globals", reflect.TypeOf((*MockConfig)(nil).Globals))
 }
 
+// RouteAlert mocks base method.
+func (m *MockConfig) RouteAlert(ctx context.Context, alert *model.Alert) []config.Route {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RouteAlert", ctx, alert)
+	ret0, _ := ret[0].([]config.Route)
+	return ret0
+}
+
+// RouteAlert indicates an expected call of RouteAlert.
+func (mr *MockConfigMockRecorder) RouteAlert(ctx, alert interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RouteAlert", reflect.TypeOf((*MockConfig)(nil).RouteAlert), ctx, alert)
+}
+
+// TransformAlert mocks base method.
+func (m *MockConfig) TransformAlert(ctx context.Context, alert *model.Alert) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransformAlert", ctx, alert)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransformAlert indicates an expected call of TransformAlert.
+func (mr *MockConfigMockRecorder) TransformAlert(ctx, alert interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransformAlert", reflect.TypeOf((*MockConfig)(nil).TransformAlert), ctx, alert)
+}
+
 // ValidateData mocks base method.
 func (m *MockConfig) ValidateData(ctx context.Context, data config.Fielder) error {
 	m.ctrl.T.Helper()
@@ -2,10 +2,11 @@ package mock_config
 
 import gomock "github.com/golang/mock/gomock"
 
-// NewMockConfigAllowingEverything returns a MockConfig set up with a `ValidateData` method that returns nil.
+// NewMockConfigAllowingEverything returns a MockConfig set up with `ValidateData` and `TransformAlert` methods that return nil.
 func NewMockConfigAllowingEverything(ctrl *gomock.Controller) *MockConfig {
 	conf := NewMockConfig(ctrl)
 	conf.EXPECT().ValidateData(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	conf.EXPECT().TransformAlert(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	return conf
 }